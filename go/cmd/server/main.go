@@ -2,34 +2,146 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/blck-snwmn/hello-typespec/go/internal/handlers"
+	"github.com/blck-snwmn/hello-typespec/go/internal/inventory"
+	"github.com/blck-snwmn/hello-typespec/go/internal/jwt"
 	"github.com/blck-snwmn/hello-typespec/go/internal/middleware"
+	"github.com/blck-snwmn/hello-typespec/go/internal/oidc"
 	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
 	"github.com/blck-snwmn/hello-typespec/go/internal/store"
 )
 
 func main() {
+	// Handle "server migrate up|down|status"
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+	// Handle "server grant-admin <email>"
+	if len(os.Args) > 1 && os.Args[1] == "grant-admin" {
+		if err := runGrantAdmin(context.Background(), os.Args[2:]); err != nil {
+			log.Fatalf("Granting admin failed: %v", err)
+		}
+		return
+	}
+
 	// Initialize store
-	memoryStore := store.NewMemoryStore()
+	dataStore, closeStore, err := openStore(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to open store: %v", err)
+	}
+	defer closeStore()
+
+	// Product search is indexed in memory, which only sees the writes of this
+	// process, so no other server may use the database
+	if claimer, ok := dataStore.(store.Claimer); ok {
+		if err := claimer.Claim(context.Background()); err != nil {
+			log.Fatalf("Failed to claim store: %v", err)
+		}
+	}
+	// Bring the database schema up to date unless disabled
+	if migrator, ok := dataStore.(store.Migrator); ok && os.Getenv("AUTO_MIGRATE") != "false" {
+		if err := migrator.MigrateUp(context.Background()); err != nil {
+			log.Fatalf("Failed to migrate store: %v", err)
+		}
+	}
+	// Add the demo catalog and users to an empty store when SEED_DEMO_DATA=true
+	if os.Getenv("SEED_DEMO_DATA") == "true" {
+		if err := store.SeedDemoData(context.Background(), dataStore); err != nil {
+			log.Fatalf("Failed to seed demo data: %v", err)
+		}
+	}
 
 	// Initialize auth storage
-	authStore := storage.NewAuthStore()
+	authOpts, err := authOptions()
+	if err != nil {
+		log.Fatalf("Invalid token configuration: %v", err)
+	}
+	authStore := storage.NewAuthStore(dataStore, authOpts...)
+
+	// Drop expired sessions and tokens every SESSION_CLEANUP_INTERVAL until shutdown
+	cleanupInterval := time.Minute
+	if v := os.Getenv("SESSION_CLEANUP_INTERVAL"); v != "" {
+		cleanupInterval, err = time.ParseDuration(v)
+		if err != nil || cleanupInterval <= 0 {
+			log.Fatalf("Invalid SESSION_CLEANUP_INTERVAL: %q", v)
+		}
+	}
+	janitorCtx, stopJanitor := context.WithCancel(context.Background())
+	janitorDone := make(chan struct{})
+	go func() {
+		defer close(janitorDone)
+		authStore.RunJanitor(janitorCtx, cleanupInterval)
+	}()
+
+	// Cart reservations expire after RESERVATION_TTL (e.g. "10m")
+	reservationTTL := inventory.DefaultTTL
+	if v := os.Getenv("RESERVATION_TTL"); v != "" {
+		reservationTTL, err = time.ParseDuration(v)
+		if err != nil {
+			log.Fatalf("Invalid RESERVATION_TTL: %v", err)
+		}
+	}
+	reservations := inventory.NewReservations(reservationTTL, nil)
+
+	serverOpts := []handlers.ServerOption{handlers.WithReservations(reservations)}
+	// Reject PATCH and DELETE without If-Match when REQUIRE_IF_MATCH=true
+	if os.Getenv("REQUIRE_IF_MATCH") == "true" {
+		serverOpts = append(serverOpts, handlers.WithRequireIfMatch())
+	}
+	// Sign list cursors with CURSOR_SECRET (base64, at least 32 bytes) so that
+	// they survive restarts and work across instances
+	if v := os.Getenv("CURSOR_SECRET"); v != "" {
+		secret, err := base64.StdEncoding.DecodeString(v)
+		if err != nil || len(secret) < 32 {
+			log.Fatalf("Invalid CURSOR_SECRET: want at least 32 base64-encoded bytes")
+		}
+		serverOpts = append(serverOpts, handlers.WithCursorSecret(secret))
+	}
+	// Deliver password reset tokens to PASSWORD_RESET_WEBHOOK_URL; without it
+	// password resets are turned off
+	if webhookURL := os.Getenv("PASSWORD_RESET_WEBHOOK_URL"); webhookURL != "" {
+		serverOpts = append(serverOpts, handlers.WithPasswordResetSender(handlers.NewWebhookResetSender(webhookURL)))
+	}
+	// Log in through an OpenID Connect provider when OIDC_ISSUER is set
+	if issuer := os.Getenv("OIDC_ISSUER"); issuer != "" {
+		client, err := oidc.NewClient(context.Background(), oidc.Config{
+			Issuer:       issuer,
+			ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		})
+		if err != nil {
+			log.Fatalf("Failed to set up OIDC login: %v", err)
+		}
+		serverOpts = append(serverOpts, handlers.WithOIDC(client))
+	}
 
 	// Create server with handlers
-	server := handlers.NewServer(memoryStore, authStore)
+	server := handlers.NewServer(dataStore, authStore, serverOpts...)
 
 	// Create auth middleware
 	authMiddleware := middleware.AuthMiddleware(authStore)
 
-	// Create HTTP handler with generated server and wrap with custom middleware
-	handler := handlers.CreateHandlerWithMiddleware(server, authMiddleware)
+	// Create HTTP handler with generated server and wrap with custom middleware.
+	// Every operation is protected as its security requirements in the
+	// OpenAPI document say.
+	handler, err := handlers.CreateHandlerWithMiddleware(server, authMiddleware)
+	if err != nil {
+		log.Fatalf("Failed to set up routes: %v", err)
+	}
 
 	// Setup CORS middleware
 	corsHandler := corsMiddleware(handler)
@@ -67,15 +179,89 @@ func main() {
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
+	stopJanitor()
+	<-janitorDone
+
 	log.Println("Server exiting")
 }
 
+// authOptions configures access tokens from the environment. JWT_KEYS is a
+// comma-separated list of "kid:base64key" entries for JWT_ALGORITHM (HS256 by
+// default or EdDSA); the first key signs and the others are still accepted,
+// so that keys can be rotated. ACCESS_TOKEN_TTL and REFRESH_TOKEN_TTL set
+// token lifetimes (e.g. "15m", "720h"). Failed logins are throttled: within
+// LOGIN_FAILURE_WINDOW, LOGIN_MAX_FAILURES per email and client IP,
+// LOGIN_MAX_FAILURES_PER_EMAIL per email or LOGIN_MAX_FAILURES_PER_IP per IP
+// lock them out for LOGIN_LOCKOUT, doubling with each repeated lockout up to
+// LOGIN_MAX_LOCKOUT; a maximum of 0 turns its count off.
+func authOptions() ([]storage.AuthOption, error) {
+	var opts []storage.AuthOption
+
+	if spec := os.Getenv("JWT_KEYS"); spec != "" {
+		algorithm := os.Getenv("JWT_ALGORITHM")
+		if algorithm == "" {
+			algorithm = jwt.HS256
+		}
+		keys, err := jwt.ParseKeySet(algorithm, spec)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_KEYS: %w", err)
+		}
+		opts = append(opts, storage.WithSigningKeys(keys))
+	} else {
+		log.Println("JWT_KEYS is not set; using a random signing key, tokens will not survive a restart")
+	}
+
+	accessTTL, refreshTTL := storage.DefaultAccessTokenTTL, storage.DefaultRefreshTokenTTL
+	for name, ttl := range map[string]*time.Duration{"ACCESS_TOKEN_TTL": &accessTTL, "REFRESH_TOKEN_TTL": &refreshTTL} {
+		if v := os.Getenv(name); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("%s: want a positive duration, got %q", name, v)
+			}
+			*ttl = d
+		}
+	}
+	opts = append(opts, storage.WithTokenTTL(accessTTL, refreshTTL))
+
+	limits := storage.DefaultLoginLimits
+	for name, n := range map[string]*int{
+		"LOGIN_MAX_FAILURES":           &limits.MaxFailures,
+		"LOGIN_MAX_FAILURES_PER_EMAIL": &limits.MaxFailuresPerEmail,
+		"LOGIN_MAX_FAILURES_PER_IP":    &limits.MaxFailuresPerIP,
+	} {
+		if v := os.Getenv(name); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil || parsed < 0 {
+				return nil, fmt.Errorf("%s: want a non-negative integer, got %q", name, v)
+			}
+			*n = parsed
+		}
+	}
+	for name, d := range map[string]*time.Duration{
+		"LOGIN_FAILURE_WINDOW": &limits.Window,
+		"LOGIN_LOCKOUT":        &limits.BaseLockout,
+		"LOGIN_MAX_LOCKOUT":    &limits.MaxLockout,
+	} {
+		if v := os.Getenv(name); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil || parsed <= 0 {
+				return nil, fmt.Errorf("%s: want a positive duration, got %q", name, v)
+			}
+			*d = parsed
+		}
+	}
+	opts = append(opts, storage.WithLoginLimits(limits))
+
+	return opts, nil
+}
+
 // corsMiddleware adds CORS headers to responses
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, If-Match, If-None-Match, If-Modified-Since")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, Last-Modified, Retry-After")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+)
+
+// openStore creates the store selected by the STORE_DRIVER environment variable
+func openStore(ctx context.Context) (store.Store, func() error, error) {
+	switch driver := os.Getenv("STORE_DRIVER"); driver {
+	case "", "memory":
+		return store.NewMemoryStore(), func() error { return nil }, nil
+	case "sqlite":
+		path := os.Getenv("DATABASE_PATH")
+		if path == "" {
+			path = "hello-typespec.db"
+		}
+		sqliteStore, err := store.NewSQLiteStore(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("Using SQLite store at %s", path)
+		return sqliteStore, sqliteStore.Close, nil
+	case "postgres":
+		dsn := os.Getenv("DATABASE_URL")
+		if dsn == "" {
+			return nil, nil, errors.New("DATABASE_URL is required for the postgres store")
+		}
+		postgresStore, err := store.NewPostgresStore(ctx, dsn)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Println("Using PostgreSQL store")
+		return postgresStore, postgresStore.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown STORE_DRIVER %q", driver)
+	}
+}
+
+// runMigrate implements the "migrate up|down|status" subcommand
+func runMigrate(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: server migrate up|down|status")
+	}
+
+	dataStore, closeStore, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	migrator, ok := dataStore.(store.Migrator)
+	if !ok {
+		return fmt.Errorf("store driver %q has no schema to migrate", os.Getenv("STORE_DRIVER"))
+	}
+
+	switch args[0] {
+	case "up":
+		if err := migrator.MigrateUp(ctx); err != nil {
+			return err
+		}
+		log.Println("Migrations applied")
+	case "down":
+		if err := migrator.MigrateDown(ctx); err != nil {
+			return err
+		}
+		log.Println("Rolled back the latest migration")
+	case "status":
+		statuses, err := migrator.MigrationStatus(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+
+	return nil
+}
+
+// runGrantAdmin implements the "grant-admin <email>" subcommand, which makes
+// a registered user an admin
+func runGrantAdmin(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: server grant-admin <email>")
+	}
+
+	dataStore, closeStore, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+	if _, ok := dataStore.(store.Migrator); !ok {
+		return fmt.Errorf("store driver %q does not persist users", os.Getenv("STORE_DRIVER"))
+	}
+
+	err = storage.NewAuthStore(dataStore).GrantRole(ctx, args[0], storage.RoleAdmin)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fmt.Errorf("no user with email %q", args[0])
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("Granted admin to %s", args[0])
+	return nil
+}
@@ -20,8 +20,45 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/oapi-codegen/runtime"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// Defines values for ApiKeyScope.
+const (
+	CartsRead       ApiKeyScope = "carts:read"
+	CartsWrite      ApiKeyScope = "carts:write"
+	CategoriesWrite ApiKeyScope = "categories:write"
+	OrdersRead      ApiKeyScope = "orders:read"
+	OrdersWrite     ApiKeyScope = "orders:write"
+	ProductsWrite   ApiKeyScope = "products:write"
+	UsersRead       ApiKeyScope = "users:read"
+	UsersWrite      ApiKeyScope = "users:write"
+)
+
+// Valid indicates whether the value is a known member of the ApiKeyScope enum.
+func (e ApiKeyScope) Valid() bool {
+	switch e {
+	case CartsRead:
+		return true
+	case CartsWrite:
+		return true
+	case CategoriesWrite:
+		return true
+	case OrdersRead:
+		return true
+	case OrdersWrite:
+		return true
+	case ProductsWrite:
+		return true
+	case UsersRead:
+		return true
+	case UsersWrite:
+		return true
+	default:
+		return false
+	}
+}
+
 // Defines values for ErrorCode.
 const (
 	BADREQUEST             ErrorCode = "BAD_REQUEST"
@@ -32,6 +69,7 @@ const (
 	INVALIDSTATETRANSITION ErrorCode = "INVALID_STATE_TRANSITION"
 	NOTFOUND               ErrorCode = "NOT_FOUND"
 	SERVICEUNAVAILABLE     ErrorCode = "SERVICE_UNAVAILABLE"
+	TOOMANYREQUESTS        ErrorCode = "TOO_MANY_REQUESTS"
 	UNAUTHORIZED           ErrorCode = "UNAUTHORIZED"
 	VALIDATIONERROR        ErrorCode = "VALIDATION_ERROR"
 )
@@ -55,6 +93,8 @@ func (e ErrorCode) Valid() bool {
 		return true
 	case SERVICEUNAVAILABLE:
 		return true
+	case TOOMANYREQUESTS:
+		return true
 	case UNAUTHORIZED:
 		return true
 	case VALIDATIONERROR:
@@ -106,6 +146,21 @@ func (e OrderStatus) Valid() bool {
 	}
 }
 
+// Defines values for Role.
+const (
+	Admin Role = "admin"
+)
+
+// Valid indicates whether the value is a known member of the Role enum.
+func (e Role) Valid() bool {
+	switch e {
+	case Admin:
+		return true
+	default:
+		return false
+	}
+}
+
 // Defines values for ProductSearchParamsOrder.
 const (
 	ProductSearchParamsOrderAsc  ProductSearchParamsOrder = "asc"
@@ -129,6 +184,7 @@ const (
 	ProductSearchParamsSortByCreatedAt ProductSearchParamsSortBy = "createdAt"
 	ProductSearchParamsSortByName      ProductSearchParamsSortBy = "name"
 	ProductSearchParamsSortByPrice     ProductSearchParamsSortBy = "price"
+	ProductSearchParamsSortByRelevance ProductSearchParamsSortBy = "relevance"
 )
 
 // Valid indicates whether the value is a known member of the ProductSearchParamsSortBy enum.
@@ -140,6 +196,8 @@ func (e ProductSearchParamsSortBy) Valid() bool {
 		return true
 	case ProductSearchParamsSortByPrice:
 		return true
+	case ProductSearchParamsSortByRelevance:
+		return true
 	default:
 		return false
 	}
@@ -150,6 +208,7 @@ const (
 	ProductsServiceListParamsSortByCreatedAt ProductsServiceListParamsSortBy = "createdAt"
 	ProductsServiceListParamsSortByName      ProductsServiceListParamsSortBy = "name"
 	ProductsServiceListParamsSortByPrice     ProductsServiceListParamsSortBy = "price"
+	ProductsServiceListParamsSortByRelevance ProductsServiceListParamsSortBy = "relevance"
 )
 
 // Valid indicates whether the value is a known member of the ProductsServiceListParamsSortBy enum.
@@ -161,6 +220,8 @@ func (e ProductsServiceListParamsSortBy) Valid() bool {
 		return true
 	case ProductsServiceListParamsSortByPrice:
 		return true
+	case ProductsServiceListParamsSortByRelevance:
+		return true
 	default:
 		return false
 	}
@@ -184,6 +245,27 @@ func (e ProductsServiceListParamsOrder) Valid() bool {
 	}
 }
 
+// Defines values for ProductsServiceListParamsFacets.
+const (
+	ProductsServiceListParamsFacetsCategory ProductsServiceListParamsFacets = "category"
+	ProductsServiceListParamsFacetsPrice    ProductsServiceListParamsFacets = "price"
+	ProductsServiceListParamsFacetsStock    ProductsServiceListParamsFacets = "stock"
+)
+
+// Valid indicates whether the value is a known member of the ProductsServiceListParamsFacets enum.
+func (e ProductsServiceListParamsFacets) Valid() bool {
+	switch e {
+	case ProductsServiceListParamsFacetsCategory:
+		return true
+	case ProductsServiceListParamsFacetsPrice:
+		return true
+	case ProductsServiceListParamsFacetsStock:
+		return true
+	default:
+		return false
+	}
+}
+
 // AddCartItemRequest Add item to cart request
 type AddCartItemRequest struct {
 	// ProductId ID of the product to add
@@ -211,6 +293,30 @@ type Address struct {
 	Street string `json:"street"`
 }
 
+// ApiKey API key a machine client authenticates with instead of logging in
+type ApiKey struct {
+	// CreatedAt When the key was created
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Id API key ID
+	Id string `json:"id"`
+
+	// LastUsedAt When the key was last used
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+
+	// Name Name of the client the key is for
+	Name string `json:"name"`
+
+	// Prefix First characters of the key, to tell keys apart
+	Prefix string `json:"prefix"`
+
+	// Scopes What the key may be used for
+	Scopes []ApiKeyScope `json:"scopes"`
+}
+
+// ApiKeyScope Permission granted to an API key
+type ApiKeyScope string
+
 // AuthUser Authenticated user context
 type AuthUser struct {
 	// Email User's email address
@@ -221,6 +327,9 @@ type AuthUser struct {
 
 	// Name User's full name
 	Name string `json:"name"`
+
+	// Roles Roles granted to the user; customers have none
+	Roles *[]Role `json:"roles,omitempty"`
 }
 
 // Cart Shopping cart
@@ -293,6 +402,21 @@ type Category struct {
 
 	// UpdatedAt Timestamp when the resource was last updated
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Version Version of the resource, incremented on every update
+	Version *int32 `json:"version,omitempty"`
+}
+
+// CategoryFacet Number of products in a category and its descendants
+type CategoryFacet struct {
+	// CategoryId ID of the category
+	CategoryId Uuid `json:"categoryId"`
+
+	// Count Number of products in the category or one of its descendants
+	Count int32 `json:"count"`
+
+	// Name Name of the category
+	Name string `json:"name"`
 }
 
 // CategoryTree Category with nested children
@@ -314,6 +438,27 @@ type CategoryTree struct {
 
 	// UpdatedAt Timestamp when the resource was last updated
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Version Version of the resource, incremented on every update
+	Version *int32 `json:"version,omitempty"`
+}
+
+// ChangePasswordRequest Password change request
+type ChangePasswordRequest struct {
+	// CurrentPassword Current password
+	CurrentPassword string `json:"currentPassword"`
+
+	// NewPassword New password (at least 8 characters)
+	NewPassword string `json:"newPassword"`
+}
+
+// CreateApiKeyRequest API key creation request
+type CreateApiKeyRequest struct {
+	// Name Name of the client the key is for
+	Name string `json:"name"`
+
+	// Scopes What the key may be used for
+	Scopes []ApiKeyScope `json:"scopes"`
 }
 
 // CreateCategoryRequest Category creation request
@@ -325,10 +470,19 @@ type CreateCategoryRequest struct {
 	ParentId *Uuid `json:"parentId,omitempty"`
 }
 
+// CreateOrderItem Item of a create order request. Price and name are taken from the product.
+type CreateOrderItem struct {
+	// ProductId ID of the product to order
+	ProductId Uuid `json:"productId"`
+
+	// Quantity Quantity to order
+	Quantity int32 `json:"quantity"`
+}
+
 // CreateOrderRequest Create order request
 type CreateOrderRequest struct {
 	// Items List of items to order
-	Items []OrderItem `json:"items"`
+	Items []CreateOrderItem `json:"items"`
 
 	// ShippingAddress Shipping address for the order
 	ShippingAddress Address `json:"shippingAddress"`
@@ -361,10 +515,37 @@ type CreateUserRequest struct {
 	Address *Address `json:"address,omitempty"`
 
 	// Email User's email address
-	Email string `json:"email"`
+	Email openapi_types.Email `json:"email"`
 
 	// Name User's full name
 	Name string `json:"name"`
+
+	// Password Optional initial password (at least 8 characters); without one the user sets it via password reset
+	Password *string `json:"password,omitempty"`
+}
+
+// CreatedApiKey Newly created API key
+type CreatedApiKey struct {
+	// CreatedAt When the key was created
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Id API key ID
+	Id string `json:"id"`
+
+	// Key The key itself. It is only returned on creation.
+	Key string `json:"key"`
+
+	// LastUsedAt When the key was last used
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+
+	// Name Name of the client the key is for
+	Name string `json:"name"`
+
+	// Prefix First characters of the key, to tell keys apart
+	Prefix string `json:"prefix"`
+
+	// Scopes What the key may be used for
+	Scopes []ApiKeyScope `json:"scopes"`
 }
 
 // ErrorCode Standard error codes used throughout the API
@@ -377,14 +558,26 @@ type ErrorResponse struct {
 		// Code Error code identifying the type of error
 		Code ErrorCode `json:"code"`
 
-		// Details Additional error details
-		Details interface{} `json:"details,omitempty"`
+		// Details Fields that failed validation, for VALIDATION_ERROR responses
+		Details *[]FieldError `json:"details,omitempty"`
 
 		// Message Human-readable error message
 		Message string `json:"message"`
 	} `json:"error"`
 }
 
+// FieldError Field of a request that failed validation
+type FieldError struct {
+	// Field JSON pointer to the field in the request body, such as /items/0/quantity, or the name of a parameter
+	Field string `json:"field"`
+
+	// Message Human-readable description of the problem
+	Message string `json:"message"`
+
+	// Rule Rule the field broke, such as required, minLength or minimum
+	Rule string `json:"rule"`
+}
+
 // LoginRequest Login request
 type LoginRequest struct {
 	// Email User's email address
@@ -402,6 +595,9 @@ type LoginResponse struct {
 	// ExpiresIn Token expiration time in seconds
 	ExpiresIn int32 `json:"expiresIn"`
 
+	// RefreshToken Single-use token for getting new tokens from /auth/refresh
+	RefreshToken *string `json:"refreshToken,omitempty"`
+
 	// TokenType Token type (always Bearer)
 	TokenType LoginResponseTokenType `json:"tokenType"`
 
@@ -421,6 +617,12 @@ type LoginResponse struct {
 // LoginResponseTokenType Token type (always Bearer)
 type LoginResponseTokenType string
 
+// OidcLink Identity provider login that links the account to the current user
+type OidcLink struct {
+	// AuthorizationUrl Authorization URL of the identity provider to send the user to
+	AuthorizationUrl string `json:"authorizationUrl"`
+}
+
 // OkResponse Simple OK response
 type OkResponse struct {
 	Message string `json:"message"`
@@ -471,8 +673,62 @@ type OrderItem struct {
 // OrderStatus Order status enum
 type OrderStatus string
 
+// PasswordResetConfirmRequest Password reset confirmation
+type PasswordResetConfirmRequest struct {
+	// NewPassword New password (at least 8 characters)
+	NewPassword string `json:"newPassword"`
+
+	// Token Password reset token
+	Token string `json:"token"`
+}
+
+// PasswordResetRequest Password reset request
+type PasswordResetRequest struct {
+	// Email Email address of the account to reset
+	Email string `json:"email"`
+}
+
+// PriceRangeFacet Number of products in a price range
+type PriceRangeFacet struct {
+	// Count Number of products in the range
+	Count int32 `json:"count"`
+
+	// Max Highest price of the range, exclusive. Absent for the most expensive range.
+	Max *float32 `json:"max,omitempty"`
+
+	// Min Lowest price of the range, inclusive. Absent for the cheapest range.
+	Min *float32 `json:"min,omitempty"`
+}
+
+// ProblemDetails RFC 9457 problem details, sent to clients that accept application/problem+json
+type ProblemDetails struct {
+	// Code Error code identifying the type of error
+	Code ErrorCode `json:"code"`
+
+	// Detail Human-readable explanation of this occurrence of the problem
+	Detail string `json:"detail"`
+
+	// Details Fields that failed validation, for VALIDATION_ERROR problems
+	Details *[]FieldError `json:"details,omitempty"`
+
+	// Instance Path of the request the problem occurred on
+	Instance string `json:"instance"`
+
+	// Status HTTP status code of the response
+	Status int32 `json:"status"`
+
+	// Title Short summary of the type of problem
+	Title string `json:"title"`
+
+	// Type URI identifying the type of problem; there is one per error code
+	Type string `json:"type"`
+}
+
 // Product Product model
 type Product struct {
+	// AvailableStock Quantity that can still be added to a cart (stock minus reservedStock)
+	AvailableStock *int32 `json:"availableStock,omitempty"`
+
 	// CategoryId ID of the category this product belongs to
 	CategoryId Uuid `json:"categoryId"`
 
@@ -482,6 +738,9 @@ type Product struct {
 	// Description Detailed description of the product
 	Description string `json:"description"`
 
+	// Highlight Parts of the product that matched the name search, only set in search results
+	Highlight *ProductHighlight `json:"highlight,omitempty"`
+
 	// Id Unique identifier for the product
 	Id Uuid `json:"id"`
 
@@ -494,11 +753,117 @@ type Product struct {
 	// Price Price of the product
 	Price float32 `json:"price"`
 
+	// ReservedStock Quantity held by unexpired cart reservations
+	ReservedStock *int32 `json:"reservedStock,omitempty"`
+
+	// Score Relevance of the product to the name search, only set in search results
+	Score *float32 `json:"score,omitempty"`
+
 	// Stock Current stock quantity
 	Stock int32 `json:"stock"`
 
 	// UpdatedAt Timestamp when the resource was last updated
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Version Version of the resource, incremented on every update
+	Version *int32 `json:"version,omitempty"`
+}
+
+// ProductFacets Aggregate counts over the products that match a search
+type ProductFacets struct {
+	// Categories Matching products per category, counting those in descendant categories too. Categories without matching products are left out.
+	Categories *[]CategoryFacet `json:"categories,omitempty"`
+
+	// PriceRanges Matching products per price range, from the cheapest range up
+	PriceRanges *[]PriceRangeFacet `json:"priceRanges,omitempty"`
+
+	// Stock Matching products with and without available stock
+	Stock *StockFacet `json:"stock,omitempty"`
+}
+
+// ProductHighlight Snippets of a product with the words that matched a search wrapped in
+// <mark> tags. The snippets are HTML-escaped.
+type ProductHighlight struct {
+	// Description Part of the description around the first match, if it matched
+	Description *string `json:"description,omitempty"`
+
+	// Name Name of the product, if it matched
+	Name *string `json:"name,omitempty"`
+}
+
+// ProductListResponse Page of products, with the facets that were asked for
+type ProductListResponse struct {
+	// Facets Counts over all products that match the filters, not just the current page
+	Facets *ProductFacets `json:"facets,omitempty"`
+
+	// Items Array of items in the current page
+	Items []Product `json:"items"`
+
+	// Limit Maximum number of items per page
+	Limit int32 `json:"limit"`
+
+	// NextCursor Cursor to the page after this one, absent on the last page
+	NextCursor *string `json:"nextCursor,omitempty"`
+
+	// Offset Number of items skipped
+	Offset int32 `json:"offset"`
+
+	// PrevCursor Cursor to the page before this one, absent on the first page
+	PrevCursor *string `json:"prevCursor,omitempty"`
+
+	// Total Total number of items
+	Total int32 `json:"total"`
+}
+
+// RefreshRequest Token refresh request
+type RefreshRequest struct {
+	// RefreshToken Refresh token from the login or the previous refresh
+	RefreshToken string `json:"refreshToken"`
+}
+
+// RegisterRequest Registration request
+type RegisterRequest struct {
+	// Email User's email address
+	Email string `json:"email"`
+
+	// Name User's full name
+	Name string `json:"name"`
+
+	// Password User's password (at least 8 characters)
+	Password string `json:"password"`
+}
+
+// Role Role granting access beyond a user's own resources
+type Role string
+
+// Session Active login session of the current user
+type Session struct {
+	// CreatedAt When the user logged in
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Current Whether the request was made with this session's access token
+	Current bool `json:"current"`
+
+	// Device User agent of the client that last used the session
+	Device *string `json:"device,omitempty"`
+
+	// Id Session ID
+	Id string `json:"id"`
+
+	// IpAddress IP address the session was last used from
+	IpAddress *string `json:"ipAddress,omitempty"`
+
+	// LastSeenAt When the session was last used
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
+
+// StockFacet Number of products with and without available stock
+type StockFacet struct {
+	// InStock Number of products with availableStock above zero
+	InStock int32 `json:"inStock"`
+
+	// OutOfStock Number of products with no availableStock
+	OutOfStock int32 `json:"outOfStock"`
 }
 
 // UpdateCartItemRequest Update cart item request
@@ -549,7 +914,7 @@ type UpdateUserRequest struct {
 	Address *Address `json:"address,omitempty"`
 
 	// Email Updated email address
-	Email *string `json:"email,omitempty"`
+	Email *openapi_types.Email `json:"email,omitempty"`
 
 	// Name Updated user name
 	Name *string `json:"name,omitempty"`
@@ -574,6 +939,9 @@ type User struct {
 
 	// UpdatedAt Timestamp when the resource was last updated
 	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Version Version of the resource, incremented on every update
+	Version *int32 `json:"version,omitempty"`
 }
 
 // Uuid UUID type alias
@@ -591,6 +959,9 @@ type OrderSearchParamsStatus = OrderStatus
 // OrderSearchParamsUserId UUID type alias
 type OrderSearchParamsUserId = Uuid
 
+// PaginationParamsCursor defines model for PaginationParams.cursor.
+type PaginationParamsCursor = string
+
 // PaginationParamsLimit defines model for PaginationParams.limit.
 type PaginationParamsLimit = int32
 
@@ -600,6 +971,12 @@ type PaginationParamsOffset = int32
 // ProductSearchParamsCategoryId UUID type alias
 type ProductSearchParamsCategoryId = Uuid
 
+// ProductSearchParamsFacets defines model for ProductSearchParams.facets.
+type ProductSearchParamsFacets = []string
+
+// ProductSearchParamsIncludeDescendants defines model for ProductSearchParams.includeDescendants.
+type ProductSearchParamsIncludeDescendants = bool
+
 // ProductSearchParamsMaxPrice defines model for ProductSearchParams.maxPrice.
 type ProductSearchParamsMaxPrice = float32
 
@@ -615,6 +992,24 @@ type ProductSearchParamsOrder string
 // ProductSearchParamsSortBy defines model for ProductSearchParams.sortBy.
 type ProductSearchParamsSortBy string
 
+// AuthServiceListApiKeys200JSONResponseBody0 defines parameters for AuthServiceListApiKeys.
+type AuthServiceListApiKeys200JSONResponseBody0 = []ApiKey
+
+// AuthServiceListApiKeys200JSONResponseBody defines parameters for AuthServiceListApiKeys.
+type AuthServiceListApiKeys200JSONResponseBody struct {
+	union json.RawMessage
+}
+
+// AuthServiceCreateApiKey200JSONResponseBody defines parameters for AuthServiceCreateApiKey.
+type AuthServiceCreateApiKey200JSONResponseBody struct {
+	union json.RawMessage
+}
+
+// AuthServiceChangePassword200JSONResponseBody defines parameters for AuthServiceChangePassword.
+type AuthServiceChangePassword200JSONResponseBody struct {
+	union json.RawMessage
+}
+
 // AuthServiceLogin200JSONResponseBody defines parameters for AuthServiceLogin.
 type AuthServiceLogin200JSONResponseBody struct {
 	union json.RawMessage
@@ -630,6 +1025,56 @@ type AuthServiceGetCurrentUser200JSONResponseBody struct {
 	union json.RawMessage
 }
 
+// AuthServiceOidcCallbackParams defines parameters for AuthServiceOidcCallback.
+type AuthServiceOidcCallbackParams struct {
+	// Code Authorization code issued by the identity provider
+	Code *string `form:"code,omitempty" json:"code,omitempty"`
+
+	// State State of the login being completed
+	State string `form:"state" json:"state"`
+
+	// Error Error reported by the identity provider instead of a code
+	Error *string `form:"error,omitempty" json:"error,omitempty"`
+}
+
+// AuthServiceOidcCallback200JSONResponseBody defines parameters for AuthServiceOidcCallback.
+type AuthServiceOidcCallback200JSONResponseBody struct {
+	union json.RawMessage
+}
+
+// AuthServiceOidcLink200JSONResponseBody defines parameters for AuthServiceOidcLink.
+type AuthServiceOidcLink200JSONResponseBody struct {
+	union json.RawMessage
+}
+
+// AuthServiceRequestPasswordReset200JSONResponseBody defines parameters for AuthServiceRequestPasswordReset.
+type AuthServiceRequestPasswordReset200JSONResponseBody struct {
+	union json.RawMessage
+}
+
+// AuthServiceConfirmPasswordReset200JSONResponseBody defines parameters for AuthServiceConfirmPasswordReset.
+type AuthServiceConfirmPasswordReset200JSONResponseBody struct {
+	union json.RawMessage
+}
+
+// AuthServiceRefresh200JSONResponseBody defines parameters for AuthServiceRefresh.
+type AuthServiceRefresh200JSONResponseBody struct {
+	union json.RawMessage
+}
+
+// AuthServiceRegister200JSONResponseBody defines parameters for AuthServiceRegister.
+type AuthServiceRegister200JSONResponseBody struct {
+	union json.RawMessage
+}
+
+// AuthServiceListSessions200JSONResponseBody0 defines parameters for AuthServiceListSessions.
+type AuthServiceListSessions200JSONResponseBody0 = []Session
+
+// AuthServiceListSessions200JSONResponseBody defines parameters for AuthServiceListSessions.
+type AuthServiceListSessions200JSONResponseBody struct {
+	union json.RawMessage
+}
+
 // CartsServiceGetByUser200JSONResponseBody defines parameters for CartsServiceGetByUser.
 type CartsServiceGetByUser200JSONResponseBody struct {
 	union json.RawMessage
@@ -689,6 +1134,9 @@ type OrdersServiceListParams struct {
 	// Offset Number of items to skip
 	Offset *PaginationParamsOffset `form:"offset,omitempty" json:"offset,omitempty"`
 
+	// Cursor Opaque cursor from the nextCursor or prevCursor of a previous page. It selects the page instead of offset.
+	Cursor *PaginationParamsCursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+
 	// Status Filter by order status
 	Status *OrderSearchParamsStatus `form:"status,omitempty" json:"status,omitempty"`
 
@@ -710,9 +1158,15 @@ type OrdersServiceList200JSONResponseBody0 struct {
 	// Limit Maximum number of items per page
 	Limit int32 `json:"limit"`
 
+	// NextCursor Cursor to the page after this one, absent on the last page
+	NextCursor *string `json:"nextCursor,omitempty"`
+
 	// Offset Number of items skipped
 	Offset int32 `json:"offset"`
 
+	// PrevCursor Cursor to the page before this one, absent on the first page
+	PrevCursor *string `json:"prevCursor,omitempty"`
+
 	// Total Total number of items
 	Total int32 `json:"total"`
 }
@@ -739,6 +1193,9 @@ type OrdersServiceListByUserParams struct {
 
 	// Offset Number of items to skip
 	Offset *PaginationParamsOffset `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// Cursor Opaque cursor from the nextCursor or prevCursor of a previous page. It selects the page instead of offset.
+	Cursor *PaginationParamsCursor `form:"cursor,omitempty" json:"cursor,omitempty"`
 }
 
 // OrdersServiceListByUser200JSONResponseBody0 defines parameters for OrdersServiceListByUser.
@@ -749,9 +1206,15 @@ type OrdersServiceListByUser200JSONResponseBody0 struct {
 	// Limit Maximum number of items per page
 	Limit int32 `json:"limit"`
 
+	// NextCursor Cursor to the page after this one, absent on the last page
+	NextCursor *string `json:"nextCursor,omitempty"`
+
 	// Offset Number of items skipped
 	Offset int32 `json:"offset"`
 
+	// PrevCursor Cursor to the page before this one, absent on the first page
+	PrevCursor *string `json:"prevCursor,omitempty"`
+
 	// Total Total number of items
 	Total int32 `json:"total"`
 }
@@ -779,23 +1242,32 @@ type ProductsServiceListParams struct {
 	// Offset Number of items to skip
 	Offset *PaginationParamsOffset `form:"offset,omitempty" json:"offset,omitempty"`
 
-	// Name Search by product name
+	// Cursor Opaque cursor from the nextCursor or prevCursor of a previous page. It selects the page instead of offset.
+	Cursor *PaginationParamsCursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Name Full-text search over product names and descriptions. Words are matched by stem, by prefix and with typos, and every word must match.
 	Name *ProductSearchParamsName `form:"name,omitempty" json:"name,omitempty"`
 
 	// CategoryId Filter by category ID
 	CategoryId *ProductSearchParamsCategoryId `form:"categoryId,omitempty" json:"categoryId,omitempty"`
 
+	// IncludeDescendants Also match products in the subcategories of categoryId, at any depth
+	IncludeDescendants *ProductSearchParamsIncludeDescendants `form:"includeDescendants,omitempty" json:"includeDescendants,omitempty"`
+
 	// MinPrice Minimum price
 	MinPrice *ProductSearchParamsMinPrice `form:"minPrice,omitempty" json:"minPrice,omitempty"`
 
 	// MaxPrice Maximum price
 	MaxPrice *ProductSearchParamsMaxPrice `form:"maxPrice,omitempty" json:"maxPrice,omitempty"`
 
-	// SortBy Sort field
+	// SortBy Sort field. relevance sorts by score, and is the default when searching by name.
 	SortBy *ProductsServiceListParamsSortBy `form:"sortBy,omitempty" json:"sortBy,omitempty"`
 
 	// Order Sort order
 	Order *ProductsServiceListParamsOrder `form:"order,omitempty" json:"order,omitempty"`
+
+	// Facets Facets to count over all products that match the filters, e.g. facets=category,price
+	Facets *ProductSearchParamsFacets `form:"facets,omitempty" json:"facets,omitempty"`
 }
 
 // ProductsServiceListParamsSortBy defines parameters for ProductsServiceList.
@@ -804,20 +1276,8 @@ type ProductsServiceListParamsSortBy string
 // ProductsServiceListParamsOrder defines parameters for ProductsServiceList.
 type ProductsServiceListParamsOrder string
 
-// ProductsServiceList200JSONResponseBody0 defines parameters for ProductsServiceList.
-type ProductsServiceList200JSONResponseBody0 struct {
-	// Items Array of items in the current page
-	Items []Product `json:"items"`
-
-	// Limit Maximum number of items per page
-	Limit int32 `json:"limit"`
-
-	// Offset Number of items skipped
-	Offset int32 `json:"offset"`
-
-	// Total Total number of items
-	Total int32 `json:"total"`
-}
+// ProductsServiceListParamsFacets defines parameters for ProductsServiceList.
+type ProductsServiceListParamsFacets string
 
 // ProductsServiceList200JSONResponseBody defines parameters for ProductsServiceList.
 type ProductsServiceList200JSONResponseBody struct {
@@ -846,6 +1306,9 @@ type UsersServiceListParams struct {
 
 	// Offset Number of items to skip
 	Offset *PaginationParamsOffset `form:"offset,omitempty" json:"offset,omitempty"`
+
+	// Cursor Opaque cursor from the nextCursor or prevCursor of a previous page. It selects the page instead of offset.
+	Cursor *PaginationParamsCursor `form:"cursor,omitempty" json:"cursor,omitempty"`
 }
 
 // UsersServiceList200JSONResponseBody0 defines parameters for UsersServiceList.
@@ -856,9 +1319,15 @@ type UsersServiceList200JSONResponseBody0 struct {
 	// Limit Maximum number of items per page
 	Limit int32 `json:"limit"`
 
+	// NextCursor Cursor to the page after this one, absent on the last page
+	NextCursor *string `json:"nextCursor,omitempty"`
+
 	// Offset Number of items skipped
 	Offset int32 `json:"offset"`
 
+	// PrevCursor Cursor to the page before this one, absent on the first page
+	PrevCursor *string `json:"prevCursor,omitempty"`
+
 	// Total Total number of items
 	Total int32 `json:"total"`
 }
@@ -883,9 +1352,27 @@ type UsersServiceUpdate200JSONResponseBody struct {
 	union json.RawMessage
 }
 
+// AuthServiceCreateApiKeyJSONRequestBody defines body for AuthServiceCreateApiKey for application/json ContentType.
+type AuthServiceCreateApiKeyJSONRequestBody = CreateApiKeyRequest
+
+// AuthServiceChangePasswordJSONRequestBody defines body for AuthServiceChangePassword for application/json ContentType.
+type AuthServiceChangePasswordJSONRequestBody = ChangePasswordRequest
+
 // AuthServiceLoginJSONRequestBody defines body for AuthServiceLogin for application/json ContentType.
 type AuthServiceLoginJSONRequestBody = LoginRequest
 
+// AuthServiceRequestPasswordResetJSONRequestBody defines body for AuthServiceRequestPasswordReset for application/json ContentType.
+type AuthServiceRequestPasswordResetJSONRequestBody = PasswordResetRequest
+
+// AuthServiceConfirmPasswordResetJSONRequestBody defines body for AuthServiceConfirmPasswordReset for application/json ContentType.
+type AuthServiceConfirmPasswordResetJSONRequestBody = PasswordResetConfirmRequest
+
+// AuthServiceRefreshJSONRequestBody defines body for AuthServiceRefresh for application/json ContentType.
+type AuthServiceRefreshJSONRequestBody = RefreshRequest
+
+// AuthServiceRegisterJSONRequestBody defines body for AuthServiceRegister for application/json ContentType.
+type AuthServiceRegisterJSONRequestBody = RegisterRequest
+
 // CartsServiceAddItemJSONRequestBody defines body for CartsServiceAddItem for application/json ContentType.
 type CartsServiceAddItemJSONRequestBody = AddCartItemRequest
 
@@ -916,22 +1403,22 @@ type UsersServiceCreateJSONRequestBody = CreateUserRequest
 // UsersServiceUpdateJSONRequestBody defines body for UsersServiceUpdate for application/json ContentType.
 type UsersServiceUpdateJSONRequestBody = UpdateUserRequest
 
-// AsLoginResponse returns the union data inside the AuthServiceLogin200JSONResponseBody as a LoginResponse
-func (t AuthServiceLogin200JSONResponseBody) AsLoginResponse() (LoginResponse, error) {
-	var body LoginResponse
+// AsAuthServiceListApiKeys200JSONResponseBody0 returns the union data inside the AuthServiceListApiKeys200JSONResponseBody as a AuthServiceListApiKeys200JSONResponseBody0
+func (t AuthServiceListApiKeys200JSONResponseBody) AsAuthServiceListApiKeys200JSONResponseBody0() (AuthServiceListApiKeys200JSONResponseBody0, error) {
+	var body AuthServiceListApiKeys200JSONResponseBody0
 	err := json.Unmarshal(t.union, &body)
 	return body, err
 }
 
-// FromLoginResponse overwrites any union data inside the AuthServiceLogin200JSONResponseBody as the provided LoginResponse
-func (t *AuthServiceLogin200JSONResponseBody) FromLoginResponse(v LoginResponse) error {
+// FromAuthServiceListApiKeys200JSONResponseBody0 overwrites any union data inside the AuthServiceListApiKeys200JSONResponseBody as the provided AuthServiceListApiKeys200JSONResponseBody0
+func (t *AuthServiceListApiKeys200JSONResponseBody) FromAuthServiceListApiKeys200JSONResponseBody0(v AuthServiceListApiKeys200JSONResponseBody0) error {
 	b, err := json.Marshal(v)
 	t.union = b
 	return err
 }
 
-// MergeLoginResponse performs a merge with any union data inside the AuthServiceLogin200JSONResponseBody, using the provided LoginResponse
-func (t *AuthServiceLogin200JSONResponseBody) MergeLoginResponse(v LoginResponse) error {
+// MergeAuthServiceListApiKeys200JSONResponseBody0 performs a merge with any union data inside the AuthServiceListApiKeys200JSONResponseBody, using the provided AuthServiceListApiKeys200JSONResponseBody0
+func (t *AuthServiceListApiKeys200JSONResponseBody) MergeAuthServiceListApiKeys200JSONResponseBody0(v AuthServiceListApiKeys200JSONResponseBody0) error {
 	b, err := json.Marshal(v)
 	if err != nil {
 		return err
@@ -942,22 +1429,22 @@ func (t *AuthServiceLogin200JSONResponseBody) MergeLoginResponse(v LoginResponse
 	return err
 }
 
-// AsErrorResponse returns the union data inside the AuthServiceLogin200JSONResponseBody as a ErrorResponse
-func (t AuthServiceLogin200JSONResponseBody) AsErrorResponse() (ErrorResponse, error) {
+// AsErrorResponse returns the union data inside the AuthServiceListApiKeys200JSONResponseBody as a ErrorResponse
+func (t AuthServiceListApiKeys200JSONResponseBody) AsErrorResponse() (ErrorResponse, error) {
 	var body ErrorResponse
 	err := json.Unmarshal(t.union, &body)
 	return body, err
 }
 
-// FromErrorResponse overwrites any union data inside the AuthServiceLogin200JSONResponseBody as the provided ErrorResponse
-func (t *AuthServiceLogin200JSONResponseBody) FromErrorResponse(v ErrorResponse) error {
+// FromErrorResponse overwrites any union data inside the AuthServiceListApiKeys200JSONResponseBody as the provided ErrorResponse
+func (t *AuthServiceListApiKeys200JSONResponseBody) FromErrorResponse(v ErrorResponse) error {
 	b, err := json.Marshal(v)
 	t.union = b
 	return err
 }
 
-// MergeErrorResponse performs a merge with any union data inside the AuthServiceLogin200JSONResponseBody, using the provided ErrorResponse
-func (t *AuthServiceLogin200JSONResponseBody) MergeErrorResponse(v ErrorResponse) error {
+// MergeErrorResponse performs a merge with any union data inside the AuthServiceListApiKeys200JSONResponseBody, using the provided ErrorResponse
+func (t *AuthServiceListApiKeys200JSONResponseBody) MergeErrorResponse(v ErrorResponse) error {
 	b, err := json.Marshal(v)
 	if err != nil {
 		return err
@@ -968,25 +1455,211 @@ func (t *AuthServiceLogin200JSONResponseBody) MergeErrorResponse(v ErrorResponse
 	return err
 }
 
-func (t AuthServiceLogin200JSONResponseBody) MarshalJSON() ([]byte, error) {
+func (t AuthServiceListApiKeys200JSONResponseBody) MarshalJSON() ([]byte, error) {
 	b, err := t.union.MarshalJSON()
 	return b, err
 }
 
-func (t *AuthServiceLogin200JSONResponseBody) UnmarshalJSON(b []byte) error {
+func (t *AuthServiceListApiKeys200JSONResponseBody) UnmarshalJSON(b []byte) error {
 	err := t.union.UnmarshalJSON(b)
 	return err
 }
 
-// AsOkResponse returns the union data inside the AuthServiceLogout200JSONResponseBody as a OkResponse
-func (t AuthServiceLogout200JSONResponseBody) AsOkResponse() (OkResponse, error) {
-	var body OkResponse
+// AsCreatedApiKey returns the union data inside the AuthServiceCreateApiKey200JSONResponseBody as a CreatedApiKey
+func (t AuthServiceCreateApiKey200JSONResponseBody) AsCreatedApiKey() (CreatedApiKey, error) {
+	var body CreatedApiKey
 	err := json.Unmarshal(t.union, &body)
 	return body, err
 }
 
-// FromOkResponse overwrites any union data inside the AuthServiceLogout200JSONResponseBody as the provided OkResponse
-func (t *AuthServiceLogout200JSONResponseBody) FromOkResponse(v OkResponse) error {
+// FromCreatedApiKey overwrites any union data inside the AuthServiceCreateApiKey200JSONResponseBody as the provided CreatedApiKey
+func (t *AuthServiceCreateApiKey200JSONResponseBody) FromCreatedApiKey(v CreatedApiKey) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeCreatedApiKey performs a merge with any union data inside the AuthServiceCreateApiKey200JSONResponseBody, using the provided CreatedApiKey
+func (t *AuthServiceCreateApiKey200JSONResponseBody) MergeCreatedApiKey(v CreatedApiKey) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsErrorResponse returns the union data inside the AuthServiceCreateApiKey200JSONResponseBody as a ErrorResponse
+func (t AuthServiceCreateApiKey200JSONResponseBody) AsErrorResponse() (ErrorResponse, error) {
+	var body ErrorResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromErrorResponse overwrites any union data inside the AuthServiceCreateApiKey200JSONResponseBody as the provided ErrorResponse
+func (t *AuthServiceCreateApiKey200JSONResponseBody) FromErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeErrorResponse performs a merge with any union data inside the AuthServiceCreateApiKey200JSONResponseBody, using the provided ErrorResponse
+func (t *AuthServiceCreateApiKey200JSONResponseBody) MergeErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t AuthServiceCreateApiKey200JSONResponseBody) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *AuthServiceCreateApiKey200JSONResponseBody) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// AsOkResponse returns the union data inside the AuthServiceChangePassword200JSONResponseBody as a OkResponse
+func (t AuthServiceChangePassword200JSONResponseBody) AsOkResponse() (OkResponse, error) {
+	var body OkResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromOkResponse overwrites any union data inside the AuthServiceChangePassword200JSONResponseBody as the provided OkResponse
+func (t *AuthServiceChangePassword200JSONResponseBody) FromOkResponse(v OkResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeOkResponse performs a merge with any union data inside the AuthServiceChangePassword200JSONResponseBody, using the provided OkResponse
+func (t *AuthServiceChangePassword200JSONResponseBody) MergeOkResponse(v OkResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsErrorResponse returns the union data inside the AuthServiceChangePassword200JSONResponseBody as a ErrorResponse
+func (t AuthServiceChangePassword200JSONResponseBody) AsErrorResponse() (ErrorResponse, error) {
+	var body ErrorResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromErrorResponse overwrites any union data inside the AuthServiceChangePassword200JSONResponseBody as the provided ErrorResponse
+func (t *AuthServiceChangePassword200JSONResponseBody) FromErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeErrorResponse performs a merge with any union data inside the AuthServiceChangePassword200JSONResponseBody, using the provided ErrorResponse
+func (t *AuthServiceChangePassword200JSONResponseBody) MergeErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t AuthServiceChangePassword200JSONResponseBody) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *AuthServiceChangePassword200JSONResponseBody) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// AsLoginResponse returns the union data inside the AuthServiceLogin200JSONResponseBody as a LoginResponse
+func (t AuthServiceLogin200JSONResponseBody) AsLoginResponse() (LoginResponse, error) {
+	var body LoginResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromLoginResponse overwrites any union data inside the AuthServiceLogin200JSONResponseBody as the provided LoginResponse
+func (t *AuthServiceLogin200JSONResponseBody) FromLoginResponse(v LoginResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeLoginResponse performs a merge with any union data inside the AuthServiceLogin200JSONResponseBody, using the provided LoginResponse
+func (t *AuthServiceLogin200JSONResponseBody) MergeLoginResponse(v LoginResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsErrorResponse returns the union data inside the AuthServiceLogin200JSONResponseBody as a ErrorResponse
+func (t AuthServiceLogin200JSONResponseBody) AsErrorResponse() (ErrorResponse, error) {
+	var body ErrorResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromErrorResponse overwrites any union data inside the AuthServiceLogin200JSONResponseBody as the provided ErrorResponse
+func (t *AuthServiceLogin200JSONResponseBody) FromErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeErrorResponse performs a merge with any union data inside the AuthServiceLogin200JSONResponseBody, using the provided ErrorResponse
+func (t *AuthServiceLogin200JSONResponseBody) MergeErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t AuthServiceLogin200JSONResponseBody) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *AuthServiceLogin200JSONResponseBody) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// AsOkResponse returns the union data inside the AuthServiceLogout200JSONResponseBody as a OkResponse
+func (t AuthServiceLogout200JSONResponseBody) AsOkResponse() (OkResponse, error) {
+	var body OkResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromOkResponse overwrites any union data inside the AuthServiceLogout200JSONResponseBody as the provided OkResponse
+func (t *AuthServiceLogout200JSONResponseBody) FromOkResponse(v OkResponse) error {
 	b, err := json.Marshal(v)
 	t.union = b
 	return err
@@ -1102,6 +1775,440 @@ func (t *AuthServiceGetCurrentUser200JSONResponseBody) UnmarshalJSON(b []byte) e
 	return err
 }
 
+// AsLoginResponse returns the union data inside the AuthServiceOidcCallback200JSONResponseBody as a LoginResponse
+func (t AuthServiceOidcCallback200JSONResponseBody) AsLoginResponse() (LoginResponse, error) {
+	var body LoginResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromLoginResponse overwrites any union data inside the AuthServiceOidcCallback200JSONResponseBody as the provided LoginResponse
+func (t *AuthServiceOidcCallback200JSONResponseBody) FromLoginResponse(v LoginResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeLoginResponse performs a merge with any union data inside the AuthServiceOidcCallback200JSONResponseBody, using the provided LoginResponse
+func (t *AuthServiceOidcCallback200JSONResponseBody) MergeLoginResponse(v LoginResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsErrorResponse returns the union data inside the AuthServiceOidcCallback200JSONResponseBody as a ErrorResponse
+func (t AuthServiceOidcCallback200JSONResponseBody) AsErrorResponse() (ErrorResponse, error) {
+	var body ErrorResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromErrorResponse overwrites any union data inside the AuthServiceOidcCallback200JSONResponseBody as the provided ErrorResponse
+func (t *AuthServiceOidcCallback200JSONResponseBody) FromErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeErrorResponse performs a merge with any union data inside the AuthServiceOidcCallback200JSONResponseBody, using the provided ErrorResponse
+func (t *AuthServiceOidcCallback200JSONResponseBody) MergeErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t AuthServiceOidcCallback200JSONResponseBody) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *AuthServiceOidcCallback200JSONResponseBody) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// AsOidcLink returns the union data inside the AuthServiceOidcLink200JSONResponseBody as a OidcLink
+func (t AuthServiceOidcLink200JSONResponseBody) AsOidcLink() (OidcLink, error) {
+	var body OidcLink
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromOidcLink overwrites any union data inside the AuthServiceOidcLink200JSONResponseBody as the provided OidcLink
+func (t *AuthServiceOidcLink200JSONResponseBody) FromOidcLink(v OidcLink) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeOidcLink performs a merge with any union data inside the AuthServiceOidcLink200JSONResponseBody, using the provided OidcLink
+func (t *AuthServiceOidcLink200JSONResponseBody) MergeOidcLink(v OidcLink) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsErrorResponse returns the union data inside the AuthServiceOidcLink200JSONResponseBody as a ErrorResponse
+func (t AuthServiceOidcLink200JSONResponseBody) AsErrorResponse() (ErrorResponse, error) {
+	var body ErrorResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromErrorResponse overwrites any union data inside the AuthServiceOidcLink200JSONResponseBody as the provided ErrorResponse
+func (t *AuthServiceOidcLink200JSONResponseBody) FromErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeErrorResponse performs a merge with any union data inside the AuthServiceOidcLink200JSONResponseBody, using the provided ErrorResponse
+func (t *AuthServiceOidcLink200JSONResponseBody) MergeErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t AuthServiceOidcLink200JSONResponseBody) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *AuthServiceOidcLink200JSONResponseBody) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// AsOkResponse returns the union data inside the AuthServiceRequestPasswordReset200JSONResponseBody as a OkResponse
+func (t AuthServiceRequestPasswordReset200JSONResponseBody) AsOkResponse() (OkResponse, error) {
+	var body OkResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromOkResponse overwrites any union data inside the AuthServiceRequestPasswordReset200JSONResponseBody as the provided OkResponse
+func (t *AuthServiceRequestPasswordReset200JSONResponseBody) FromOkResponse(v OkResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeOkResponse performs a merge with any union data inside the AuthServiceRequestPasswordReset200JSONResponseBody, using the provided OkResponse
+func (t *AuthServiceRequestPasswordReset200JSONResponseBody) MergeOkResponse(v OkResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsErrorResponse returns the union data inside the AuthServiceRequestPasswordReset200JSONResponseBody as a ErrorResponse
+func (t AuthServiceRequestPasswordReset200JSONResponseBody) AsErrorResponse() (ErrorResponse, error) {
+	var body ErrorResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromErrorResponse overwrites any union data inside the AuthServiceRequestPasswordReset200JSONResponseBody as the provided ErrorResponse
+func (t *AuthServiceRequestPasswordReset200JSONResponseBody) FromErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeErrorResponse performs a merge with any union data inside the AuthServiceRequestPasswordReset200JSONResponseBody, using the provided ErrorResponse
+func (t *AuthServiceRequestPasswordReset200JSONResponseBody) MergeErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t AuthServiceRequestPasswordReset200JSONResponseBody) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *AuthServiceRequestPasswordReset200JSONResponseBody) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// AsOkResponse returns the union data inside the AuthServiceConfirmPasswordReset200JSONResponseBody as a OkResponse
+func (t AuthServiceConfirmPasswordReset200JSONResponseBody) AsOkResponse() (OkResponse, error) {
+	var body OkResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromOkResponse overwrites any union data inside the AuthServiceConfirmPasswordReset200JSONResponseBody as the provided OkResponse
+func (t *AuthServiceConfirmPasswordReset200JSONResponseBody) FromOkResponse(v OkResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeOkResponse performs a merge with any union data inside the AuthServiceConfirmPasswordReset200JSONResponseBody, using the provided OkResponse
+func (t *AuthServiceConfirmPasswordReset200JSONResponseBody) MergeOkResponse(v OkResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsErrorResponse returns the union data inside the AuthServiceConfirmPasswordReset200JSONResponseBody as a ErrorResponse
+func (t AuthServiceConfirmPasswordReset200JSONResponseBody) AsErrorResponse() (ErrorResponse, error) {
+	var body ErrorResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromErrorResponse overwrites any union data inside the AuthServiceConfirmPasswordReset200JSONResponseBody as the provided ErrorResponse
+func (t *AuthServiceConfirmPasswordReset200JSONResponseBody) FromErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeErrorResponse performs a merge with any union data inside the AuthServiceConfirmPasswordReset200JSONResponseBody, using the provided ErrorResponse
+func (t *AuthServiceConfirmPasswordReset200JSONResponseBody) MergeErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t AuthServiceConfirmPasswordReset200JSONResponseBody) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *AuthServiceConfirmPasswordReset200JSONResponseBody) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// AsLoginResponse returns the union data inside the AuthServiceRefresh200JSONResponseBody as a LoginResponse
+func (t AuthServiceRefresh200JSONResponseBody) AsLoginResponse() (LoginResponse, error) {
+	var body LoginResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromLoginResponse overwrites any union data inside the AuthServiceRefresh200JSONResponseBody as the provided LoginResponse
+func (t *AuthServiceRefresh200JSONResponseBody) FromLoginResponse(v LoginResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeLoginResponse performs a merge with any union data inside the AuthServiceRefresh200JSONResponseBody, using the provided LoginResponse
+func (t *AuthServiceRefresh200JSONResponseBody) MergeLoginResponse(v LoginResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsErrorResponse returns the union data inside the AuthServiceRefresh200JSONResponseBody as a ErrorResponse
+func (t AuthServiceRefresh200JSONResponseBody) AsErrorResponse() (ErrorResponse, error) {
+	var body ErrorResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromErrorResponse overwrites any union data inside the AuthServiceRefresh200JSONResponseBody as the provided ErrorResponse
+func (t *AuthServiceRefresh200JSONResponseBody) FromErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeErrorResponse performs a merge with any union data inside the AuthServiceRefresh200JSONResponseBody, using the provided ErrorResponse
+func (t *AuthServiceRefresh200JSONResponseBody) MergeErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t AuthServiceRefresh200JSONResponseBody) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *AuthServiceRefresh200JSONResponseBody) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// AsAuthUser returns the union data inside the AuthServiceRegister200JSONResponseBody as a AuthUser
+func (t AuthServiceRegister200JSONResponseBody) AsAuthUser() (AuthUser, error) {
+	var body AuthUser
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromAuthUser overwrites any union data inside the AuthServiceRegister200JSONResponseBody as the provided AuthUser
+func (t *AuthServiceRegister200JSONResponseBody) FromAuthUser(v AuthUser) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeAuthUser performs a merge with any union data inside the AuthServiceRegister200JSONResponseBody, using the provided AuthUser
+func (t *AuthServiceRegister200JSONResponseBody) MergeAuthUser(v AuthUser) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsErrorResponse returns the union data inside the AuthServiceRegister200JSONResponseBody as a ErrorResponse
+func (t AuthServiceRegister200JSONResponseBody) AsErrorResponse() (ErrorResponse, error) {
+	var body ErrorResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromErrorResponse overwrites any union data inside the AuthServiceRegister200JSONResponseBody as the provided ErrorResponse
+func (t *AuthServiceRegister200JSONResponseBody) FromErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeErrorResponse performs a merge with any union data inside the AuthServiceRegister200JSONResponseBody, using the provided ErrorResponse
+func (t *AuthServiceRegister200JSONResponseBody) MergeErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t AuthServiceRegister200JSONResponseBody) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *AuthServiceRegister200JSONResponseBody) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
+// AsAuthServiceListSessions200JSONResponseBody0 returns the union data inside the AuthServiceListSessions200JSONResponseBody as a AuthServiceListSessions200JSONResponseBody0
+func (t AuthServiceListSessions200JSONResponseBody) AsAuthServiceListSessions200JSONResponseBody0() (AuthServiceListSessions200JSONResponseBody0, error) {
+	var body AuthServiceListSessions200JSONResponseBody0
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromAuthServiceListSessions200JSONResponseBody0 overwrites any union data inside the AuthServiceListSessions200JSONResponseBody as the provided AuthServiceListSessions200JSONResponseBody0
+func (t *AuthServiceListSessions200JSONResponseBody) FromAuthServiceListSessions200JSONResponseBody0(v AuthServiceListSessions200JSONResponseBody0) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeAuthServiceListSessions200JSONResponseBody0 performs a merge with any union data inside the AuthServiceListSessions200JSONResponseBody, using the provided AuthServiceListSessions200JSONResponseBody0
+func (t *AuthServiceListSessions200JSONResponseBody) MergeAuthServiceListSessions200JSONResponseBody0(v AuthServiceListSessions200JSONResponseBody0) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+// AsErrorResponse returns the union data inside the AuthServiceListSessions200JSONResponseBody as a ErrorResponse
+func (t AuthServiceListSessions200JSONResponseBody) AsErrorResponse() (ErrorResponse, error) {
+	var body ErrorResponse
+	err := json.Unmarshal(t.union, &body)
+	return body, err
+}
+
+// FromErrorResponse overwrites any union data inside the AuthServiceListSessions200JSONResponseBody as the provided ErrorResponse
+func (t *AuthServiceListSessions200JSONResponseBody) FromErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	t.union = b
+	return err
+}
+
+// MergeErrorResponse performs a merge with any union data inside the AuthServiceListSessions200JSONResponseBody, using the provided ErrorResponse
+func (t *AuthServiceListSessions200JSONResponseBody) MergeErrorResponse(v ErrorResponse) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	merged, err := runtime.JSONMerge(t.union, b)
+	t.union = merged
+	return err
+}
+
+func (t AuthServiceListSessions200JSONResponseBody) MarshalJSON() ([]byte, error) {
+	b, err := t.union.MarshalJSON()
+	return b, err
+}
+
+func (t *AuthServiceListSessions200JSONResponseBody) UnmarshalJSON(b []byte) error {
+	err := t.union.UnmarshalJSON(b)
+	return err
+}
+
 // AsCartSummary returns the union data inside the CartsServiceGetByUser200JSONResponseBody as a CartSummary
 func (t CartsServiceGetByUser200JSONResponseBody) AsCartSummary() (CartSummary, error) {
 	var body CartSummary
@@ -2032,22 +3139,22 @@ func (t *OrdersServiceGet200JSONResponseBody) UnmarshalJSON(b []byte) error {
 	return err
 }
 
-// AsProductsServiceList200JSONResponseBody0 returns the union data inside the ProductsServiceList200JSONResponseBody as a ProductsServiceList200JSONResponseBody0
-func (t ProductsServiceList200JSONResponseBody) AsProductsServiceList200JSONResponseBody0() (ProductsServiceList200JSONResponseBody0, error) {
-	var body ProductsServiceList200JSONResponseBody0
+// AsProductListResponse returns the union data inside the ProductsServiceList200JSONResponseBody as a ProductListResponse
+func (t ProductsServiceList200JSONResponseBody) AsProductListResponse() (ProductListResponse, error) {
+	var body ProductListResponse
 	err := json.Unmarshal(t.union, &body)
 	return body, err
 }
 
-// FromProductsServiceList200JSONResponseBody0 overwrites any union data inside the ProductsServiceList200JSONResponseBody as the provided ProductsServiceList200JSONResponseBody0
-func (t *ProductsServiceList200JSONResponseBody) FromProductsServiceList200JSONResponseBody0(v ProductsServiceList200JSONResponseBody0) error {
+// FromProductListResponse overwrites any union data inside the ProductsServiceList200JSONResponseBody as the provided ProductListResponse
+func (t *ProductsServiceList200JSONResponseBody) FromProductListResponse(v ProductListResponse) error {
 	b, err := json.Marshal(v)
 	t.union = b
 	return err
 }
 
-// MergeProductsServiceList200JSONResponseBody0 performs a merge with any union data inside the ProductsServiceList200JSONResponseBody, using the provided ProductsServiceList200JSONResponseBody0
-func (t *ProductsServiceList200JSONResponseBody) MergeProductsServiceList200JSONResponseBody0(v ProductsServiceList200JSONResponseBody0) error {
+// MergeProductListResponse performs a merge with any union data inside the ProductsServiceList200JSONResponseBody, using the provided ProductListResponse
+func (t *ProductsServiceList200JSONResponseBody) MergeProductListResponse(v ProductListResponse) error {
 	b, err := json.Marshal(v)
 	if err != nil {
 		return err
@@ -2528,8 +3635,20 @@ func (t *UsersServiceUpdate200JSONResponseBody) UnmarshalJSON(b []byte) error {
 	return err
 }
 
-// ServerInterface represents all server handlers.
-type ServerInterface interface {
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+
+	// (GET /auth/api-keys)
+	AuthServiceListApiKeys(w http.ResponseWriter, r *http.Request)
+
+	// (POST /auth/api-keys)
+	AuthServiceCreateApiKey(w http.ResponseWriter, r *http.Request)
+
+	// (DELETE /auth/api-keys/{keyId})
+	AuthServiceRevokeApiKey(w http.ResponseWriter, r *http.Request, keyId string)
+
+	// (POST /auth/change-password)
+	AuthServiceChangePassword(w http.ResponseWriter, r *http.Request)
 
 	// (POST /auth/login)
 	AuthServiceLogin(w http.ResponseWriter, r *http.Request)
@@ -2540,6 +3659,33 @@ type ServerInterface interface {
 	// (GET /auth/me)
 	AuthServiceGetCurrentUser(w http.ResponseWriter, r *http.Request)
 
+	// (GET /auth/oidc/callback)
+	AuthServiceOidcCallback(w http.ResponseWriter, r *http.Request, params AuthServiceOidcCallbackParams)
+
+	// (POST /auth/oidc/link)
+	AuthServiceOidcLink(w http.ResponseWriter, r *http.Request)
+
+	// (GET /auth/oidc/login)
+	AuthServiceOidcLogin(w http.ResponseWriter, r *http.Request)
+
+	// (POST /auth/password-reset)
+	AuthServiceRequestPasswordReset(w http.ResponseWriter, r *http.Request)
+
+	// (POST /auth/password-reset/confirm)
+	AuthServiceConfirmPasswordReset(w http.ResponseWriter, r *http.Request)
+
+	// (POST /auth/refresh)
+	AuthServiceRefresh(w http.ResponseWriter, r *http.Request)
+
+	// (POST /auth/register)
+	AuthServiceRegister(w http.ResponseWriter, r *http.Request)
+
+	// (GET /auth/sessions)
+	AuthServiceListSessions(w http.ResponseWriter, r *http.Request)
+
+	// (DELETE /auth/sessions/{sessionId})
+	AuthServiceRevokeSession(w http.ResponseWriter, r *http.Request, sessionId string)
+
 	// (GET /carts/users/{userId})
 	CartsServiceGetByUser(w http.ResponseWriter, r *http.Request, userId Uuid)
 
@@ -2631,6 +3777,74 @@ type ServerInterfaceWrapper struct {
 
 type MiddlewareFunc func(http.Handler) http.Handler
 
+// AuthServiceListApiKeys operation middleware
+func (siw *ServerInterfaceWrapper) AuthServiceListApiKeys(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuthServiceListApiKeys(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AuthServiceCreateApiKey operation middleware
+func (siw *ServerInterfaceWrapper) AuthServiceCreateApiKey(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuthServiceCreateApiKey(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AuthServiceRevokeApiKey operation middleware
+func (siw *ServerInterfaceWrapper) AuthServiceRevokeApiKey(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+	_ = err
+
+	// ------------- Path parameter "keyId" -------------
+	var keyId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "keyId", r.PathValue("keyId"), &keyId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: "", ValueIsUnescaped: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "keyId", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuthServiceRevokeApiKey(w, r, keyId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AuthServiceChangePassword operation middleware
+func (siw *ServerInterfaceWrapper) AuthServiceChangePassword(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuthServiceChangePassword(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // AuthServiceLogin operation middleware
 func (siw *ServerInterfaceWrapper) AuthServiceLogin(w http.ResponseWriter, r *http.Request) {
 
@@ -2673,6 +3887,189 @@ func (siw *ServerInterfaceWrapper) AuthServiceGetCurrentUser(w http.ResponseWrit
 	handler.ServeHTTP(w, r)
 }
 
+// AuthServiceOidcCallback operation middleware
+func (siw *ServerInterfaceWrapper) AuthServiceOidcCallback(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+	_ = err
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params AuthServiceOidcCallbackParams
+
+	// ------------- Optional query parameter "code" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", false, false, "code", r.URL.Query(), &params.Code, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		var requiredError *runtime.RequiredParameterError
+		if errors.As(err, &requiredError) {
+			siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "code"})
+		} else {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "code", Err: err})
+		}
+		return
+	}
+
+	// ------------- Required query parameter "state" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", false, true, "state", r.URL.Query(), &params.State, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		var requiredError *runtime.RequiredParameterError
+		if errors.As(err, &requiredError) {
+			siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "state"})
+		} else {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "state", Err: err})
+		}
+		return
+	}
+
+	// ------------- Optional query parameter "error" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", false, false, "error", r.URL.Query(), &params.Error, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		var requiredError *runtime.RequiredParameterError
+		if errors.As(err, &requiredError) {
+			siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "error"})
+		} else {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "error", Err: err})
+		}
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuthServiceOidcCallback(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AuthServiceOidcLink operation middleware
+func (siw *ServerInterfaceWrapper) AuthServiceOidcLink(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuthServiceOidcLink(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AuthServiceOidcLogin operation middleware
+func (siw *ServerInterfaceWrapper) AuthServiceOidcLogin(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuthServiceOidcLogin(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AuthServiceRequestPasswordReset operation middleware
+func (siw *ServerInterfaceWrapper) AuthServiceRequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuthServiceRequestPasswordReset(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AuthServiceConfirmPasswordReset operation middleware
+func (siw *ServerInterfaceWrapper) AuthServiceConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuthServiceConfirmPasswordReset(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AuthServiceRefresh operation middleware
+func (siw *ServerInterfaceWrapper) AuthServiceRefresh(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuthServiceRefresh(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AuthServiceRegister operation middleware
+func (siw *ServerInterfaceWrapper) AuthServiceRegister(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuthServiceRegister(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AuthServiceListSessions operation middleware
+func (siw *ServerInterfaceWrapper) AuthServiceListSessions(w http.ResponseWriter, r *http.Request) {
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuthServiceListSessions(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// AuthServiceRevokeSession operation middleware
+func (siw *ServerInterfaceWrapper) AuthServiceRevokeSession(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+	_ = err
+
+	// ------------- Path parameter "sessionId" -------------
+	var sessionId string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "sessionId", r.PathValue("sessionId"), &sessionId, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true, Type: "string", Format: "", ValueIsUnescaped: true})
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sessionId", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.AuthServiceRevokeSession(w, r, sessionId)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
 // CartsServiceGetByUser operation middleware
 func (siw *ServerInterfaceWrapper) CartsServiceGetByUser(w http.ResponseWriter, r *http.Request) {
 
@@ -2976,6 +4373,19 @@ func (siw *ServerInterfaceWrapper) OrdersServiceList(w http.ResponseWriter, r *h
 		return
 	}
 
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", false, false, "cursor", r.URL.Query(), &params.Cursor, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		var requiredError *runtime.RequiredParameterError
+		if errors.As(err, &requiredError) {
+			siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "cursor"})
+		} else {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		}
+		return
+	}
+
 	// ------------- Optional query parameter "status" -------------
 
 	err = runtime.BindQueryParameterWithOptions("form", false, false, "status", r.URL.Query(), &params.Status, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
@@ -3135,6 +4545,19 @@ func (siw *ServerInterfaceWrapper) OrdersServiceListByUser(w http.ResponseWriter
 		return
 	}
 
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", false, false, "cursor", r.URL.Query(), &params.Cursor, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		var requiredError *runtime.RequiredParameterError
+		if errors.As(err, &requiredError) {
+			siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "cursor"})
+		} else {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		}
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		siw.Handler.OrdersServiceListByUser(w, r, userId, params)
 	}))
@@ -3233,6 +4656,19 @@ func (siw *ServerInterfaceWrapper) ProductsServiceList(w http.ResponseWriter, r
 		return
 	}
 
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", false, false, "cursor", r.URL.Query(), &params.Cursor, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		var requiredError *runtime.RequiredParameterError
+		if errors.As(err, &requiredError) {
+			siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "cursor"})
+		} else {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		}
+		return
+	}
+
 	// ------------- Optional query parameter "name" -------------
 
 	err = runtime.BindQueryParameterWithOptions("form", false, false, "name", r.URL.Query(), &params.Name, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
@@ -3259,6 +4695,19 @@ func (siw *ServerInterfaceWrapper) ProductsServiceList(w http.ResponseWriter, r
 		return
 	}
 
+	// ------------- Optional query parameter "includeDescendants" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", false, false, "includeDescendants", r.URL.Query(), &params.IncludeDescendants, runtime.BindQueryParameterOptions{Type: "boolean", Format: ""})
+	if err != nil {
+		var requiredError *runtime.RequiredParameterError
+		if errors.As(err, &requiredError) {
+			siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "includeDescendants"})
+		} else {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "includeDescendants", Err: err})
+		}
+		return
+	}
+
 	// ------------- Optional query parameter "minPrice" -------------
 
 	err = runtime.BindQueryParameterWithOptions("form", false, false, "minPrice", r.URL.Query(), &params.MinPrice, runtime.BindQueryParameterOptions{Type: "number", Format: "float"})
@@ -3311,6 +4760,19 @@ func (siw *ServerInterfaceWrapper) ProductsServiceList(w http.ResponseWriter, r
 		return
 	}
 
+	// ------------- Optional query parameter "facets" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", false, false, "facets", r.URL.Query(), &params.Facets, runtime.BindQueryParameterOptions{Type: "array", Format: ""})
+	if err != nil {
+		var requiredError *runtime.RequiredParameterError
+		if errors.As(err, &requiredError) {
+			siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "facets"})
+		} else {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "facets", Err: err})
+		}
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		siw.Handler.ProductsServiceList(w, r, params)
 	}))
@@ -3449,6 +4911,19 @@ func (siw *ServerInterfaceWrapper) UsersServiceList(w http.ResponseWriter, r *ht
 		return
 	}
 
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameterWithOptions("form", false, false, "cursor", r.URL.Query(), &params.Cursor, runtime.BindQueryParameterOptions{Type: "string", Format: ""})
+	if err != nil {
+		var requiredError *runtime.RequiredParameterError
+		if errors.As(err, &requiredError) {
+			siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "cursor"})
+		} else {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		}
+		return
+	}
+
 	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		siw.Handler.UsersServiceList(w, r, params)
 	}))
@@ -3675,6 +5150,19 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 	m.HandleFunc(http.MethodPost+" "+options.BaseURL+"/auth/login", wrapper.AuthServiceLogin)
 	m.HandleFunc(http.MethodPost+" "+options.BaseURL+"/auth/logout", wrapper.AuthServiceLogout)
 	m.HandleFunc(http.MethodGet+" "+options.BaseURL+"/auth/me", wrapper.AuthServiceGetCurrentUser)
+	m.HandleFunc(http.MethodPost+" "+options.BaseURL+"/auth/register", wrapper.AuthServiceRegister)
+	m.HandleFunc(http.MethodPost+" "+options.BaseURL+"/auth/change-password", wrapper.AuthServiceChangePassword)
+	m.HandleFunc(http.MethodPost+" "+options.BaseURL+"/auth/password-reset", wrapper.AuthServiceRequestPasswordReset)
+	m.HandleFunc(http.MethodPost+" "+options.BaseURL+"/auth/password-reset/confirm", wrapper.AuthServiceConfirmPasswordReset)
+	m.HandleFunc(http.MethodPost+" "+options.BaseURL+"/auth/refresh", wrapper.AuthServiceRefresh)
+	m.HandleFunc(http.MethodGet+" "+options.BaseURL+"/auth/sessions", wrapper.AuthServiceListSessions)
+	m.HandleFunc(http.MethodDelete+" "+options.BaseURL+"/auth/sessions/{sessionId}", wrapper.AuthServiceRevokeSession)
+	m.HandleFunc(http.MethodGet+" "+options.BaseURL+"/auth/api-keys", wrapper.AuthServiceListApiKeys)
+	m.HandleFunc(http.MethodPost+" "+options.BaseURL+"/auth/api-keys", wrapper.AuthServiceCreateApiKey)
+	m.HandleFunc(http.MethodDelete+" "+options.BaseURL+"/auth/api-keys/{keyId}", wrapper.AuthServiceRevokeApiKey)
+	m.HandleFunc(http.MethodGet+" "+options.BaseURL+"/auth/oidc/login", wrapper.AuthServiceOidcLogin)
+	m.HandleFunc(http.MethodPost+" "+options.BaseURL+"/auth/oidc/link", wrapper.AuthServiceOidcLink)
+	m.HandleFunc(http.MethodGet+" "+options.BaseURL+"/auth/oidc/callback", wrapper.AuthServiceOidcCallback)
 	m.HandleFunc(http.MethodGet+" "+options.BaseURL+"/carts/users/{userId}", wrapper.CartsServiceGetByUser)
 	m.HandleFunc(http.MethodDelete+" "+options.BaseURL+"/carts/users/{userId}/items", wrapper.CartsServiceClear)
 	m.HandleFunc(http.MethodPost+" "+options.BaseURL+"/carts/users/{userId}/items", wrapper.CartsServiceAddItem)
@@ -3711,68 +5199,119 @@ func HandlerWithOptions(si ServerInterface, options StdHTTPServerOptions) http.H
 // const string: with thousands of chunks the chained `+` fold is several
 // times slower for the Go compiler than parsing a slice literal.
 var swaggerSpec = []string{
-	"7F3db9s4Ev9XBN0B1wLaOujeU97cJN31bc7Jxs4esN2iYKRxzI0kqiSV1Cjyvx84pL4s0qZdx0navDXm",
-	"13A+f5wh1a9hzLKC5ZBLER5+DQvCSQYSOP51xhPgEyA8np+rBvEG8uSYSFCNCYiY00JSloeH4UmeBAmR",
-	"EMwYD5gaF8QcCLZGIXwpUpZAeDgjqYAopGrI5xL4IozCnGQQHobV1FEo4jlkRK0xYzwjMjwM1dQ/SZqp",
-	"ZrkoVH8hOc2vw/v7yEKnkIRLO6UT1fSNtDbT74paWYo+qe9pKoEHVwtDpennTaPu3BD4Tw6z8DD8x6AR",
-	"+kC3ioGmSo+xU1kK4KNkFZWqRzA69iTQzOdLYFnSBCk7J9c0R2kZwlKaUdmn67/kC83KLMjL7Ap4wGYB",
-	"lZCJQLKAgyy5r7D19G0yE5iRMpXh4duDqJE6zeXPbxuJ01zCNXA7yWw2E2ChedynVdzQwpNSM6uVVF9K",
-	"OUvKWHYEHxMJ14wvVgu/6uWvAK15N1cCC50Z+XLOaQxuVSiw2Y+6ejaric9SRmTDQq1jbspo7qKM5ptT",
-	"Vs22A8r0lD0fiV2UVAs9JsB+fuSZrg1pfRdoowRdnIUUxqV2f75GYPpabACnVtPkZRYefggJ/oU/fow8",
-	"yRSMy3cLB50zCmni65/1RHZCMR5BMpQtag1fK01pulhov6+mxagyTJIjwuVIQnYBn0sQFs8zTBL0Ocrl",
-	"xCpCctNTrcgK4JKChgiaKdoZkDQ9m4WHH3ys9mO0tOToWDk6OYdayyQLSJKE91H4uSS5pNLC6N9NS9XZ",
-	"z6+p3VAOiWJks4PWOg0T2dXfEEtFxDBJOAhLYL5UgY6Y1mUGxVayjxTJRoRL0orCmJW55LZRusE5sGBC",
-	"kvQIdW157Dm2BYwHf47Og1j1scygYIIdJUlQYwvObmkeO8ZysEWxCf7eYlDftNryMNNEmnMVSZ3NNSyy",
-	"yqmUcyUSi1aXcg65pCrUJBqfxCyX8KWv15ARmtpF/S8RYKt7R1FIv90eLg18uq98hIOWWZmmDo1Y4ixF",
-	"Z4QbM3Pa2Kd8g0WIc1YUNL9Gb9BX8tr79AZOaQZCkqwI7uaQo31zEKzkMQR3RARmaNtwVwDm3XA2p59L",
-	"CGiidGFGgSP0V5Th5tQiCm7193JKhWzQGM2bMa0hqwiq/K5aw+yMcE4W6u+ySLblYUqEDMx4b0Y2AH5H",
-	"bhvt6W7OAnaXi0DOqTD8tKlhDfc136JOjGtY4VJQZGLfP6pIpSZ0hSn/3Zpgb9mwaQkSkISmInhVsKJM",
-	"0aOgdGYg43llKa/RLT9gjGwroV+g7E7wkAFTiWNSZhnRscxv5+h++jtH0Qo9WXBH5TyISRobvksmSdoP",
-	"vfjzMFOhwmJTqlEDbcUSkqZWs14HpCO9ysjuMPQiy0dOxwqe7G/vqrO6XQT6UGWzFXNAy1gC6Xfs0Q0D",
-	"nGF0TDKojKLubQNXhEO+YyPGKZuzsiJ6ToGrAwaNSRoIyctYlhz2HyBsTtugjE1ctd7ZlANs4gIqmVnc",
-	"gGEVuoAchDL/eE7ThEPeV+KqwRnJsUclADXKO5C3NtYL5ku8q8mwsgiZWc3nPJDVG69yk84T2RPR8jP8",
-	"B0kDp7r3+OSGpMgjzEG6GYR9TGrUxRsvXCdZnWHw0gWky4XqxJwibm4dHv3YWg3oc3ZipqzOH7Wv01T3",
-	"LNcArGVK3Iw2CMfJ6goBrVXFbqpwR06z9paIMCscdAUpy6+V8NQ+OmOXyT9G4AZJ0Pq5j4r6YSoj13DJ",
-	"U4v+1LqeGkWq0ZkaE1xenHYcS2/qZaVZb8Ir6CzsqcXzCuk4wJ8T3wjJ4pv+fKOcSoohisU3QY0DN8cz",
-	"Jqi0Z2/SWnrxqK1Jbr1VB2Kn0uJxeq3Gkt2Zaa0UYsleFcHfmF7YUUJgbS7ghHPG7fmkiSR5QngSgOqD",
-	"CSWhjoBJIOeclddzVkrUteH5qJW2fDc8/nRx8vvlyWQaRuHleHg5/fXsYvTnyXEYhe/PLt6Njo9PxmEU",
-	"js+mn96fXY7V70dn4/enoyM14o/h6eh4OB2djT+dXFycXYRROBpPLt+/Hx2NTsbTT5Pp2dFv+CP2/DSZ",
-	"Dqcnn6YXw/FkpEZh0/TkYjw8rSeYnFz8MTo6+XQ5Hv4xHJ0O352eWLKohhsXIAqWC7Bl57KM5YYfvOrW",
-	"SyypZkvFFEfRXFtPZQQdZ2rE4KeXjeT6mnlSS6xCzAulnkpYas/KSWgq0ZPiKdeaIaZGwfWOq573UZiB",
-	"EOTawqJfy4zkP3EgCblKwQyseq/TVpOzrLr39XVZu3EPNrU+Zdc0d3oKbHV6iG+03IIIccd44pyh7uBr",
-	"vPWAFTt1qWy1Vd2uQTWJYwUqJLuxIGrdOMW23mz/+d90eXRv//CloBzEKLcdlm8gD7CDdtLqYKIOywJi",
-	"lifCJ7aoA/EN5FP82b4Aqvgrkt6RhQjeAeHAX7cdFP5itf/SN6m8yox/1MTy0pC2IrWF1tYQw3GbXp/d",
-	"uJV6QrMiheDsN7cTbjmo1TtzOxtzJ8ICBvEE8n1nVcxJY7NE+bM+VEVhcy3Hb63OFZp+IqPkeBjWs1a4",
-	"vF7MI3VJsLU70gfPfz91hiIlMeJN59HXWmnoJlCbi1FLSrVJnqvRVoc7cBQlVpzUiEbPGAPZbAP5PkS1",
-	"AVeHpD43NsuMfY+qrg319MWnhKHJ2VXtojlutjfllPPEcT/vrHUrL0A40aCKAvJE7Q+XUJFP/4E6hxtJ",
-	"IKW3ZlMxyWNIU0isKOS8KWXZ0zKO0PNYuZg9xrwHSfs8ZBxt2dOK9NLpj5NVasLiplmlJ1Qf8U5ltaW+",
-	"Sbi5xNa1l7h0N31/Cy9zuc60bpc7hrtaCp1bElVE29QBrywYV/vyrYVoLm1YCdGLJHiy2WtFpFp4ueqn",
-	"jmL3Tm60Qs46QbevhTuZslsYq/TDLLi6CmGWdUvdt+iwRuY7DHOVvDqCWhNkqjE7Ky1UEz5oZcFmEpvH",
-	"glq/dxwTqnm3qjQ4tG19qWCNnu2wUFDvb4M6gRmybaHADMeTlDPD02edNf+F3LJjzl0ySeekbDzaI8B8",
-	"Ask7J5bEXNk35PSeAoDq5BA3QUTIrf6mL0fHOt9LUkrsV48FxCWncjFRfNdqq1PAw1LOMWBiQ3hYZYbr",
-	"SeZSFvpyPc1nzFLSOQomVGIB7K/8r3xCMC0JP8Usy0Axbng+Cq5KmkqdeJ8uCpgUEKsVqEyhO0UYhbfA",
-	"hZ764M3BmwO1b1ZATgoaHoY/408Krsg57mJASjkfpOyaYpwqmLvWgcsb7c2TdglCWTPmsVU8xVT3BPgt",
-	"jQEHhlqEIOQ7lix0kSqXoHNWpChSGuPgwd9CB0u/Zz2d8sx9V1EkLwF/0Jld3Onbg4ON1ib5wsMAu6WT",
-	"+8ij3tb0/ohkL9nQvA4nwZyIQJRxDJBA8kZt8j5qJMZKuVJkrJTBK5rfkpRikML0+es14lKT7oVxrdz8",
-	"Q3OtMV+kqm24Hz6q6Sqmapd4bXue8AvIIDbHTkv1xsnSX0Ca0yrGxb2wtn7a8BQYq46BYqBYJgZfdZb1",
-	"fjWX1bmx8zC0y1x1oBUNd98tDGPbz5A/fNXPp5Sbszwe7TqKDd8RftyLDNu3oh9ZjFEoybViqmZ96Jbr",
-	"oFXoScH2ROgoBcJb16hnnGXVFWe3mHHUMxLxBsLaTFRR+Pbg3xbcNQcOARVBzgJDJL4ChjwxwI/WR/0o",
-	"uDJXbuZAEuAiyMgiuEJkOCvTN8E2ChE5otDyA8GVUh4mycgUQPYp590jE8vTyUfDJ8/Yjwy+1nWY+1U+",
-	"5QIydgtaz/z8iR6xZ2WLrHO3S00vYenb1AmPNfHcI8XdSg+5tUSP+g61ZPcez15qeHF6bqdXPyVxQWEs",
-	"5ymg1Hl2sqytVVN1gKNih8e3jd64WPLID834hrM1h9xQxLz8IEEOd02y/tUwyWgesDxdvF7PXj3HA+Uz",
-	"7E98HtGGark+GQNqiblrRQNpno6tOFVWdxA4QPDK9jDMQwHwIdfj2Jf9Ddnj2NgS87825bSVQO0Yfw/I",
-	"tuanx3sF4075/OUsuJ+zYMcLOy2xJf6rhT3BsyT3X/BDH89J6E/OxbpC5Uq8vLWh6vGPIbOHg7YvYdk3",
-	"LOPVDg9gq/vpshKrnsHN8NtwNL9erXJ432QJ9S4pm405TZeB44uALrauGmk+oecx1PkZx63GmhPntuua",
-	"r1FuNbz68OYOPeXSRR7NZEha7444KQqd6vd5rj1UCKn/OQ1Twyn0WzL/Vwa2ezIbfkSyAF6t63FR0fdz",
-	"j+Kmuh3s9e5JktTzCyRb3NvrXJ4Po/ozmGYvlpr8E3Fi2p90HNhAX7AefMU/DbR1nC2xZ0Dy+r76Cm+l",
-	"O3sFR7Py80AzlZU8YYlqZ7sk0ZUQqHNZ0j8i6dGT6tHIfiX9UBjIcsf00WDQM1C2DUreBgmtKHr38M7e",
-	"y97RYyCqF3Txgi6+J3ThlZzWMQcrmaW+j2ktaHZBRZWZfualc8s3nF5ijDvGdJCMO+NmcKkj39ZRJN9c",
-	"2ws03ZEkTWnXI19S9XRlTHqCNY9hnl+axPnR+S3HtpKMW85Qfz5/2/HVfwyw5Xjz4fctR5vHVj8Cmjpv",
-	"3sq+4Klnh6cqD1l5Ll/IVL13W3lCX3KHeyjmL71WfDQk0xjFE4mALfm2Y6Dvjb+6kLyN3DeoIj+Zy3k/",
-	"TBG5Y/grSsj1RySsiHZJ4r6Y9pndxdyfVdvd8pra8Ta2uUHh+HlciXyJAH4RAJOl649A2G21Rl2Kp1cY",
-	"/hGAt3lw9oK6v9ssJlqWLyIvhaUO2rbNPcDv9ucbHs3z7ukh5rKQaqfaqUCtBdRrxbYBen55i7dX6NyY",
-	"5wrcjPVFO2huS9kXMT+nF7VP4UW01ZmuwdFrTXID0Pykaz/9b+587057Q13RQ/htJeBlH34LKSsy/DAe",
-	"9gqjsOSp+QbJ4WCQspikcybk4c8HBwdha4nqK2gNLFebN7+1Lni2ftVEdbrx7jhT5rj/eP//AAAA//8=",
+	"7D1rc9w4cn8FxaTqdiu0pNu9VBJd5cNYks+T9Uo6jbSX3K7LBZE9MziRIBcAJc+59N9TeJHgECA5o7et",
+	"T9KQeDS6G/1CN/glSoq8LChQwaP9L1GJGc5BAFO/TlgKbAaYJctT+YLvAE0PsQD5MgWeMFIKUtBoPzqi",
+	"KUqxADQvGCpkP5QwwOptHMHnMitSiPbnOOMQR0R2+b0CtoriiOIcov3IDh1HPFlCjuUc84LlWET7kRz6",
+	"jSC5fC1WpWzPBSN0Ed3exh44ucBM+CGdyVd3hLUZ/r6gFRXvgvqOZAIYulwZKE270TDqxg2A/8pgHu1H",
+	"/7LbEH1Xv+W7Girdxw9lxYFN0z4oZQs0PRwJoBlvLIBVRVIF2SleEKqoZQBLKsYL1gXspMS/V4D0azRn",
+	"RY7EEhCFz+JAPysYKhlc219zhNVvUlQclXgBO2gqEIcMEsFVX/kQEcoF4FS2L+ZzDmJn5IoNoO6Ku+zR",
+	"WV5GciK6q/sZfyZ5lSNa5ZeggCcCco5EgRiIio3lZT28C1MKc1xlItr/YS9umJpQ8eMPDUMTKmABzA+y",
+	"RksX5uMurPyKlCMhNaN6QR0LKSvSKhEtvk6wgEXBVv28bVuN529n3M153APnHCcgfFJCPZe4TIqKClRc",
+	"A0M4y1CpB5GciwXKsUiWionnalE8RrCz2EF62P+20MYlIwmMXKIByV2eIqz8B2iVR/u/1miI4sgOzUWR",
+	"XEUfO8KxfoAZw6sgIghNsiqFQ+AJ0BRTH1ImGS/Mkms0EKqWz6tLAxMBLnmxIVSMsECYrlAKpViORIIH",
+	"HC+LmiHMEi+LIgNMg4vM8edTha7gxt+EUPVoXn01zwosmg2jJUoYMkJDkBG6OWSE3htkesjO/qiy7I2A",
+	"zwJx1VhvEMMVSPbhCEvzpenFd9DfCpZyhBloLoJUCgEuII/lPyWDOfmsut0QsURiVRY8Vr/hGtgK3RQs",
+	"RXnFzb4bqyHUnwH94Fm4Mg88pk7BhDYdxkpY09bDvWroKK63NVa/1MOP8UgwecHE21UAzjmBLN1BDDK4",
+	"xjQBJFtzhfWkYKCRS7QaNlChmyVQQ1VCF7KtXMhYZBtw/MtVFiGkE+Gs2VDHsrfbpAbbh41bO4USVJM0",
+	"PcBMTAXkZ/B7BdyjKCdpqlSkkurSXmWmpZy9KIEJAmoww8Zad+EsO5lH+7+OUTIf47Upp4dSFiojx2wN",
+	"USCcplIs/15hKojwkO6v5o1t7FHDuZYK0f4fvSpZrowwSCWCm9U4czYILS7/AYmQAE3SlAH3yP0LLpWf",
+	"ebuOrMS7hAMJviFtRyEplcp8vfSLYMey4AJnB4oH1/ueqnfS+vz79BQlReodgYuQ/yJAW67FNaFJoC8D",
+	"nwE2U88dBHU3rksPM0ysMWdBai2uQZGXTiX5CTzYm5xO0RWsEEY5lnsXUJIRoALhSiyBCiJ1Mtey1TG3",
+	"s2KxkBud0C5t683YmexvUkpIxpYz3mCOTNsoHuWyxRFJwyuYHvp6ZJiLCz4SHNlY+k7jAfIrumOcg93D",
+	"Bp12GsKls+vlU6XJfJYv4wIlS8xwIoBxO/AVrGK52QVkmfzBES4xE14mTIoSuA8BuIEsxyt0CWr5BsTa",
+	"guwTYpqxZnIGr93ocjFJrcyvl1sD50rxMAPrebr7GFhOOCcFRQuGqYBUiUGKDHM4qsMaofs3jKgt1Jig",
+	"9SOlffk+A5w2v5r2TNTv9A/7quJON/1Dv/JZ2JNKLKWQ9HC0s/VS7csnBZWGU2ezQY5J5he+f+BIvQ3L",
+	"GLuf7qStLkyoIbgZDCzzKsuCMpoVmY8/z+Rjl6KSVyU+/oySiosil9thia8B0YLCWI6Vo45jVY1dszAf",
+	"U0rzwSPbl0VZSumYYNYlWY98PCc5cIHzUttTcrUMeFGxBO4gLu9GXkpk6IakkiHnBJiK1SnBJhd36+C8",
+	"3fED4aKJLxDa9BlJJmuadUkVR1WZbotDLePLdCNENhG3e7Ls1Ka+WRaouKHSkibc4NPHhnV8TuOtbe82",
+	"qAgxqEJi12ySxqwcMGTJjl+t8TA8CzZvUAoCk4yj78qirDIl1hR15iC0wyAX/73Wgg9nRrtMOM6Wbg8Q",
+	"jQttbWVHS3LMqjzHbDV+5QdqJZ2VK9JyPZi23BKcJQbvohA461rk6vEklxakZ0/JlzqYoGKzWebd1kPB",
+	"gljPMvULDD3JehA1MMNI9Luras3uJ4GJj3n2in6D8iKF7CuW6AYB4wzbJpzYtWUxA3rPm1gNWc+qgF4S",
+	"YCrkkOAMccGqRFQMnkJBXAPjavj12X7RL+wq7EwxIjRhkIOybApqglV62i0Y3bWsN9EOGpkqcN13SOBG",
+	"bnFDAxUIEhylrYjr2uZoBfXviRlcTk38MssPvNtZuu4FBS1r1hcxSIC7bZE18rVOKCwZ1br6yHbOADZR",
+	"FhZnHoVhMKKUBQUumTJZkixl4HHw7YugzadaoMaxGm/yOQsbstBrMLwoWmK6gFPMuYz/BqN7toF0r+kC",
+	"gsG9pGIMqLDNPRpCN0ClbeGLFcBNeIBjuKk7o++wQBlIOfSfjuP//TAbrYHZntOLJyUrtG8djoGaCIs9",
+	"mw+i6R6jIc8lZFGfAyhwwii0rBtEYr3H7gOLjVzJCf0AdCGWblT3ARTxifoHZyiokf2oC2NM5TX4fRP5",
+	"VCcBaF1msi4MwnaQOqdS2kfOoc6GBL4C2qQWGJm/89hhegXo+EC9PeZ5lFC9g/Ywl3rw3cHhKI/fWd04",
+	"2b/GFLcKCcZX+GM3BMCXRAVZnAOIceS0HboUnZkhbcSsNowNVddtLuONr0MSxr1xh8PqyDDSoIh4UKNK",
+	"hyMsU19CVtCFpGd0u9Z3HfxD5eVD6wC360J3fZocL+CCZdyXOGSkTmZ4y0Kl+qCLsw8t22Igi2GMydbA",
+	"OSRZ/Sfvp9ZJDsQNrGtcb/A9j5us0zK6YpESQZSnUyRXqN7rfeJjb1B8GAXnzrSeHxK7/BbmbhnoDbL2",
+	"BQc2zNf4/jZzzTp8bVdHtzawOzZsXqPXxoNHHgN5It+D6jpkH9bLIYYJhozFPytjvqiE8nHqmCMHwRER",
+	"6JrgZggGOp2r37ocjoYbxzNw1HgMN9nKRjyck5nnfHx45VvIuQGBCA7ZXKUmEo4Kmq1Mwp926S2377ye",
+	"S34l55KaH3ysf8RYwfw5BjOBaYpZikC2UUkGXK9GLFlRLdQelYudnE6dc8q3k8NPZ0d/vTianUdxdHE8",
+	"uTh/f3I2/fuR5NN3J2dvp4eHR8dRHB2fnH96d3JxLJ8fnBy/+zA9kD1+mXyYHk7OpyfHn47Ozk7Oojia",
+	"Hs8u3r2bHkyPjs8/zc5PDn5SD1XLT7PzyfnRp/OzyfFsKntFcXR+cvLp58nx/1k4Zqr5+dHZ8eRDPejs",
+	"6OyX6cHRp4vjyS+T6YfJ2w9H3uNOhaEz4GVBOfiyOPK8oAZHzDbrHHfK156cd9WLUL03rApzOyaGNOO0",
+	"SkPNrl45qqloQ6grqVwkAeWa5S7QUCprSR17+PYOZKlJB51rm+kaZyRVwMfK7lynX42U0dEUNcmRhWXd",
+	"FMqBc7zwUOJ9lWP6hgFO8WUGhiK29WAEQmei2ObdrbLWXqPKt6Mc6P3o0w6isSQCqOzwgcpt6w74P7OT",
+	"Y1QWhApg9pxZNbUxQzvNZZGuYsSrZIkwR7uKDrt7u9YWi5HxGKiRtBjVhRw+ATiWCH6D+jKD3DcqqzLP",
+	"kGdVBs7CLllxBc1SLFFiVJsoci3WkByiu8armbqP/nH0oVgQGrQT1dugfXjHdIeweWVGCIfvArZQ2Rdc",
+	"MysNiTy7VP1ex15xkgCXztaVJ/CqX56rd10W/tv5eu/O+uFzSRjwKfWdvsn4iWqgTXRBcpDczyEpaDoy",
+	"JM5gzoAvAyDOCF1k8KbioEFUcm4BQkgBSuFGP+U6irMrE9B2zYC+xajG5ytfOpBejOyBvsPZDV5x9BYw",
+	"A/a9q2DVE6+uqsam5fSpnK8iNWeLrJi1Li7TukRzudFg3LeHTkiafCDU5w0r7StWOv0yBSaTEpW4lg4R",
+	"oVc6QxknuhjDSHUTIlfk626wSiwLRv6pCHrBMj8P1C1k/MGKY9KBRhSIA00b10sUg/jtAOBFyVVYpsxI",
+	"XmaATn4K21COzukHpk+In/jz3NXjr/yUvA7xbpL4tFEotBUEfQZxzzhq6iLHzdWqYeweN5o9qEe1O6ie",
+	"bEQqCs51gdW8hdzh/JOvJ2+szHACaV902ps51k6IaSpT15hqkySCnnMc9SqUZNYTPjXevzJBZG3naPo+",
+	"xOmOmh3SOpjbTHM8NpocWlCHX8akpGlwHuLQqIn7ugsM0nwWKJY+cUqkkbK2nOxroKlcq5oiAc71D8V/",
+	"alEpZOTaLDDBNIEsg9RrpDWH+xzEQUHnhOXDB/1MtkaJbu433B7gnN4YPoNwBSz3TlabbjV0uN/C0FjU",
+	"bOh6Hbl2rGV7x+baJLTsXYPkyDOZnLFhkpLOVmSypycatGHKkB1mhAuUY0+s9D1ZLIGLJoWyHjRG8DnJ",
+	"Kk6uYQdNLrnUi1b95gUX0iUDKl/r9juj5GBOfNk5xU0IBlU564UhWQIuZbfRs3ciQqE0plMdwTgMBcnO",
+	"3h2g//rTv/+HDXXYLOIYKQhFYWLYJo4mHY1SIFyWmXTRSEF3Tcd/+wd/ZiHB4ajb5zLDupJfk4pwVCTa",
+	"eWmdL4ZiQPcaejTz3FPkkVAupGT3iSKxtGtrAnv1Oi0G5OlKqE7Pp5Den5+fWn2kqFRPUbtIIza2IMIX",
+	"VpstCyfb2oxsyd5DIOGNXFycTYM8ZAb7s3zIQB85ASqBOScL7lIqRoZViXbF9docg9CwqUOsWG+YwEa2",
+	"BQP+fAa/Q4ivMckkt8/8x91Noozk1QRTxAXJMnkYhNPUlHjpotzv9JF4TmjFlcph15CqYb8fR9wnS6h4",
+	"RK/4IXI3lmSxzMhiuXm1yPu6p6dsBDPBO9lV9d0Zxu9RMXZdcx7r01cOQocs5TOJrCoT/MEDAo5j0JPK",
+	"8uHxM1juO2elY2a0dlrPBl6qA4cVqqiO+aW2lF52V4pnZHxZXUDgsRTqqwq6GXmbcMqYNQdyc5qYxlBu",
+	"TndV33Kxwui8I3dzbRKeMOLmXeDOnsliwWCBBeg7e7i+k8ThodalPdhwTCghj/iyGX7GpsitHlHqbLu0",
+	"WE+slX3B1ZlLU4rgJNEjURQ76KD5bfN78s74mAHKYC5QUYkdV7yMSb5XmPKJnrJ2xUYv0nHC4iY5t+1T",
+	"oKocC+K6M+gBst6e44S9klxmsI/x4Jr0GR1Na9zXNoze+NFtA1KHBd+7unLNiKSkLEHrPFxLLzWbRNiN",
+	"ugKnpQAtJ6IbhssS5EH1b/S3am/vxyTH7Er9B0jgBd9BMl+J2xkkb7w///nDG+AJLiHtpkj32glSNVv5",
+	"4LxBmBWVOeyYq8QgBWiMiAyEW6i3y1Qy6Bgcqwf1UvmGD01OpSZ2vP64wfzcXOklUX8DDBDmV3Ue0lpq",
+	"QS1hNrKDjGDyRMkdeTTqEjFaCPSPyrhMSV2QsoCew4qJ3Dfdaku38+i9WVtB63tyw3vzlODAY+MtzSWC",
+	"XqXMizqhQ46J8FyleCy19xQjrMMdhV64UqelN9EljsbepcevbDBzBPjNrYejwL+EecEgCL/eeqEFqOD/",
+	"yMLbbdS6e8YQxfV9hgZvPuV8po/3g7FJfZBvkgCCocn+rAMzh005sEpIHxbXJry5azKYb7C21taU/pUt",
+	"CBc9CdG6AevPh75jDsH2F3KMzpTZulatlUUwkEijbuzw3g6iLwdRZ5c69+USVgWV6rHScBY3tLZjuXtr",
+	"WpoT6j1XmAH3W8eTRJBryzhct6pd/b60gjEpzbKjHHqhVPloU97M6x1YLI0la0Np0mHIcQpWvRFul/EH",
+	"Hsgdqi9HjKMUrr3eo77oawHNeWydWixZw6YuqzdmurG52YYUgdxsUgYvH5ue1kcSzrTtXGolCkKp2TMA",
+	"2ksv75AjyeZzh1yHxgGgobBvXzjW65jDjEHrdZ1xCQ149sHBWyE9hC+La0D/BFaMU4ZFJU7mG05Ji7VZ",
+	"t9Fc1PZ0IPAh/EK5moOXBupmOsihLg8Myffwea88Z7RvW1fu2OP0u5z+9pYJ2jWOrWfV7veG1ax6krTO",
+	"k32iqlYLxvrlEjJp7jaIG+f0e4gF3OvCgyi63+wayTlmwv76RTNtmAfGlisOcMA9xtYtvVqEGohs2z73",
+	"VpRoB3zQmkTfBrlrbWLN7Q9Yo2jnuHONYoAnh4sMB7jxHksM67VuUGFoutxbiaEZT5mPY2oMvZj1pjsr",
+	"ZAZO7u4Rh9pM96HwEY/GnkGudvB4SZL2bs7cN3m+sOZfjj8wUATq4vlieqhP4nFGsP/CXg5JxYhYzSSp",
+	"9U7RVYgya1zb09F+tASss/80QaP/fTM5nb75CZxbgrDqJYHRRQq2v+Ih2UA/bjoshSj19dayIsGTpXWA",
+	"ZkSoEsPf6G90hlWWOLxJijwHSWtZBXtZkcwEnWWG/qyEpE4NaA0ROSSO9nb2dvaU8V4CxSWJ9qMf1SNp",
+	"pomlwoKu5cAleSPrQeWThc9lUUek6970H7gtFZZIl1JIhUymqcnGnwGTfqnsq5EtmzW1cvtfoh/29iKV",
+	"70OFcZXdHCGVG2Rxq64ex3Rl9vEGhaYezT4ioaiOSd9+VNTr1hlb332JuazVSgBSGbN32U2B6jLKrx9v",
+	"P5qLr4M3fDR349rsKcER5h30K34gVN2QVVfEBqngXicU6U0JXLwt0tVGFBi+J6R9Y9FtWwIIVsHtvTHB",
+	"MDBpzQJPS/LbeG2n7X65gtU0vdVckIHwnp1fF1dgL0LbcvfpQWq6ux+v+tWIPSkMGqGn4IrWidb3vYWP",
+	"dyToBoTZjCxx9MPen7yXBOgkLVogA2RdEqRVO6k9whhdmhpwrRy4U/Q+r7IdtAnx9VVmb9wIbkASqIY+",
+	"ktd9+7Z664a1h9rs3mvcnmy7O5VXz2avq3B0mMi6yFQpdWPG0nQUfVXHByJrqwT4yajZLs99aIK2KFZU",
+	"opdkMjj7HaEmMdeUy34/QC456De7DXIImpV/AdE2adpVu0GU/gWEyeq60Kc6j4Da+qMAzwaxBUmT3QRn",
+	"2SVOroI4PiikR6HtypMS6PQQHRSUQiJQ1kggb6GsPHtqVdWaPOYgXWRJ8IEFqGNs9NXr6hR9ziv9ESkv",
+	"OGO/KaeB7Pk+VOBzMXPn9PkS1N3nBnfpBt+ThI1sp9h/YQqDsmCiBxfuF1+wpcsYEHW5w0Oac89bxKtN",
+	"k5mydb+U118/lW1U2It6sN9fut67P1TJ/OMoAzvb85JYtVm0gCDyQ7LqcoUYpIRBotMyi6Ck6KdBbUE9",
+	"T7flx70ffO6gWbjSjcYdkc2yQsN4lzsRNhJat+6GshbrG11PGNxVxqhE65fbOReOYNoJ6Pb4tWq4ViHl",
+	"A9nE3mLNb8HTCRF51xTp9ohQkISmbkWuzkLwEr/XmdVTPTqd18qWvy1y21y7IH2PPptr2XGdAthsY0l2",
+	"k7MkXdpWg4E9bVP8HoK8axmN35xzy0zeY5+I1i3M1q2DzBvGJ+wwD0bHdv7mkxHy0TzChoYmt23zgxqs",
+	"MyTr/gPnNbOm3WMe2Jhpn92JTQf/u1/MfwMh/COa9sTvx1BDx+9ndXbmcAC/huw1iH/PQXz1ScldSTy+",
+	"+0Vf4HPbH9uSvszlSkvS6WGHzjJdkTcxrbcrE84aJnJ9fVCYwiM+ov84rr77AbWn3clx+9hfEjaOZCVW",
+	"tP+rJkYUpvSuU6jj3+0HGWDmfINNVTSY76OFCa96vSCif907fRSLhE7w1z9J3kv3SZpOzf1bj0n5+zfI",
+	"PB9rf7pj/69J1ux+qS8DG0gUyGU2v+K8cTJH93hk9ou9Y7v3nb0qs/tmMEldkSxHFEI4CcFhvtG9vkK+",
+	"uX+p6C9IeRWMmwhG9+aGsLsrDS6nbZd/7SvHwX1kx/bA+SrmI3u2DWZrDA2nIKr4T13K8d1ElmSq61m+",
+	"H0avHuNBcwzXS6CecFfVdH3GW8ohfHtf7QrzndIeD9bwgGyJvvN9hXQES6ivhj7NjvN/sPRpdt0a8r80",
+	"5Ve95t0h6PyNbTek7j9KYbdu9Xn1Mp/Ky2xJ6uDedBjicuUPL61xwl9AvDA2eHZiOKROe63srbfuhS20",
+	"eXSaPZxB/Kq6t1fdqlx4hDms2+nDu8J+n1BfRyTzZnqZUNUwr9nKa+znQ1fTZPcUL4i+IfdUPuQ7+r6Z",
+	"23jznuaGmm26JvrunhFdddW2urTL9DUF2Fv1NU7vtvMycYgFbNcdaKo735/Y7dzHJZEMqfNxJHXLGRv7",
+	"KeL7u9bqpP4qxeulVq+XWo251OrZynktclsyfld/YmH3i/ppPISA065ayuw5+/WKHoGuG4+yKMzML8ME",
+	"tNLgRdFYq5k1Gvdakq1bS8arcd17Zu8Qf1zaP5Qp6bns5enS9F4i+22QyaB79OUydMzGR89miF+YYfpq",
+	"pL0aaa9G2ssz0kYdnmhFrU7jTbaf91C+bZvZk5MXnhCi16GW9qqYt1HMLYMwHP81Bn8g+ttirbGR31eb",
+	"/8Foay/HHI7eta/R7MbvOqQ21/19U0E7s+ZW/Esx8ZZ9nfj5liOoT7WlcFh/IoJvO1JOqPqgwtb98ec7",
+	"9ecFE29X2/YuerflQOd5c+n+I0gQ30cIHu8MyW7bsTaFvc6y1+9fkwWPkI2xdhnpk6n65jsHz1YhOBR3",
+	"VcLYRM86E2AbTtggDeDZ5GR+w1kALeHQkwNQf7zOawSu8cBYM/CFJeU+3s73i+6Bw/9tdusGJ/8vIxP2",
+	"VUtsqyVUmHbYa1DN+nlMBmS/IifhWwigmgLj1/jpa/z0hcdPlfAZ6+qoU6bRsuwRnBz3dvsn013P4P65",
+	"USSutVbrcHHQq/Fen+XSeQMX5rVW9on9l2a79zgvapv7PReX7mPdlpdUFf9S9vKwezO4bTfwZZ71uVb3",
+	"OyevmmBIE6hB2LX/DtBDuIasKHMppnSrKI4qlpnvJuzv7sq77bJlwcX+j3t7e5Ezhf1+VeMt3cb1Mydx",
+	"2nmqgWo1Y+1+5sDm9uPt/w8A",
 }
 
 // decodeSpec returns the embedded OpenAPI spec as raw JSON bytes,
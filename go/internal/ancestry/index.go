@@ -0,0 +1,84 @@
+// Package ancestry indexes the category tree. An Index knows the ancestors
+// and descendants of every category up front, so that filtering products by
+// a category and its subcategories needs no walk through the tree.
+package ancestry
+
+import (
+	"context"
+	"slices"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+)
+
+// Index is the ancestry of a set of categories. It is never modified once
+// built, so it is safe for concurrent use.
+type Index struct {
+	categories []generated.Category
+	// ancestors holds the ancestors of each category, its parent first
+	ancestors map[string][]string
+	// descendants holds the descendants of each category, in the order of
+	// categories
+	descendants map[string][]string
+}
+
+// NewIndex indexes categories. Parent links that form a cycle are cut where
+// the cycle closes, and links to missing categories are kept as they are.
+func NewIndex(categories []generated.Category) *Index {
+	x := &Index{
+		categories:  slices.Clone(categories),
+		ancestors:   make(map[string][]string, len(categories)),
+		descendants: make(map[string][]string),
+	}
+	parents := make(map[string]string, len(categories))
+	for _, category := range categories {
+		if category.ParentId != nil {
+			parents[category.Id] = *category.ParentId
+		}
+	}
+	for _, category := range x.categories {
+		var ancestors []string
+		visited := map[string]bool{category.Id: true}
+		for id, ok := parents[category.Id]; ok && !visited[id]; id, ok = parents[id] {
+			visited[id] = true
+			ancestors = append(ancestors, id)
+			x.descendants[id] = append(x.descendants[id], category.Id)
+		}
+		x.ancestors[category.Id] = ancestors
+	}
+	return x
+}
+
+// Load indexes the categories of s as they are now. There are few categories,
+// so an index is built for each use rather than cached, which would miss the
+// writes of other servers sharing the database.
+func Load(ctx context.Context, s store.Tx) (*Index, error) {
+	categories, err := s.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewIndex(categories), nil
+}
+
+// Categories returns the indexed categories in the order they were given
+func (x *Index) Categories() []generated.Category {
+	return x.categories
+}
+
+// Ancestors returns the IDs of the ancestors of the category with id, its
+// parent first
+func (x *Index) Ancestors(id string) []string {
+	return x.ancestors[id]
+}
+
+// Descendants returns the IDs of the categories below the category with id,
+// at any depth
+func (x *Index) Descendants(id string) []string {
+	return x.descendants[id]
+}
+
+// IsDescendant reports whether the category with id is below the category
+// with ancestorID
+func (x *Index) IsDescendant(id, ancestorID string) bool {
+	return slices.Contains(x.ancestors[id], ancestorID)
+}
@@ -0,0 +1,89 @@
+package ancestry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func category(id string, parentID string) generated.Category {
+	c := generated.Category{Id: id, Name: "Category " + id}
+	if parentID != "" {
+		c.ParentId = &parentID
+	}
+	return c
+}
+
+func TestIndex(t *testing.T) {
+	// root ─┬─ a ── a1 ── a2
+	//       └─ b
+	// x ⇄ y form a cycle; orphan points at a missing category
+	index := NewIndex([]generated.Category{
+		category("root", ""),
+		category("a", "root"),
+		category("a1", "a"),
+		category("a2", "a1"),
+		category("b", "root"),
+		category("x", "y"),
+		category("y", "x"),
+		category("orphan", "missing"),
+	})
+
+	for _, tc := range []struct {
+		name        string
+		id          string
+		ancestors   []string
+		descendants []string
+	}{
+		{"root", "root", nil, []string{"a", "a1", "a2", "b"}},
+		{"middle of a branch", "a1", []string{"a", "root"}, []string{"a2"}},
+		{"leaf", "a2", []string{"a1", "a", "root"}, nil},
+		{"cycle is cut where it closes", "x", []string{"y"}, []string{"y"}},
+		{"link to a missing category is kept", "orphan", []string{"missing"}, nil},
+		{"missing category", "missing", nil, []string{"orphan"}},
+		{"unknown category", "unknown", nil, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.ancestors, index.Ancestors(tc.id))
+			assert.Equal(t, tc.descendants, index.Descendants(tc.id))
+		})
+	}
+
+	t.Run("descendants", func(t *testing.T) {
+		assert.True(t, index.IsDescendant("a2", "root"))
+		assert.True(t, index.IsDescendant("a2", "a"))
+		assert.False(t, index.IsDescendant("a", "a2"), "ancestors are not descendants")
+		assert.False(t, index.IsDescendant("b", "a"), "siblings are not descendants")
+		assert.False(t, index.IsDescendant("a", "a"), "a category is not its own descendant")
+	})
+
+	t.Run("categories keep their order", func(t *testing.T) {
+		var ids []string
+		for _, c := range index.Categories() {
+			ids = append(ids, c.Id)
+		}
+		assert.Equal(t, []string{"root", "a", "a1", "a2", "b", "x", "y", "orphan"}, ids)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+	require.NoError(t, store.SeedDemoData(ctx, s))
+
+	index, err := Load(ctx, s)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"2", "3"}, index.Descendants("1"), "Electronics has Laptops and Smartphones")
+
+	// Every load sees the latest categories
+	_, err = s.CreateCategory(ctx, category("tablets", "1"))
+	require.NoError(t, err)
+	index, err = Load(ctx, s)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"2", "3", "tablets"}, index.Descendants("1"))
+	assert.Equal(t, []string{"1"}, index.Ancestors("tablets"))
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
+)
+
+// ClientFromRequest describes the client that sent r. The IP address is the
+// connection's peer; forwarding headers are not trusted.
+func ClientFromRequest(r *http.Request) storage.ClientInfo {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return storage.ClientInfo{
+		Device: r.UserAgent(),
+		IP:     ip,
+	}
+}
@@ -0,0 +1,84 @@
+package cursor_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/cursor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestSigner_EncodeDecode(t *testing.T) {
+	signer := cursor.NewSigner(testSecret)
+
+	for _, c := range []cursor.Cursor{
+		{Sort: "products:price:asc", Key: json.RawMessage(`19.99`), ID: "p-1"},
+		{Sort: "products:name:desc", Key: json.RawMessage(`"Cable"`), ID: "p-2", Before: true},
+	} {
+		t.Run(c.Sort, func(t *testing.T) {
+			token, err := signer.Encode(c)
+			require.NoError(t, err)
+
+			decoded, err := signer.Decode(token)
+			require.NoError(t, err)
+			assert.Equal(t, c, decoded)
+		})
+	}
+}
+
+func TestSigner_Decode(t *testing.T) {
+	signer := cursor.NewSigner(testSecret)
+	token, err := signer.Encode(cursor.Cursor{Sort: "products:price:asc", Key: json.RawMessage(`19.99`), ID: "p-1"})
+	require.NoError(t, err)
+	payload, signature, _ := strings.Cut(token, ".")
+
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	// A cursor moved to another position under the original signature
+	moved := encode(`{"s":"products:price:asc","k":0,"i":"p-0"}`)
+	// A payload that is not a cursor, signed with the right secret
+	notJSON := encode("not json")
+	mac := hmac.New(sha256.New, testSecret)
+	mac.Write([]byte(notJSON))
+	notJSONSignature := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	otherSecret, err := cursor.NewSigner([]byte("fedcba9876543210fedcba9876543210")).Encode(cursor.Cursor{Sort: "products:price:asc", Key: json.RawMessage(`19.99`), ID: "p-1"})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"no signature", payload},
+		{"empty signature", payload + "."},
+		{"signature is not base64", payload + ".!"},
+		{"truncated signature", payload + "." + signature[:len(signature)-2]},
+		{"appended to", token + "x"},
+		{"moved position", moved + "." + signature},
+		{"signature over a payload that is not a cursor", notJSON + "." + notJSONSignature},
+		{"signed with another secret", otherSecret},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := signer.Decode(tc.token)
+			assert.ErrorIs(t, err, cursor.ErrInvalid)
+		})
+	}
+}
+
+func TestNewRandomSigner(t *testing.T) {
+	first, second := cursor.NewRandomSigner(), cursor.NewRandomSigner()
+	token, err := first.Encode(cursor.Cursor{Sort: "users:id:asc", Key: json.RawMessage(`"u-1"`), ID: "u-1"})
+	require.NoError(t, err)
+
+	_, err = first.Decode(token)
+	assert.NoError(t, err)
+	_, err = second.Decode(token)
+	assert.ErrorIs(t, err, cursor.ErrInvalid, "random secrets differ")
+}
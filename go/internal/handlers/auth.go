@@ -1,23 +1,44 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/blck-snwmn/hello-typespec/go/generated"
 	authctx "github.com/blck-snwmn/hello-typespec/go/internal/auth"
+	"github.com/blck-snwmn/hello-typespec/go/internal/oidc"
 	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/blck-snwmn/hello-typespec/go/internal/validation"
+	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// PasswordResetSender delivers password reset tokens to their account's owner
+type PasswordResetSender interface {
+	SendPasswordReset(ctx context.Context, email, token string) error
+}
+
 // AuthHandlers handles authentication endpoints
 type AuthHandlers struct {
-	authStore *storage.AuthStore
+	store       store.Store
+	authStore   *storage.AuthStore
+	resetSender PasswordResetSender // nil unless password resets are configured
+	oidc        *oidc.Client        // nil unless OIDC login is configured
 }
 
-// NewAuthHandlers creates a new auth handlers instance
-func NewAuthHandlers(authStore *storage.AuthStore) *AuthHandlers {
+// NewAuthHandlers creates a new auth handlers instance. Accounts are the
+// users of store.
+func NewAuthHandlers(store store.Store, authStore *storage.AuthStore) *AuthHandlers {
 	return &AuthHandlers{
+		store:     store,
 		authStore: authStore,
 	}
 }
@@ -35,16 +56,58 @@ func (h *AuthHandlers) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	session, err := h.authStore.Login(req.Email, req.Password)
-	if err != nil {
+	session, err := h.authStore.Login(r.Context(), req.Email, req.Password, authctx.ClientFromRequest(r))
+	if errors.Is(err, storage.ErrInvalidCredentials) {
 		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "Invalid email or password")
 		return
 	}
+	var locked *storage.LoginLockedError
+	if errors.As(err, &locked) {
+		// Round up so that clients retrying on time are not turned away again
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		errorResponse(w, http.StatusTooManyRequests, generated.TOOMANYREQUESTS, "Too many failed login attempts, try again later")
+		return
+	}
+	if err != nil {
+		storeErrorResponse(w, err, "User")
+		return
+	}
+
+	h.sessionResponse(w, session)
+}
+
+// Refresh handles POST /auth/refresh
+func (h *AuthHandlers) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req generated.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorResponse(w, http.StatusBadRequest, generated.BADREQUEST, "Invalid request body")
+		return
+	}
+	if req.RefreshToken == "" {
+		errorResponse(w, http.StatusBadRequest, generated.VALIDATIONERROR, "Refresh token is required")
+		return
+	}
 
+	session, err := h.authStore.Refresh(r.Context(), req.RefreshToken, authctx.ClientFromRequest(r))
+	if errors.Is(err, storage.ErrInvalidRefreshToken) {
+		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "Invalid or expired refresh token")
+		return
+	}
+	if err != nil {
+		storeErrorResponse(w, err, "User")
+		return
+	}
+
+	h.sessionResponse(w, session)
+}
+
+// sessionResponse sends the tokens of a new or refreshed session
+func (h *AuthHandlers) sessionResponse(w http.ResponseWriter, session *storage.AuthSession) {
 	response := generated.LoginResponse{
-		AccessToken: session.Token,
-		TokenType:   "Bearer",
-		ExpiresIn:   86400, // 24 hours in seconds
+		AccessToken:  session.Token,
+		TokenType:    "Bearer",
+		ExpiresIn:    int32(h.authStore.AccessTokenTTL().Seconds()),
+		RefreshToken: &session.RefreshToken,
 		User: struct {
 			Email string `json:"email"`
 			Id    string `json:"id"`
@@ -68,7 +131,10 @@ func (h *AuthHandlers) Logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.authStore.Logout(token)
+	if err := h.authStore.Logout(r.Context(), token); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		storeErrorResponse(w, err, "Session")
+		return
+	}
 
 	response := generated.OkResponse{
 		Message: "Logged out successfully",
@@ -81,22 +147,566 @@ func (h *AuthHandlers) Logout(w http.ResponseWriter, r *http.Request) {
 // GetCurrentUser handles GET /auth/me
 func (h *AuthHandlers) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	// User is already validated by middleware
-	user, ok := authctx.GetUser(r.Context())
+	authUser, ok := authctx.GetUser(r.Context())
 	if !ok {
 		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "User not found in context")
 		return
 	}
 
+	// Read the profile from the store so that edits made since login show up
+	user, err := h.store.GetUser(r.Context(), authUser.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "User no longer exists")
+		return
+	}
+	if err != nil {
+		storeErrorResponse(w, err, "User")
+		return
+	}
+
 	response := generated.AuthUser{
-		Id:    user.ID,
+		Id:    user.Id,
 		Email: user.Email,
 		Name:  user.Name,
 	}
+	if len(authUser.Roles) > 0 {
+		roles := make([]generated.Role, len(authUser.Roles))
+		for i, role := range authUser.Roles {
+			roles[i] = generated.Role(role)
+		}
+		response.Roles = &roles
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Register handles POST /auth/register
+func (h *AuthHandlers) Register(w http.ResponseWriter, r *http.Request) {
+	var req generated.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorResponse(w, http.StatusBadRequest, generated.BADREQUEST, "Invalid request body")
+		return
+	}
+
+	var errs validation.Errors
+	errs.Email("/email", req.Email)
+	errs.NotBlank("/name", req.Name)
+	checkPassword(&errs, "/password", req.Password)
+	if len(errs) > 0 {
+		validationErrorResponse(w, errs)
+		return
+	}
+
+	passwordHash, err := storage.HashPassword(req.Password)
+	if err != nil {
+		storeErrorResponse(w, err, "User")
+		return
+	}
+
+	// Registering creates the same user, cart and credential as POST /users
+	var user generated.User
+	err = h.store.WithTx(r.Context(), func(tx store.Tx) error {
+		var err error
+		user, err = createUser(r.Context(), tx, generated.CreateUserRequest{
+			Email: openapi_types.Email(req.Email),
+			Name:  strings.TrimSpace(req.Name),
+		}, passwordHash)
+		return err
+	})
+	if err != nil {
+		txErrorResponse(w, err, "User")
+		return
+	}
+
+	response := generated.AuthUser{
+		Id:    user.Id,
+		Email: user.Email,
+		Name:  user.Name,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ChangePassword handles POST /auth/change-password
+func (h *AuthHandlers) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	user, ok := authctx.GetUser(r.Context())
+	if !ok {
+		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "User not found in context")
+		return
+	}
+
+	var req generated.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorResponse(w, http.StatusBadRequest, generated.BADREQUEST, "Invalid request body")
+		return
+	}
+	var errs validation.Errors
+	checkPassword(&errs, "/newPassword", req.NewPassword)
+	if len(errs) > 0 {
+		validationErrorResponse(w, errs)
+		return
+	}
+
+	err := h.authStore.ChangePassword(r.Context(), user.ID, req.CurrentPassword, req.NewPassword)
+	if errors.Is(err, storage.ErrInvalidCredentials) {
+		errorResponse(w, http.StatusBadRequest, generated.BADREQUEST, "Current password is incorrect")
+		return
+	}
+	if err != nil {
+		storeErrorResponse(w, err, "User")
+		return
+	}
+
+	// Keep the session that changed the password and end every other one
+	if err := h.authStore.RevokeUserSessions(r.Context(), user.ID, user.SessionID); err != nil {
+		storeErrorResponse(w, err, "User")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(generated.OkResponse{Message: "Password changed successfully"})
+}
+
+// RequestPasswordReset handles POST /auth/password-reset. It answers the same
+// way whether or not the email has an account.
+func (h *AuthHandlers) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	if h.resetSender == nil {
+		errorResponse(w, http.StatusNotFound, generated.NOTFOUND, "Password reset is not configured")
+		return
+	}
+
+	var req generated.PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorResponse(w, http.StatusBadRequest, generated.BADREQUEST, "Invalid request body")
+		return
+	}
+	if req.Email == "" {
+		errorResponse(w, http.StatusBadRequest, generated.VALIDATIONERROR, "Email is required")
+		return
+	}
+
+	token, err := h.authStore.RequestPasswordReset(r.Context(), req.Email)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+	case err != nil:
+		storeErrorResponse(w, err, "User")
+		return
+	default:
+		if err := h.resetSender.SendPasswordReset(r.Context(), req.Email, token); err != nil {
+			log.Printf("Failed to send password reset: %v", err)
+			errorResponse(w, http.StatusInternalServerError, generated.INTERNALERROR, "Internal server error")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(generated.OkResponse{
+		Message: "If the email is registered, a password reset token has been sent",
+	})
+}
+
+// ListSessions handles GET /auth/sessions
+func (h *AuthHandlers) ListSessions(w http.ResponseWriter, r *http.Request) {
+	user, ok := authctx.GetUser(r.Context())
+	if !ok {
+		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "User not found in context")
+		return
+	}
+
+	sessions, err := h.authStore.ListSessions(r.Context(), user.ID)
+	if err != nil {
+		storeErrorResponse(w, err, "User")
+		return
+	}
+
+	response := []generated.Session{}
+	for _, session := range sessions {
+		response = append(response, generated.Session{
+			Id:         session.ID,
+			Device:     optionalString(session.Client.Device),
+			IpAddress:  optionalString(session.Client.IP),
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == user.SessionID,
+		})
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// RevokeSession handles DELETE /auth/sessions/{sessionId}
+func (h *AuthHandlers) RevokeSession(w http.ResponseWriter, r *http.Request, sessionId string) {
+	user, ok := authctx.GetUser(r.Context())
+	if !ok {
+		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "User not found in context")
+		return
+	}
+
+	// Other users' sessions are reported as missing
+	err := h.authStore.RevokeSession(r.Context(), user.ID, sessionId)
+	if errors.Is(err, storage.ErrNotFound) {
+		errorResponse(w, http.StatusNotFound, generated.NOTFOUND, "Session not found")
+		return
+	}
+	if err != nil {
+		storeErrorResponse(w, err, "Session")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateAPIKey handles POST /auth/api-keys
+func (h *AuthHandlers) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	user, ok := authctx.GetUser(r.Context())
+	if !ok {
+		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "User not found in context")
+		return
+	}
+
+	var req generated.CreateApiKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorResponse(w, http.StatusBadRequest, generated.BADREQUEST, "Invalid request body")
+		return
+	}
+	var errs validation.Errors
+	errs.NotBlank("/name", req.Name)
+	errs.MinItems("/scopes", len(req.Scopes), 1)
+	scopes := make([]string, 0, len(req.Scopes))
+	for i, scope := range req.Scopes {
+		if !scope.Valid() {
+			errs.Add(validation.Pointer("scopes", strconv.Itoa(i)), validation.RuleEnum, "unknown scope "+strconv.Quote(string(scope)))
+		}
+		if !slices.Contains(scopes, string(scope)) {
+			scopes = append(scopes, string(scope))
+		}
+	}
+	if len(errs) > 0 {
+		validationErrorResponse(w, errs)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+
+	key, apiKey, err := h.authStore.CreateAPIKey(r.Context(), user.ID, name, scopes)
+	if err != nil {
+		storeErrorResponse(w, err, "API key")
+		return
+	}
+
+	response := apiKeyResponse(apiKey)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(generated.CreatedApiKey{
+		Id:         response.Id,
+		Name:       response.Name,
+		Prefix:     response.Prefix,
+		Scopes:     response.Scopes,
+		CreatedAt:  response.CreatedAt,
+		LastUsedAt: response.LastUsedAt,
+		Key:        key,
+	})
+}
+
+// ListAPIKeys handles GET /auth/api-keys
+func (h *AuthHandlers) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	user, ok := authctx.GetUser(r.Context())
+	if !ok {
+		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "User not found in context")
+		return
+	}
+
+	keys, err := h.authStore.ListAPIKeys(r.Context(), user.ID)
+	if err != nil {
+		storeErrorResponse(w, err, "API key")
+		return
+	}
+	response := make([]generated.ApiKey, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, apiKeyResponse(key))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// RevokeAPIKey handles DELETE /auth/api-keys/{keyId}
+func (h *AuthHandlers) RevokeAPIKey(w http.ResponseWriter, r *http.Request, keyId string) {
+	user, ok := authctx.GetUser(r.Context())
+	if !ok {
+		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "User not found in context")
+		return
+	}
+
+	// Other users' keys are reported as missing
+	err := h.authStore.RevokeAPIKey(r.Context(), user.ID, keyId)
+	if errors.Is(err, storage.ErrNotFound) {
+		errorResponse(w, http.StatusNotFound, generated.NOTFOUND, "API key not found")
+		return
+	}
+	if err != nil {
+		storeErrorResponse(w, err, "API key")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apiKeyResponse describes an API key without the key itself
+func apiKeyResponse(key store.APIKey) generated.ApiKey {
+	scopes := make([]generated.ApiKeyScope, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, generated.ApiKeyScope(scope))
+	}
+	return generated.ApiKey{
+		Id:         key.Id,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+	}
+}
+
+// optionalString returns nil for empty strings
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// OIDCLogin handles GET /auth/oidc/login
+func (h *AuthHandlers) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if h.oidc == nil {
+		errorResponse(w, http.StatusNotFound, generated.NOTFOUND, "OIDC login is not configured")
+		return
+	}
+
+	authURL, ok := h.startOIDCLogin(w, "")
+	if !ok {
+		return
+	}
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCLink handles POST /auth/oidc/link. The provider account the user logs
+// in with at the returned URL is linked to the current user when the login
+// comes back to the callback.
+func (h *AuthHandlers) OIDCLink(w http.ResponseWriter, r *http.Request) {
+	if h.oidc == nil {
+		errorResponse(w, http.StatusNotFound, generated.NOTFOUND, "OIDC login is not configured")
+		return
+	}
+	user, ok := authctx.GetUser(r.Context())
+	if !ok {
+		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "User not found in context")
+		return
+	}
+
+	authURL, ok := h.startOIDCLogin(w, user.ID)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(generated.OidcLink{AuthorizationUrl: authURL})
+}
+
+// startOIDCLogin starts a login, or a link to the user with linkUserID, and
+// binds it to the browser with the state cookie. It responds itself when
+// the login cannot be started.
+func (h *AuthHandlers) startOIDCLogin(w http.ResponseWriter, linkUserID string) (string, bool) {
+	authURL, state, err := h.oidc.AuthCodeURL(linkUserID)
+	if errors.Is(err, oidc.ErrTooManyPendingLogins) {
+		errorResponse(w, http.StatusServiceUnavailable, ErrorCodeServiceUnavailable, "Too many logins in progress, try again later")
+		return "", false
+	}
+	if err != nil {
+		log.Printf("Failed to start OIDC login: %v", err)
+		errorResponse(w, http.StatusInternalServerError, generated.INTERNALERROR, "Internal server error")
+		return "", false
+	}
+	http.SetCookie(w, h.oidc.StateCookieFor(state))
+	return authURL, true
+}
+
+// OIDCCallback handles GET /auth/oidc/callback. The issuer and subject of the
+// ID token pick the linked local user, or are linked to the user who started
+// a link. Logins with an unlinked account create a user.
+func (h *AuthHandlers) OIDCCallback(w http.ResponseWriter, r *http.Request, params generated.AuthServiceOidcCallbackParams) {
+	if h.oidc == nil {
+		errorResponse(w, http.StatusNotFound, generated.NOTFOUND, "OIDC login is not configured")
+		return
+	}
+	if params.Error != nil {
+		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "The identity provider did not complete the login: "+*params.Error)
+		return
+	}
+	if params.Code == nil || *params.Code == "" {
+		errorResponse(w, http.StatusBadRequest, generated.VALIDATIONERROR, "Authorization code is required")
+		return
+	}
+
+	// The login must come back to the browser that started it, or anyone
+	// could have a victim complete a login or link of their own
+	var browserState string
+	if cookie, err := r.Cookie(oidc.StateCookie); err == nil {
+		browserState = cookie.Value
+	}
+	http.SetCookie(w, h.oidc.ClearStateCookie())
+
+	claims, err := h.oidc.Exchange(r.Context(), *params.Code, params.State, browserState)
+	if errors.Is(err, oidc.ErrInvalidState) {
+		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "Unknown or expired login state")
+		return
+	}
+	if err != nil {
+		log.Printf("OIDC login failed: %v", err)
+		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "Could not complete the login with the identity provider")
+		return
+	}
+
+	var userID string
+	if claims.LinkUserID != "" {
+		userID, err = h.linkOIDCIdentity(r.Context(), claims)
+	} else {
+		userID, err = h.oidcUser(r.Context(), claims)
+	}
+	if err != nil {
+		txErrorResponse(w, err, "User")
+		return
+	}
+	session, err := h.authStore.StartSession(r.Context(), userID, authctx.ClientFromRequest(r))
+	if err != nil {
+		storeErrorResponse(w, err, "User")
+		return
+	}
+
+	h.sessionResponse(w, session)
+}
+
+// oidcUser returns the ID of the user linked to the provider account of
+// claims. An unlinked account gets a new user without a password and with its
+// verified email. If that email belongs to a user already, the account is
+// not linked to them: they have to log in and link it themselves.
+func (h *AuthHandlers) oidcUser(ctx context.Context, claims *oidc.Claims) (string, error) {
+	var userID string
+	err := h.store.WithTx(ctx, func(tx store.Tx) error {
+		identity, err := tx.GetIdentity(ctx, claims.Issuer, claims.Subject)
+		if err == nil {
+			userID = identity.UserId
+			return nil
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+
+		if claims.Email == "" || !claims.EmailVerified {
+			return &apiError{http.StatusForbidden, ErrorCodeForbidden, "The identity provider did not verify an email for this account"}
+		}
+		_, err = tx.GetCredentialByEmail(ctx, storage.NormalizeEmail(claims.Email))
+		if err == nil {
+			return &apiError{http.StatusConflict, ErrorCodeConflict, "Email is already registered; log in and link the identity provider account instead"}
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+
+		name := strings.TrimSpace(claims.Name)
+		if name == "" {
+			name = claims.Email
+		}
+		user, err := createUser(ctx, tx, generated.CreateUserRequest{Email: openapi_types.Email(claims.Email), Name: name}, "")
+		if err != nil {
+			return err
+		}
+		userID = user.Id
+		return createIdentity(ctx, tx, claims, userID)
+	})
+	return userID, err
+}
+
+// linkOIDCIdentity links the provider account of claims to the user who
+// started the link and returns their ID. An account linked to another user
+// is not moved.
+func (h *AuthHandlers) linkOIDCIdentity(ctx context.Context, claims *oidc.Claims) (string, error) {
+	err := h.store.WithTx(ctx, func(tx store.Tx) error {
+		if _, err := tx.GetUser(ctx, claims.LinkUserID); err != nil {
+			return err
+		}
+		identity, err := tx.GetIdentity(ctx, claims.Issuer, claims.Subject)
+		if err == nil {
+			if identity.UserId != claims.LinkUserID {
+				return &apiError{http.StatusConflict, ErrorCodeConflict, "The identity provider account is linked to another user"}
+			}
+			return nil
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+		return createIdentity(ctx, tx, claims, claims.LinkUserID)
+	})
+	return claims.LinkUserID, err
+}
+
+// createIdentity links the provider account of claims to the user with userID
+func createIdentity(ctx context.Context, tx store.Tx, claims *oidc.Claims, userID string) error {
+	_, err := tx.CreateIdentity(ctx, store.Identity{
+		Issuer:    claims.Issuer,
+		Subject:   claims.Subject,
+		UserId:    userID,
+		CreatedAt: time.Now(),
+	})
+	if errors.Is(err, store.ErrConflict) {
+		return &apiError{http.StatusConflict, ErrorCodeConflict, "The identity provider account is linked to another user"}
+	}
+	return err
+}
+
+// ConfirmPasswordReset handles POST /auth/password-reset/confirm
+func (h *AuthHandlers) ConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	if h.resetSender == nil {
+		errorResponse(w, http.StatusNotFound, generated.NOTFOUND, "Password reset is not configured")
+		return
+	}
+
+	var req generated.PasswordResetConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errorResponse(w, http.StatusBadRequest, generated.BADREQUEST, "Invalid request body")
+		return
+	}
+	var errs validation.Errors
+	checkPassword(&errs, "/newPassword", req.NewPassword)
+	if len(errs) > 0 {
+		validationErrorResponse(w, errs)
+		return
+	}
+
+	err := h.authStore.ResetPassword(r.Context(), req.Token, req.NewPassword)
+	if errors.Is(err, storage.ErrInvalidResetToken) {
+		errorResponse(w, http.StatusBadRequest, generated.BADREQUEST, "Invalid or expired reset token")
+		return
+	}
+	if err != nil {
+		storeErrorResponse(w, err, "User")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(generated.OkResponse{Message: "Password has been reset"})
+}
+
+// checkPassword records a new password outside the password policy as a
+// field error
+func checkPassword(errs *validation.Errors, field, password string) {
+	if err := storage.ValidatePassword(password); err != nil {
+		errs.Add(field, validation.RulePassword, strings.TrimPrefix(err.Error(), storage.ErrInvalidPassword.Error()+": "))
+	}
+}
+
 // extractToken extracts the bearer token from Authorization header
 func extractToken(r *http.Request) string {
 	authHeader := r.Header.Get("Authorization")
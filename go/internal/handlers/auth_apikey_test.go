@@ -0,0 +1,135 @@
+package handlers_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createAPIKey creates an API key with scopes as the owner of token
+func createAPIKey(t testing.TB, server *TestServer, token string, scopes ...generated.ApiKeyScope) generated.CreatedApiKey {
+	t.Helper()
+
+	rr := makeAuthenticatedRequest(t, server, "POST", "/auth/api-keys", generated.CreateApiKeyRequest{Name: "Warehouse", Scopes: scopes}, token)
+	assertStatus(t, rr, http.StatusCreated)
+	var created generated.CreatedApiKey
+	require.NoError(t, decodeJSON(rr, &created))
+	return created
+}
+
+func TestAuthService_APIKeys(t *testing.T) {
+	server, adminID, adminToken := setupTestServerWithAuth(t)
+	userToken := loginTestUser(t, server, "bob@example.com", "password456")
+	key := createAPIKey(t, server, adminToken, generated.ProductsWrite, generated.OrdersRead)
+
+	t.Run("should return the key only on creation", func(t *testing.T) {
+		assert.True(t, strings.HasPrefix(key.Key, "hts_"))
+		assert.True(t, strings.HasPrefix(key.Key, key.Prefix))
+		assert.Less(t, len(key.Prefix), len(key.Key))
+		assert.Equal(t, []generated.ApiKeyScope{generated.ProductsWrite, generated.OrdersRead}, key.Scopes)
+		assert.Nil(t, key.LastUsedAt)
+
+		rr := makeAuthenticatedRequest(t, server, "GET", "/auth/api-keys", nil, adminToken)
+		assertStatus(t, rr, http.StatusOK)
+		assert.NotContains(t, rr.Body.String(), key.Key)
+	})
+
+	t.Run("should store only a hash of the key", func(t *testing.T) {
+		keys, err := server.store.GetAPIKeys(t.Context(), adminID)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.NotEqual(t, key.Key, keys[0].KeyHash)
+		assert.NotContains(t, keys[0].KeyHash, key.Key)
+	})
+
+	t.Run("should authenticate with the X-API-Key header", func(t *testing.T) {
+		rr := makeRequestWithHeaders(t, server, "PATCH", "/products/1", map[string]any{"price": 42}, map[string]string{"X-API-Key": key.Key})
+		assertStatus(t, rr, http.StatusOK)
+	})
+
+	t.Run("should authenticate with the ApiKey scheme", func(t *testing.T) {
+		rr := makeRequestWithHeaders(t, server, "GET", "/orders", nil, map[string]string{"Authorization": "ApiKey " + key.Key})
+		assertStatus(t, rr, http.StatusOK)
+	})
+
+	t.Run("should record when the key was last used", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "GET", "/auth/api-keys", nil, adminToken)
+		assertStatus(t, rr, http.StatusOK)
+		var keys []generated.ApiKey
+		require.NoError(t, decodeJSON(rr, &keys))
+		require.Len(t, keys, 1)
+		assert.Equal(t, key.Id, keys[0].Id)
+		assert.NotNil(t, keys[0].LastUsedAt)
+	})
+
+	t.Run("should reject operations outside the key's scopes", func(t *testing.T) {
+		rr := makeRequestWithHeaders(t, server, "POST", "/categories", map[string]any{"name": "Scoped"}, map[string]string{"X-API-Key": key.Key})
+		assertStatus(t, rr, http.StatusForbidden)
+		assertErrorResponse(t, rr, "FORBIDDEN")
+	})
+
+	t.Run("should not manage sessions or keys with a key", func(t *testing.T) {
+		for _, path := range []string{"/auth/me", "/auth/sessions", "/auth/api-keys"} {
+			rr := makeRequestWithHeaders(t, server, "GET", path, nil, map[string]string{"X-API-Key": key.Key})
+			assertStatus(t, rr, http.StatusForbidden)
+		}
+	})
+
+	t.Run("should not grant more than the owner's roles", func(t *testing.T) {
+		customerKey := createAPIKey(t, server, userToken, generated.ProductsWrite, generated.UsersRead)
+
+		rr := makeRequestWithHeaders(t, server, "PATCH", "/products/1", map[string]any{"price": 1}, map[string]string{"X-API-Key": customerKey.Key})
+		assertStatus(t, rr, http.StatusForbidden)
+
+		rr = makeRequestWithHeaders(t, server, "GET", "/users/550e8400-e29b-41d4-a716-446655440002", nil, map[string]string{"X-API-Key": customerKey.Key})
+		assertStatus(t, rr, http.StatusOK)
+		rr = makeRequestWithHeaders(t, server, "GET", "/users/"+adminID, nil, map[string]string{"X-API-Key": customerKey.Key})
+		assertStatus(t, rr, http.StatusForbidden)
+	})
+
+	t.Run("should reject unknown keys", func(t *testing.T) {
+		rr := makeRequestWithHeaders(t, server, "GET", "/orders", nil, map[string]string{"X-API-Key": "hts_unknown"})
+		assertStatus(t, rr, http.StatusUnauthorized)
+		assertErrorResponse(t, rr, "UNAUTHORIZED")
+	})
+
+	t.Run("should reject keys without the prefix", func(t *testing.T) {
+		for _, apiKey := range []string{strings.TrimPrefix(key.Key, "hts_"), "HTS_" + strings.TrimPrefix(key.Key, "hts_"), adminToken} {
+			rr := makeRequestWithHeaders(t, server, "GET", "/orders", nil, map[string]string{"X-API-Key": apiKey})
+			assertStatus(t, rr, http.StatusUnauthorized)
+			rr = makeRequestWithHeaders(t, server, "GET", "/orders", nil, map[string]string{"Authorization": "ApiKey " + apiKey})
+			assertStatus(t, rr, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("should validate the request", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "POST", "/auth/api-keys", generated.CreateApiKeyRequest{Name: "No scopes"}, adminToken)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertErrorResponse(t, rr, "VALIDATION_ERROR")
+
+		rr = makeAuthenticatedRequest(t, server, "POST", "/auth/api-keys", map[string]any{"name": "Bad", "scopes": []string{"everything"}}, adminToken)
+		assertStatus(t, rr, http.StatusBadRequest)
+		details := assertValidationErrors(t, rr, "/scopes/0")
+		assert.Equal(t, "enum", details[0].Rule)
+	})
+
+	t.Run("should not revoke other users' keys", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/auth/api-keys/"+key.Id, nil, userToken)
+		assertStatus(t, rr, http.StatusNotFound)
+	})
+
+	t.Run("should revoke a key", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/auth/api-keys/"+key.Id, nil, adminToken)
+		assertStatus(t, rr, http.StatusNoContent)
+
+		rr = makeRequestWithHeaders(t, server, "GET", "/orders", nil, map[string]string{"X-API-Key": key.Key})
+		assertStatus(t, rr, http.StatusUnauthorized)
+
+		rr = makeAuthenticatedRequest(t, server, "DELETE", "/auth/api-keys/"+key.Id, nil, adminToken)
+		assertStatus(t, rr, http.StatusNotFound)
+	})
+}
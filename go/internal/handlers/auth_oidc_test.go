@@ -0,0 +1,412 @@
+package handlers_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/handlers"
+	"github.com/blck-snwmn/hello-typespec/go/internal/jwt"
+	"github.com/blck-snwmn/hello-typespec/go/internal/oidc"
+	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOIDCClientID     = "hello-typespec"
+	testOIDCClientSecret = "s3cret"
+	testOIDCRedirectURL  = "http://localhost:8080/auth/oidc/callback"
+)
+
+// testIdentity is the account a testIdP logs users in as
+type testIdentity struct {
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+}
+
+// testIdP is an in-process OpenID Connect provider. Its authorization
+// endpoint logs in as identity straight away and redirects back with a code.
+type testIdP struct {
+	*httptest.Server
+	keys *jwt.KeySet
+
+	mu       sync.Mutex
+	identity testIdentity
+	codes    map[string]url.Values // authorization request of each issued code
+}
+
+func newTestIdP(t testing.TB) *testIdP {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keys, err := jwt.NewKeySet(jwt.NewRS256Key("idp-key", rsaKey))
+	require.NoError(t, err)
+
+	idp := &testIdP{keys: keys, codes: make(map[string]url.Values)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "idp-key",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("GET /authorize", idp.authorize)
+	mux.HandleFunc("POST /token", idp.token)
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+// logInAs sets the account the provider logs users in as
+func (idp *testIdP) logInAs(identity testIdentity) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+	idp.identity = identity
+}
+
+func (idp *testIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != testOIDCClientID || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	code := rand.Text()
+	idp.mu.Lock()
+	idp.codes[code] = query
+	idp.mu.Unlock()
+
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	redirect.RawQuery = url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (idp *testIdP) token(w http.ResponseWriter, r *http.Request) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	clientID, secret, _ := r.BasicAuth()
+	code := r.PostFormValue("code")
+	authorization, ok := idp.codes[code]
+	delete(idp.codes, code)
+	switch {
+	case clientID != testOIDCClientID || secret != testOIDCClientSecret:
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	case !ok,
+		r.PostFormValue("redirect_uri") != authorization.Get("redirect_uri"),
+		oidc.CodeChallenge(r.PostFormValue("code_verifier")) != authorization.Get("code_challenge"):
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	idToken, err := idp.keys.Sign(struct {
+		testIdentity
+		Issuer    string `json:"iss"`
+		Audience  string `json:"aud"`
+		Nonce     string `json:"nonce"`
+		IssuedAt  int64  `json:"iat"`
+		ExpiresAt int64  `json:"exp"`
+	}{idp.identity, idp.URL, testOIDCClientID, authorization.Get("nonce"), now.Unix(), now.Add(time.Minute).Unix()})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "idp-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     idToken,
+	})
+}
+
+// setupOIDCTestServer returns a test server that logs in through idp
+func setupOIDCTestServer(t testing.TB, idp *testIdP) *TestServer {
+	t.Helper()
+
+	client, err := oidc.NewClient(t.Context(), oidc.Config{
+		Issuer:       idp.URL,
+		ClientID:     testOIDCClientID,
+		ClientSecret: testOIDCClientSecret,
+		RedirectURL:  testOIDCRedirectURL,
+	})
+	require.NoError(t, err)
+	dataStore := newTestStore(t)
+	return serveTestServer(t, dataStore, storage.NewAuthStore(dataStore), handlers.WithOIDC(client))
+}
+
+// oidcCallback is a login the provider redirected back, with the state
+// cookie of the browser that started it
+type oidcCallback struct {
+	path   string
+	cookie string
+}
+
+// complete requests the callback from the browser that started the login
+func (c oidcCallback) complete(t testing.TB, server *TestServer) *httptest.ResponseRecorder {
+	t.Helper()
+	return makeRequestWithHeaders(t, server, "GET", c.path, nil, map[string]string{"Cookie": c.cookie})
+}
+
+// startOIDCLogin starts a login and follows it through the provider
+func startOIDCLogin(t testing.TB, server *TestServer) oidcCallback {
+	t.Helper()
+
+	rr := makeRequest(t, server, "GET", "/auth/oidc/login", nil)
+	assertStatus(t, rr, http.StatusFound)
+	return oidcCallback{followOIDCLogin(t, rr.Header().Get("Location")), stateCookie(t, rr)}
+}
+
+// startOIDCLink starts linking a provider account to the user of token and
+// follows it through the provider
+func startOIDCLink(t testing.TB, server *TestServer, token string) oidcCallback {
+	t.Helper()
+
+	rr := makeAuthenticatedRequest(t, server, "POST", "/auth/oidc/link", nil, token)
+	assertStatus(t, rr, http.StatusOK)
+	var link generated.OidcLink
+	require.NoError(t, decodeJSON(rr, &link))
+	return oidcCallback{followOIDCLogin(t, link.AuthorizationUrl), stateCookie(t, rr)}
+}
+
+// stateCookie returns the state cookie set by rr as a Cookie header value
+func stateCookie(t testing.TB, rr *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	for _, cookie := range rr.Result().Cookies() {
+		if cookie.Name == oidc.StateCookie {
+			assert.True(t, cookie.HttpOnly)
+			assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+			assert.Equal(t, "/auth/oidc/callback", cookie.Path)
+			return cookie.Name + "=" + cookie.Value
+		}
+	}
+	require.Fail(t, "no state cookie set")
+	return ""
+}
+
+// followOIDCLogin logs in at the provider authorization URL authURL and
+// returns the callback path it redirects back to
+func followOIDCLogin(t testing.TB, authURL string) string {
+	t.Helper()
+
+	noRedirects := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := noRedirects.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	return callback.RequestURI()
+}
+
+func TestAuthService_OIDC(t *testing.T) {
+	idp := newTestIdP(t)
+	server := setupOIDCTestServer(t, idp)
+
+	t.Run("should redirect to the provider with PKCE", func(t *testing.T) {
+		rr := makeRequest(t, server, "GET", "/auth/oidc/login", nil)
+		assertStatus(t, rr, http.StatusFound)
+
+		location, err := url.Parse(rr.Header().Get("Location"))
+		require.NoError(t, err)
+		assert.Equal(t, idp.URL+"/authorize", location.Scheme+"://"+location.Host+location.Path)
+		query := location.Query()
+		assert.Equal(t, "code", query.Get("response_type"))
+		assert.Equal(t, testOIDCClientID, query.Get("client_id"))
+		assert.Equal(t, testOIDCRedirectURL, query.Get("redirect_uri"))
+		assert.Equal(t, "openid email profile", query.Get("scope"))
+		assert.Equal(t, "S256", query.Get("code_challenge_method"))
+		assert.NotEmpty(t, query.Get("code_challenge"))
+		assert.NotEmpty(t, query.Get("state"))
+		assert.NotEmpty(t, query.Get("nonce"))
+	})
+
+	t.Run("should not log in an existing user by email alone", func(t *testing.T) {
+		idp.logInAs(testIdentity{Subject: "idp|mallory", Email: "Alice@Example.com", EmailVerified: true, Name: "Mallory"})
+
+		rr := startOIDCLogin(t, server).complete(t, server)
+		assertStatus(t, rr, http.StatusConflict)
+		assertErrorResponse(t, rr, "CONFLICT")
+	})
+
+	t.Run("should log in a user who linked their account", func(t *testing.T) {
+		idp.logInAs(testIdentity{Subject: "idp|alice", Email: "alice@idp.example.com", EmailVerified: true, Name: "Alice at IdP"})
+		alice := loginSession(t, server, "alice@example.com", "password123")
+
+		rr := startOIDCLink(t, server, alice.AccessToken).complete(t, server)
+		assertStatus(t, rr, http.StatusOK)
+		var linked generated.LoginResponse
+		require.NoError(t, decodeJSON(rr, &linked))
+		assert.Equal(t, "550e8400-e29b-41d4-a716-446655440001", linked.User.Id)
+
+		// Linking again is a no-op
+		rr = startOIDCLink(t, server, alice.AccessToken).complete(t, server)
+		assertStatus(t, rr, http.StatusOK)
+
+		// Logins match the account, whatever its email
+		rr = startOIDCLogin(t, server).complete(t, server)
+		assertStatus(t, rr, http.StatusOK)
+		var response generated.LoginResponse
+		require.NoError(t, decodeJSON(rr, &response))
+		assert.Equal(t, "550e8400-e29b-41d4-a716-446655440001", response.User.Id)
+		assert.Equal(t, "alice@example.com", response.User.Email)
+		require.NotNil(t, response.RefreshToken)
+
+		// The session is the same as a password login's
+		rr = makeAuthenticatedRequest(t, server, "GET", "/auth/me", nil, response.AccessToken)
+		assertStatus(t, rr, http.StatusOK)
+		rr = makeRequest(t, server, "POST", "/auth/refresh", generated.RefreshRequest{RefreshToken: *response.RefreshToken})
+		assertStatus(t, rr, http.StatusOK)
+	})
+
+	t.Run("should create a user on first login", func(t *testing.T) {
+		idp.logInAs(testIdentity{Subject: "idp|dana", Email: "dana@example.com", EmailVerified: true, Name: "Dana"})
+
+		rr := startOIDCLogin(t, server).complete(t, server)
+		assertStatus(t, rr, http.StatusOK)
+		var response generated.LoginResponse
+		require.NoError(t, decodeJSON(rr, &response))
+		assert.Equal(t, "dana@example.com", response.User.Email)
+		assert.Equal(t, "Dana", response.User.Name)
+
+		rr = makeAuthenticatedRequest(t, server, "GET", "/carts/users/"+response.User.Id, nil, response.AccessToken)
+		assertStatus(t, rr, http.StatusOK)
+
+		// Logging in again finds the same user, even with a new email
+		idp.logInAs(testIdentity{Subject: "idp|dana", Email: "dana@new.example.com", EmailVerified: true, Name: "Dana"})
+		rr = startOIDCLogin(t, server).complete(t, server)
+		assertStatus(t, rr, http.StatusOK)
+		var again generated.LoginResponse
+		require.NoError(t, decodeJSON(rr, &again))
+		assert.Equal(t, response.User.Id, again.User.Id)
+
+		// Without a password, password logins fail
+		rr = login(t, server, "dana@example.com", "password123")
+		assertStatus(t, rr, http.StatusUnauthorized)
+	})
+
+	t.Run("should not link an account linked to another user", func(t *testing.T) {
+		idp.logInAs(testIdentity{Subject: "idp|dana", Email: "dana@example.com", EmailVerified: true, Name: "Dana"})
+		bob := loginSession(t, server, "bob@example.com", "password456")
+
+		rr := startOIDCLink(t, server, bob.AccessToken).complete(t, server)
+		assertStatus(t, rr, http.StatusConflict)
+		assertErrorResponse(t, rr, "CONFLICT")
+
+		// The account still logs in as its user
+		rr = startOIDCLogin(t, server).complete(t, server)
+		assertStatus(t, rr, http.StatusOK)
+		var response generated.LoginResponse
+		require.NoError(t, decodeJSON(rr, &response))
+		assert.Equal(t, "dana@example.com", response.User.Email)
+	})
+
+	t.Run("should require a login to link an account", func(t *testing.T) {
+		rr := makeRequest(t, server, "POST", "/auth/oidc/link", nil)
+		assertStatus(t, rr, http.StatusUnauthorized)
+	})
+
+	t.Run("should reject unverified emails", func(t *testing.T) {
+		idp.logInAs(testIdentity{Subject: "idp|mallory", Email: "bob@example.com", EmailVerified: false, Name: "Mallory"})
+
+		rr := startOIDCLogin(t, server).complete(t, server)
+		assertStatus(t, rr, http.StatusForbidden)
+		assertErrorResponse(t, rr, "FORBIDDEN")
+	})
+
+	t.Run("should reject unknown and reused states", func(t *testing.T) {
+		idp.logInAs(testIdentity{Subject: "idp|alice", Email: "alice@example.com", EmailVerified: true})
+		callback := startOIDCLogin(t, server)
+
+		rr := oidcCallback{"/auth/oidc/callback?code=whatever&state=forged", oidc.StateCookie + "=forged"}.complete(t, server)
+		assertStatus(t, rr, http.StatusUnauthorized)
+		assertErrorResponse(t, rr, "UNAUTHORIZED")
+
+		rr = callback.complete(t, server)
+		assertStatus(t, rr, http.StatusOK)
+		rr = callback.complete(t, server)
+		assertStatus(t, rr, http.StatusUnauthorized)
+	})
+
+	t.Run("should reject logins completed in another browser", func(t *testing.T) {
+		idp.logInAs(testIdentity{Subject: "idp|mallory", Email: "mallory@example.com", EmailVerified: true})
+		attackers := startOIDCLogin(t, server)
+		victims := startOIDCLogin(t, server)
+
+		// The victim is sent the attacker's callback
+		rr := oidcCallback{attackers.path, victims.cookie}.complete(t, server)
+		assertStatus(t, rr, http.StatusUnauthorized)
+		assertErrorResponse(t, rr, "UNAUTHORIZED")
+		rr = makeRequest(t, server, "GET", attackers.path, nil)
+		assertStatus(t, rr, http.StatusUnauthorized)
+
+		// The same goes for links
+		bob := loginSession(t, server, "bob@example.com", "password456")
+		link := startOIDCLink(t, server, bob.AccessToken)
+		rr = oidcCallback{link.path, victims.cookie}.complete(t, server)
+		assertStatus(t, rr, http.StatusUnauthorized)
+	})
+
+	t.Run("should reject codes the provider does not accept", func(t *testing.T) {
+		callback := startOIDCLogin(t, server)
+		path, err := url.Parse(callback.path)
+		require.NoError(t, err)
+		query := path.Query()
+		query.Set("code", "tampered")
+		path.RawQuery = query.Encode()
+		callback.path = path.RequestURI()
+
+		rr := callback.complete(t, server)
+		assertStatus(t, rr, http.StatusUnauthorized)
+	})
+
+	t.Run("should report provider errors", func(t *testing.T) {
+		rr := makeRequest(t, server, "GET", "/auth/oidc/callback?error=access_denied&state=any", nil)
+		assertStatus(t, rr, http.StatusUnauthorized)
+		assertErrorResponse(t, rr, "UNAUTHORIZED")
+	})
+}
+
+func TestAuthService_OIDCNotConfigured(t *testing.T) {
+	server := setupTestServer(t)
+
+	rr := makeRequest(t, server, "GET", "/auth/oidc/login", nil)
+	assertStatus(t, rr, http.StatusNotFound)
+	assertErrorResponse(t, rr, "NOT_FOUND")
+
+	token := loginTestUser(t, server, "alice@example.com", "password123")
+	rr = makeAuthenticatedRequest(t, server, "POST", "/auth/oidc/link", nil, token)
+	assertStatus(t, rr, http.StatusNotFound)
+	assertErrorResponse(t, rr, "NOT_FOUND")
+}
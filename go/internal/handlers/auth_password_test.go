@@ -0,0 +1,281 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturingResetSender records the password reset tokens it is asked to send
+type capturingResetSender struct {
+	mu     sync.Mutex
+	tokens map[string]string // email -> token
+}
+
+func (c *capturingResetSender) SendPasswordReset(ctx context.Context, email, token string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens == nil {
+		c.tokens = make(map[string]string)
+	}
+	c.tokens[email] = token
+	return nil
+}
+
+func (c *capturingResetSender) token(email string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens[email]
+}
+
+// login attempts a login and returns the response
+func login(t testing.TB, server *TestServer, email, password string) *httptest.ResponseRecorder {
+	t.Helper()
+	return makeRequest(t, server, "POST", "/auth/login", generated.LoginRequest{Email: email, Password: password})
+}
+
+func TestAuthService_Register(t *testing.T) {
+	server := setupTestServer(t)
+
+	t.Run("should register a user who can then log in", func(t *testing.T) {
+		rr := makeRequest(t, server, "POST", "/auth/register", generated.RegisterRequest{
+			Email:    "Carol@Example.com",
+			Name:     "Carol",
+			Password: "correct horse",
+		})
+		assertStatus(t, rr, http.StatusCreated)
+
+		var user generated.AuthUser
+		require.NoError(t, decodeJSON(rr, &user))
+		assert.NotEmpty(t, user.Id)
+		assert.Equal(t, "carol@example.com", user.Email)
+		assert.Equal(t, "Carol", user.Name)
+
+		token := loginTestUser(t, server, "carol@example.com", "correct horse")
+		rr = makeAuthenticatedRequest(t, server, "GET", "/auth/me", nil, token)
+		assertStatus(t, rr, http.StatusOK)
+	})
+
+	t.Run("should reject a registered email regardless of case", func(t *testing.T) {
+		rr := makeRequest(t, server, "POST", "/auth/register", generated.RegisterRequest{
+			Email:    "ALICE@example.com",
+			Name:     "Another Alice",
+			Password: "password123",
+		})
+		assertStatus(t, rr, http.StatusConflict)
+		assertErrorResponse(t, rr, "CONFLICT")
+	})
+
+	t.Run("should enforce the password policy", func(t *testing.T) {
+		rr := makeRequest(t, server, "POST", "/auth/register", generated.RegisterRequest{
+			Email:    "dave@example.com",
+			Name:     "Dave",
+			Password: "short",
+		})
+		assertStatus(t, rr, http.StatusBadRequest)
+		details := assertValidationErrors(t, rr, "/password")
+		assert.Equal(t, "password", details[0].Rule)
+	})
+
+	t.Run("should require an email and name", func(t *testing.T) {
+		rr := makeRequest(t, server, "POST", "/auth/register", generated.RegisterRequest{
+			Email:    "not-an-email",
+			Name:     "",
+			Password: "password123",
+		})
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertValidationErrors(t, rr, "/email", "/name")
+	})
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	server := setupTestServer(t)
+
+	t.Run("should require authentication", func(t *testing.T) {
+		rr := makeRequest(t, server, "POST", "/auth/change-password", generated.ChangePasswordRequest{
+			CurrentPassword: "password456",
+			NewPassword:     "new password",
+		})
+		assertStatus(t, rr, http.StatusUnauthorized)
+	})
+
+	t.Run("should reject a wrong current password", func(t *testing.T) {
+		token := loginTestUser(t, server, "bob@example.com", "password456")
+		rr := makeAuthenticatedRequest(t, server, "POST", "/auth/change-password", generated.ChangePasswordRequest{
+			CurrentPassword: "wrong password",
+			NewPassword:     "new password",
+		}, token)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertErrorResponse(t, rr, "BAD_REQUEST")
+	})
+
+	t.Run("should change the password and end other sessions", func(t *testing.T) {
+		otherToken := loginTestUser(t, server, "bob@example.com", "password456")
+		token := loginTestUser(t, server, "bob@example.com", "password456")
+
+		rr := makeAuthenticatedRequest(t, server, "POST", "/auth/change-password", generated.ChangePasswordRequest{
+			CurrentPassword: "password456",
+			NewPassword:     "new password",
+		}, token)
+		assertStatus(t, rr, http.StatusOK)
+
+		assert.Equal(t, http.StatusUnauthorized, login(t, server, "bob@example.com", "password456").Code)
+		loginTestUser(t, server, "bob@example.com", "new password")
+
+		rr = makeAuthenticatedRequest(t, server, "GET", "/auth/me", nil, token)
+		assertStatus(t, rr, http.StatusOK)
+		_, err := server.authStorage.ValidateToken(context.Background(), otherToken)
+		assert.Error(t, err, "other sessions should be revoked")
+	})
+}
+
+func TestAuthService_PasswordReset(t *testing.T) {
+	sender := &capturingResetSender{}
+	server := setupTestServer(t, handlers.WithPasswordResetSender(sender))
+
+	t.Run("should answer the same for unknown emails", func(t *testing.T) {
+		rr := makeRequest(t, server, "POST", "/auth/password-reset", generated.PasswordResetRequest{Email: "nobody@example.com"})
+		assertStatus(t, rr, http.StatusOK)
+		assert.Empty(t, sender.token("nobody@example.com"))
+	})
+
+	t.Run("should reject an unknown token", func(t *testing.T) {
+		rr := makeRequest(t, server, "POST", "/auth/password-reset/confirm", generated.PasswordResetConfirmRequest{
+			Token:       "unknown",
+			NewPassword: "new password",
+		})
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertErrorResponse(t, rr, "BAD_REQUEST")
+	})
+
+	t.Run("should reset the password once with the sent token", func(t *testing.T) {
+		session := loginTestUser(t, server, "alice@example.com", "password123")
+
+		rr := makeRequest(t, server, "POST", "/auth/password-reset", generated.PasswordResetRequest{Email: "alice@example.com"})
+		assertStatus(t, rr, http.StatusOK)
+		token := sender.token("alice@example.com")
+		require.NotEmpty(t, token)
+
+		rr = makeRequest(t, server, "POST", "/auth/password-reset/confirm", generated.PasswordResetConfirmRequest{
+			Token:       token,
+			NewPassword: "reset password",
+		})
+		assertStatus(t, rr, http.StatusOK)
+
+		assert.Equal(t, http.StatusUnauthorized, login(t, server, "alice@example.com", "password123").Code)
+		loginTestUser(t, server, "alice@example.com", "reset password")
+		_, err := server.authStorage.ValidateToken(context.Background(), session)
+		assert.Error(t, err, "existing sessions should be revoked")
+
+		// Tokens are single-use
+		rr = makeRequest(t, server, "POST", "/auth/password-reset/confirm", generated.PasswordResetConfirmRequest{
+			Token:       token,
+			NewPassword: "another password",
+		})
+		assertStatus(t, rr, http.StatusBadRequest)
+	})
+}
+
+func TestAuthService_PasswordResetNotConfigured(t *testing.T) {
+	server := setupTestServer(t)
+
+	rr := makeRequest(t, server, "POST", "/auth/password-reset", generated.PasswordResetRequest{Email: "alice@example.com"})
+	assertStatus(t, rr, http.StatusNotFound)
+	assertErrorResponse(t, rr, "NOT_FOUND")
+
+	rr = makeRequest(t, server, "POST", "/auth/password-reset/confirm", generated.PasswordResetConfirmRequest{
+		Token:       "any",
+		NewPassword: "new password",
+	})
+	assertStatus(t, rr, http.StatusNotFound)
+}
+
+func TestWebhookResetSender(t *testing.T) {
+	var received map[string]string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		if received["email"] == "fail@example.com" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	t.Cleanup(webhook.Close)
+	sender := handlers.NewWebhookResetSender(webhook.URL)
+
+	t.Run("should post the email and token", func(t *testing.T) {
+		server := setupTestServer(t, handlers.WithPasswordResetSender(sender))
+
+		rr := makeRequest(t, server, "POST", "/auth/password-reset", generated.PasswordResetRequest{Email: "bob@example.com"})
+		assertStatus(t, rr, http.StatusOK)
+		assert.Equal(t, "bob@example.com", received["email"])
+		assert.NotEmpty(t, received["token"])
+
+		rr = makeRequest(t, server, "POST", "/auth/password-reset/confirm", generated.PasswordResetConfirmRequest{
+			Token:       received["token"],
+			NewPassword: "webhook password",
+		})
+		assertStatus(t, rr, http.StatusOK)
+		loginTestUser(t, server, "bob@example.com", "webhook password")
+	})
+
+	t.Run("should fail when the webhook does", func(t *testing.T) {
+		err := sender.SendPasswordReset(t.Context(), "fail@example.com", "token")
+		assert.ErrorContains(t, err, "502")
+	})
+}
+
+func TestAuthService_StoreIdentity(t *testing.T) {
+	server, userID, token := setupTestServerWithAuth(t)
+
+	t.Run("should log in as the store user", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "GET", "/auth/me", nil, token)
+		assertStatus(t, rr, http.StatusOK)
+
+		var me generated.AuthUser
+		require.NoError(t, decodeJSON(rr, &me))
+		assert.Equal(t, userID, me.Id)
+
+		rr = makeAuthenticatedRequest(t, server, "GET", "/users/"+me.Id, nil, token)
+		assertStatus(t, rr, http.StatusOK)
+		rr = makeAuthenticatedRequest(t, server, "GET", "/carts/users/"+me.Id, nil, token)
+		assertStatus(t, rr, http.StatusOK)
+		rr = makeAuthenticatedRequest(t, server, "GET", "/orders/users/"+me.Id, nil, token)
+		assertStatus(t, rr, http.StatusOK)
+	})
+
+	t.Run("should reflect profile updates in /auth/me", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/users/"+userID, map[string]any{"name": "Alice Cooper"}, token)
+		assertStatus(t, rr, http.StatusOK)
+
+		rr = makeAuthenticatedRequest(t, server, "GET", "/auth/me", nil, token)
+		assertStatus(t, rr, http.StatusOK)
+		var me generated.AuthUser
+		require.NoError(t, decodeJSON(rr, &me))
+		assert.Equal(t, "Alice Cooper", me.Name)
+	})
+
+	t.Run("should register a user with a cart", func(t *testing.T) {
+		rr := makeRequest(t, server, "POST", "/auth/register", generated.RegisterRequest{
+			Email:    "ivan@example.com",
+			Name:     "Ivan",
+			Password: "ivan password",
+		})
+		assertStatus(t, rr, http.StatusCreated)
+		var registered generated.AuthUser
+		require.NoError(t, decodeJSON(rr, &registered))
+
+		ivanToken := loginTestUser(t, server, "ivan@example.com", "ivan password")
+		rr = makeAuthenticatedRequest(t, server, "GET", "/users/"+registered.Id, nil, ivanToken)
+		assertStatus(t, rr, http.StatusOK)
+		rr = makeAuthenticatedRequest(t, server, "GET", "/carts/users/"+registered.Id, nil, ivanToken)
+		assertStatus(t, rr, http.StatusOK)
+	})
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -16,7 +17,10 @@ import (
 func TestAuthHandlers_Login(t *testing.T) {
 	// Setup
 	memoryStore := store.NewMemoryStore()
-	authStore := storage.NewAuthStore()
+	if err := store.SeedDemoData(context.Background(), memoryStore); err != nil {
+		t.Fatalf("Failed to seed demo data: %v", err)
+	}
+	authStore := storage.NewAuthStore(memoryStore)
 	server := NewServer(memoryStore, authStore)
 
 	// Test successful login with pre-configured user
@@ -95,11 +99,14 @@ func TestAuthHandlers_Login(t *testing.T) {
 func TestAuthHandlers_Logout(t *testing.T) {
 	// Setup
 	memoryStore := store.NewMemoryStore()
-	authStore := storage.NewAuthStore()
+	if err := store.SeedDemoData(context.Background(), memoryStore); err != nil {
+		t.Fatalf("Failed to seed demo data: %v", err)
+	}
+	authStore := storage.NewAuthStore(memoryStore)
 	server := NewServer(memoryStore, authStore)
 
 	// Login to create a token
-	session, err := authStore.Login("alice@example.com", "password123")
+	session, err := authStore.Login(context.Background(), "alice@example.com", "password123", storage.ClientInfo{})
 	if err != nil {
 		t.Fatalf("Failed to create test session: %v", err)
 	}
@@ -118,7 +125,7 @@ func TestAuthHandlers_Logout(t *testing.T) {
 		}
 
 		// Verify token is deleted by trying to validate it
-		if _, err := authStore.ValidateToken(token); err == nil {
+		if _, err := authStore.ValidateToken(context.Background(), token); err == nil {
 			t.Error("expected token to be deleted, but validation succeeded")
 		}
 	})
@@ -139,11 +146,14 @@ func TestAuthHandlers_Logout(t *testing.T) {
 func TestAuthHandlers_GetCurrentUser(t *testing.T) {
 	// Setup
 	memoryStore := store.NewMemoryStore()
-	authStore := storage.NewAuthStore()
+	if err := store.SeedDemoData(context.Background(), memoryStore); err != nil {
+		t.Fatalf("Failed to seed demo data: %v", err)
+	}
+	authStore := storage.NewAuthStore(memoryStore)
 	server := NewServer(memoryStore, authStore)
 
 	// Login to create a token
-	session, err := authStore.Login("alice@example.com", "password123")
+	session, err := authStore.Login(context.Background(), "alice@example.com", "password123", storage.ClientInfo{})
 	if err != nil {
 		t.Fatalf("Failed to create test session: %v", err)
 	}
@@ -0,0 +1,179 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditor keeps the failed logins it is given
+type recordingAuditor struct {
+	mu       sync.Mutex
+	failures []storage.LoginFailure
+}
+
+func (a *recordingAuditor) RecordLoginFailure(ctx context.Context, failure storage.LoginFailure) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failures = append(a.failures, failure)
+}
+
+func (a *recordingAuditor) recorded() []storage.LoginFailure {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]storage.LoginFailure(nil), a.failures...)
+}
+
+func TestAuthService_LoginThrottle(t *testing.T) {
+	dataStore := newTestStore(t)
+	auditor := &recordingAuditor{}
+	authStore := storage.NewAuthStore(dataStore,
+		storage.WithLoginLimits(storage.LoginLimits{MaxFailures: 3, Window: time.Minute, BaseLockout: time.Minute, MaxLockout: time.Hour}),
+		storage.WithLoginAuditor(auditor),
+	)
+	server := serveTestServer(t, dataStore, authStore)
+
+	t.Run("should lock out after repeated failures", func(t *testing.T) {
+		for range 2 {
+			rr := login(t, server, "bob@example.com", "wrong")
+			assertStatus(t, rr, http.StatusUnauthorized)
+		}
+
+		rr := login(t, server, "bob@example.com", "wrong")
+		assertStatus(t, rr, http.StatusTooManyRequests)
+		assertErrorResponse(t, rr, "TOO_MANY_REQUESTS")
+		assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+
+		// The right password does not help while locked out
+		rr = login(t, server, "Bob@Example.com", "password456")
+		assertStatus(t, rr, http.StatusTooManyRequests)
+		assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+	})
+
+	t.Run("should not lock out other accounts", func(t *testing.T) {
+		rr := login(t, server, "alice@example.com", "password123")
+		assertStatus(t, rr, http.StatusOK)
+	})
+
+	t.Run("should record failures", func(t *testing.T) {
+		failures := auditor.recorded()
+		require.Len(t, failures, 4)
+		for _, failure := range failures[:3] {
+			assert.Equal(t, "bob@example.com", failure.Email)
+			assert.Equal(t, storage.LoginFailureInvalidCredentials, failure.Reason)
+		}
+		assert.Zero(t, failures[1].LockedUntil)
+		assert.False(t, failures[2].LockedUntil.IsZero())
+		assert.Equal(t, storage.LoginFailureLocked, failures[3].Reason)
+	})
+}
+
+func TestAuthStore_LoginThrottle(t *testing.T) {
+	ctx := context.Background()
+	limits := storage.LoginLimits{MaxFailures: 2, Window: time.Minute, BaseLockout: 50 * time.Millisecond, MaxLockout: 150 * time.Millisecond}
+	attacker := storage.ClientInfo{IP: "203.0.113.1"}
+
+	// failUntilLocked fails logins of bob from client until they are locked out
+	failUntilLocked := func(t *testing.T, authStore *storage.AuthStore, client storage.ClientInfo) *storage.LoginLockedError {
+		t.Helper()
+		_, err := authStore.Login(ctx, "bob@example.com", "wrong", client)
+		require.ErrorIs(t, err, storage.ErrInvalidCredentials)
+		_, err = authStore.Login(ctx, "bob@example.com", "wrong", client)
+		var locked *storage.LoginLockedError
+		require.True(t, errors.As(err, &locked), "got %v", err)
+		return locked
+	}
+
+	t.Run("should key failures by email and IP", func(t *testing.T) {
+		authStore := storage.NewAuthStore(newTestStore(t), storage.WithLoginLimits(limits), storage.WithLoginAuditor(&recordingAuditor{}))
+		failUntilLocked(t, authStore, attacker)
+
+		_, err := authStore.Login(ctx, "bob@example.com", "password456", storage.ClientInfo{IP: "198.51.100.7"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should lock out an email tried from many IPs", func(t *testing.T) {
+		perEmail := storage.LoginLimits{MaxFailuresPerEmail: 3, Window: time.Minute, BaseLockout: time.Minute, MaxLockout: time.Hour}
+		authStore := storage.NewAuthStore(newTestStore(t), storage.WithLoginLimits(perEmail), storage.WithLoginAuditor(&recordingAuditor{}))
+		for _, ip := range []string{"203.0.113.1", "203.0.113.2"} {
+			_, err := authStore.Login(ctx, "bob@example.com", "wrong", storage.ClientInfo{IP: ip})
+			require.ErrorIs(t, err, storage.ErrInvalidCredentials)
+		}
+		_, err := authStore.Login(ctx, "bob@example.com", "wrong", storage.ClientInfo{IP: "203.0.113.3"})
+		var locked *storage.LoginLockedError
+		require.True(t, errors.As(err, &locked), "got %v", err)
+
+		_, err = authStore.Login(ctx, "bob@example.com", "password456", storage.ClientInfo{IP: "198.51.100.7"})
+		assert.True(t, errors.As(err, &locked), "got %v", err)
+		_, err = authStore.Login(ctx, "alice@example.com", "password123", attacker)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should lock out an IP trying many emails", func(t *testing.T) {
+		perIP := storage.LoginLimits{MaxFailuresPerIP: 3, Window: time.Minute, BaseLockout: time.Minute, MaxLockout: time.Hour}
+		authStore := storage.NewAuthStore(newTestStore(t), storage.WithLoginLimits(perIP), storage.WithLoginAuditor(&recordingAuditor{}))
+		for _, email := range []string{"bob@example.com", "carol@example.com"} {
+			_, err := authStore.Login(ctx, email, "wrong", attacker)
+			require.ErrorIs(t, err, storage.ErrInvalidCredentials)
+		}
+		_, err := authStore.Login(ctx, "dave@example.com", "wrong", attacker)
+		var locked *storage.LoginLockedError
+		require.True(t, errors.As(err, &locked), "got %v", err)
+
+		_, err = authStore.Login(ctx, "alice@example.com", "password123", attacker)
+		assert.True(t, errors.As(err, &locked), "got %v", err)
+		_, err = authStore.Login(ctx, "alice@example.com", "password123", storage.ClientInfo{IP: "198.51.100.7"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should back off exponentially", func(t *testing.T) {
+		authStore := storage.NewAuthStore(newTestStore(t), storage.WithLoginLimits(limits), storage.WithLoginAuditor(&recordingAuditor{}))
+
+		var lockouts []time.Duration
+		for range 3 {
+			locked := failUntilLocked(t, authStore, attacker)
+			lockouts = append(lockouts, locked.RetryAfter)
+			time.Sleep(locked.RetryAfter)
+		}
+		assert.Equal(t, []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 150 * time.Millisecond}, lockouts)
+	})
+
+	t.Run("should keep per-email failures after a successful login", func(t *testing.T) {
+		perEmail := storage.LoginLimits{MaxFailuresPerEmail: 2, Window: time.Minute, BaseLockout: time.Minute, MaxLockout: time.Hour}
+		authStore := storage.NewAuthStore(newTestStore(t), storage.WithLoginLimits(perEmail), storage.WithLoginAuditor(&recordingAuditor{}))
+		_, err := authStore.Login(ctx, "bob@example.com", "wrong", attacker)
+		require.ErrorIs(t, err, storage.ErrInvalidCredentials)
+		_, err = authStore.Login(ctx, "bob@example.com", "password456", storage.ClientInfo{IP: "198.51.100.7"})
+		require.NoError(t, err)
+
+		_, err = authStore.Login(ctx, "bob@example.com", "wrong", storage.ClientInfo{IP: "203.0.113.2"})
+		var locked *storage.LoginLockedError
+		assert.True(t, errors.As(err, &locked), "got %v", err)
+	})
+
+	t.Run("should forget failures after a successful login", func(t *testing.T) {
+		authStore := storage.NewAuthStore(newTestStore(t), storage.WithLoginLimits(limits), storage.WithLoginAuditor(&recordingAuditor{}))
+		_, err := authStore.Login(ctx, "bob@example.com", "wrong", attacker)
+		require.ErrorIs(t, err, storage.ErrInvalidCredentials)
+		_, err = authStore.Login(ctx, "bob@example.com", "password456", attacker)
+		require.NoError(t, err)
+
+		_, err = authStore.Login(ctx, "bob@example.com", "wrong", attacker)
+		assert.ErrorIs(t, err, storage.ErrInvalidCredentials)
+	})
+
+	t.Run("should not throttle when disabled", func(t *testing.T) {
+		authStore := storage.NewAuthStore(newTestStore(t), storage.WithLoginLimits(storage.LoginLimits{}), storage.WithLoginAuditor(&recordingAuditor{}))
+		for range 10 {
+			_, err := authStore.Login(ctx, "bob@example.com", "wrong", attacker)
+			require.ErrorIs(t, err, storage.ErrInvalidCredentials)
+		}
+	})
+}
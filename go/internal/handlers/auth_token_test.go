@@ -0,0 +1,386 @@
+package handlers_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/jwt"
+	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loginSession logs in and returns the whole login response
+func loginSession(t testing.TB, server *TestServer, email, password string) generated.LoginResponse {
+	t.Helper()
+
+	rr := login(t, server, email, password)
+	assertStatus(t, rr, http.StatusOK)
+	var response generated.LoginResponse
+	require.NoError(t, decodeJSON(rr, &response))
+	require.NotNil(t, response.RefreshToken)
+	return response
+}
+
+// refresh exchanges a refresh token and returns the response
+func refresh(t testing.TB, server *TestServer, refreshToken string) generated.LoginResponse {
+	t.Helper()
+
+	rr := makeRequest(t, server, "POST", "/auth/refresh", generated.RefreshRequest{RefreshToken: refreshToken})
+	assertStatus(t, rr, http.StatusOK)
+	var response generated.LoginResponse
+	require.NoError(t, decodeJSON(rr, &response))
+	require.NotNil(t, response.RefreshToken)
+	return response
+}
+
+func TestAuthService_Tokens(t *testing.T) {
+	server := setupTestServer(t)
+
+	t.Run("should issue a signed JWT", func(t *testing.T) {
+		session := loginSession(t, server, "alice@example.com", "password123")
+
+		assert.Len(t, strings.Split(session.AccessToken, "."), 3)
+		assert.Equal(t, int32(storage.DefaultAccessTokenTTL.Seconds()), session.ExpiresIn)
+
+		user, err := server.authStorage.ValidateToken(context.Background(), session.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, session.User.Id, user.ID)
+		assert.Equal(t, []string{storage.RoleAdmin}, user.Roles)
+
+		_, err = server.authStorage.ValidateToken(context.Background(), session.AccessToken+"x")
+		assert.Error(t, err)
+	})
+
+	t.Run("should rotate refresh tokens", func(t *testing.T) {
+		session := loginSession(t, server, "bob@example.com", "password456")
+
+		refreshed := refresh(t, server, *session.RefreshToken)
+		assert.NotEqual(t, *session.RefreshToken, *refreshed.RefreshToken)
+		assert.Equal(t, session.User.Id, refreshed.User.Id)
+		rr := makeAuthenticatedRequest(t, server, "GET", "/users/"+refreshed.User.Id, nil, refreshed.AccessToken)
+		assertStatus(t, rr, http.StatusOK)
+
+		refreshed = refresh(t, server, *refreshed.RefreshToken)
+		rr = makeAuthenticatedRequest(t, server, "GET", "/users/"+refreshed.User.Id, nil, refreshed.AccessToken)
+		assertStatus(t, rr, http.StatusOK)
+	})
+
+	t.Run("should revoke the session when a refresh token is reused", func(t *testing.T) {
+		session := loginSession(t, server, "bob@example.com", "password456")
+		refreshed := refresh(t, server, *session.RefreshToken)
+
+		rr := makeRequest(t, server, "POST", "/auth/refresh", generated.RefreshRequest{RefreshToken: *session.RefreshToken})
+		assertStatus(t, rr, http.StatusUnauthorized)
+		assertErrorResponse(t, rr, "UNAUTHORIZED")
+
+		// Both the refreshed tokens are revoked
+		_, err := server.authStorage.ValidateToken(context.Background(), refreshed.AccessToken)
+		assert.Error(t, err)
+		rr = makeRequest(t, server, "POST", "/auth/refresh", generated.RefreshRequest{RefreshToken: *refreshed.RefreshToken})
+		assertStatus(t, rr, http.StatusUnauthorized)
+
+		// Other sessions are not affected
+		other := loginSession(t, server, "bob@example.com", "password456")
+		refresh(t, server, *other.RefreshToken)
+	})
+
+	t.Run("should end the session on logout", func(t *testing.T) {
+		session := loginSession(t, server, "bob@example.com", "password456")
+
+		rr := makeAuthenticatedRequest(t, server, "POST", "/auth/logout", nil, session.AccessToken)
+		assertStatus(t, rr, http.StatusOK)
+
+		rr = makeAuthenticatedRequest(t, server, "GET", "/users/"+session.User.Id, nil, session.AccessToken)
+		assertStatus(t, rr, http.StatusUnauthorized)
+		rr = makeRequest(t, server, "POST", "/auth/refresh", generated.RefreshRequest{RefreshToken: *session.RefreshToken})
+		assertStatus(t, rr, http.StatusUnauthorized)
+	})
+
+	t.Run("should reject unknown refresh tokens", func(t *testing.T) {
+		rr := makeRequest(t, server, "POST", "/auth/refresh", generated.RefreshRequest{RefreshToken: "unknown"})
+		assertStatus(t, rr, http.StatusUnauthorized)
+
+		rr = makeRequest(t, server, "POST", "/auth/refresh", generated.RefreshRequest{})
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertErrorResponse(t, rr, "VALIDATION_ERROR")
+	})
+
+	t.Run("should pick up role changes at once", func(t *testing.T) {
+		userID, _ := createLoggedInUser(t, server, "promoted@example.com", "Promoted")
+		session := loginSession(t, server, "promoted@example.com", "password123")
+		rr := makeAuthenticatedRequest(t, server, "GET", "/users", nil, session.AccessToken)
+		assertStatus(t, rr, http.StatusForbidden)
+
+		setRoles := func(roles []string) {
+			credential, err := server.store.GetCredential(context.Background(), userID)
+			require.NoError(t, err)
+			credential.Roles = roles
+			_, err = server.store.UpdateCredential(context.Background(), userID, credential)
+			require.NoError(t, err)
+		}
+
+		// The access token issued before the grant acts with the new role
+		setRoles([]string{storage.RoleAdmin})
+		rr = makeAuthenticatedRequest(t, server, "GET", "/users", nil, session.AccessToken)
+		assertStatus(t, rr, http.StatusOK)
+
+		// and loses it as soon as it is revoked
+		setRoles(nil)
+		rr = makeAuthenticatedRequest(t, server, "GET", "/users", nil, session.AccessToken)
+		assertStatus(t, rr, http.StatusForbidden)
+	})
+}
+
+func TestAuthStore_SigningKeys(t *testing.T) {
+	ctx := context.Background()
+	users := newTestStore(t)
+
+	oldKey := jwt.NewHS256Key("old", []byte("0123456789abcdef0123456789abcdef"))
+	_, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	newKey := jwt.NewEdDSAKey("new", private)
+
+	oldKeys, err := jwt.NewKeySet(oldKey)
+	require.NoError(t, err)
+	oldSession, err := storage.NewAuthStore(users, storage.WithSigningKeys(oldKeys)).Login(ctx, "alice@example.com", "password123", storage.ClientInfo{})
+	require.NoError(t, err)
+
+	t.Run("should accept tokens signed with a previous key", func(t *testing.T) {
+		keys, err := jwt.NewKeySet(newKey, oldKey)
+		require.NoError(t, err)
+		authStore := storage.NewAuthStore(users, storage.WithSigningKeys(keys))
+
+		user, err := authStore.ValidateToken(ctx, oldSession.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", user.Email)
+
+		session, err := authStore.Login(ctx, "alice@example.com", "password123", storage.ClientInfo{})
+		require.NoError(t, err)
+		_, err = authStore.ValidateToken(ctx, session.Token)
+		assert.NoError(t, err)
+
+		// Servers that only know the old key reject the new one
+		_, err = storage.NewAuthStore(users, storage.WithSigningKeys(oldKeys)).ValidateToken(ctx, session.Token)
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject tokens signed with a retired key", func(t *testing.T) {
+		keys, err := jwt.NewKeySet(newKey)
+		require.NoError(t, err)
+
+		_, err = storage.NewAuthStore(users, storage.WithSigningKeys(keys)).ValidateToken(ctx, oldSession.Token)
+		assert.Error(t, err)
+	})
+
+	t.Run("should reject expired tokens", func(t *testing.T) {
+		authStore := storage.NewAuthStore(users, storage.WithSigningKeys(oldKeys), storage.WithTokenTTL(-time.Minute, time.Hour))
+		session, err := authStore.Login(ctx, "alice@example.com", "password123", storage.ClientInfo{})
+		require.NoError(t, err)
+
+		_, err = authStore.ValidateToken(ctx, session.Token)
+		assert.Error(t, err)
+
+		// The session can still be refreshed
+		_, err = authStore.Refresh(ctx, session.RefreshToken, storage.ClientInfo{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should parse configured keys", func(t *testing.T) {
+		_, err := jwt.ParseKeySet(jwt.HS256, "k2:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=,k1:YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXoxMjM0NTY=")
+		assert.NoError(t, err)
+		_, err = jwt.ParseKeySet(jwt.EdDSA, "k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+		assert.NoError(t, err)
+
+		_, err = jwt.ParseKeySet(jwt.HS256, "k1:c2hvcnQ=")
+		assert.Error(t, err, "short secrets are rejected")
+		_, err = jwt.ParseKeySet(jwt.HS256, "no-kid")
+		assert.Error(t, err)
+		_, err = jwt.ParseKeySet("none", "k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+		assert.Error(t, err)
+	})
+}
+
+func TestAuthStore_SharedSessions(t *testing.T) {
+	ctx := context.Background()
+	users := newTestStore(t)
+	keys, err := jwt.NewKeySet(jwt.NewHS256Key("k1", []byte("0123456789abcdef0123456789abcdef")))
+	require.NoError(t, err)
+
+	// Two auth stores on one data store stand for a restart or two replicas
+	first := storage.NewAuthStore(users, storage.WithSigningKeys(keys))
+	second := storage.NewAuthStore(users, storage.WithSigningKeys(keys))
+
+	t.Run("should refresh sessions started elsewhere", func(t *testing.T) {
+		session, err := first.Login(ctx, "bob@example.com", "password456", storage.ClientInfo{Device: "Laptop"})
+		require.NoError(t, err)
+
+		refreshed, err := second.Refresh(ctx, session.RefreshToken, storage.ClientInfo{Device: "Laptop"})
+		require.NoError(t, err)
+		_, err = first.ValidateToken(ctx, refreshed.Token)
+		assert.NoError(t, err)
+
+		sessions, err := first.ListSessions(ctx, session.User.ID)
+		require.NoError(t, err)
+		assert.Len(t, sessions, 1)
+	})
+
+	t.Run("should detect refresh token reuse elsewhere", func(t *testing.T) {
+		session, err := first.Login(ctx, "bob@example.com", "password456", storage.ClientInfo{})
+		require.NoError(t, err)
+		refreshed, err := first.Refresh(ctx, session.RefreshToken, storage.ClientInfo{})
+		require.NoError(t, err)
+
+		_, err = second.Refresh(ctx, session.RefreshToken, storage.ClientInfo{})
+		assert.ErrorIs(t, err, storage.ErrInvalidRefreshToken)
+		_, err = first.ValidateToken(ctx, refreshed.Token)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+		_, err = first.Refresh(ctx, refreshed.RefreshToken, storage.ClientInfo{})
+		assert.ErrorIs(t, err, storage.ErrInvalidRefreshToken)
+	})
+
+	t.Run("should reject tokens of sessions ended elsewhere", func(t *testing.T) {
+		session, err := first.Login(ctx, "bob@example.com", "password456", storage.ClientInfo{})
+		require.NoError(t, err)
+
+		require.NoError(t, second.Logout(ctx, session.Token))
+		_, err = first.ValidateToken(ctx, session.Token)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("should accept password reset tokens issued elsewhere once", func(t *testing.T) {
+		token, err := first.RequestPasswordReset(ctx, "bob@example.com")
+		require.NoError(t, err)
+
+		require.NoError(t, second.ResetPassword(ctx, token, "new-password789"))
+		assert.ErrorIs(t, first.ResetPassword(ctx, token, "other-password789"), storage.ErrInvalidResetToken)
+	})
+}
+
+func TestAuthService_Sessions(t *testing.T) {
+	server := setupTestServer(t)
+
+	loginFrom := func(t *testing.T, device string) generated.LoginResponse {
+		t.Helper()
+		rr := makeRequestWithHeaders(t, server, "POST", "/auth/login", generated.LoginRequest{
+			Email:    "bob@example.com",
+			Password: "password456",
+		}, map[string]string{"User-Agent": device})
+		assertStatus(t, rr, http.StatusOK)
+		var response generated.LoginResponse
+		require.NoError(t, decodeJSON(rr, &response))
+		return response
+	}
+	listSessions := func(t *testing.T, token string) []generated.Session {
+		t.Helper()
+		rr := makeAuthenticatedRequest(t, server, "GET", "/auth/sessions", nil, token)
+		assertStatus(t, rr, http.StatusOK)
+		var sessions []generated.Session
+		require.NoError(t, decodeJSON(rr, &sessions))
+		return sessions
+	}
+
+	laptop := loginFrom(t, "Laptop Browser")
+	phone := loginFrom(t, "Phone App")
+
+	t.Run("should list the user's sessions", func(t *testing.T) {
+		sessions := listSessions(t, laptop.AccessToken)
+		require.Len(t, sessions, 2)
+
+		// Most recently used first
+		assert.Equal(t, "Phone App", *sessions[0].Device)
+		assert.False(t, sessions[0].Current)
+		assert.Equal(t, "Laptop Browser", *sessions[1].Device)
+		assert.True(t, sessions[1].Current)
+		assert.False(t, sessions[1].CreatedAt.IsZero())
+		assert.False(t, sessions[1].LastSeenAt.IsZero())
+	})
+
+	t.Run("should not show other users' sessions", func(t *testing.T) {
+		aliceToken := loginTestUser(t, server, "alice@example.com", "password123")
+		assert.Len(t, listSessions(t, aliceToken), 1)
+
+		phoneID := listSessions(t, phone.AccessToken)[0].Id
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/auth/sessions/"+phoneID, nil, aliceToken)
+		assertStatus(t, rr, http.StatusNotFound)
+		assertErrorResponse(t, rr, "NOT_FOUND")
+	})
+
+	t.Run("should revoke a session", func(t *testing.T) {
+		sessions := listSessions(t, laptop.AccessToken)
+		require.Len(t, sessions, 2)
+		phoneID := sessions[0].Id
+
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/auth/sessions/"+phoneID, nil, laptop.AccessToken)
+		assertStatus(t, rr, http.StatusNoContent)
+
+		rr = makeAuthenticatedRequest(t, server, "GET", "/auth/sessions", nil, phone.AccessToken)
+		assertStatus(t, rr, http.StatusUnauthorized)
+		rr = makeRequest(t, server, "POST", "/auth/refresh", generated.RefreshRequest{RefreshToken: *phone.RefreshToken})
+		assertStatus(t, rr, http.StatusUnauthorized)
+		assert.Len(t, listSessions(t, laptop.AccessToken), 1)
+
+		rr = makeAuthenticatedRequest(t, server, "DELETE", "/auth/sessions/"+phoneID, nil, laptop.AccessToken)
+		assertStatus(t, rr, http.StatusNotFound)
+	})
+
+	t.Run("should record when a session was last used", func(t *testing.T) {
+		desktop := loginFrom(t, "Desktop Browser")
+		var current generated.Session
+		for _, session := range listSessions(t, desktop.AccessToken) {
+			if session.Current {
+				current = session
+			}
+		}
+		stored, err := server.store.GetSession(context.Background(), current.Id)
+		require.NoError(t, err)
+		stale := time.Now().Add(-time.Hour)
+		stored.LastSeenAt = stale
+		_, err = server.store.UpdateSession(context.Background(), stored.Id, stored)
+		require.NoError(t, err)
+
+		// Using the token marks the session as seen
+		sessions := listSessions(t, desktop.AccessToken)
+		require.NotEmpty(t, sessions)
+		assert.Equal(t, current.Id, sessions[0].Id)
+		assert.True(t, sessions[0].LastSeenAt.After(stale.Add(time.Minute)))
+	})
+
+	t.Run("should require authentication", func(t *testing.T) {
+		rr := makeRequest(t, server, "GET", "/auth/sessions", nil)
+		assertStatus(t, rr, http.StatusUnauthorized)
+	})
+}
+
+func TestAuthStore_Janitor(t *testing.T) {
+	ctx := context.Background()
+	authStore := storage.NewAuthStore(newTestStore(t), storage.WithTokenTTL(time.Minute, time.Millisecond))
+	session, err := authStore.Login(ctx, "bob@example.com", "password456", storage.ClientInfo{})
+	require.NoError(t, err)
+
+	janitorCtx, stop := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		authStore.RunJanitor(janitorCtx, time.Millisecond)
+	}()
+
+	assert.Eventually(t, func() bool {
+		sessions, err := authStore.ListSessions(ctx, session.User.ID)
+		return err == nil && len(sessions) == 0
+	}, time.Second, time.Millisecond)
+	_, err = authStore.Refresh(ctx, session.RefreshToken, storage.ClientInfo{})
+	assert.ErrorIs(t, err, storage.ErrInvalidRefreshToken)
+
+	stop()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("janitor did not stop")
+	}
+}
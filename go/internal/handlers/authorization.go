@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	authctx "github.com/blck-snwmn/hello-typespec/go/internal/auth"
+	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
+)
+
+// authorizeOwner checks that the authenticated user is ownerId, the user a
+// cart, order or profile belongs to. It returns an apiError so that it can
+// also abort a transaction.
+func authorizeOwner(ctx context.Context, ownerId string) error {
+	user, ok := authctx.GetUser(ctx)
+	if !ok {
+		return &apiError{http.StatusUnauthorized, ErrorCodeUnauthorized, "Authentication required"}
+	}
+	if user.ID != ownerId {
+		return &apiError{http.StatusForbidden, ErrorCodeForbidden, "You do not have access to this resource"}
+	}
+	return nil
+}
+
+// authorizeOwnerOrAdmin is like authorizeOwner but also lets admins through,
+// for the records admins manage on behalf of every user
+func authorizeOwnerOrAdmin(ctx context.Context, ownerId string) error {
+	if user, ok := authctx.GetUser(ctx); ok && user.HasRole(storage.RoleAdmin) {
+		return nil
+	}
+	return authorizeOwner(ctx, ownerId)
+}
+
+// requireOwner sends an error response and returns false unless the
+// authenticated user is ownerId
+func requireOwner(w http.ResponseWriter, r *http.Request, ownerId string) bool {
+	if err := authorizeOwner(r.Context(), ownerId); err != nil {
+		txErrorResponse(w, err, "User")
+		return false
+	}
+	return true
+}
+
+// requireOwnerOrAdmin sends an error response and returns false unless the
+// authenticated user is ownerId or an admin
+func requireOwnerOrAdmin(w http.ResponseWriter, r *http.Request, ownerId string) bool {
+	if err := authorizeOwnerOrAdmin(r.Context(), ownerId); err != nil {
+		txErrorResponse(w, err, "User")
+		return false
+	}
+	return true
+}
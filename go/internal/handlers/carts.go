@@ -1,143 +1,304 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/inventory"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/blck-snwmn/hello-typespec/go/internal/validation"
 )
 
 // CartsServiceGetByUser implements GET /carts/users/{userId}
 func (s *Server) CartsServiceGetByUser(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
-	cart := s.store.GetCartByUserId(userId)
+	if !requireOwner(w, r, userId) {
+		return
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(cart)
+	// The total is priced from the products, which change without the cart
+	lastModified, err := s.modifiedAt(r.Context(), store.TableProducts)
+	if err != nil {
+		storeErrorResponse(w, err, "Product")
+		return
+	}
+	cart, err := s.store.GetCartByUserId(r.Context(), userId)
+	if err != nil {
+		storeErrorResponse(w, err, "Cart")
+		return
+	}
+	summary, err := s.cartSummary(r.Context(), cart)
+	if err != nil {
+		storeErrorResponse(w, err, "Product")
+		return
+	}
+	if cart.UpdatedAt.After(lastModified) {
+		lastModified = cart.UpdatedAt
+	}
+
+	writeConditionalJSON(w, r, summary, lastModified)
 }
 
 // CartsServiceAddItem implements POST /carts/users/{userId}/items
 func (s *Server) CartsServiceAddItem(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
+	if !requireOwner(w, r, userId) {
+		return
+	}
+
 	var req generated.AddCartItemRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		errorResponse(w, http.StatusBadRequest, ErrorCodeBadRequest, "Invalid request body")
 		return
 	}
-
-	cart := s.store.GetCartByUserId(userId)
-	product, ok := s.store.GetProduct(req.ProductId)
-
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Product not found")
+	var errs validation.Errors
+	errs.Minimum("/quantity", float64(req.Quantity), 1)
+	if len(errs) > 0 {
+		validationErrorResponse(w, errs)
 		return
 	}
 
-	if product.Stock < req.Quantity {
-		errorResponse(w, http.StatusBadRequest, ErrorCodeInsufficientStock, "Insufficient stock")
-		return
-	}
+	// Read the cart, reserve the stock and write the cart in one transaction
+	// so that concurrent changes to the cart cannot lose items and the
+	// reservation stands or falls with the cart
+	var updated generated.Cart
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		cart, err := tx.GetCartByUserId(r.Context(), userId)
+		if err != nil {
+			return err
+		}
+		product, err := getCartProduct(r.Context(), tx, req.ProductId)
+		if err != nil {
+			return err
+		}
 
-	// Check if item already exists in cart
-	itemFound := false
-	for i := range cart.Items {
-		if cart.Items[i].ProductId == req.ProductId {
-			cart.Items[i].Quantity += req.Quantity
-			itemFound = true
-			break
+		// Check if item already exists in cart
+		quantity := req.Quantity
+		itemIndex := -1
+		for i := range cart.Items {
+			if cart.Items[i].ProductId == req.ProductId {
+				quantity += cart.Items[i].Quantity
+				itemIndex = i
+				break
+			}
 		}
-	}
 
-	if !itemFound {
-		// Add new item
-		cart.Items = append(cart.Items, generated.CartItem{
-			ProductId: req.ProductId,
-			Quantity:  req.Quantity,
-		})
-	}
+		// Hold the full cart quantity so other users cannot buy it before checkout
+		if err := s.reserve(r.Context(), tx, userId, product, quantity); err != nil {
+			return err
+		}
+
+		if itemIndex >= 0 {
+			cart.Items[itemIndex].Quantity = quantity
+		} else {
+			// Add new item
+			cart.Items = append(cart.Items, generated.CartItem{
+				ProductId: req.ProductId,
+				Quantity:  req.Quantity,
+			})
+		}
 
-	cart.UpdatedAt = time.Now()
-	updated := s.store.UpdateCart(userId, cart)
+		cart.UpdatedAt = time.Now()
+		updated, err = tx.UpdateCart(r.Context(), userId, cart)
+		return err
+	})
+	if err != nil {
+		txErrorResponse(w, err, "Cart")
+		return
+	}
+	summary, err := s.cartSummary(r.Context(), updated)
+	if err != nil {
+		storeErrorResponse(w, err, "Product")
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(updated)
+	json.NewEncoder(w).Encode(summary)
 }
 
 // CartsServiceUpdateItem implements PATCH /carts/users/{userId}/items/{productId}
 func (s *Server) CartsServiceUpdateItem(w http.ResponseWriter, r *http.Request, userId generated.Uuid, productId generated.Uuid) {
+	if !requireOwner(w, r, userId) {
+		return
+	}
+
 	var req generated.UpdateCartItemRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		errorResponse(w, http.StatusBadRequest, ErrorCodeBadRequest, "Invalid request body")
 		return
 	}
-
-	cart := s.store.GetCartByUserId(userId)
-	product, ok := s.store.GetProduct(productId)
-
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Product not found")
+	var errs validation.Errors
+	errs.Minimum("/quantity", float64(req.Quantity), 1)
+	if len(errs) > 0 {
+		validationErrorResponse(w, errs)
 		return
 	}
 
-	itemIndex := -1
-	for i := range cart.Items {
-		if cart.Items[i].ProductId == productId {
-			itemIndex = i
-			break
+	// Read the cart, reserve the stock and write the cart in one transaction
+	// so that concurrent changes to the cart cannot lose items and the
+	// reservation stands or falls with the cart
+	var updated generated.Cart
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		cart, err := tx.GetCartByUserId(r.Context(), userId)
+		if err != nil {
+			return err
+		}
+		product, err := getCartProduct(r.Context(), tx, productId)
+		if err != nil {
+			return err
+		}
+
+		itemIndex := -1
+		for i := range cart.Items {
+			if cart.Items[i].ProductId == productId {
+				itemIndex = i
+				break
+			}
+		}
+
+		if itemIndex < 0 {
+			return &apiError{http.StatusNotFound, ErrorCodeNotFound, "Item not found in cart"}
 		}
-	}
 
-	if itemIndex < 0 {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Item not found in cart")
+		if err := s.reserve(r.Context(), tx, userId, product, req.Quantity); err != nil {
+			return err
+		}
+
+		cart.Items[itemIndex].Quantity = req.Quantity
+		cart.UpdatedAt = time.Now()
+
+		updated, err = tx.UpdateCart(r.Context(), userId, cart)
+		return err
+	})
+	if err != nil {
+		txErrorResponse(w, err, "Cart")
 		return
 	}
-
-	if product.Stock < req.Quantity {
-		errorResponse(w, http.StatusBadRequest, ErrorCodeInsufficientStock, "Insufficient stock")
+	summary, err := s.cartSummary(r.Context(), updated)
+	if err != nil {
+		storeErrorResponse(w, err, "Product")
 		return
 	}
 
-	cart.Items[itemIndex].Quantity = req.Quantity
-	cart.UpdatedAt = time.Now()
-
-	updated := s.store.UpdateCart(userId, cart)
-
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(updated)
+	json.NewEncoder(w).Encode(summary)
 }
 
 // CartsServiceRemoveItem implements DELETE /carts/users/{userId}/items/{productId}
 func (s *Server) CartsServiceRemoveItem(w http.ResponseWriter, r *http.Request, userId generated.Uuid, productId generated.Uuid) {
-	cart := s.store.GetCartByUserId(userId)
+	if !requireOwner(w, r, userId) {
+		return
+	}
 
-	itemIndex := -1
-	for i := range cart.Items {
-		if cart.Items[i].ProductId == productId {
-			itemIndex = i
-			break
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		cart, err := tx.GetCartByUserId(r.Context(), userId)
+		if err != nil {
+			return err
 		}
-	}
 
-	if itemIndex < 0 {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Item not found in cart")
-		return
-	}
+		itemIndex := -1
+		for i := range cart.Items {
+			if cart.Items[i].ProductId == productId {
+				itemIndex = i
+				break
+			}
+		}
 
-	// Remove item from cart
-	cart.Items = append(cart.Items[:itemIndex], cart.Items[itemIndex+1:]...)
-	cart.UpdatedAt = time.Now()
+		if itemIndex < 0 {
+			return &apiError{http.StatusNotFound, ErrorCodeNotFound, "Item not found in cart"}
+		}
 
-	s.store.UpdateCart(userId, cart)
+		// Remove item from cart
+		cart.Items = append(cart.Items[:itemIndex], cart.Items[itemIndex+1:]...)
+		cart.UpdatedAt = time.Now()
+
+		if _, err := tx.UpdateCart(r.Context(), userId, cart); err != nil {
+			return err
+		}
+		return s.reservations.Release(r.Context(), tx, userId, productId)
+	})
+	if err != nil {
+		txErrorResponse(w, err, "Cart")
+		return
+	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
 // CartsServiceClear implements DELETE /carts/users/{userId}/items
 func (s *Server) CartsServiceClear(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
-	cart := s.store.GetCartByUserId(userId)
-	cart.Items = []generated.CartItem{}
-	cart.UpdatedAt = time.Now()
+	if !requireOwner(w, r, userId) {
+		return
+	}
 
-	s.store.UpdateCart(userId, cart)
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		if err := clearCart(r.Context(), tx, userId, time.Now()); err != nil {
+			return err
+		}
+		return s.reservations.ReleaseAll(r.Context(), tx, userId)
+	})
+	if err != nil {
+		txErrorResponse(w, err, "Cart")
+		return
+	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// getCartProduct returns the product a cart item is for
+func getCartProduct(ctx context.Context, tx store.Tx, productId string) (generated.Product, error) {
+	product, err := tx.GetProduct(ctx, productId)
+	if errors.Is(err, store.ErrNotFound) {
+		return product, &apiError{http.StatusNotFound, ErrorCodeNotFound, "Product not found"}
+	}
+	return product, err
+}
+
+// reserve holds quantity units of product for userID's cart in tx, which
+// has read product
+func (s *Server) reserve(ctx context.Context, tx store.Tx, userID string, product generated.Product, quantity int32) error {
+	err := s.reservations.Reserve(ctx, tx, userID, product.Id, quantity, product.Stock)
+	if errors.Is(err, inventory.ErrInsufficientStock) {
+		return &apiError{http.StatusBadRequest, ErrorCodeInsufficientStock, "Insufficient stock"}
+	}
+	return err
+}
+
+// cartSummary adds the item count and the total at current product prices to
+// cart. Items whose product has been deleted count towards neither.
+func (s *Server) cartSummary(ctx context.Context, cart generated.Cart) (generated.CartSummary, error) {
+	summary := generated.CartSummary{
+		Id:        cart.Id,
+		UserId:    cart.UserId,
+		Items:     cart.Items,
+		CreatedAt: cart.CreatedAt,
+		UpdatedAt: cart.UpdatedAt,
+	}
+	for _, item := range cart.Items {
+		product, err := s.store.GetProduct(ctx, item.ProductId)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return generated.CartSummary{}, err
+		}
+		summary.TotalItems += item.Quantity
+		summary.TotalAmount += product.Price * float32(item.Quantity)
+	}
+	return summary, nil
+}
+
+// clearCart removes every item from the user's cart
+func clearCart(ctx context.Context, tx store.Tx, userId string, now time.Time) error {
+	cart, err := tx.GetCartByUserId(ctx, userId)
+	if err != nil {
+		return err
+	}
+	cart.Items = []generated.CartItem{}
+	cart.UpdatedAt = now
+
+	_, err = tx.UpdateCart(ctx, userId, cart)
+	return err
+}
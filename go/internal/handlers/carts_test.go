@@ -1,25 +1,30 @@
 package handlers_test
 
 import (
+	"fmt"
 	"net/http"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/blck-snwmn/hello-typespec/go/internal/handlers"
+	"github.com/blck-snwmn/hello-typespec/go/internal/inventory"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestCartsService_Get(t *testing.T) {
-	server, _, token := setupTestServerWithAuth(t)
+	server, userID, token := setupTestServerWithAuth(t)
 
 	t.Run("should return cart for existing user", func(t *testing.T) {
-		rr := makeAuthenticatedRequest(t, server, "GET", "/carts/users/1", nil, token)
+		rr := makeAuthenticatedRequest(t, server, "GET", "/carts/users/"+userID, nil, token)
 		assertStatus(t, rr, http.StatusOK)
 
 		var cart map[string]any
 		err := decodeJSON(rr, &cart)
 		require.NoError(t, err)
 
-		assert.Equal(t, "1", cart["userId"])
+		assert.Equal(t, userID, cart["userId"])
 		assert.NotNil(t, cart["items"])
 		items := cart["items"].([]any)
 		assert.Len(t, items, 0) // Initially empty
@@ -28,17 +33,10 @@ func TestCartsService_Get(t *testing.T) {
 		assert.NotEmpty(t, cart["updatedAt"])
 	})
 
-	t.Run("should create empty cart for new user", func(t *testing.T) {
-		rr := makeAuthenticatedRequest(t, server, "GET", "/carts/users/999", nil, token)
-		assertStatus(t, rr, http.StatusOK)
-
-		var cart map[string]any
-		err := decodeJSON(rr, &cart)
-		require.NoError(t, err)
-
-		assert.Equal(t, "999", cart["userId"])
-		items := cart["items"].([]any)
-		assert.Len(t, items, 0)
+	t.Run("should return 403 for another user's cart", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "GET", "/carts/users/1", nil, token)
+		assertStatus(t, rr, http.StatusForbidden)
+		assertErrorResponse(t, rr, "FORBIDDEN")
 	})
 
 	t.Run("should return 401 without authentication", func(t *testing.T) {
@@ -48,8 +46,52 @@ func TestCartsService_Get(t *testing.T) {
 	})
 }
 
+func TestCartsService_ConditionalGet(t *testing.T) {
+	server, userID, token := setupTestServerWithAuth(t)
+	path := "/carts/users/" + userID
+	auth := "Bearer " + token
+
+	rr := makeRequestWithHeaders(t, server, "POST", path+"/items", map[string]any{
+		"productId": "1",
+		"quantity":  1,
+	}, map[string]string{"Authorization": auth})
+	assertStatus(t, rr, http.StatusOK)
+
+	rr = makeAuthenticatedRequest(t, server, "GET", path, nil, token)
+	assertStatus(t, rr, http.StatusOK)
+	etag := rr.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rr = makeRequestWithHeaders(t, server, "GET", path, nil, map[string]string{"Authorization": auth, "If-None-Match": etag})
+	assertStatus(t, rr, http.StatusNotModified)
+	assert.Empty(t, rr.Body.String())
+
+	waitForNextSecond()
+	rr = makeAuthenticatedRequest(t, server, "GET", path, nil, token)
+	lastModified := rr.Header().Get("Last-Modified")
+	require.NotEmpty(t, lastModified)
+
+	rr = makeRequestWithHeaders(t, server, "GET", path, nil, map[string]string{"Authorization": auth, "If-Modified-Since": lastModified})
+	assertStatus(t, rr, http.StatusNotModified)
+
+	// The total is priced from the product, which changes without the cart
+	rr = makeAuthenticatedRequest(t, server, "PATCH", "/products/1", map[string]any{"price": 1.5}, token)
+	assertStatus(t, rr, http.StatusOK)
+	waitForNextSecond()
+
+	rr = makeRequestWithHeaders(t, server, "GET", path, nil, map[string]string{"Authorization": auth, "If-Modified-Since": lastModified})
+	assertStatus(t, rr, http.StatusOK)
+
+	rr = makeRequestWithHeaders(t, server, "PATCH", path+"/items/1", map[string]any{"quantity": 2}, map[string]string{"Authorization": auth})
+	assertStatus(t, rr, http.StatusOK)
+
+	rr = makeRequestWithHeaders(t, server, "GET", path, nil, map[string]string{"Authorization": auth, "If-None-Match": etag})
+	assertStatus(t, rr, http.StatusOK)
+	assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+}
+
 func TestCartsService_AddItem(t *testing.T) {
-	server, _, token := setupTestServerWithAuth(t)
+	server, userID, token := setupTestServerWithAuth(t)
 
 	t.Run("should add item to cart", func(t *testing.T) {
 		addItem := map[string]any{
@@ -57,7 +99,7 @@ func TestCartsService_AddItem(t *testing.T) {
 			"quantity":  2,
 		}
 
-		rr := makeAuthenticatedRequest(t, server, "POST", "/carts/users/1/items", addItem, token)
+		rr := makeAuthenticatedRequest(t, server, "POST", "/carts/users/"+userID+"/items", addItem, token)
 		assertStatus(t, rr, http.StatusOK)
 
 		var cart map[string]any
@@ -78,10 +120,11 @@ func TestCartsService_AddItem(t *testing.T) {
 			"productId": "1",
 			"quantity":  2,
 		}
-		makeAuthenticatedRequest(t, server, "POST", "/carts/users/2/items", addItem, token)
+		otherID, otherToken := createLoggedInUser(t, server, "repeat@example.com", "Repeat Buyer")
+		makeAuthenticatedRequest(t, server, "POST", "/carts/users/"+otherID+"/items", addItem, otherToken)
 
 		// Add same item again
-		rr := makeAuthenticatedRequest(t, server, "POST", "/carts/users/2/items", addItem, token)
+		rr := makeAuthenticatedRequest(t, server, "POST", "/carts/users/"+otherID+"/items", addItem, otherToken)
 		assertStatus(t, rr, http.StatusOK)
 
 		var cart map[string]any
@@ -95,13 +138,42 @@ func TestCartsService_AddItem(t *testing.T) {
 		assert.Equal(t, float64(4), item["quantity"]) // 2 + 2
 	})
 
+	t.Run("should keep every item added concurrently", func(t *testing.T) {
+		buyerID, buyerToken := createLoggedInUser(t, server, "concurrent-cart@example.com", "Concurrent Cart")
+		const products = 10
+		productIDs := make([]string, products)
+		for i := range productIDs {
+			productIDs[i] = createTestProduct(t, server, fmt.Sprintf("Concurrent Item %d", i), 5.00, 10)
+		}
+
+		var wg sync.WaitGroup
+		for _, productID := range productIDs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rr := makeAuthenticatedRequest(t, server, "POST", "/carts/users/"+buyerID+"/items", map[string]any{
+					"productId": productID,
+					"quantity":  1,
+				}, buyerToken)
+				assert.Equal(t, http.StatusOK, rr.Code)
+			}()
+		}
+		wg.Wait()
+
+		rr := makeAuthenticatedRequest(t, server, "GET", "/carts/users/"+buyerID, nil, buyerToken)
+		assertStatus(t, rr, http.StatusOK)
+		var cart map[string]any
+		require.NoError(t, decodeJSON(rr, &cart))
+		assert.Len(t, cart["items"], products)
+	})
+
 	t.Run("should return 404 for non-existent product", func(t *testing.T) {
 		addItem := map[string]any{
 			"productId": "999",
 			"quantity":  1,
 		}
 
-		rr := makeAuthenticatedRequest(t, server, "POST", "/carts/users/1/items", addItem, token)
+		rr := makeAuthenticatedRequest(t, server, "POST", "/carts/users/"+userID+"/items", addItem, token)
 		assertStatus(t, rr, http.StatusNotFound)
 		assertErrorResponse(t, rr, "NOT_FOUND")
 	})
@@ -112,11 +184,22 @@ func TestCartsService_AddItem(t *testing.T) {
 			"quantity":  100, // More than available stock (10)
 		}
 
-		rr := makeAuthenticatedRequest(t, server, "POST", "/carts/users/3/items", addItem, token)
+		rr := makeAuthenticatedRequest(t, server, "POST", "/carts/users/"+userID+"/items", addItem, token)
 		assertStatus(t, rr, http.StatusBadRequest)
 		assertErrorResponse(t, rr, "INSUFFICIENT_STOCK")
 	})
 
+	t.Run("should return 403 for another user's cart", func(t *testing.T) {
+		addItem := map[string]any{
+			"productId": "1",
+			"quantity":  1,
+		}
+
+		rr := makeAuthenticatedRequest(t, server, "POST", "/carts/users/1/items", addItem, token)
+		assertStatus(t, rr, http.StatusForbidden)
+		assertErrorResponse(t, rr, "FORBIDDEN")
+	})
+
 	t.Run("should return 401 without authentication", func(t *testing.T) {
 		addItem := map[string]any{
 			"productId": "1",
@@ -130,25 +213,27 @@ func TestCartsService_AddItem(t *testing.T) {
 }
 
 func TestCartsService_UpdateItem(t *testing.T) {
-	server, _, token := setupTestServerWithAuth(t)
+	server, aliceID, token := setupTestServerWithAuth(t)
 
-	// Setup: Add item to cart first
-	setupCart := func(userID string) {
+	// Setup: Add item to a new user's cart first
+	setupCart := func(email string) (string, string) {
+		userID, token := createLoggedInUser(t, server, email, "Cart User")
 		addItem := map[string]any{
 			"productId": "1",
 			"quantity":  2,
 		}
 		makeAuthenticatedRequest(t, server, "POST", "/carts/users/"+userID+"/items", addItem, token)
+		return userID, token
 	}
 
 	t.Run("should update item quantity", func(t *testing.T) {
-		setupCart("4")
+		userID, token := setupCart("update-item@example.com")
 
 		update := map[string]any{
 			"quantity": 5,
 		}
 
-		rr := makeAuthenticatedRequest(t, server, "PATCH", "/carts/users/4/items/1", update, token)
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/carts/users/"+userID+"/items/1", update, token)
 		assertStatus(t, rr, http.StatusOK)
 
 		var cart map[string]any
@@ -165,23 +250,35 @@ func TestCartsService_UpdateItem(t *testing.T) {
 			"quantity": 5,
 		}
 
-		rr := makeAuthenticatedRequest(t, server, "PATCH", "/carts/users/5/items/999", update, token)
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/carts/users/"+aliceID+"/items/999", update, token)
 		assertStatus(t, rr, http.StatusNotFound)
 		assertErrorResponse(t, rr, "NOT_FOUND")
 	})
 
 	t.Run("should return 400 for insufficient stock", func(t *testing.T) {
-		setupCart("6")
+		userID, token := setupCart("update-stock@example.com")
 
 		update := map[string]any{
 			"quantity": 100,
 		}
 
-		rr := makeAuthenticatedRequest(t, server, "PATCH", "/carts/users/6/items/1", update, token)
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/carts/users/"+userID+"/items/1", update, token)
 		assertStatus(t, rr, http.StatusBadRequest)
 		assertErrorResponse(t, rr, "INSUFFICIENT_STOCK")
 	})
 
+	t.Run("should return 403 for another user's cart", func(t *testing.T) {
+		userID, _ := setupCart("update-other@example.com")
+
+		update := map[string]any{
+			"quantity": 1,
+		}
+
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/carts/users/"+userID+"/items/1", update, token)
+		assertStatus(t, rr, http.StatusForbidden)
+		assertErrorResponse(t, rr, "FORBIDDEN")
+	})
+
 	t.Run("should return 401 without authentication", func(t *testing.T) {
 		update := map[string]any{
 			"quantity": 5,
@@ -194,10 +291,11 @@ func TestCartsService_UpdateItem(t *testing.T) {
 }
 
 func TestCartsService_RemoveItem(t *testing.T) {
-	server, _, token := setupTestServerWithAuth(t)
+	server, aliceID, token := setupTestServerWithAuth(t)
 
-	// Setup: Add items to cart
-	setupCart := func(userID string) {
+	// Setup: Add items to a new user's cart
+	setupCart := func(email string) (string, string) {
+		userID, token := createLoggedInUser(t, server, email, "Cart User")
 		// Add two different products
 		makeAuthenticatedRequest(t, server, "POST", "/carts/users/"+userID+"/items", map[string]any{
 			"productId": "1",
@@ -207,16 +305,17 @@ func TestCartsService_RemoveItem(t *testing.T) {
 			"productId": "2",
 			"quantity":  1,
 		}, token)
+		return userID, token
 	}
 
 	t.Run("should remove item from cart", func(t *testing.T) {
-		setupCart("7")
+		userID, token := setupCart("remove-item@example.com")
 
-		rr := makeAuthenticatedRequest(t, server, "DELETE", "/carts/users/7/items/1", nil, token)
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/carts/users/"+userID+"/items/1", nil, token)
 		assertStatus(t, rr, http.StatusNoContent)
 
 		// Verify item was removed
-		cartRR := makeAuthenticatedRequest(t, server, "GET", "/carts/users/7", nil, token)
+		cartRR := makeAuthenticatedRequest(t, server, "GET", "/carts/users/"+userID, nil, token)
 		var cart map[string]any
 		err := decodeJSON(cartRR, &cart)
 		require.NoError(t, err)
@@ -228,10 +327,18 @@ func TestCartsService_RemoveItem(t *testing.T) {
 	})
 
 	t.Run("should return 404 for item not in cart", func(t *testing.T) {
-		rr := makeAuthenticatedRequest(t, server, "DELETE", "/carts/users/8/items/999", nil, token)
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/carts/users/"+aliceID+"/items/999", nil, token)
 		assertStatus(t, rr, http.StatusNotFound)
 	})
 
+	t.Run("should return 403 for another user's cart", func(t *testing.T) {
+		userID, _ := setupCart("remove-other@example.com")
+
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/carts/users/"+userID+"/items/1", nil, token)
+		assertStatus(t, rr, http.StatusForbidden)
+		assertErrorResponse(t, rr, "FORBIDDEN")
+	})
+
 	t.Run("should return 401 without authentication", func(t *testing.T) {
 		rr := makeRequest(t, server, "DELETE", "/carts/users/1/items/1", nil)
 		assertStatus(t, rr, http.StatusUnauthorized)
@@ -242,8 +349,9 @@ func TestCartsService_RemoveItem(t *testing.T) {
 func TestCartsService_Clear(t *testing.T) {
 	server, _, token := setupTestServerWithAuth(t)
 
-	// Setup: Add items to cart
-	setupCart := func(userID string) {
+	// Setup: Add items to a new user's cart
+	setupCart := func(email string) (string, string) {
+		userID, token := createLoggedInUser(t, server, email, "Cart User")
 		makeAuthenticatedRequest(t, server, "POST", "/carts/users/"+userID+"/items", map[string]any{
 			"productId": "1",
 			"quantity":  2,
@@ -252,16 +360,17 @@ func TestCartsService_Clear(t *testing.T) {
 			"productId": "2",
 			"quantity":  1,
 		}, token)
+		return userID, token
 	}
 
 	t.Run("should clear all items from cart", func(t *testing.T) {
-		setupCart("9")
+		userID, token := setupCart("clear@example.com")
 
-		rr := makeAuthenticatedRequest(t, server, "DELETE", "/carts/users/9/items", nil, token)
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/carts/users/"+userID+"/items", nil, token)
 		assertStatus(t, rr, http.StatusNoContent)
 
 		// Verify cart is empty
-		cartRR := makeAuthenticatedRequest(t, server, "GET", "/carts/users/9", nil, token)
+		cartRR := makeAuthenticatedRequest(t, server, "GET", "/carts/users/"+userID, nil, token)
 		var cart map[string]any
 		err := decodeJSON(cartRR, &cart)
 		require.NoError(t, err)
@@ -270,6 +379,20 @@ func TestCartsService_Clear(t *testing.T) {
 		assert.Len(t, items, 0)
 	})
 
+	t.Run("should return 403 for another user's cart", func(t *testing.T) {
+		userID, otherToken := setupCart("clear-other@example.com")
+
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/carts/users/"+userID+"/items", nil, token)
+		assertStatus(t, rr, http.StatusForbidden)
+		assertErrorResponse(t, rr, "FORBIDDEN")
+
+		// The cart is left untouched
+		cartRR := makeAuthenticatedRequest(t, server, "GET", "/carts/users/"+userID, nil, otherToken)
+		var cart map[string]any
+		require.NoError(t, decodeJSON(cartRR, &cart))
+		assert.Len(t, cart["items"].([]any), 2)
+	})
+
 	t.Run("should return 401 without authentication", func(t *testing.T) {
 		rr := makeRequest(t, server, "DELETE", "/carts/users/1/items", nil)
 		assertStatus(t, rr, http.StatusUnauthorized)
@@ -278,11 +401,9 @@ func TestCartsService_Clear(t *testing.T) {
 }
 
 func TestCartsService_Integration(t *testing.T) {
-	server, _, token := setupTestServerWithAuth(t)
+	server, userID, token := setupTestServerWithAuth(t)
 
 	t.Run("should handle complete cart workflow", func(t *testing.T) {
-		userID := "100"
-
 		// 1. Start with empty cart
 		getEmptyCart := makeAuthenticatedRequest(t, server, "GET", "/carts/users/"+userID, nil, token)
 		assertStatus(t, getEmptyCart, http.StatusOK)
@@ -336,3 +457,135 @@ func TestCartsService_Integration(t *testing.T) {
 		assert.Len(t, clearedItems, 0)
 	})
 }
+
+func TestCartsService_Reservations(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+	reservations := inventory.NewReservations(10*time.Minute, clock)
+	server := setupTestServer(t, handlers.WithReservations(reservations))
+
+	getStockLevels := func(t *testing.T, productID string) (reserved, available float64) {
+		t.Helper()
+		rr := makeRequest(t, server, "GET", "/products/"+productID, nil)
+		assertStatus(t, rr, http.StatusOK)
+		var product map[string]any
+		require.NoError(t, decodeJSON(rr, &product))
+		return product["reservedStock"].(float64), product["availableStock"].(float64)
+	}
+
+	t.Run("should reserve stock when adding items", func(t *testing.T) {
+		productID := createTestProduct(t, server, "Reserved Product", 10.00, 5)
+		holder, holderToken := createLoggedInUser(t, server, "holder@example.com", "Holder")
+		other, otherToken := createLoggedInUser(t, server, "other-holder@example.com", "Other Holder")
+
+		addToCartAuth(t, server, holder, productID, 3, holderToken)
+
+		reserved, available := getStockLevels(t, productID)
+		assert.Equal(t, float64(3), reserved)
+		assert.Equal(t, float64(2), available)
+
+		// Another cart cannot take more than what is left
+		rr := makeAuthenticatedRequest(t, server, "POST", "/carts/users/"+other+"/items", map[string]any{
+			"productId": productID,
+			"quantity":  3,
+		}, otherToken)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertErrorResponse(t, rr, "INSUFFICIENT_STOCK")
+
+		addToCartAuth(t, server, other, productID, 2, otherToken)
+		_, available = getStockLevels(t, productID)
+		assert.Equal(t, float64(0), available)
+	})
+
+	t.Run("should release stock when removing or clearing items", func(t *testing.T) {
+		productID := createTestProduct(t, server, "Released Product", 10.00, 5)
+		remover, removerToken := createLoggedInUser(t, server, "remover@example.com", "Remover")
+		clearer, clearerToken := createLoggedInUser(t, server, "clearer@example.com", "Clearer")
+
+		addToCartAuth(t, server, remover, productID, 2, removerToken)
+		addToCartAuth(t, server, clearer, productID, 1, clearerToken)
+
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/carts/users/"+remover+"/items/"+productID, nil, removerToken)
+		assertStatus(t, rr, http.StatusNoContent)
+		reserved, _ := getStockLevels(t, productID)
+		assert.Equal(t, float64(1), reserved)
+
+		rr = makeAuthenticatedRequest(t, server, "DELETE", "/carts/users/"+clearer+"/items", nil, clearerToken)
+		assertStatus(t, rr, http.StatusNoContent)
+		reserved, available := getStockLevels(t, productID)
+		assert.Equal(t, float64(0), reserved)
+		assert.Equal(t, float64(5), available)
+	})
+
+	t.Run("should only let orders take stock not reserved by others", func(t *testing.T) {
+		productID := createTestProduct(t, server, "Ordered Product", 10.00, 5)
+		holder, holderToken := createLoggedInUser(t, server, "order-holder@example.com", "Order Holder")
+		buyer, token := createLoggedInUser(t, server, "reserving-buyer@example.com", "Reserving Buyer")
+
+		addToCartAuth(t, server, holder, productID, 3, holderToken)
+		addToCartAuth(t, server, buyer, productID, 2, token)
+
+		orderRequest := map[string]any{
+			"items": []any{
+				map[string]any{"productId": productID, "quantity": 3},
+			},
+			"shippingAddress": map[string]any{
+				"street":     "123 Test",
+				"city":       "Test",
+				"state":      "TS",
+				"postalCode": "12345",
+				"country":    "USA",
+			},
+		}
+		rr := makeAuthenticatedRequest(t, server, "POST", "/orders/users/"+buyer, orderRequest, token)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertErrorResponse(t, rr, "INSUFFICIENT_STOCK")
+
+		createOrderAuth(t, server, buyer, token)
+
+		// The buyer's reservation is released once the order is placed
+		reserved, available := getStockLevels(t, productID)
+		assert.Equal(t, float64(3), reserved)
+		assert.Equal(t, float64(0), available)
+	})
+
+	t.Run("should release stock when reservations expire", func(t *testing.T) {
+		productID := createTestProduct(t, server, "Expiring Product", 10.00, 5)
+		holder, holderToken := createLoggedInUser(t, server, "expiring-holder@example.com", "Expiring Holder")
+		buyer, buyerToken := createLoggedInUser(t, server, "late-buyer@example.com", "Late Buyer")
+
+		addToCartAuth(t, server, holder, productID, 5, holderToken)
+		_, available := getStockLevels(t, productID)
+		assert.Equal(t, float64(0), available)
+
+		now = now.Add(11 * time.Minute)
+
+		reserved, available := getStockLevels(t, productID)
+		assert.Equal(t, float64(0), reserved)
+		assert.Equal(t, float64(5), available)
+		addToCartAuth(t, server, buyer, productID, 5, buyerToken)
+	})
+
+	t.Run("should share reservations with other servers of the store", func(t *testing.T) {
+		productID := createTestProduct(t, server, "Shared Product", 10.00, 5)
+		holder, holderToken := createLoggedInUser(t, server, "shared-holder@example.com", "Shared Holder")
+		addToCartAuth(t, server, holder, productID, 4, holderToken)
+
+		// Another server of the store, e.g. after a restart
+		restarted := serveTestServer(t, server.store, server.authStorage,
+			handlers.WithReservations(inventory.NewReservations(10*time.Minute, clock)))
+		rr := makeRequest(t, restarted, "GET", "/products/"+productID, nil)
+		assertStatus(t, rr, http.StatusOK)
+		var product map[string]any
+		require.NoError(t, decodeJSON(rr, &product))
+		assert.Equal(t, float64(4), product["reservedStock"])
+
+		buyer, buyerToken := createLoggedInUser(t, restarted, "shared-buyer@example.com", "Shared Buyer")
+		rr = makeAuthenticatedRequest(t, restarted, "POST", "/carts/users/"+buyer+"/items", map[string]any{
+			"productId": productID,
+			"quantity":  2,
+		}, buyerToken)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertErrorResponse(t, rr, "INSUFFICIENT_STOCK")
+	})
+}
@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/blck-snwmn/hello-typespec/go/internal/validation"
 )
 
 // CategoryWithChildren represents a category with its child categories
@@ -17,7 +21,11 @@ type CategoryWithChildren struct {
 
 // CategoriesServiceList implements GET /categories
 func (s *Server) CategoriesServiceList(w http.ResponseWriter, r *http.Request) {
-	categories := s.store.GetCategories()
+	categories, err := s.store.GetCategories(r.Context())
+	if err != nil {
+		storeErrorResponse(w, err, "Category")
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(categories)
@@ -25,7 +33,16 @@ func (s *Server) CategoriesServiceList(w http.ResponseWriter, r *http.Request) {
 
 // CategoriesServiceTree implements GET /categories/tree
 func (s *Server) CategoriesServiceTree(w http.ResponseWriter, r *http.Request) {
-	allCategories := s.store.GetCategories()
+	lastModified, err := s.modifiedAt(r.Context(), store.TableCategories)
+	if err != nil {
+		storeErrorResponse(w, err, "Category")
+		return
+	}
+	allCategories, err := s.store.GetCategories(r.Context())
+	if err != nil {
+		storeErrorResponse(w, err, "Category")
+		return
+	}
 
 	// Build tree structure
 	categoryMap := make(map[string]*CategoryWithChildren)
@@ -39,8 +56,10 @@ func (s *Server) CategoriesServiceTree(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Second pass: build tree and collect roots
-	for _, catWithChildren := range categoryMap {
+	// Second pass: build tree and collect roots, in store order so the
+	// response and its ETag are stable
+	for _, cat := range allCategories {
+		catWithChildren := categoryMap[cat.Id]
 		if catWithChildren.ParentId == nil {
 			rootCategories = append(rootCategories, catWithChildren)
 		} else {
@@ -51,18 +70,18 @@ func (s *Server) CategoriesServiceTree(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(rootCategories)
+	writeConditionalJSON(w, r, rootCategories, lastModified)
 }
 
 // CategoriesServiceGet implements GET /categories/{categoryId}
 func (s *Server) CategoriesServiceGet(w http.ResponseWriter, r *http.Request, categoryId generated.Uuid) {
-	category, ok := s.store.GetCategory(categoryId)
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Category not found")
+	category, err := s.store.GetCategory(r.Context(), categoryId)
+	if err != nil {
+		storeErrorResponse(w, err, "Category")
 		return
 	}
 
+	setVersionETag(w, category.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(category)
 }
@@ -74,12 +93,17 @@ func (s *Server) CategoriesServiceCreate(w http.ResponseWriter, r *http.Request)
 		errorResponse(w, http.StatusBadRequest, ErrorCodeBadRequest, "Invalid request body")
 		return
 	}
+	var errs validation.Errors
+	errs.NotBlank("/name", req.Name)
+	if len(errs) > 0 {
+		validationErrorResponse(w, errs)
+		return
+	}
 
 	// Validate parent category exists if provided
 	if req.ParentId != nil {
-		_, exists := s.store.GetCategory(*req.ParentId)
-		if !exists {
-			errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Parent category not found")
+		if _, err := s.store.GetCategory(r.Context(), *req.ParentId); err != nil {
+			storeErrorResponse(w, err, "Parent category")
 			return
 		}
 	}
@@ -92,10 +116,16 @@ func (s *Server) CategoriesServiceCreate(w http.ResponseWriter, r *http.Request)
 		ParentId:  req.ParentId,
 		CreatedAt: now,
 		UpdatedAt: now,
+		Version:   initialVersion(),
 	}
 
-	created := s.store.CreateCategory(newCategory)
+	created, err := s.store.CreateCategory(r.Context(), newCategory)
+	if err != nil {
+		storeErrorResponse(w, err, "Category")
+		return
+	}
 
+	setVersionETag(w, created.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(created)
@@ -103,39 +133,103 @@ func (s *Server) CategoriesServiceCreate(w http.ResponseWriter, r *http.Request)
 
 // CategoriesServiceUpdate implements PATCH /categories/{categoryId}
 func (s *Server) CategoriesServiceUpdate(w http.ResponseWriter, r *http.Request, categoryId generated.Uuid) {
-	existing, ok := s.store.GetCategory(categoryId)
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Category not found")
-		return
-	}
-
 	var req generated.UpdateCategoryRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		errorResponse(w, http.StatusBadRequest, ErrorCodeBadRequest, "Invalid request body")
 		return
 	}
-
-	// Update fields if provided
-	updatedCategory := *existing
+	var errs validation.Errors
 	if req.Name != nil {
-		updatedCategory.Name = *req.Name
+		errs.NotBlank("/name", *req.Name)
 	}
-	if req.ParentId != nil {
-		updatedCategory.ParentId = req.ParentId
+	if req.ParentId != nil && *req.ParentId == categoryId {
+		errs.Add("/parentId", validation.RuleReference, "a category cannot be its own parent")
+	}
+	if len(errs) > 0 {
+		validationErrorResponse(w, errs)
+		return
 	}
-	updatedCategory.UpdatedAt = time.Now()
 
-	updated := s.store.UpdateCategory(categoryId, updatedCategory)
+	// Check the version and write in one transaction so concurrent edits cannot clobber each other
+	var updated generated.Category
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		existing, err := tx.GetCategory(r.Context(), categoryId)
+		if err != nil {
+			return err
+		}
+		if err := s.checkIfMatch(r, existing.Version); err != nil {
+			return err
+		}
+		if req.ParentId != nil {
+			if err := checkParent(r.Context(), tx, categoryId, *req.ParentId); err != nil {
+				return err
+			}
+		}
+
+		// Update fields if provided
+		updatedCategory := existing
+		if req.Name != nil {
+			updatedCategory.Name = *req.Name
+		}
+		if req.ParentId != nil {
+			updatedCategory.ParentId = req.ParentId
+		}
+		updatedCategory.UpdatedAt = time.Now()
+		updatedCategory.Version = nextVersion(existing.Version)
+
+		updated, err = tx.UpdateCategory(r.Context(), categoryId, updatedCategory)
+		return err
+	})
+	if err != nil {
+		txErrorResponse(w, err, "Category")
+		return
+	}
 
+	setVersionETag(w, updated.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updated)
 }
 
+// checkParent reports whether the category with id can be moved below the
+// category with parentID: the parent has to exist and must not be below the
+// category. The parents are read with tx, which locks them on Postgres, so
+// concurrent moves that would close a cycle wait for each other.
+func checkParent(ctx context.Context, tx store.Tx, id, parentID string) error {
+	seen := make(map[string]bool)
+	for ancestorID := &parentID; ancestorID != nil && !seen[*ancestorID]; {
+		seen[*ancestorID] = true
+		ancestor, err := tx.GetCategory(ctx, *ancestorID)
+		if errors.Is(err, store.ErrNotFound) && *ancestorID == parentID {
+			return &apiError{http.StatusNotFound, ErrorCodeNotFound, "Parent category not found"}
+		}
+		if err != nil {
+			return err
+		}
+		if ancestor.Id == id {
+			var errs validation.Errors
+			errs.Add("/parentId", validation.RuleReference, "a category cannot be moved below its own subcategory")
+			return errs
+		}
+		ancestorID = ancestor.ParentId
+	}
+	return nil
+}
+
 // CategoriesServiceDelete implements DELETE /categories/{categoryId}
 func (s *Server) CategoriesServiceDelete(w http.ResponseWriter, r *http.Request, categoryId generated.Uuid) {
-	_, ok := s.store.DeleteCategory(categoryId)
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Category not found")
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		existing, err := tx.GetCategory(r.Context(), categoryId)
+		if err != nil {
+			return err
+		}
+		if err := s.checkIfMatch(r, existing.Version); err != nil {
+			return err
+		}
+		_, err = tx.DeleteCategory(r.Context(), categoryId)
+		return err
+	})
+	if err != nil {
+		txErrorResponse(w, err, "Category")
 		return
 	}
 
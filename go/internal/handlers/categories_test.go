@@ -63,6 +63,41 @@ func TestCategoriesService_GetTree(t *testing.T) {
 	})
 }
 
+func TestCategoriesService_TreeConditionalGet(t *testing.T) {
+	server, _, token := setupTestServerWithAuth(t)
+
+	rr := makeRequest(t, server, "GET", "/categories/tree", nil)
+	assertStatus(t, rr, http.StatusOK)
+	etag := rr.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rr = makeRequestWithHeaders(t, server, "GET", "/categories/tree", nil, map[string]string{"If-None-Match": etag})
+	assertStatus(t, rr, http.StatusNotModified)
+	assert.Empty(t, rr.Body.String())
+
+	categoryID := createTestCategory(t, server, "New Category", nil)
+
+	rr = makeRequestWithHeaders(t, server, "GET", "/categories/tree", nil, map[string]string{"If-None-Match": etag})
+	assertStatus(t, rr, http.StatusOK)
+	assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+
+	waitForNextSecond()
+	rr = makeRequest(t, server, "GET", "/categories/tree", nil)
+	lastModified := rr.Header().Get("Last-Modified")
+	require.NotEmpty(t, lastModified)
+
+	rr = makeRequestWithHeaders(t, server, "GET", "/categories/tree", nil, map[string]string{"If-Modified-Since": lastModified})
+	assertStatus(t, rr, http.StatusNotModified)
+
+	// Deleting a category leaves no newer updatedAt behind
+	rr = makeAuthenticatedRequest(t, server, "DELETE", "/categories/"+categoryID, nil, token)
+	assertStatus(t, rr, http.StatusNoContent)
+	waitForNextSecond()
+
+	rr = makeRequestWithHeaders(t, server, "GET", "/categories/tree", nil, map[string]string{"If-Modified-Since": lastModified})
+	assertStatus(t, rr, http.StatusOK)
+}
+
 func TestCategoriesService_Get(t *testing.T) {
 	server := setupTestServer(t)
 
@@ -87,15 +122,14 @@ func TestCategoriesService_Get(t *testing.T) {
 }
 
 func TestCategoriesService_Create(t *testing.T) {
-	server := setupTestServer(t)
+	server, _, token := setupTestServerWithAuth(t)
 
 	t.Run("should create a new root category", func(t *testing.T) {
 		newCategory := map[string]any{
-			"name":     "New Root Category",
-			"parentId": nil,
+			"name": "New Root Category",
 		}
 
-		rr := makeRequest(t, server, "POST", "/categories", newCategory)
+		rr := makeAuthenticatedRequest(t, server, "POST", "/categories", newCategory, token)
 		assertStatus(t, rr, http.StatusCreated)
 
 		var category map[string]any
@@ -119,7 +153,7 @@ func TestCategoriesService_Create(t *testing.T) {
 			"parentId": parentID,
 		}
 
-		rr := makeRequest(t, server, "POST", "/categories", newCategory)
+		rr := makeAuthenticatedRequest(t, server, "POST", "/categories", newCategory, token)
 		assertStatus(t, rr, http.StatusCreated)
 
 		var category map[string]any
@@ -137,26 +171,35 @@ func TestCategoriesService_Create(t *testing.T) {
 			"parentId": "999999",
 		}
 
-		rr := makeRequest(t, server, "POST", "/categories", newCategory)
+		rr := makeAuthenticatedRequest(t, server, "POST", "/categories", newCategory, token)
 		assertStatus(t, rr, http.StatusNotFound)
 		assertErrorResponse(t, rr, "NOT_FOUND")
 	})
 
-	// TODO: Add validation tests when implemented
-	// t.Run("should return 400 for empty name", func(t *testing.T) {
-	// 	newCategory := map[string]any{
-	// 		"name":     "",
-	// 		"parentId": nil,
-	// 	}
+	t.Run("should return 400 for empty name", func(t *testing.T) {
+		newCategory := map[string]any{
+			"name": "",
+		}
 
-	// 	rr := makeRequest(t, server, "POST", "/categories", newCategory)
-	// 	assertStatus(t, rr, http.StatusBadRequest)
-	// 	assertErrorResponse(t, rr, "VALIDATION_ERROR")
-	// })
+		rr := makeAuthenticatedRequest(t, server, "POST", "/categories", newCategory, token)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertValidationErrors(t, rr, "/name")
+	})
+
+	t.Run("should return 400 for null parent", func(t *testing.T) {
+		newCategory := map[string]any{
+			"name":     "Null Parent",
+			"parentId": nil,
+		}
+
+		rr := makeAuthenticatedRequest(t, server, "POST", "/categories", newCategory, token)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertValidationErrors(t, rr, "/parentId")
+	})
 }
 
 func TestCategoriesService_Update(t *testing.T) {
-	server := setupTestServer(t)
+	server, _, token := setupTestServerWithAuth(t)
 
 	t.Run("should update category name", func(t *testing.T) {
 		// Create a category to update
@@ -166,7 +209,7 @@ func TestCategoriesService_Update(t *testing.T) {
 			"name": "Updated Name",
 		}
 
-		rr := makeRequest(t, server, "PATCH", "/categories/"+categoryID, update)
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/categories/"+categoryID, update, token)
 		assertStatus(t, rr, http.StatusOK)
 
 		var category map[string]any
@@ -186,7 +229,7 @@ func TestCategoriesService_Update(t *testing.T) {
 			"parentId": parentID,
 		}
 
-		rr := makeRequest(t, server, "PATCH", "/categories/"+childID, update)
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/categories/"+childID, update, token)
 		assertStatus(t, rr, http.StatusOK)
 
 		var category map[string]any
@@ -196,12 +239,52 @@ func TestCategoriesService_Update(t *testing.T) {
 		assert.Equal(t, parentID, category["parentId"])
 	})
 
+	t.Run("should return 400 when a category is made its own parent", func(t *testing.T) {
+		categoryID := createTestCategory(t, server, "Self Parent", nil)
+
+		update := map[string]any{
+			"parentId": categoryID,
+		}
+
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/categories/"+categoryID, update, token)
+		assertStatus(t, rr, http.StatusBadRequest)
+		details := assertValidationErrors(t, rr, "/parentId")
+		assert.Equal(t, "reference", details[0].Rule)
+	})
+
+	t.Run("should return 400 when a category is moved below its subcategory", func(t *testing.T) {
+		parentID := createTestCategory(t, server, "Outer", nil)
+		childID := createTestCategory(t, server, "Inner", &parentID)
+		grandchildID := createTestCategory(t, server, "Innermost", &childID)
+
+		update := map[string]any{
+			"parentId": grandchildID,
+		}
+
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/categories/"+parentID, update, token)
+		assertStatus(t, rr, http.StatusBadRequest)
+		details := assertValidationErrors(t, rr, "/parentId")
+		assert.Equal(t, "reference", details[0].Rule)
+	})
+
+	t.Run("should return 404 when the new parent does not exist", func(t *testing.T) {
+		categoryID := createTestCategory(t, server, "Stray", nil)
+
+		update := map[string]any{
+			"parentId": "999999",
+		}
+
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/categories/"+categoryID, update, token)
+		assertStatus(t, rr, http.StatusNotFound)
+		assertErrorResponse(t, rr, "NOT_FOUND")
+	})
+
 	t.Run("should return 404 when updating non-existent category", func(t *testing.T) {
 		update := map[string]any{
 			"name": "Ghost Category",
 		}
 
-		rr := makeRequest(t, server, "PATCH", "/categories/999", update)
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/categories/999", update, token)
 		assertStatus(t, rr, http.StatusNotFound)
 		assertErrorResponse(t, rr, "NOT_FOUND")
 	})
@@ -223,15 +306,44 @@ func TestCategoriesService_Update(t *testing.T) {
 	// })
 }
 
+func TestCategoriesService_OptimisticConcurrency(t *testing.T) {
+	server, _, token := setupTestServerWithAuth(t)
+	auth := "Bearer " + token
+
+	t.Run("should reject updates based on a stale version", func(t *testing.T) {
+		categoryID := createTestCategory(t, server, "Versioned Category", nil)
+
+		getRR := makeRequest(t, server, "GET", "/categories/"+categoryID, nil)
+		assertStatus(t, getRR, http.StatusOK)
+		etag := getRR.Header().Get("ETag")
+		assert.Equal(t, `"1"`, etag)
+
+		rr := makeRequestWithHeaders(t, server, "PATCH", "/categories/"+categoryID, map[string]any{
+			"name": "First Edit",
+		}, map[string]string{"If-Match": etag, "Authorization": auth})
+		assertStatus(t, rr, http.StatusOK)
+		assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+
+		rr = makeRequestWithHeaders(t, server, "PATCH", "/categories/"+categoryID, map[string]any{
+			"name": "Second Edit",
+		}, map[string]string{"If-Match": etag, "Authorization": auth})
+		assertStatus(t, rr, http.StatusPreconditionFailed)
+		assertErrorResponse(t, rr, "CONFLICT")
+
+		rr = makeRequestWithHeaders(t, server, "DELETE", "/categories/"+categoryID, nil, map[string]string{"If-Match": etag, "Authorization": auth})
+		assertStatus(t, rr, http.StatusPreconditionFailed)
+	})
+}
+
 func TestCategoriesService_Delete(t *testing.T) {
-	server := setupTestServer(t)
+	server, _, token := setupTestServerWithAuth(t)
 
 	t.Run("should delete a category", func(t *testing.T) {
 		// Create a category to delete
 		categoryID := createTestCategory(t, server, "Delete Me", nil)
 
 		// Delete the category
-		rr := makeRequest(t, server, "DELETE", "/categories/"+categoryID, nil)
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/categories/"+categoryID, nil, token)
 		assertStatus(t, rr, http.StatusNoContent)
 
 		// Verify category is deleted
@@ -240,7 +352,7 @@ func TestCategoriesService_Delete(t *testing.T) {
 	})
 
 	t.Run("should return 404 when deleting non-existent category", func(t *testing.T) {
-		rr := makeRequest(t, server, "DELETE", "/categories/999", nil)
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/categories/999", nil, token)
 		assertStatus(t, rr, http.StatusNotFound)
 		assertErrorResponse(t, rr, "NOT_FOUND")
 	})
@@ -259,7 +371,7 @@ func TestCategoriesService_Delete(t *testing.T) {
 }
 
 func TestCategoriesService_Integration(t *testing.T) {
-	server := setupTestServer(t)
+	server, _, token := setupTestServerWithAuth(t)
 
 	t.Run("should handle complete category hierarchy", func(t *testing.T) {
 		// Create a category hierarchy
@@ -311,9 +423,9 @@ func TestCategoriesService_Integration(t *testing.T) {
 		assert.True(t, foundChild1, "Child1 should be found in the tree")
 
 		// Clean up: Delete in reverse order (grandchild first)
-		makeRequest(t, server, "DELETE", "/categories/"+grandchildID, nil)
-		makeRequest(t, server, "DELETE", "/categories/"+child1ID, nil)
-		makeRequest(t, server, "DELETE", "/categories/"+child2ID, nil)
-		makeRequest(t, server, "DELETE", "/categories/"+rootID, nil)
+		makeAuthenticatedRequest(t, server, "DELETE", "/categories/"+grandchildID, nil, token)
+		makeAuthenticatedRequest(t, server, "DELETE", "/categories/"+child1ID, nil, token)
+		makeAuthenticatedRequest(t, server, "DELETE", "/categories/"+child2ID, nil, token)
+		makeAuthenticatedRequest(t, server, "DELETE", "/categories/"+rootID, nil, token)
 	})
 }
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/ancestry"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+)
+
+// priceRangeBounds splits products into the price ranges of the price facet:
+// under 50, 50 to 100, 100 to 500, 500 to 1000 and 1000 or more
+var priceRangeBounds = []float32{50, 100, 500, 1000}
+
+// productFacets counts the facets named in facets over every product that
+// query matches. The store counts them without reading the products.
+func (s *Server) productFacets(ctx context.Context, query store.ProductQuery, facets []string) (*generated.ProductFacets, error) {
+	counts, err := s.store.CountProducts(ctx, query, priceRangeBounds)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &generated.ProductFacets{}
+	for _, facet := range facets {
+		switch generated.ProductsServiceListParamsFacets(facet) {
+		case generated.ProductsServiceListParamsFacetsCategory:
+			categories, err := s.categoryFacets(ctx, counts.Categories)
+			if err != nil {
+				return nil, err
+			}
+			result.Categories = &categories
+		case generated.ProductsServiceListParamsFacetsPrice:
+			priceRanges := priceRangeFacets(counts.PriceRanges)
+			result.PriceRanges = &priceRanges
+		case generated.ProductsServiceListParamsFacetsStock:
+			stock, err := s.stockFacet(ctx, query, counts)
+			if err != nil {
+				return nil, err
+			}
+			result.Stock = &stock
+		}
+	}
+	return result, nil
+}
+
+// categoryFacets turns the product counts of categories into facets. A
+// product counts for its own category and every ancestor of it, so that a
+// parent category counts the products of its subcategories.
+func (s *Server) categoryFacets(ctx context.Context, categoryCounts map[string]int) ([]generated.CategoryFacet, error) {
+	index, err := ancestry.Load(ctx, s.store)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int32)
+	for categoryID, count := range categoryCounts {
+		counts[categoryID] += int32(count)
+		for _, id := range index.Ancestors(categoryID) {
+			counts[id] += int32(count)
+		}
+	}
+
+	facets := []generated.CategoryFacet{}
+	for _, category := range index.Categories() {
+		if count := counts[category.Id]; count > 0 {
+			facets = append(facets, generated.CategoryFacet{
+				CategoryId: category.Id,
+				Name:       category.Name,
+				Count:      count,
+			})
+		}
+	}
+	return facets, nil
+}
+
+// priceRangeFacets turns the product counts of the ranges priceRangeBounds
+// splits prices into into facets, including empty ranges
+func priceRangeFacets(rangeCounts []int) []generated.PriceRangeFacet {
+	facets := make([]generated.PriceRangeFacet, len(priceRangeBounds)+1)
+	for i := range facets {
+		if i > 0 {
+			lower := priceRangeBounds[i-1]
+			facets[i].Min = &lower
+		}
+		if i < len(priceRangeBounds) {
+			upper := priceRangeBounds[i]
+			facets[i].Max = &upper
+		}
+		facets[i].Count = int32(rangeCounts[i])
+	}
+	return facets
+}
+
+// stockFacet counts the products query matches that can and cannot be added
+// to a cart. The store counts the products in stock; of those, the ones
+// whose stock is all held by carts are out of stock.
+func (s *Server) stockFacet(ctx context.Context, query store.ProductQuery, counts store.ProductCounts) (generated.StockFacet, error) {
+	facet := generated.StockFacet{InStock: int32(counts.InStock), OutOfStock: int32(counts.Total - counts.InStock)}
+
+	reserved, err := s.reservations.ReservedProducts(ctx, s.store)
+	if err != nil {
+		return generated.StockFacet{}, err
+	}
+	if len(reserved) == 0 {
+		return facet, nil
+	}
+	ids := slices.Collect(maps.Keys(reserved))
+	if query.IDs != nil {
+		ids = slices.DeleteFunc(ids, func(id string) bool { return !slices.Contains(query.IDs, id) })
+	}
+	query.IDs = ids
+	query.Page = store.Page{Limit: store.NoLimit}
+	held, err := s.store.QueryProducts(ctx, query)
+	if err != nil {
+		return generated.StockFacet{}, err
+	}
+	for _, product := range held.Items {
+		if product.Stock > 0 && product.Stock-reserved[product.Id] <= 0 {
+			facet.InStock--
+			facet.OutOfStock++
+		}
+	}
+	return facet, nil
+}
@@ -1,10 +1,15 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"log"
 	"net/http"
 
 	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/middleware"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/blck-snwmn/hello-typespec/go/internal/validation"
 )
 
 // Common error codes matching TypeSpec definition
@@ -17,23 +22,63 @@ const (
 	ErrorCodeValidationError        = generated.VALIDATIONERROR
 	ErrorCodeInsufficientStock      = generated.INSUFFICIENTSTOCK
 	ErrorCodeInvalidStateTransition = generated.INVALIDSTATETRANSITION
+	ErrorCodeTooManyRequests        = generated.TOOMANYREQUESTS
 	ErrorCodeInternalError          = generated.INTERNALERROR
 	ErrorCodeServiceUnavailable     = generated.SERVICEUNAVAILABLE
 )
 
-// errorResponse sends a standardized error response
+// errorResponse sends a standardized error response, as problem details to
+// clients that asked for them
 func errorResponse(w http.ResponseWriter, statusCode int, code generated.ErrorCode, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	response := generated.ErrorResponse{
-		Error: struct {
-			Code    generated.ErrorCode `json:"code"`
-			Details interface{}         `json:"details,omitempty"`
-			Message string              `json:"message"`
-		}{
-			Code:    code,
-			Message: message,
-		},
+	middleware.WriteError(w, statusCode, code, message, nil)
+}
+
+// validationErrorResponse sends 400 VALIDATION_ERROR listing the fields that
+// failed validation in the error's details
+func validationErrorResponse(w http.ResponseWriter, errs validation.Errors) {
+	middleware.WriteError(w, http.StatusBadRequest, ErrorCodeValidationError, "Request validation failed", errs)
+}
+
+// storeErrorResponse maps an error returned by the store to an error response.
+// resource names the entity in not-found and conflict messages, e.g. "Product".
+func storeErrorResponse(w http.ResponseWriter, err error, resource string) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, resource+" not found")
+	case errors.Is(err, store.ErrConflict):
+		errorResponse(w, http.StatusConflict, ErrorCodeConflict, resource+" already exists")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		errorResponse(w, http.StatusServiceUnavailable, ErrorCodeServiceUnavailable, "Request was cancelled")
+	default:
+		log.Printf("store error: %v", err)
+		errorResponse(w, http.StatusInternalServerError, ErrorCodeInternalError, "Internal server error")
+	}
+}
+
+// apiError aborts a store transaction with a specific error response
+type apiError struct {
+	statusCode int
+	code       generated.ErrorCode
+	message    string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+// txErrorResponse sends the response for an error returned by Store.WithTx
+// or paginate. apiErrors and validation.Errors raised inside the transaction
+// are sent as-is; any other error is mapped like storeErrorResponse.
+func txErrorResponse(w http.ResponseWriter, err error, resource string) {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		errorResponse(w, apiErr.statusCode, apiErr.code, apiErr.message)
+		return
+	}
+	var validationErrs validation.Errors
+	if errors.As(err, &validationErrs) {
+		validationErrorResponse(w, validationErrs)
+		return
 	}
-	json.NewEncoder(w).Encode(response)
+	storeErrorResponse(w, err, resource)
 }
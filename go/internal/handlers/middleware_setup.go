@@ -1,45 +1,488 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/blck-snwmn/hello-typespec/go/generated"
+	authctx "github.com/blck-snwmn/hello-typespec/go/internal/auth"
+	"github.com/blck-snwmn/hello-typespec/go/internal/middleware"
+	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
+	"github.com/getkin/kin-openapi/openapi3"
 )
 
-// ProtectedRoutes defines which routes require authentication
-var ProtectedRoutes = map[string]bool{
-	"/carts":       true,
-	"/orders":      true,
-	"/users":       true,
-	"/auth/me":     true,
-	"/auth/logout": true,
-}
-
-// CreateHandlerWithMiddleware creates an HTTP handler with authentication middleware applied to protected routes
-func CreateHandlerWithMiddleware(server generated.ServerInterface, authMiddleware func(http.Handler) http.Handler) http.Handler {
-	// Use the generated handler as the base
-	baseHandler := generated.Handler(server)
-
-	// Wrap the handler to apply middleware selectively
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Check if the route requires authentication
-		requiresAuth := false
-		for route := range ProtectedRoutes {
-			if r.URL.Path == route ||
-				(len(r.URL.Path) > len(route) && r.URL.Path[:len(route)+1] == route+"/") {
-				requiresAuth = true
-				break
-			}
-		}
-
-		if requiresAuth {
-			// Apply auth middleware
-			authMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				baseHandler.ServeHTTP(w, r)
-			})).ServeHTTP(w, r)
-		} else {
-			// No auth required
-			baseHandler.ServeHTTP(w, r)
+// Access is who may call an operation
+type Access int
+
+const (
+	// AccessAdmin requires an authenticated user with the admin role
+	AccessAdmin Access = iota
+	// AccessUser requires an authenticated user; handlers check ownership
+	AccessUser
+	// AccessPublic requires no authentication
+	AccessPublic
+)
+
+// OperationPolicy is how an operation is protected
+type OperationPolicy struct {
+	Access Access
+	// Logins allows Bearer access tokens, and APIKeys allows API keys with
+	// Scope. Public operations allow neither.
+	Logins  bool
+	APIKeys bool
+	Scope   generated.ApiKeyScope
+}
+
+// AdminOperations need the admin role on top of the authentication the
+// OpenAPI document requires, keyed by generated.ServerInterface method name
+var AdminOperations = map[string]bool{
+	"CategoriesServiceCreate":   true,
+	"CategoriesServiceUpdate":   true,
+	"CategoriesServiceDelete":   true,
+	"OrdersServiceList":         true,
+	"OrdersServiceUpdateStatus": true,
+	"ProductsServiceCreate":     true,
+	"ProductsServiceUpdate":     true,
+	"ProductsServiceDelete":     true,
+	"UsersServiceList":          true,
+	"UsersServiceCreate":        true,
+}
+
+// OperationScopes defines the scope an API key needs for each operation the
+// OpenAPI document allows API keys for
+var OperationScopes = map[string]generated.ApiKeyScope{
+	"CartsServiceGetByUser":  generated.CartsRead,
+	"CartsServiceClear":      generated.CartsWrite,
+	"CartsServiceAddItem":    generated.CartsWrite,
+	"CartsServiceRemoveItem": generated.CartsWrite,
+	"CartsServiceUpdateItem": generated.CartsWrite,
+
+	"CategoriesServiceCreate": generated.CategoriesWrite,
+	"CategoriesServiceUpdate": generated.CategoriesWrite,
+	"CategoriesServiceDelete": generated.CategoriesWrite,
+
+	"OrdersServiceListByUser":   generated.OrdersRead,
+	"OrdersServiceGet":          generated.OrdersRead,
+	"OrdersServiceList":         generated.OrdersRead,
+	"OrdersServiceCreate":       generated.OrdersWrite,
+	"OrdersServiceCancel":       generated.OrdersWrite,
+	"OrdersServiceUpdateStatus": generated.OrdersWrite,
+
+	"ProductsServiceCreate": generated.ProductsWrite,
+	"ProductsServiceUpdate": generated.ProductsWrite,
+	"ProductsServiceDelete": generated.ProductsWrite,
+
+	"UsersServiceGet":    generated.UsersRead,
+	"UsersServiceList":   generated.UsersRead,
+	"UsersServiceUpdate": generated.UsersWrite,
+	"UsersServiceDelete": generated.UsersWrite,
+	"UsersServiceCreate": generated.UsersWrite,
+}
+
+// LoadOperationPolicies derives the policy of every operation from the
+// security requirements of spec: operations without any are public, a
+// bearer scheme allows logins and an API key scheme allows API keys.
+// AdminOperations and OperationScopes add roles and scopes on top. It fails
+// if an operation of generated.ServerInterface has no policy, uses a scheme
+// that is not supported, or disagrees with AdminOperations or OperationScopes.
+func LoadOperationPolicies(spec *openapi3.T) (map[string]OperationPolicy, error) {
+	policies := make(map[string]OperationPolicy)
+	for path, item := range spec.Paths.Map() {
+		for method, op := range item.Operations() {
+			if op.OperationID == "" {
+				return nil, fmt.Errorf("%s %s has no operationId", method, path)
+			}
+			name := operationName(op.OperationID)
+			security := spec.Security
+			if op.Security != nil {
+				security = *op.Security
+			}
+			policy, err := operationPolicy(spec, name, security)
+			if err != nil {
+				return nil, fmt.Errorf("%s (%s %s): %w", name, method, path, err)
+			}
+			policies[name] = policy
+		}
+	}
+
+	operations := reflect.TypeFor[generated.ServerInterface]()
+	for i := range operations.NumMethod() {
+		if name := operations.Method(i).Name; !hasPolicy(policies, name) {
+			return nil, fmt.Errorf("%s has no security policy in the OpenAPI document", name)
+		}
+	}
+	for name := range AdminOperations {
+		if !hasPolicy(policies, name) {
+			return nil, fmt.Errorf("admin role defined for unknown operation %s", name)
+		}
+	}
+	for name := range OperationScopes {
+		if !hasPolicy(policies, name) {
+			return nil, fmt.Errorf("API key scope defined for unknown operation %s", name)
 		}
+	}
+	return policies, nil
+}
+
+func hasPolicy(policies map[string]OperationPolicy, name string) bool {
+	_, ok := policies[name]
+	return ok
+}
+
+// operationPolicy resolves the policy of the operation name from its
+// security requirements
+func operationPolicy(spec *openapi3.T, name string, security openapi3.SecurityRequirements) (OperationPolicy, error) {
+	policy := OperationPolicy{Access: AccessPublic}
+	for _, requirement := range security {
+		if len(requirement) == 0 {
+			return OperationPolicy{}, fmt.Errorf("optional authentication is not supported")
+		}
+		for scheme := range requirement {
+			var definition *openapi3.SecurityScheme
+			if spec.Components != nil {
+				if ref := spec.Components.SecuritySchemes[scheme]; ref != nil {
+					definition = ref.Value
+				}
+			}
+			switch {
+			case definition == nil:
+				return OperationPolicy{}, fmt.Errorf("unknown security scheme %s", scheme)
+			case definition.Type == "http" && strings.EqualFold(definition.Scheme, "bearer"):
+				policy.Logins = true
+			case definition.Type == "apiKey" && definition.In == "header" && strings.EqualFold(definition.Name, middleware.APIKeyHeader):
+				policy.APIKeys = true
+			default:
+				return OperationPolicy{}, fmt.Errorf("security scheme %s is not supported", scheme)
+			}
+			policy.Access = AccessUser
+		}
+	}
+
+	if AdminOperations[name] {
+		if policy.Access == AccessPublic {
+			return OperationPolicy{}, fmt.Errorf("admin role required but the operation is public")
+		}
+		policy.Access = AccessAdmin
+	}
+	scope, scoped := OperationScopes[name]
+	switch {
+	case policy.APIKeys && !scoped:
+		return OperationPolicy{}, fmt.Errorf("API keys allowed but no scope defined")
+	case !policy.APIKeys && scoped:
+		return OperationPolicy{}, fmt.Errorf("API key scope defined but API keys are not allowed")
+	}
+	policy.Scope = scope
+	return policy, nil
+}
+
+// operationName returns the generated.ServerInterface method name of an
+// operation ID, such as AuthServiceLogin for AuthService_login
+func operationName(operationID string) string {
+	var name strings.Builder
+	for part := range strings.FieldsFuncSeq(operationID, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || r == ' '
+	}) {
+		name.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return name.String()
+}
+
+// CreateHandlerWithMiddleware creates an HTTP handler that applies the
+// authentication middleware and the policy of each operation, as derived by
+// LoadOperationPolicies from the embedded OpenAPI document, and then
+// validates requests against the document. Errors are sent as problem
+// details to clients that ask for them. It fails if any operation has no
+// policy.
+func CreateHandlerWithMiddleware(server generated.ServerInterface, authMiddleware func(http.Handler) http.Handler, validatorOpts ...middleware.ValidatorOption) (http.Handler, error) {
+	spec, err := generated.GetSwagger()
+	if err != nil {
+		return nil, fmt.Errorf("load OpenAPI document: %w", err)
+	}
+	policies, err := LoadOperationPolicies(spec)
+	if err != nil {
+		return nil, fmt.Errorf("derive operation policies: %w", err)
+	}
+	validator, err := middleware.NewValidator(spec, validatorOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("create request validator: %w", err)
+	}
+	return middleware.ProblemDetails(generated.Handler(&guardedServer{
+		next:         server,
+		authenticate: authMiddleware,
+		validate:     validator.Middleware,
+		policies:     policies,
+	})), nil
+}
+
+// guardedServer checks access to every operation and validates its request
+// before passing it on to next
+type guardedServer struct {
+	next         generated.ServerInterface
+	authenticate func(http.Handler) http.Handler
+	validate     func(http.Handler) http.Handler
+	policies     map[string]OperationPolicy
+}
+
+// guard calls handler if the request may perform operation and is valid
+func (g *guardedServer) guard(operation string, w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+	policy, ok := g.policies[operation]
+	if !ok {
+		// LoadOperationPolicies covers every operation, so this is a bug
+		errorResponse(w, http.StatusInternalServerError, ErrorCodeInternalError, "Internal server error")
+		return
+	}
+	validated := g.validate(handler)
+	if policy.Access == AccessPublic {
+		validated.ServeHTTP(w, r)
+		return
+	}
+
+	g.authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := authctx.GetUser(r.Context())
+		switch {
+		case ok && user.APIKeyID != "":
+			if !policy.APIKeys {
+				errorResponse(w, http.StatusForbidden, ErrorCodeForbidden, "API keys cannot be used for this operation")
+				return
+			}
+			if !user.HasScope(string(policy.Scope)) {
+				errorResponse(w, http.StatusForbidden, ErrorCodeForbidden, "API key lacks the "+string(policy.Scope)+" scope")
+				return
+			}
+		case !policy.Logins:
+			errorResponse(w, http.StatusForbidden, ErrorCodeForbidden, "An API key is required for this operation")
+			return
+		}
+		if policy.Access == AccessAdmin {
+			if !ok || !user.HasRole(storage.RoleAdmin) {
+				errorResponse(w, http.StatusForbidden, ErrorCodeForbidden, "Admin role required")
+				return
+			}
+		}
+		validated.ServeHTTP(w, r)
+	})).ServeHTTP(w, r)
+}
+
+func (g *guardedServer) AuthServiceChangePassword(w http.ResponseWriter, r *http.Request) {
+	g.guard("AuthServiceChangePassword", w, r, g.next.AuthServiceChangePassword)
+}
+
+func (g *guardedServer) AuthServiceLogin(w http.ResponseWriter, r *http.Request) {
+	g.guard("AuthServiceLogin", w, r, g.next.AuthServiceLogin)
+}
+
+func (g *guardedServer) AuthServiceLogout(w http.ResponseWriter, r *http.Request) {
+	g.guard("AuthServiceLogout", w, r, g.next.AuthServiceLogout)
+}
+
+func (g *guardedServer) AuthServiceGetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	g.guard("AuthServiceGetCurrentUser", w, r, g.next.AuthServiceGetCurrentUser)
+}
+
+func (g *guardedServer) AuthServiceRequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	g.guard("AuthServiceRequestPasswordReset", w, r, g.next.AuthServiceRequestPasswordReset)
+}
+
+func (g *guardedServer) AuthServiceConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	g.guard("AuthServiceConfirmPasswordReset", w, r, g.next.AuthServiceConfirmPasswordReset)
+}
+
+func (g *guardedServer) AuthServiceRegister(w http.ResponseWriter, r *http.Request) {
+	g.guard("AuthServiceRegister", w, r, g.next.AuthServiceRegister)
+}
+
+func (g *guardedServer) AuthServiceRefresh(w http.ResponseWriter, r *http.Request) {
+	g.guard("AuthServiceRefresh", w, r, g.next.AuthServiceRefresh)
+}
+
+func (g *guardedServer) AuthServiceListSessions(w http.ResponseWriter, r *http.Request) {
+	g.guard("AuthServiceListSessions", w, r, g.next.AuthServiceListSessions)
+}
+
+func (g *guardedServer) AuthServiceRevokeSession(w http.ResponseWriter, r *http.Request, sessionId string) {
+	g.guard("AuthServiceRevokeSession", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.AuthServiceRevokeSession(w, r, sessionId)
+	})
+}
+
+func (g *guardedServer) AuthServiceCreateApiKey(w http.ResponseWriter, r *http.Request) {
+	g.guard("AuthServiceCreateApiKey", w, r, g.next.AuthServiceCreateApiKey)
+}
+
+func (g *guardedServer) AuthServiceListApiKeys(w http.ResponseWriter, r *http.Request) {
+	g.guard("AuthServiceListApiKeys", w, r, g.next.AuthServiceListApiKeys)
+}
+
+func (g *guardedServer) AuthServiceRevokeApiKey(w http.ResponseWriter, r *http.Request, keyId string) {
+	g.guard("AuthServiceRevokeApiKey", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.AuthServiceRevokeApiKey(w, r, keyId)
+	})
+}
+
+func (g *guardedServer) AuthServiceOidcLogin(w http.ResponseWriter, r *http.Request) {
+	g.guard("AuthServiceOidcLogin", w, r, g.next.AuthServiceOidcLogin)
+}
+
+func (g *guardedServer) AuthServiceOidcLink(w http.ResponseWriter, r *http.Request) {
+	g.guard("AuthServiceOidcLink", w, r, g.next.AuthServiceOidcLink)
+}
+
+func (g *guardedServer) AuthServiceOidcCallback(w http.ResponseWriter, r *http.Request, params generated.AuthServiceOidcCallbackParams) {
+	g.guard("AuthServiceOidcCallback", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.AuthServiceOidcCallback(w, r, params)
+	})
+}
+
+func (g *guardedServer) CartsServiceGetByUser(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
+	g.guard("CartsServiceGetByUser", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.CartsServiceGetByUser(w, r, userId)
+	})
+}
+
+func (g *guardedServer) CartsServiceClear(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
+	g.guard("CartsServiceClear", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.CartsServiceClear(w, r, userId)
+	})
+}
+
+func (g *guardedServer) CartsServiceAddItem(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
+	g.guard("CartsServiceAddItem", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.CartsServiceAddItem(w, r, userId)
+	})
+}
+
+func (g *guardedServer) CartsServiceRemoveItem(w http.ResponseWriter, r *http.Request, userId generated.Uuid, productId generated.Uuid) {
+	g.guard("CartsServiceRemoveItem", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.CartsServiceRemoveItem(w, r, userId, productId)
+	})
+}
+
+func (g *guardedServer) CartsServiceUpdateItem(w http.ResponseWriter, r *http.Request, userId generated.Uuid, productId generated.Uuid) {
+	g.guard("CartsServiceUpdateItem", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.CartsServiceUpdateItem(w, r, userId, productId)
+	})
+}
+
+func (g *guardedServer) CategoriesServiceList(w http.ResponseWriter, r *http.Request) {
+	g.guard("CategoriesServiceList", w, r, g.next.CategoriesServiceList)
+}
+
+func (g *guardedServer) CategoriesServiceCreate(w http.ResponseWriter, r *http.Request) {
+	g.guard("CategoriesServiceCreate", w, r, g.next.CategoriesServiceCreate)
+}
+
+func (g *guardedServer) CategoriesServiceTree(w http.ResponseWriter, r *http.Request) {
+	g.guard("CategoriesServiceTree", w, r, g.next.CategoriesServiceTree)
+}
+
+func (g *guardedServer) CategoriesServiceDelete(w http.ResponseWriter, r *http.Request, categoryId generated.Uuid) {
+	g.guard("CategoriesServiceDelete", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.CategoriesServiceDelete(w, r, categoryId)
+	})
+}
+
+func (g *guardedServer) CategoriesServiceGet(w http.ResponseWriter, r *http.Request, categoryId generated.Uuid) {
+	g.guard("CategoriesServiceGet", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.CategoriesServiceGet(w, r, categoryId)
+	})
+}
+
+func (g *guardedServer) CategoriesServiceUpdate(w http.ResponseWriter, r *http.Request, categoryId generated.Uuid) {
+	g.guard("CategoriesServiceUpdate", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.CategoriesServiceUpdate(w, r, categoryId)
+	})
+}
+
+func (g *guardedServer) OrdersServiceList(w http.ResponseWriter, r *http.Request, params generated.OrdersServiceListParams) {
+	g.guard("OrdersServiceList", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.OrdersServiceList(w, r, params)
+	})
+}
+
+func (g *guardedServer) OrdersServiceCancel(w http.ResponseWriter, r *http.Request, orderId generated.Uuid) {
+	g.guard("OrdersServiceCancel", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.OrdersServiceCancel(w, r, orderId)
+	})
+}
+
+func (g *guardedServer) OrdersServiceUpdateStatus(w http.ResponseWriter, r *http.Request, orderId generated.Uuid) {
+	g.guard("OrdersServiceUpdateStatus", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.OrdersServiceUpdateStatus(w, r, orderId)
+	})
+}
+
+func (g *guardedServer) OrdersServiceListByUser(w http.ResponseWriter, r *http.Request, userId generated.Uuid, params generated.OrdersServiceListByUserParams) {
+	g.guard("OrdersServiceListByUser", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.OrdersServiceListByUser(w, r, userId, params)
+	})
+}
+
+func (g *guardedServer) OrdersServiceCreate(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
+	g.guard("OrdersServiceCreate", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.OrdersServiceCreate(w, r, userId)
+	})
+}
+
+func (g *guardedServer) OrdersServiceGet(w http.ResponseWriter, r *http.Request, orderId generated.Uuid) {
+	g.guard("OrdersServiceGet", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.OrdersServiceGet(w, r, orderId)
+	})
+}
+
+func (g *guardedServer) ProductsServiceList(w http.ResponseWriter, r *http.Request, params generated.ProductsServiceListParams) {
+	g.guard("ProductsServiceList", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.ProductsServiceList(w, r, params)
+	})
+}
+
+func (g *guardedServer) ProductsServiceCreate(w http.ResponseWriter, r *http.Request) {
+	g.guard("ProductsServiceCreate", w, r, g.next.ProductsServiceCreate)
+}
+
+func (g *guardedServer) ProductsServiceDelete(w http.ResponseWriter, r *http.Request, productId generated.Uuid) {
+	g.guard("ProductsServiceDelete", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.ProductsServiceDelete(w, r, productId)
+	})
+}
+
+func (g *guardedServer) ProductsServiceGet(w http.ResponseWriter, r *http.Request, productId generated.Uuid) {
+	g.guard("ProductsServiceGet", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.ProductsServiceGet(w, r, productId)
+	})
+}
+
+func (g *guardedServer) ProductsServiceUpdate(w http.ResponseWriter, r *http.Request, productId generated.Uuid) {
+	g.guard("ProductsServiceUpdate", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.ProductsServiceUpdate(w, r, productId)
+	})
+}
+
+func (g *guardedServer) UsersServiceList(w http.ResponseWriter, r *http.Request, params generated.UsersServiceListParams) {
+	g.guard("UsersServiceList", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.UsersServiceList(w, r, params)
+	})
+}
+
+func (g *guardedServer) UsersServiceCreate(w http.ResponseWriter, r *http.Request) {
+	g.guard("UsersServiceCreate", w, r, g.next.UsersServiceCreate)
+}
+
+func (g *guardedServer) UsersServiceDelete(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
+	g.guard("UsersServiceDelete", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.UsersServiceDelete(w, r, userId)
+	})
+}
+
+func (g *guardedServer) UsersServiceGet(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
+	g.guard("UsersServiceGet", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.UsersServiceGet(w, r, userId)
+	})
+}
+
+func (g *guardedServer) UsersServiceUpdate(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
+	g.guard("UsersServiceUpdate", w, r, func(w http.ResponseWriter, r *http.Request) {
+		g.next.UsersServiceUpdate(w, r, userId)
 	})
 }
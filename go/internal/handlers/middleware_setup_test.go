@@ -0,0 +1,134 @@
+package handlers_test
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/handlers"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadSpec returns a fresh copy of the embedded OpenAPI document
+func loadSpec(t testing.TB) *openapi3.T {
+	t.Helper()
+	spec, err := generated.GetSwagger()
+	require.NoError(t, err)
+	return spec
+}
+
+func TestLoadOperationPolicies(t *testing.T) {
+	t.Run("should derive a policy for every operation from the spec", func(t *testing.T) {
+		policies, err := handlers.LoadOperationPolicies(loadSpec(t))
+		require.NoError(t, err)
+		assert.Len(t, policies, reflect.TypeFor[generated.ServerInterface]().NumMethod())
+
+		assert.Equal(t, handlers.OperationPolicy{Access: handlers.AccessPublic}, policies["ProductsServiceList"])
+		assert.Equal(t, handlers.OperationPolicy{Access: handlers.AccessPublic}, policies["AuthServiceLogin"])
+		assert.Equal(t, handlers.OperationPolicy{Access: handlers.AccessUser, Logins: true}, policies["AuthServiceListSessions"])
+		assert.Equal(t, handlers.OperationPolicy{Access: handlers.AccessUser, Logins: true, APIKeys: true, Scope: generated.OrdersWrite}, policies["OrdersServiceCreate"])
+		assert.Equal(t, handlers.OperationPolicy{Access: handlers.AccessAdmin, Logins: true, APIKeys: true, Scope: generated.ProductsWrite}, policies["ProductsServiceUpdate"])
+	})
+
+	t.Run("should define valid API key scopes outside /auth", func(t *testing.T) {
+		for name, scope := range handlers.OperationScopes {
+			assert.False(t, strings.HasPrefix(name, "AuthService"), "%s should need a login", name)
+			assert.True(t, scope.Valid(), "unknown scope %q for %s", scope, name)
+		}
+	})
+
+	t.Run("should fail for operations missing from the spec", func(t *testing.T) {
+		spec := loadSpec(t)
+		spec.Paths.Delete("/auth/oidc/login")
+
+		_, err := handlers.LoadOperationPolicies(spec)
+		assert.ErrorContains(t, err, "AuthServiceOidcLogin")
+	})
+
+	t.Run("should fail for unsupported security schemes", func(t *testing.T) {
+		spec := loadSpec(t)
+		spec.Paths.Find("/orders").Get.Security = &openapi3.SecurityRequirements{{"OAuth2": {}}}
+
+		_, err := handlers.LoadOperationPolicies(spec)
+		assert.ErrorContains(t, err, "OrdersServiceList")
+		assert.ErrorContains(t, err, "OAuth2")
+	})
+
+	t.Run("should fail when the spec disagrees with the role and scope tables", func(t *testing.T) {
+		spec := loadSpec(t)
+		spec.Paths.Find("/products/{productId}").Patch.Security = nil
+		_, err := handlers.LoadOperationPolicies(spec)
+		assert.ErrorContains(t, err, "ProductsServiceUpdate")
+
+		spec = loadSpec(t)
+		spec.Paths.Find("/products").Post.Security = &openapi3.SecurityRequirements{{"BearerAuth": {}}}
+		_, err = handlers.LoadOperationPolicies(spec)
+		assert.ErrorContains(t, err, "ProductsServiceCreate")
+	})
+}
+
+func TestAdminOnlyOperations(t *testing.T) {
+	server, _, adminToken := setupTestServerWithAuth(t)
+	userToken := loginTestUser(t, server, "bob@example.com", "password456")
+
+	operations := []struct {
+		method string
+		path   string
+		body   any
+	}{
+		{"POST", "/products", map[string]any{"name": "Admin Product", "description": "", "price": 1, "stock": 1, "categoryId": "1"}},
+		{"PATCH", "/products/1", map[string]any{"price": 2}},
+		{"DELETE", "/products/1", nil},
+		{"POST", "/categories", map[string]any{"name": "Admin Category"}},
+		{"PATCH", "/categories/1", map[string]any{"name": "Renamed"}},
+		{"DELETE", "/categories/1", nil},
+		{"GET", "/orders", nil},
+		{"PATCH", "/orders/status/1", map[string]any{"status": "processing"}},
+		{"GET", "/users", nil},
+		{"POST", "/users", map[string]any{"email": "admin-created@example.com", "name": "Admin Created"}},
+	}
+
+	for _, op := range operations {
+		t.Run(op.method+" "+op.path, func(t *testing.T) {
+			rr := makeRequest(t, server, op.method, op.path, op.body)
+			assertStatus(t, rr, http.StatusUnauthorized)
+			assertErrorResponse(t, rr, "UNAUTHORIZED")
+
+			rr = makeAuthenticatedRequest(t, server, op.method, op.path, op.body, userToken)
+			assertStatus(t, rr, http.StatusForbidden)
+			assertErrorResponse(t, rr, "FORBIDDEN")
+		})
+	}
+
+	t.Run("should let admins through", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "GET", "/users", nil, adminToken)
+		assertStatus(t, rr, http.StatusOK)
+
+		rr = makeAuthenticatedRequest(t, server, "PATCH", "/products/1", map[string]any{"price": 2}, adminToken)
+		assertStatus(t, rr, http.StatusOK)
+	})
+
+	t.Run("should not let admins act as other users", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "GET", "/carts/users/550e8400-e29b-41d4-a716-446655440002", nil, adminToken)
+		assertStatus(t, rr, http.StatusForbidden)
+	})
+
+	t.Run("should report roles in /auth/me", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "GET", "/auth/me", nil, adminToken)
+		assertStatus(t, rr, http.StatusOK)
+		var admin generated.AuthUser
+		require.NoError(t, decodeJSON(rr, &admin))
+		require.NotNil(t, admin.Roles)
+		assert.Equal(t, []generated.Role{generated.Admin}, *admin.Roles)
+
+		rr = makeAuthenticatedRequest(t, server, "GET", "/auth/me", nil, userToken)
+		assertStatus(t, rr, http.StatusOK)
+		var user generated.AuthUser
+		require.NoError(t, decodeJSON(rr, &user))
+		assert.Nil(t, user.Roles)
+	})
+}
@@ -1,49 +1,56 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"sort"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/blck-snwmn/hello-typespec/go/internal/validation"
 )
 
+// newestOrdersFirst is the order orders are listed in
+var newestOrdersFirst = struct {
+	sort  store.Sort
+	order listOrder[generated.Order, time.Time]
+}{
+	sort: store.Sort{Field: store.SortByCreatedAt, Desc: true},
+	order: listOrder[generated.Order, time.Time]{
+		name: orderName("orders", string(store.SortByCreatedAt), true),
+		key:  func(order generated.Order) time.Time { return order.CreatedAt },
+		id:   func(order generated.Order) string { return order.Id },
+	},
+}
+
+// orderListResponse is a page of orders
+type orderListResponse struct {
+	Items      []generated.Order `json:"items"`
+	Total      int32             `json:"total"`
+	Limit      int32             `json:"limit"`
+	Offset     int32             `json:"offset"`
+	NextCursor *string           `json:"nextCursor,omitempty"`
+	PrevCursor *string           `json:"prevCursor,omitempty"`
+}
+
 // OrdersServiceList implements GET /orders
 func (s *Server) OrdersServiceList(w http.ResponseWriter, r *http.Request, params generated.OrdersServiceListParams) {
-	var allOrders []generated.Order
-
-	if params.UserId != nil && *params.UserId != "" {
-		allOrders = s.store.GetOrdersByUserId(*params.UserId)
-	} else {
-		allOrders = s.store.GetOrders()
+	// Admin only, so every user's orders are listed
+	query := store.OrderQuery{
+		Status:      params.Status,
+		CreatedFrom: params.StartDate,
+		CreatedTo:   params.EndDate,
+		Sort:        newestOrdersFirst.sort,
 	}
-
-	// Apply filters
-	var filteredOrders []generated.Order
-	for _, order := range allOrders {
-		// Status filter
-		if params.Status != nil && order.Status != *params.Status {
-			continue
-		}
-
-		// Date filters
-		if params.StartDate != nil && order.CreatedAt.Before(*params.StartDate) {
-			continue
-		}
-		if params.EndDate != nil && order.CreatedAt.After(*params.EndDate) {
-			continue
-		}
-
-		filteredOrders = append(filteredOrders, order)
+	if params.UserId != nil {
+		query.UserId = *params.UserId
 	}
 
-	// Sort by createdAt descending
-	sort.Slice(filteredOrders, func(i, j int) bool {
-		return filteredOrders[i].CreatedAt.After(filteredOrders[j].CreatedAt)
-	})
-
 	// Apply pagination
 	limit := int32(20)
 	if params.Limit != nil {
@@ -55,44 +62,15 @@ func (s *Server) OrdersServiceList(w http.ResponseWriter, r *http.Request, param
 		offset = *params.Offset
 	}
 
-	total := int32(len(filteredOrders))
-	start := int(offset)
-	end := int(offset + limit)
-
-	if start > len(filteredOrders) {
-		start = len(filteredOrders)
-	}
-	if end > len(filteredOrders) {
-		end = len(filteredOrders)
-	}
-
-	paginatedOrders := filteredOrders[start:end]
-
-	// Create response
-	response := struct {
-		Items  []generated.Order `json:"items"`
-		Total  int32             `json:"total"`
-		Limit  int32             `json:"limit"`
-		Offset int32             `json:"offset"`
-	}{
-		Items:  paginatedOrders,
-		Total:  total,
-		Limit:  limit,
-		Offset: offset,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	s.listOrders(w, r, query, limit, offset, params.Cursor)
 }
 
 // OrdersServiceListByUser implements GET /orders/users/{userId}
 func (s *Server) OrdersServiceListByUser(w http.ResponseWriter, r *http.Request, userId generated.Uuid, params generated.OrdersServiceListByUserParams) {
-	allOrders := s.store.GetOrdersByUserId(userId)
-
-	// Sort by createdAt descending
-	sort.Slice(allOrders, func(i, j int) bool {
-		return allOrders[i].CreatedAt.After(allOrders[j].CreatedAt)
-	})
+	// Admins can list every order, so they can list any user's
+	if !requireOwnerOrAdmin(w, r, userId) {
+		return
+	}
 
 	// Apply pagination
 	limit := int32(20)
@@ -105,30 +83,28 @@ func (s *Server) OrdersServiceListByUser(w http.ResponseWriter, r *http.Request,
 		offset = *params.Offset
 	}
 
-	total := int32(len(allOrders))
-	start := int(offset)
-	end := int(offset + limit)
+	s.listOrders(w, r, store.OrderQuery{UserId: userId, Sort: newestOrdersFirst.sort}, limit, offset, params.Cursor)
+}
 
-	if start > len(allOrders) {
-		start = len(allOrders)
-	}
-	if end > len(allOrders) {
-		end = len(allOrders)
+// listOrders sends the page of the orders query matches
+func (s *Server) listOrders(w http.ResponseWriter, r *http.Request, query store.OrderQuery, limit, offset int32, cursorParam *string) {
+	page, err := paginate(s.cursors, newestOrdersFirst.order, limit, offset, cursorParam, func(page store.Page) (store.Result[generated.Order], error) {
+		query.Page = page
+		return s.store.QueryOrders(r.Context(), query)
+	})
+	if err != nil {
+		txErrorResponse(w, err, "Order")
+		return
 	}
 
-	paginatedOrders := allOrders[start:end]
-
 	// Create response
-	response := struct {
-		Items  []generated.Order `json:"items"`
-		Total  int32             `json:"total"`
-		Limit  int32             `json:"limit"`
-		Offset int32             `json:"offset"`
-	}{
-		Items:  paginatedOrders,
-		Total:  total,
-		Limit:  limit,
-		Offset: offset,
+	response := orderListResponse{
+		Items:      page.items,
+		Total:      page.total,
+		Limit:      limit,
+		Offset:     page.offset,
+		NextCursor: page.nextCursor,
+		PrevCursor: page.prevCursor,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -137,9 +113,12 @@ func (s *Server) OrdersServiceListByUser(w http.ResponseWriter, r *http.Request,
 
 // OrdersServiceGet implements GET /orders/{orderId}
 func (s *Server) OrdersServiceGet(w http.ResponseWriter, r *http.Request, orderId generated.Uuid) {
-	order, ok := s.store.GetOrder(orderId)
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Order not found")
+	order, err := s.store.GetOrder(r.Context(), orderId)
+	if err != nil {
+		storeErrorResponse(w, err, "Order")
+		return
+	}
+	if !requireOwnerOrAdmin(w, r, order.UserId) {
 		return
 	}
 
@@ -149,76 +128,105 @@ func (s *Server) OrdersServiceGet(w http.ResponseWriter, r *http.Request, orderI
 
 // OrdersServiceCreate implements POST /orders/users/{userId}
 func (s *Server) OrdersServiceCreate(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
+	if !requireOwner(w, r, userId) {
+		return
+	}
+
 	var req generated.CreateOrderRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		errorResponse(w, http.StatusBadRequest, ErrorCodeBadRequest, "Invalid request body")
 		return
 	}
 
-	// Validate user exists
-	_, ok := s.store.GetUser(userId)
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "User not found")
+	var errs validation.Errors
+	errs.MinItems("/items", len(req.Items), 1)
+	for i, item := range req.Items {
+		errs.Minimum(validation.Pointer("items", strconv.Itoa(i), "quantity"), float64(item.Quantity), 1)
+	}
+	if len(errs) > 0 {
+		validationErrorResponse(w, errs)
 		return
 	}
 
-	// Validate items from request
-	if len(req.Items) == 0 {
-		errorResponse(w, http.StatusBadRequest, ErrorCodeValidationError, "No items in order")
+	// Validate user exists
+	if _, err := s.store.GetUser(r.Context(), userId); err != nil {
+		storeErrorResponse(w, err, "User")
 		return
 	}
 
-	// Validate stock and calculate total
-	var totalAmount float32
-	orderItems := []generated.OrderItem{}
-
-	for _, item := range req.Items {
-		product, ok := s.store.GetProduct(item.ProductId)
-		if !ok {
-			errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, fmt.Sprintf("Product %s not found", item.ProductId))
-			return
+	// Reserve stock, create the order and clear the cart in one transaction so
+	// concurrent orders cannot oversell and a failure leaves nothing behind
+	var created generated.Order
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		// Validate stock and calculate total
+		var totalAmount float32
+		orderItems := []generated.OrderItem{}
+
+		for _, item := range req.Items {
+			product, err := tx.GetProduct(r.Context(), item.ProductId)
+			if errors.Is(err, store.ErrNotFound) {
+				return &apiError{http.StatusNotFound, ErrorCodeNotFound, fmt.Sprintf("Product %s not found", item.ProductId)}
+			}
+			if err != nil {
+				return err
+			}
+			// Units held in other users' carts cannot be ordered
+			reserved, err := s.reservations.ReservedByOthers(r.Context(), tx, product.Id, userId)
+			if err != nil {
+				return err
+			}
+			if product.Stock-reserved < item.Quantity {
+				return &apiError{http.StatusBadRequest, ErrorCodeInsufficientStock, fmt.Sprintf("Insufficient stock for product %s", product.Name)}
+			}
+
+			itemPrice := product.Price
+			totalAmount += itemPrice * float32(item.Quantity)
+			orderItems = append(orderItems, generated.OrderItem{
+				ProductId:   item.ProductId,
+				Quantity:    item.Quantity,
+				Price:       itemPrice,
+				ProductName: product.Name,
+			})
+
+			// Update product stock
+			product.Stock -= item.Quantity
+			product.UpdatedAt = time.Now()
+			product.Version = nextVersion(product.Version)
+			if _, err := tx.UpdateProduct(r.Context(), product.Id, product); err != nil {
+				return err
+			}
 		}
-		if product.Stock < item.Quantity {
-			errorResponse(w, http.StatusBadRequest, ErrorCodeInsufficientStock, fmt.Sprintf("Insufficient stock for product %s", product.Name))
-			return
+
+		// Create order
+		now := time.Now()
+		newOrder := generated.Order{
+			Id:              fmt.Sprintf("%d", now.UnixNano()),
+			UserId:          userId,
+			Items:           orderItems,
+			TotalAmount:     totalAmount,
+			Status:          generated.Pending,
+			ShippingAddress: req.ShippingAddress,
+			CreatedAt:       now,
+			UpdatedAt:       now,
 		}
 
-		itemPrice := product.Price
-		totalAmount += itemPrice * float32(item.Quantity)
-		orderItems = append(orderItems, generated.OrderItem{
-			ProductId:   item.ProductId,
-			Quantity:    item.Quantity,
-			Price:       itemPrice,
-			ProductName: product.Name,
-		})
-
-		// Update product stock
-		product.Stock -= item.Quantity
-		product.UpdatedAt = time.Now()
-		s.store.UpdateProduct(product.Id, *product)
-	}
+		var err error
+		created, err = tx.CreateOrder(r.Context(), newOrder)
+		if err != nil {
+			return err
+		}
 
-	// Create order
-	now := time.Now()
-	newOrder := generated.Order{
-		Id:              fmt.Sprintf("%d", now.UnixNano()),
-		UserId:          userId,
-		Items:           orderItems,
-		TotalAmount:     totalAmount,
-		Status:          generated.Pending,
-		ShippingAddress: req.ShippingAddress,
-		CreatedAt:       now,
-		UpdatedAt:       now,
+		// Clear the cart, whose reservations are no longer needed
+		if err := clearCart(r.Context(), tx, userId, now); err != nil {
+			return err
+		}
+		return s.reservations.ReleaseAll(r.Context(), tx, userId)
+	})
+	if err != nil {
+		txErrorResponse(w, err, "Order")
+		return
 	}
 
-	created := s.store.CreateOrder(newOrder)
-
-	// Clear cart
-	clearedCart := s.store.GetCartByUserId(userId)
-	clearedCart.Items = []generated.CartItem{}
-	clearedCart.UpdatedAt = now
-	s.store.UpdateCart(userId, clearedCart)
-
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(created)
@@ -231,77 +239,116 @@ func (s *Server) OrdersServiceUpdateStatus(w http.ResponseWriter, r *http.Reques
 		errorResponse(w, http.StatusBadRequest, ErrorCodeBadRequest, "Invalid request body")
 		return
 	}
-
-	order, ok := s.store.GetOrder(orderId)
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Order not found")
+	var errs validation.Errors
+	if !req.Status.Valid() {
+		errs.Add("/status", validation.RuleEnum, "unknown order status "+strconv.Quote(string(req.Status)))
+	}
+	if len(errs) > 0 {
+		validationErrorResponse(w, errs)
 		return
 	}
 
-	// Validate status transition
-	validTransitions := map[generated.OrderStatus][]generated.OrderStatus{
-		generated.Pending:    {generated.Processing, generated.Cancelled},
-		generated.Processing: {generated.Shipped, generated.Cancelled},
-		generated.Shipped:    {generated.Delivered},
-		generated.Delivered:  {},
-		generated.Cancelled:  {},
-	}
+	// Check the transition and write in one transaction so that it cannot
+	// race a cancellation
+	var updated generated.Order
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		order, err := tx.GetOrder(r.Context(), orderId)
+		if err != nil {
+			return err
+		}
 
-	validNextStatuses := validTransitions[order.Status]
-	isValidTransition := false
-	for _, validStatus := range validNextStatuses {
-		if validStatus == req.Status {
-			isValidTransition = true
-			break
+		// Validate status transition
+		if !slices.Contains(orderTransitions[order.Status], req.Status) {
+			return &apiError{http.StatusBadRequest, ErrorCodeInvalidStateTransition,
+				fmt.Sprintf("Cannot transition from %s to %s", order.Status, req.Status)}
 		}
-	}
+		if req.Status == generated.Cancelled {
+			if err := restoreStock(r.Context(), tx, order); err != nil {
+				return err
+			}
+		}
+
+		updatedOrder := order
+		updatedOrder.Status = req.Status
+		updatedOrder.UpdatedAt = time.Now()
 
-	if !isValidTransition {
-		errorResponse(w, http.StatusBadRequest, ErrorCodeInvalidStateTransition,
-			fmt.Sprintf("Cannot transition from %s to %s", order.Status, req.Status))
+		updated, err = tx.UpdateOrder(r.Context(), orderId, updatedOrder)
+		return err
+	})
+	if err != nil {
+		txErrorResponse(w, err, "Order")
 		return
 	}
 
-	updatedOrder := *order
-	updatedOrder.Status = req.Status
-	updatedOrder.UpdatedAt = time.Now()
-
-	updated := s.store.UpdateOrder(orderId, updatedOrder)
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updated)
 }
 
+// orderTransitions lists the statuses an order can move to from each status
+var orderTransitions = map[generated.OrderStatus][]generated.OrderStatus{
+	generated.Pending:    {generated.Processing, generated.Cancelled},
+	generated.Processing: {generated.Shipped, generated.Cancelled},
+	generated.Shipped:    {generated.Delivered},
+	generated.Delivered:  {},
+	generated.Cancelled:  {},
+}
+
+// restoreStock puts the items of a cancelled order back in stock. Products
+// deleted since the order was placed are skipped.
+func restoreStock(ctx context.Context, tx store.Tx, order generated.Order) error {
+	for _, item := range order.Items {
+		product, err := tx.GetProduct(ctx, item.ProductId)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		product.Stock += item.Quantity
+		product.UpdatedAt = time.Now()
+		product.Version = nextVersion(product.Version)
+		if _, err := tx.UpdateProduct(ctx, product.Id, product); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // OrdersServiceCancel implements POST /orders/{orderId}/cancel
 func (s *Server) OrdersServiceCancel(w http.ResponseWriter, r *http.Request, orderId generated.Uuid) {
-	order, ok := s.store.GetOrder(orderId)
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Order not found")
-		return
-	}
+	// Restore inventory and cancel the order in one transaction
+	var updated generated.Order
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		order, err := tx.GetOrder(r.Context(), orderId)
+		if err != nil {
+			return err
+		}
+		// Admins can cancel any order through its status too
+		if err := authorizeOwnerOrAdmin(r.Context(), order.UserId); err != nil {
+			return err
+		}
 
-	// Check if order can be cancelled (only pending and processing can be cancelled)
-	if order.Status != generated.Pending && order.Status != generated.Processing {
-		errorResponse(w, http.StatusBadRequest, ErrorCodeValidationError,
-			fmt.Sprintf("Cannot cancel order with status %s", order.Status))
-		return
-	}
+		if !slices.Contains(orderTransitions[order.Status], generated.Cancelled) {
+			return &apiError{http.StatusBadRequest, ErrorCodeInvalidStateTransition,
+				fmt.Sprintf("Cannot cancel order with status %s", order.Status)}
+		}
 
-	// Restore inventory
-	for _, item := range order.Items {
-		product, ok := s.store.GetProduct(item.ProductId)
-		if ok {
-			product.Stock += item.Quantity
-			product.UpdatedAt = time.Now()
-			s.store.UpdateProduct(product.Id, *product)
+		// Restore inventory
+		if err := restoreStock(r.Context(), tx, order); err != nil {
+			return err
 		}
-	}
 
-	updatedOrder := *order
-	updatedOrder.Status = generated.Cancelled
-	updatedOrder.UpdatedAt = time.Now()
+		updatedOrder := order
+		updatedOrder.Status = generated.Cancelled
+		updatedOrder.UpdatedAt = time.Now()
 
-	updated := s.store.UpdateOrder(orderId, updatedOrder)
+		updated, err = tx.UpdateOrder(r.Context(), orderId, updatedOrder)
+		return err
+	})
+	if err != nil {
+		txErrorResponse(w, err, "Order")
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updated)
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/cursor"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/blck-snwmn/hello-typespec/go/internal/validation"
+)
+
+// listOrder is the order of a listing of T: by a key of type K, then by ID
+type listOrder[T, K any] struct {
+	// name tells the order apart from others in cursors, e.g.
+	// "products:price:desc"
+	name string
+	key  func(T) K
+	id   func(T) string
+}
+
+// position returns where record is in the listing
+func (o listOrder[T, K]) position(record T) store.Position {
+	return store.Position{Key: o.key(record), ID: o.id(record)}
+}
+
+// orderName names the order of a listing of resource by field in cursors
+func orderName(resource, field string, desc bool) string {
+	if desc {
+		return resource + ":" + field + ":desc"
+	}
+	return resource + ":" + field + ":asc"
+}
+
+// listPage is a page of a listing
+type listPage[T any] struct {
+	items      []T
+	total      int32
+	offset     int32
+	nextCursor *string
+	prevCursor *string
+}
+
+// paginate returns the page of a listing in order o that starts after or ends
+// before the position in cursorParam, or the page at offset if there is no
+// cursor. query runs the listing for a store.Page. The page's offset is where
+// a cursor led to. Cursors that do not decode or were made for another order
+// are reported as validation.Errors.
+func paginate[T, K any](signer *cursor.Signer, o listOrder[T, K], limit, offset int32, cursorParam *string, query func(store.Page) (store.Result[T], error)) (listPage[T], error) {
+	page := store.Page{Limit: int(max(limit, 0)), Offset: int(max(offset, 0))}
+	if cursorParam != nil && *cursorParam != "" {
+		at, before, err := decodePosition(signer, o, *cursorParam)
+		if err != nil {
+			var errs validation.Errors
+			errs.Add("cursor", validation.RuleInvalid, "cursor is invalid or belongs to another sort order")
+			return listPage[T]{}, errs
+		}
+		page.Offset = 0
+		if before {
+			page.Before = &at
+		} else {
+			page.After = &at
+		}
+	}
+
+	result, err := query(page)
+	if err != nil {
+		return listPage[T]{}, err
+	}
+	listed := listPage[T]{
+		items:  result.Items,
+		total:  int32(result.Total),
+		offset: int32(result.Offset),
+	}
+	if listed.items == nil {
+		listed.items = []T{}
+	}
+	n := len(result.Items)
+	if n == 0 {
+		return listed, nil
+	}
+	if result.Offset+n < result.Total {
+		next, err := encodePosition(signer, o, result.Items[n-1], false)
+		if err != nil {
+			return listPage[T]{}, err
+		}
+		listed.nextCursor = &next
+	}
+	if result.Offset > 0 {
+		prev, err := encodePosition(signer, o, result.Items[0], true)
+		if err != nil {
+			return listPage[T]{}, err
+		}
+		listed.prevCursor = &prev
+	}
+	return listed, nil
+}
+
+// encodePosition returns a cursor to the position right after record, or
+// right before it if before is set
+func encodePosition[T, K any](signer *cursor.Signer, o listOrder[T, K], record T, before bool) (string, error) {
+	key, err := json.Marshal(o.key(record))
+	if err != nil {
+		return "", err
+	}
+	return signer.Encode(cursor.Cursor{Sort: o.name, Key: key, ID: o.id(record), Before: before})
+}
+
+// decodePosition returns the position in token and whether the page ends
+// before it
+func decodePosition[T, K any](signer *cursor.Signer, o listOrder[T, K], token string) (store.Position, bool, error) {
+	c, err := signer.Decode(token)
+	if err != nil {
+		return store.Position{}, false, err
+	}
+	if c.Sort != o.name {
+		return store.Position{}, false, cursor.ErrInvalid
+	}
+	var key K
+	if err := json.Unmarshal(c.Key, &key); err != nil {
+		return store.Position{}, false, cursor.ErrInvalid
+	}
+	return store.Position{Key: key, ID: c.ID}, c.Before, nil
+}
@@ -0,0 +1,181 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/handlers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductsService_Cursors(t *testing.T) {
+	server, _, _ := setupTestServerWithAuth(t)
+
+	// Products with the same price, to be told apart by ID
+	for _, name := range []string{"Cable A", "Cable B", "Cable C", "Cable D"} {
+		createTestProduct(t, server, name, 19.99, 10)
+	}
+
+	list := func(t *testing.T, query string) generated.ProductListResponse {
+		t.Helper()
+		rr := makeRequest(t, server, "GET", "/products?"+query, nil)
+		assertStatus(t, rr, http.StatusOK)
+		var response generated.ProductListResponse
+		require.NoError(t, decodeJSON(rr, &response))
+		return response
+	}
+	ids := func(products []generated.Product) []string {
+		var ids []string
+		for _, product := range products {
+			ids = append(ids, product.Id)
+		}
+		return ids
+	}
+
+	t.Run("should list products in the same order on every call", func(t *testing.T) {
+		first := ids(list(t, "").Items)
+		require.Len(t, first, 7)
+		for range 5 {
+			assert.Equal(t, first, ids(list(t, "").Items))
+		}
+	})
+
+	t.Run("should walk every page forward and back", func(t *testing.T) {
+		all := ids(list(t, "sortBy=price&order=desc").Items)
+		require.Len(t, all, 7)
+
+		var forward []string
+		var pages []generated.ProductListResponse
+		query := "sortBy=price&order=desc&limit=2"
+		for {
+			page := list(t, query)
+			pages = append(pages, page)
+			forward = append(forward, ids(page.Items)...)
+			if page.NextCursor == nil {
+				break
+			}
+			query = "sortBy=price&order=desc&limit=2&cursor=" + url.QueryEscape(*page.NextCursor)
+		}
+		assert.Equal(t, all, forward)
+		require.Len(t, pages, 4)
+		assert.Nil(t, pages[0].PrevCursor)
+		assert.Equal(t, int32(6), pages[3].Offset)
+
+		var backward []string
+		page := pages[3]
+		for page.PrevCursor != nil {
+			page = list(t, "sortBy=price&order=desc&limit=2&cursor="+url.QueryEscape(*page.PrevCursor))
+			backward = append(ids(page.Items), backward...)
+		}
+		assert.Equal(t, all[:6], backward)
+	})
+
+	t.Run("should not shift pages when products are added before the cursor", func(t *testing.T) {
+		page := list(t, "sortBy=price&limit=3")
+		require.NotNil(t, page.NextCursor)
+
+		createTestProduct(t, server, "Sticker", 0.99, 100)
+
+		next := list(t, "sortBy=price&limit=3&cursor="+url.QueryEscape(*page.NextCursor))
+		assert.NotContains(t, ids(next.Items), page.Items[2].Id)
+		assert.Equal(t, int32(4), next.Offset)
+	})
+
+	t.Run("should reject a tampered cursor", func(t *testing.T) {
+		page := list(t, "limit=1")
+		require.NotNil(t, page.NextCursor)
+
+		rr := makeRequest(t, server, "GET", "/products?cursor="+url.QueryEscape(*page.NextCursor+"x"), nil)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertValidationErrors(t, rr, "cursor")
+	})
+
+	t.Run("should reject a cursor of another sort order", func(t *testing.T) {
+		page := list(t, "sortBy=name&limit=1")
+		require.NotNil(t, page.NextCursor)
+
+		rr := makeRequest(t, server, "GET", "/products?sortBy=price&cursor="+url.QueryEscape(*page.NextCursor), nil)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertValidationErrors(t, rr, "cursor")
+	})
+
+	t.Run("should reject a cursor of the other direction", func(t *testing.T) {
+		page := list(t, "sortBy=price&order=desc&limit=1")
+		require.NotNil(t, page.NextCursor)
+
+		rr := makeRequest(t, server, "GET", "/products?sortBy=price&order=asc&cursor="+url.QueryEscape(*page.NextCursor), nil)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertValidationErrors(t, rr, "cursor")
+	})
+
+	t.Run("should continue from the cursor's position under another filter", func(t *testing.T) {
+		all := list(t, "sortBy=price").Items
+		page := list(t, "sortBy=price&limit=2")
+		require.NotNil(t, page.NextCursor)
+
+		// The cursor is a position in the order, so the filtered listing
+		// resumes after the same product
+		var want []string
+		for _, product := range all[2:] {
+			if product.Price >= 10 {
+				want = append(want, product.Id)
+			}
+		}
+		require.NotEmpty(t, want)
+		next := list(t, "sortBy=price&minPrice=10&cursor="+url.QueryEscape(*page.NextCursor))
+		assert.Equal(t, want, ids(next.Items))
+	})
+}
+
+func TestUsersService_Cursors(t *testing.T) {
+	server, _, token := setupTestServerWithAuth(t)
+
+	type userPage struct {
+		Items      []generated.User `json:"items"`
+		NextCursor *string          `json:"nextCursor"`
+	}
+	list := func(t *testing.T, server *TestServer, token, query string) *userPage {
+		t.Helper()
+		rr := makeAuthenticatedRequest(t, server, "GET", "/users?"+query, nil, token)
+		if rr.Code != http.StatusOK {
+			return nil
+		}
+		var page userPage
+		require.NoError(t, decodeJSON(rr, &page))
+		return &page
+	}
+
+	t.Run("should walk the same users as offset pagination", func(t *testing.T) {
+		all := list(t, server, token, "")
+		require.NotNil(t, all)
+
+		var walked []generated.User
+		query := "limit=1"
+		for {
+			page := list(t, server, token, query)
+			require.NotNil(t, page)
+			walked = append(walked, page.Items...)
+			if page.NextCursor == nil {
+				break
+			}
+			query = "limit=1&cursor=" + url.QueryEscape(*page.NextCursor)
+		}
+		assert.Equal(t, all.Items, walked)
+	})
+
+	t.Run("should accept cursors of servers with the same secret", func(t *testing.T) {
+		secret := []byte("0123456789abcdef0123456789abcdef")
+		signing, _, signingToken := setupTestServerWithAuth(t, handlers.WithCursorSecret(secret))
+		page := list(t, signing, signingToken, "limit=1")
+		require.NotNil(t, page)
+		require.NotNil(t, page.NextCursor)
+		query := "limit=1&cursor=" + url.QueryEscape(*page.NextCursor)
+
+		other, _, otherToken := setupTestServerWithAuth(t, handlers.WithCursorSecret(secret))
+		assert.NotNil(t, list(t, other, otherToken, query))
+		assert.Nil(t, list(t, server, token, query), "a server with another secret should reject the cursor")
+	})
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// versionOf returns the version of a stored record. Records written before
+// versioning was introduced have none and count as version 1.
+func versionOf(version *int32) int32 {
+	if version == nil {
+		return 1
+	}
+	return *version
+}
+
+// initialVersion returns the version of a newly created record
+func initialVersion() *int32 {
+	version := int32(1)
+	return &version
+}
+
+// nextVersion returns the version to store with an update
+func nextVersion(version *int32) *int32 {
+	next := versionOf(version) + 1
+	return &next
+}
+
+// versionETag formats a record version as a strong entity tag
+func versionETag(version *int32) string {
+	return `"` + strconv.FormatInt(int64(versionOf(version)), 10) + `"`
+}
+
+// setVersionETag sets the ETag header for a single record
+func setVersionETag(w http.ResponseWriter, version *int32) {
+	w.Header().Set("ETag", versionETag(version))
+}
+
+// checkIfMatch evaluates the If-Match precondition against the current
+// version of a record. Without the header the request is unconditional
+// unless the server requires If-Match.
+func (s *Server) checkIfMatch(r *http.Request, version *int32) error {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		if s.requireIfMatch {
+			return &apiError{http.StatusPreconditionRequired, ErrorCodeBadRequest, "If-Match header is required"}
+		}
+		return nil
+	}
+	if !etagListMatches(header, versionETag(version)) {
+		return &apiError{http.StatusPreconditionFailed, ErrorCodeConflict, "Resource has been modified"}
+	}
+	return nil
+}
+
+// etagListMatches reports whether a comma-separated If-Match list contains
+// "*" or etag, using the strong comparison required for If-Match
+func etagListMatches(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeConditionalJSON writes v as JSON with an ETag computed from the
+// encoded body and a Last-Modified header from lastModified, the last time
+// anything the response is built from changed. A GET whose If-None-Match or
+// If-Modified-Since precondition shows the client's copy is current gets
+// 304 Not Modified without a body.
+//
+// HTTP dates have one-second resolution, so a lastModified that is zero or
+// not yet a whole second in the past is left out: another change within the
+// same second would not move it.
+func writeConditionalJSON(w http.ResponseWriter, r *http.Request, v any, lastModified time.Time) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(v); err != nil {
+		errorResponse(w, http.StatusInternalServerError, ErrorCodeInternalError, "Internal server error")
+		return
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	if !lastModified.Before(time.Now().Truncate(time.Second)) {
+		lastModified = time.Time{}
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body.Bytes())
+}
+
+// modifiedAt returns the latest time a row of any of tables was written.
+// Read it before the rows so that a change made in between can only make it
+// too early, which costs a client a full response rather than a stale one.
+func (s *Server) modifiedAt(ctx context.Context, tables ...string) (time.Time, error) {
+	var modifiedAt time.Time
+	for _, table := range tables {
+		t, err := s.store.ModifiedAt(ctx, table)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if t.After(modifiedAt) {
+			modifiedAt = t
+		}
+	}
+	return modifiedAt, nil
+}
+
+// notModified evaluates If-None-Match and, when that header is absent,
+// If-Modified-Since as described in RFC 9110 section 13.2.2
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return etagListMatchesWeak(header, etag)
+	}
+	if header := r.Header.Get("If-Modified-Since"); header != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(header)
+		if err != nil {
+			return false
+		}
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// etagListMatchesWeak is like etagListMatches but uses the weak comparison
+// required for If-None-Match, ignoring W/ prefixes
+func etagListMatchesWeak(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
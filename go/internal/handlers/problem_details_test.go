@@ -0,0 +1,97 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemDetails(t *testing.T) {
+	server, _, token := setupTestServerWithAuth(t)
+	problemJSON := map[string]string{"Accept": "application/problem+json"}
+
+	decodeProblem := func(t *testing.T, body []byte) generated.ProblemDetails {
+		t.Helper()
+		var problem generated.ProblemDetails
+		require.NoError(t, json.Unmarshal(body, &problem))
+		return problem
+	}
+
+	t.Run("should keep the error response by default", func(t *testing.T) {
+		rr := makeRequest(t, server, "GET", "/products/999", nil)
+		assertStatus(t, rr, http.StatusNotFound)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Values("Vary"), "Accept")
+		assertErrorResponse(t, rr, "NOT_FOUND")
+	})
+
+	t.Run("should send handler errors as problem details", func(t *testing.T) {
+		rr := makeRequestWithHeaders(t, server, "GET", "/products/999", nil, problemJSON)
+		assertStatus(t, rr, http.StatusNotFound)
+		assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+
+		problem := decodeProblem(t, rr.Body.Bytes())
+		assert.Equal(t, "https://hello-typespec.example/problems/not-found", problem.Type)
+		assert.Equal(t, "Not Found", problem.Title)
+		assert.Equal(t, int32(http.StatusNotFound), problem.Status)
+		assert.Equal(t, "Product not found", problem.Detail)
+		assert.Equal(t, "/products/999", problem.Instance)
+		assert.Equal(t, generated.NOTFOUND, problem.Code)
+		assert.Nil(t, problem.Details)
+	})
+
+	t.Run("should send authentication errors as problem details", func(t *testing.T) {
+		rr := makeRequestWithHeaders(t, server, "GET", "/auth/me", nil, problemJSON)
+		assertStatus(t, rr, http.StatusUnauthorized)
+		assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+
+		problem := decodeProblem(t, rr.Body.Bytes())
+		assert.Equal(t, "https://hello-typespec.example/problems/unauthorized", problem.Type)
+		assert.Equal(t, generated.UNAUTHORIZED, problem.Code)
+		assert.Equal(t, "/auth/me", problem.Instance)
+	})
+
+	t.Run("should list invalid fields as an extension", func(t *testing.T) {
+		rr := makeRequestWithHeaders(t, server, "POST", "/products", map[string]any{
+			"name":        "",
+			"description": "Invalid product",
+			"price":       -1,
+			"stock":       1,
+			"categoryId":  "1",
+		}, map[string]string{
+			"Accept":        "application/problem+json, application/json;q=0.5",
+			"Authorization": "Bearer " + token,
+		})
+		assertStatus(t, rr, http.StatusBadRequest)
+		assert.Equal(t, "application/problem+json", rr.Header().Get("Content-Type"))
+
+		problem := decodeProblem(t, rr.Body.Bytes())
+		assert.Equal(t, "Validation Error", problem.Title)
+		assert.Equal(t, generated.VALIDATIONERROR, problem.Code)
+		require.NotNil(t, problem.Details)
+		var fields []string
+		for _, detail := range *problem.Details {
+			fields = append(fields, detail.Field)
+		}
+		assert.ElementsMatch(t, []string{"/name", "/price"}, fields)
+	})
+
+	t.Run("should prefer the error response when the client does", func(t *testing.T) {
+		rr := makeRequestWithHeaders(t, server, "GET", "/products/999", nil, map[string]string{
+			"Accept": "application/json, application/problem+json;q=0.5",
+		})
+		assertStatus(t, rr, http.StatusNotFound)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assertErrorResponse(t, rr, "NOT_FOUND")
+	})
+
+	t.Run("should not change successful responses", func(t *testing.T) {
+		rr := makeRequestWithHeaders(t, server, "GET", "/products/1", nil, problemJSON)
+		assertStatus(t, rr, http.StatusOK)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	})
+}
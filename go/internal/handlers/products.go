@@ -1,75 +1,101 @@
 package handlers
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"sort"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/ancestry"
+	"github.com/blck-snwmn/hello-typespec/go/internal/search"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/blck-snwmn/hello-typespec/go/internal/validation"
 )
 
 // ProductsServiceList implements GET /products
 func (s *Server) ProductsServiceList(w http.ResponseWriter, r *http.Request, params generated.ProductsServiceListParams) {
-	// Get all products
-	allProducts := s.store.GetProducts()
-
-	// Apply filters
-	var filteredProducts []generated.Product
-	for _, product := range allProducts {
-		// Search filter (name)
-		if params.Name != nil && *params.Name != "" {
-			searchStr := strings.ToLower(*params.Name)
-			if !strings.Contains(strings.ToLower(product.Name), searchStr) &&
-				!strings.Contains(strings.ToLower(product.Description), searchStr) {
-				continue
-			}
-		}
-
-		// Category filter
-		if params.CategoryId != nil && *params.CategoryId != "" {
-			if product.CategoryId != *params.CategoryId {
-				continue
-			}
-		}
+	// The list changes with the products, the categories they are filtered
+	// and counted by, and the stock carts hold
+	lastModified, err := s.modifiedAt(r.Context(), store.TableProducts, store.TableCategories)
+	if err != nil {
+		storeErrorResponse(w, err, "Product")
+		return
+	}
+	changedAt, err := s.reservations.ChangedAt(r.Context(), s.store)
+	if err != nil {
+		storeErrorResponse(w, err, "Product")
+		return
+	}
+	if changedAt.After(lastModified) {
+		lastModified = changedAt
+	}
 
-		// Price filters
-		if params.MinPrice != nil && product.Price < *params.MinPrice {
-			continue
+	// Full-text search (name)
+	var results search.Results
+	var scores map[string]float64
+	if params.Name != nil && strings.TrimSpace(*params.Name) != "" {
+		results, err = s.search.Search(r.Context(), *params.Name)
+		if err != nil {
+			storeErrorResponse(w, err, "Product")
+			return
 		}
-		if params.MaxPrice != nil && product.Price > *params.MaxPrice {
-			continue
+		scores = make(map[string]float64, len(results.Hits))
+		for _, hit := range results.Hits {
+			scores[hit.ID] = hit.Score
 		}
-
-		filteredProducts = append(filteredProducts, product)
 	}
 
-	// Apply sorting
+	// Searches are sorted by relevance unless told otherwise, everything
+	// else by creation time. Without a search there is no relevance.
+	sortBy := generated.ProductsServiceListParamsSortByCreatedAt
 	if params.SortBy != nil {
-		switch *params.SortBy {
-		case generated.ProductsServiceListParamsSortByName:
-			sort.Slice(filteredProducts, func(i, j int) bool {
-				if params.Order != nil && *params.Order == generated.ProductsServiceListParamsOrderDesc {
-					return filteredProducts[i].Name > filteredProducts[j].Name
-				}
-				return filteredProducts[i].Name < filteredProducts[j].Name
-			})
-		case generated.ProductsServiceListParamsSortByPrice:
-			sort.Slice(filteredProducts, func(i, j int) bool {
-				if params.Order != nil && *params.Order == generated.ProductsServiceListParamsOrderDesc {
-					return filteredProducts[i].Price > filteredProducts[j].Price
-				}
-				return filteredProducts[i].Price < filteredProducts[j].Price
-			})
-		case generated.ProductsServiceListParamsSortByCreatedAt:
-			sort.Slice(filteredProducts, func(i, j int) bool {
-				if params.Order != nil && *params.Order == generated.ProductsServiceListParamsOrderDesc {
-					return filteredProducts[i].CreatedAt.After(filteredProducts[j].CreatedAt)
-				}
-				return filteredProducts[i].CreatedAt.Before(filteredProducts[j].CreatedAt)
-			})
+		sortBy = *params.SortBy
+	} else if scores != nil {
+		sortBy = generated.ProductsServiceListParamsSortByRelevance
+	}
+	if sortBy == generated.ProductsServiceListParamsSortByRelevance && scores == nil {
+		sortBy = generated.ProductsServiceListParamsSortByCreatedAt
+	}
+	desc := params.Order != nil && *params.Order == generated.ProductsServiceListParamsOrderDesc
+	if sortBy == generated.ProductsServiceListParamsSortByRelevance {
+		// Most relevant first unless order is asc
+		desc = params.Order == nil || *params.Order != generated.ProductsServiceListParamsOrderAsc
+	}
+	query := store.ProductQuery{
+		MinPrice: params.MinPrice,
+		MaxPrice: params.MaxPrice,
+		Sort:     store.Sort{Field: store.SortByCreatedAt, Desc: desc},
+	}
+	switch sortBy {
+	case generated.ProductsServiceListParamsSortByName:
+		query.Sort.Field = store.SortByName
+	case generated.ProductsServiceListParamsSortByPrice:
+		query.Sort.Field = store.SortByPrice
+	}
+
+	// Search filter (name); no hits match no products
+	if scores != nil {
+		query.IDs = make([]string, 0, len(results.Hits))
+		for _, hit := range results.Hits {
+			query.IDs = append(query.IDs, hit.ID)
+		}
+	}
+
+	// Category filter
+	if params.CategoryId != nil && *params.CategoryId != "" {
+		query.CategoryIDs = []string{*params.CategoryId}
+		if params.IncludeDescendants != nil && *params.IncludeDescendants {
+			index, err := ancestry.Load(r.Context(), s.store)
+			if err != nil {
+				storeErrorResponse(w, err, "Category")
+				return
+			}
+			query.CategoryIDs = append(query.CategoryIDs, index.Descendants(*params.CategoryId)...)
 		}
 	}
 
@@ -84,46 +110,119 @@ func (s *Server) ProductsServiceList(w http.ResponseWriter, r *http.Request, par
 		offset = *params.Offset
 	}
 
-	total := int32(len(filteredProducts))
-	start := int(offset)
-	end := int(offset + limit)
-
-	if start > len(filteredProducts) {
-		start = len(filteredProducts)
+	page, err := s.paginateProducts(r.Context(), query, sortBy, scores, limit, offset, params.Cursor)
+	if err != nil {
+		txErrorResponse(w, err, "Product")
+		return
 	}
-	if end > len(filteredProducts) {
-		end = len(filteredProducts)
+	reserved, err := s.reservations.ReservedProducts(r.Context(), s.store)
+	if err != nil {
+		storeErrorResponse(w, err, "Product")
+		return
+	}
+	for i := range page.items {
+		page.items[i] = withStockLevels(page.items[i], reserved[page.items[i].Id])
+		if scores != nil {
+			page.items[i] = withSearchResult(page.items[i], results, scores)
+		}
 	}
-
-	paginatedProducts := filteredProducts[start:end]
 
 	// Create response
-	response := struct {
-		Items  []generated.Product `json:"items"`
-		Total  int32               `json:"total"`
-		Limit  int32               `json:"limit"`
-		Offset int32               `json:"offset"`
-	}{
-		Items:  paginatedProducts,
-		Total:  total,
-		Limit:  limit,
-		Offset: offset,
+	response := generated.ProductListResponse{
+		Items:      page.items,
+		Total:      page.total,
+		Limit:      limit,
+		Offset:     page.offset,
+		NextCursor: page.nextCursor,
+		PrevCursor: page.prevCursor,
+	}
+	if params.Facets != nil && len(*params.Facets) > 0 {
+		// Facets count every matching product, not just the page
+		var err error
+		response.Facets, err = s.productFacets(r.Context(), query, *params.Facets)
+		if err != nil {
+			storeErrorResponse(w, err, "Product")
+			return
+		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	writeConditionalJSON(w, r, response, lastModified)
+}
+
+// paginateProducts returns a page of the products query matches, which are
+// listed by sortBy. Products sorted by relevance are sorted and paged here,
+// as the store knows nothing of scores; ties are broken by ascending ID,
+// reversed with the scores.
+func (s *Server) paginateProducts(ctx context.Context, query store.ProductQuery, sortBy generated.ProductsServiceListParamsSortBy, scores map[string]float64, limit, offset int32, cursorParam *string) (listPage[generated.Product], error) {
+	id := func(product generated.Product) string { return product.Id }
+	name := orderName("products", string(sortBy), query.Sort.Desc)
+	run := func(page store.Page) (store.Result[generated.Product], error) {
+		query.Page = page
+		return s.store.QueryProducts(ctx, query)
+	}
+	switch sortBy {
+	case generated.ProductsServiceListParamsSortByName:
+		return paginate(s.cursors, listOrder[generated.Product, string]{
+			name: name,
+			key:  func(product generated.Product) string { return product.Name },
+			id:   id,
+		}, limit, offset, cursorParam, run)
+	case generated.ProductsServiceListParamsSortByPrice:
+		return paginate(s.cursors, listOrder[generated.Product, float32]{
+			name: name,
+			key:  func(product generated.Product) float32 { return product.Price },
+			id:   id,
+		}, limit, offset, cursorParam, run)
+	case generated.ProductsServiceListParamsSortByRelevance:
+		order := listOrder[generated.Product, float64]{
+			name: name,
+			key:  func(product generated.Product) float64 { return scores[product.Id] },
+			id:   id,
+		}
+		compare := func(a, b store.Position) int {
+			c := cmp.Compare(b.Key.(float64), a.Key.(float64))
+			if c == 0 {
+				c = strings.Compare(a.ID, b.ID)
+			}
+			if !query.Sort.Desc {
+				return -c
+			}
+			return c
+		}
+		return paginate(s.cursors, order, limit, offset, cursorParam, func(page store.Page) (store.Result[generated.Product], error) {
+			all, err := run(store.Page{Limit: store.NoLimit})
+			if err != nil {
+				return store.Result[generated.Product]{}, err
+			}
+			slices.SortFunc(all.Items, func(a, b generated.Product) int {
+				return compare(order.position(a), order.position(b))
+			})
+			return store.PageRecords(all.Items, page, order.position, compare), nil
+		})
+	}
+	return paginate(s.cursors, listOrder[generated.Product, time.Time]{
+		name: name,
+		key:  func(product generated.Product) time.Time { return product.CreatedAt },
+		id:   id,
+	}, limit, offset, cursorParam, run)
 }
 
 // ProductsServiceGet implements GET /products/{productId}
 func (s *Server) ProductsServiceGet(w http.ResponseWriter, r *http.Request, productId generated.Uuid) {
-	product, ok := s.store.GetProduct(productId)
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Product not found")
+	product, err := s.store.GetProduct(r.Context(), productId)
+	if err != nil {
+		storeErrorResponse(w, err, "Product")
+		return
+	}
+	reserved, err := s.reservations.Reserved(r.Context(), s.store, productId)
+	if err != nil {
+		storeErrorResponse(w, err, "Product")
 		return
 	}
 
+	setVersionETag(w, product.Version)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(product)
+	json.NewEncoder(w).Encode(withStockLevels(product, reserved))
 }
 
 // ProductsServiceCreate implements POST /products
@@ -133,6 +232,10 @@ func (s *Server) ProductsServiceCreate(w http.ResponseWriter, r *http.Request) {
 		errorResponse(w, http.StatusBadRequest, ErrorCodeBadRequest, "Invalid request body")
 		return
 	}
+	if errs := validateProduct(&req.Name, &req.Price, &req.Stock); len(errs) > 0 {
+		validationErrorResponse(w, errs)
+		return
+	}
 
 	// Create new product
 	now := time.Now()
@@ -146,68 +249,150 @@ func (s *Server) ProductsServiceCreate(w http.ResponseWriter, r *http.Request) {
 		ImageUrls:   []string{},
 		CreatedAt:   now,
 		UpdatedAt:   now,
+		Version:     initialVersion(),
 	}
 
 	if req.ImageUrls != nil {
 		newProduct.ImageUrls = *req.ImageUrls
 	}
 
-	created := s.store.CreateProduct(newProduct)
+	created, err := s.store.CreateProduct(r.Context(), newProduct)
+	if err != nil {
+		storeErrorResponse(w, err, "Product")
+		return
+	}
 
+	setVersionETag(w, created.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(created)
+	// A new product has no reservations yet
+	json.NewEncoder(w).Encode(withStockLevels(created, 0))
 }
 
 // ProductsServiceUpdate implements PATCH /products/{productId}
 func (s *Server) ProductsServiceUpdate(w http.ResponseWriter, r *http.Request, productId generated.Uuid) {
-	existing, ok := s.store.GetProduct(productId)
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Product not found")
-		return
-	}
-
 	var req generated.UpdateProductRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		errorResponse(w, http.StatusBadRequest, ErrorCodeBadRequest, "Invalid request body")
 		return
 	}
-
-	// Update fields if provided
-	updatedProduct := *existing
-	if req.Name != nil {
-		updatedProduct.Name = *req.Name
-	}
-	if req.Description != nil {
-		updatedProduct.Description = *req.Description
-	}
-	if req.Price != nil {
-		updatedProduct.Price = *req.Price
-	}
-	if req.Stock != nil {
-		updatedProduct.Stock = *req.Stock
-	}
-	if req.CategoryId != nil {
-		updatedProduct.CategoryId = *req.CategoryId
-	}
-	if req.ImageUrls != nil {
-		updatedProduct.ImageUrls = *req.ImageUrls
+	if errs := validateProduct(req.Name, req.Price, req.Stock); len(errs) > 0 {
+		validationErrorResponse(w, errs)
+		return
 	}
-	updatedProduct.UpdatedAt = time.Now()
 
-	updated := s.store.UpdateProduct(productId, updatedProduct)
+	// Check the version and write in one transaction so concurrent edits cannot clobber each other
+	var (
+		updated  generated.Product
+		reserved int32
+	)
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		existing, err := tx.GetProduct(r.Context(), productId)
+		if err != nil {
+			return err
+		}
+		if err := s.checkIfMatch(r, existing.Version); err != nil {
+			return err
+		}
 
+		// Update fields if provided
+		updatedProduct := existing
+		if req.Name != nil {
+			updatedProduct.Name = *req.Name
+		}
+		if req.Description != nil {
+			updatedProduct.Description = *req.Description
+		}
+		if req.Price != nil {
+			updatedProduct.Price = *req.Price
+		}
+		if req.Stock != nil {
+			updatedProduct.Stock = *req.Stock
+		}
+		if req.CategoryId != nil {
+			updatedProduct.CategoryId = *req.CategoryId
+		}
+		if req.ImageUrls != nil {
+			updatedProduct.ImageUrls = *req.ImageUrls
+		}
+		updatedProduct.UpdatedAt = time.Now()
+		updatedProduct.Version = nextVersion(existing.Version)
+
+		if updated, err = tx.UpdateProduct(r.Context(), productId, updatedProduct); err != nil {
+			return err
+		}
+		reserved, err = s.reservations.Reserved(r.Context(), tx, productId)
+		return err
+	})
+	if err != nil {
+		txErrorResponse(w, err, "Product")
+		return
+	}
+
+	setVersionETag(w, updated.Version)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(updated)
+	json.NewEncoder(w).Encode(withStockLevels(updated, reserved))
 }
 
 // ProductsServiceDelete implements DELETE /products/{productId}
 func (s *Server) ProductsServiceDelete(w http.ResponseWriter, r *http.Request, productId generated.Uuid) {
-	_, ok := s.store.DeleteProduct(productId)
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "Product not found")
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		existing, err := tx.GetProduct(r.Context(), productId)
+		if err != nil {
+			return err
+		}
+		if err := s.checkIfMatch(r, existing.Version); err != nil {
+			return err
+		}
+		_, err = tx.DeleteProduct(r.Context(), productId)
+		return err
+	})
+	if err != nil {
+		txErrorResponse(w, err, "Product")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// validateProduct checks the fields of a product create or update request;
+// nil fields were left out of an update
+func validateProduct(name *string, price *float32, stock *int32) validation.Errors {
+	var errs validation.Errors
+	if name != nil {
+		errs.NotBlank("/name", *name)
+	}
+	if price != nil {
+		errs.Minimum("/price", float64(*price), 0)
+	}
+	if stock != nil {
+		errs.Minimum("/stock", float64(*stock), 0)
+	}
+	return errs
+}
+
+// withStockLevels fills in the stock held by cart reservations, reserved,
+// and the stock still available
+func withStockLevels(product generated.Product, reserved int32) generated.Product {
+	available := max(product.Stock-reserved, 0)
+	product.ReservedStock = &reserved
+	product.AvailableStock = &available
+	return product
+}
+
+// withSearchResult fills in how relevant product is to a search and which of
+// its words matched
+func withSearchResult(product generated.Product, results search.Results, scores map[string]float64) generated.Product {
+	score := float32(scores[product.Id])
+	product.Score = &score
+
+	var highlight generated.ProductHighlight
+	if name := results.Highlight(product.Name); name != "" {
+		highlight.Name = &name
+	}
+	if description := results.Highlight(product.Description); description != "" {
+		highlight.Description = &description
+	}
+	product.Highlight = &highlight
+	return product
+}
@@ -0,0 +1,127 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductsService_Facets(t *testing.T) {
+	server, userID, token := setupTestServerWithAuth(t)
+
+	// Besides the seeded MacBook (Laptops), iPhone (Smartphones) and T-Shirt
+	// (Clothing), a laptop accessory that is out of stock
+	rr := makeAuthenticatedRequest(t, server, "POST", "/products", map[string]any{
+		"name":        "Laptop Sleeve",
+		"description": "Padded sleeve",
+		"price":       49.00,
+		"stock":       0,
+		"categoryId":  "2",
+	}, token)
+	assertStatus(t, rr, http.StatusCreated)
+
+	list := func(t *testing.T, query string) generated.ProductListResponse {
+		t.Helper()
+		rr := makeRequest(t, server, "GET", "/products?"+query, nil)
+		assertStatus(t, rr, http.StatusOK)
+		var response generated.ProductListResponse
+		require.NoError(t, decodeJSON(rr, &response))
+		return response
+	}
+
+	categoryCounts := func(t *testing.T, facets *generated.ProductFacets) map[string]int32 {
+		t.Helper()
+		require.NotNil(t, facets)
+		require.NotNil(t, facets.Categories)
+		counts := make(map[string]int32)
+		for _, facet := range *facets.Categories {
+			counts[facet.Name] = facet.Count
+		}
+		return counts
+	}
+
+	t.Run("should not count facets unless asked", func(t *testing.T) {
+		assert.Nil(t, list(t, "").Facets)
+	})
+
+	t.Run("should count products per category including subcategories", func(t *testing.T) {
+		response := list(t, "facets=category")
+		assert.Equal(t, map[string]int32{
+			"Electronics": 3,
+			"Laptops":     2,
+			"Smartphones": 1,
+			"Clothing":    1,
+		}, categoryCounts(t, response.Facets))
+		assert.Nil(t, response.Facets.PriceRanges)
+		assert.Nil(t, response.Facets.Stock)
+	})
+
+	t.Run("should count products per price range", func(t *testing.T) {
+		facets := list(t, "facets=price").Facets
+		require.NotNil(t, facets)
+		require.NotNil(t, facets.PriceRanges)
+
+		type priceRange struct {
+			min, max *float32
+			count    int32
+		}
+		var ranges []priceRange
+		for _, facet := range *facets.PriceRanges {
+			ranges = append(ranges, priceRange{facet.Min, facet.Max, facet.Count})
+		}
+		bound := func(v float32) *float32 { return &v }
+		assert.Equal(t, []priceRange{
+			{nil, bound(50), 2},
+			{bound(50), bound(100), 0},
+			{bound(100), bound(500), 0},
+			{bound(500), bound(1000), 1},
+			{bound(1000), nil, 1},
+		}, ranges)
+	})
+
+	t.Run("should count products in and out of stock", func(t *testing.T) {
+		facets := list(t, "facets=stock").Facets
+		require.NotNil(t, facets)
+		require.NotNil(t, facets.Stock)
+		assert.Equal(t, generated.StockFacet{InStock: 3, OutOfStock: 1}, *facets.Stock)
+	})
+
+	t.Run("should count all products that match the filters", func(t *testing.T) {
+		response := list(t, "maxPrice=100&limit=1&facets=category,stock")
+		assert.Len(t, response.Items, 1)
+		assert.Equal(t, int32(2), response.Total)
+		assert.Equal(t, map[string]int32{
+			"Electronics": 1,
+			"Laptops":     1,
+			"Clothing":    1,
+		}, categoryCounts(t, response.Facets))
+		require.NotNil(t, response.Facets.Stock)
+		assert.Equal(t, generated.StockFacet{InStock: 1, OutOfStock: 1}, *response.Facets.Stock)
+	})
+
+	t.Run("should count products held by carts as out of stock", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "POST", "/carts/users/"+userID+"/items", map[string]any{
+			"productId": "1",
+			"quantity":  10,
+		}, token)
+		assertStatus(t, rr, http.StatusOK)
+
+		facets := list(t, "facets=stock").Facets
+		require.NotNil(t, facets)
+		require.NotNil(t, facets.Stock)
+		assert.Equal(t, generated.StockFacet{InStock: 2, OutOfStock: 2}, *facets.Stock)
+
+		facets = list(t, "maxPrice=100&facets=stock").Facets
+		require.NotNil(t, facets)
+		assert.Equal(t, generated.StockFacet{InStock: 1, OutOfStock: 1}, *facets.Stock, "the MacBook does not match")
+	})
+
+	t.Run("should return 400 for an unknown facet", func(t *testing.T) {
+		rr := makeRequest(t, server, "GET", "/products?facets=color", nil)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertValidationErrors(t, rr, "facets")
+	})
+}
@@ -0,0 +1,134 @@
+package handlers_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductsService_Search(t *testing.T) {
+	server, _, token := setupTestServerWithAuth(t)
+
+	createProduct := func(t *testing.T, name, description string) string {
+		t.Helper()
+		rr := makeAuthenticatedRequest(t, server, "POST", "/products", map[string]any{
+			"name":        name,
+			"description": description,
+			"price":       10.00,
+			"stock":       1,
+			"categoryId":  "1",
+		}, token)
+		assertStatus(t, rr, http.StatusCreated)
+		var product generated.Product
+		require.NoError(t, decodeJSON(rr, &product))
+		return product.Id
+	}
+
+	search := func(t *testing.T, query string) []generated.Product {
+		t.Helper()
+		rr := makeRequest(t, server, "GET", "/products?"+query, nil)
+		assertStatus(t, rr, http.StatusOK)
+		var response struct {
+			Items []generated.Product `json:"items"`
+			Total int32               `json:"total"`
+		}
+		require.NoError(t, decodeJSON(rr, &response))
+		assert.Len(t, response.Items, int(response.Total))
+		return response.Items
+	}
+
+	names := func(products []generated.Product) []string {
+		names := make([]string, len(products))
+		for i, product := range products {
+			names[i] = product.Name
+		}
+		return names
+	}
+
+	t.Run("should match other forms of a word", func(t *testing.T) {
+		assert.Equal(t, []string{`MacBook Pro 16"`}, names(search(t, "name=chips")))
+	})
+
+	t.Run("should match the start of a word", func(t *testing.T) {
+		assert.Equal(t, []string{`MacBook Pro 16"`}, names(search(t, "name=macb")))
+	})
+
+	t.Run("should match words with a typo", func(t *testing.T) {
+		assert.Equal(t, []string{"iPhone 15 Pro"}, names(search(t, "name=iphnoe")))
+	})
+
+	t.Run("should require every word to match", func(t *testing.T) {
+		assert.Empty(t, search(t, "name=macbook+shirt"))
+	})
+
+	t.Run("should rank name matches above description matches", func(t *testing.T) {
+		createProduct(t, "Desk Lamp", "A lamp with a wireless charging pad")
+		createProduct(t, "Wireless Charger", "Charges phones")
+
+		products := search(t, "name=wireless")
+		require.Equal(t, []string{"Wireless Charger", "Desk Lamp"}, names(products))
+		require.NotNil(t, products[0].Score)
+		require.NotNil(t, products[1].Score)
+		assert.Greater(t, *products[0].Score, *products[1].Score)
+
+		products = search(t, "name=wireless&sortBy=relevance&order=asc")
+		assert.Equal(t, []string{"Desk Lamp", "Wireless Charger"}, names(products))
+
+		products = search(t, "name=wireless&sortBy=name")
+		assert.Equal(t, []string{"Desk Lamp", "Wireless Charger"}, names(products))
+	})
+
+	t.Run("should highlight the words that matched", func(t *testing.T) {
+		products := search(t, "name=macbook")
+		require.Len(t, products, 1)
+		highlight := products[0].Highlight
+		require.NotNil(t, highlight)
+		require.NotNil(t, highlight.Name)
+		require.NotNil(t, highlight.Description)
+		assert.Equal(t, `<mark>MacBook</mark> Pro 16&#34;`, *highlight.Name)
+		assert.Equal(t, "Apple <mark>MacBook</mark> Pro with M3 chip", *highlight.Description)
+	})
+
+	t.Run("should cut long descriptions down to the match", func(t *testing.T) {
+		description := strings.Repeat("filler words ", 20) + "with a telescope inside " + strings.Repeat("more filler ", 20)
+		createProduct(t, "Mystery Box", description)
+
+		products := search(t, "name=telescope")
+		require.Len(t, products, 1)
+		highlight := products[0].Highlight
+		require.NotNil(t, highlight)
+		assert.Nil(t, highlight.Name)
+		require.NotNil(t, highlight.Description)
+		assert.Contains(t, *highlight.Description, "with a <mark>telescope</mark> inside")
+		assert.True(t, strings.HasPrefix(*highlight.Description, "…"))
+		assert.True(t, strings.HasSuffix(*highlight.Description, "…"))
+		assert.Less(t, len(*highlight.Description), len(description))
+	})
+
+	t.Run("should not set search fields without a search", func(t *testing.T) {
+		for _, product := range search(t, "limit=100") {
+			assert.Nil(t, product.Score)
+			assert.Nil(t, product.Highlight)
+		}
+	})
+
+	t.Run("should follow product updates and deletes", func(t *testing.T) {
+		id := createProduct(t, "Ergonomic Chair", "Sit comfortably")
+		assert.Equal(t, []string{"Ergonomic Chair"}, names(search(t, "name=chair")))
+
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/products/"+id, map[string]any{
+			"name": "Ergonomic Stool",
+		}, token)
+		assertStatus(t, rr, http.StatusOK)
+		assert.Empty(t, search(t, "name=chair"))
+		assert.Equal(t, []string{"Ergonomic Stool"}, names(search(t, "name=stool")))
+
+		rr = makeAuthenticatedRequest(t, server, "DELETE", "/products/"+id, nil, token)
+		assertStatus(t, rr, http.StatusNoContent)
+		assert.Empty(t, search(t, "name=stool"))
+	})
+}
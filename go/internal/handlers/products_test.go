@@ -3,8 +3,13 @@ package handlers_test
 import (
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/handlers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -136,6 +141,60 @@ func TestProductsService_List(t *testing.T) {
 	})
 }
 
+func TestProductsService_CategoryFilter(t *testing.T) {
+	server, _, token := setupTestServerWithAuth(t)
+
+	listNames := func(t *testing.T, query string) []string {
+		t.Helper()
+		rr := makeRequest(t, server, "GET", "/products?"+query, nil)
+		assertStatus(t, rr, http.StatusOK)
+		var response generated.ProductListResponse
+		require.NoError(t, decodeJSON(rr, &response))
+		var names []string
+		for _, product := range response.Items {
+			names = append(names, product.Name)
+		}
+		return names
+	}
+
+	t.Run("should match the category exactly by default", func(t *testing.T) {
+		// The seeded products are in Laptops and Smartphones, not Electronics
+		assert.Empty(t, listNames(t, "categoryId=1"))
+		assert.Empty(t, listNames(t, "categoryId=1&includeDescendants=false"))
+	})
+
+	t.Run("should include products of subcategories", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{`MacBook Pro 16"`, "iPhone 15 Pro"}, listNames(t, "categoryId=1&includeDescendants=true"))
+	})
+
+	t.Run("should follow changes to the category tree", func(t *testing.T) {
+		laptops := "2"
+		gamingID := createTestCategory(t, server, "Gaming Laptops", &laptops)
+		createTestProductWithCategory(t, server, "Gaming Laptop", 1999.99, 3, gamingID)
+
+		assert.ElementsMatch(t, []string{`MacBook Pro 16"`, "iPhone 15 Pro", "Gaming Laptop"}, listNames(t, "categoryId=1&includeDescendants=true"))
+		assert.ElementsMatch(t, []string{`MacBook Pro 16"`, "Gaming Laptop"}, listNames(t, "categoryId=2&includeDescendants=true"))
+
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/categories/"+gamingID, map[string]any{
+			"parentId": "4",
+		}, token)
+		assertStatus(t, rr, http.StatusOK)
+
+		assert.ElementsMatch(t, []string{`MacBook Pro 16"`, "iPhone 15 Pro"}, listNames(t, "categoryId=1&includeDescendants=true"))
+		assert.ElementsMatch(t, []string{"T-Shirt", "Gaming Laptop"}, listNames(t, "categoryId=4&includeDescendants=true"))
+	})
+
+	t.Run("should see category changes made by other servers", func(t *testing.T) {
+		// A category written to the store without going through this server
+		electronics := "1"
+		_, err := server.store.CreateCategory(t.Context(), generated.Category{Id: "tablets", Name: "Tablets", ParentId: &electronics})
+		require.NoError(t, err)
+		createTestProductWithCategory(t, server, "Tablet", 499.99, 3, "tablets")
+
+		assert.ElementsMatch(t, []string{`MacBook Pro 16"`, "iPhone 15 Pro", "Tablet"}, listNames(t, "categoryId=1&includeDescendants=true"))
+	})
+}
+
 func TestProductsService_Get(t *testing.T) {
 	server := setupTestServer(t)
 
@@ -161,7 +220,7 @@ func TestProductsService_Get(t *testing.T) {
 }
 
 func TestProductsService_Create(t *testing.T) {
-	server := setupTestServer(t)
+	server, _, token := setupTestServerWithAuth(t)
 
 	t.Run("should create a new product", func(t *testing.T) {
 		newProduct := map[string]any{
@@ -173,7 +232,7 @@ func TestProductsService_Create(t *testing.T) {
 			"imageUrls":   []string{"https://example.com/product1.jpg", "https://example.com/product2.jpg"},
 		}
 
-		rr := makeRequest(t, server, "POST", "/products", newProduct)
+		rr := makeAuthenticatedRequest(t, server, "POST", "/products", newProduct, token)
 		assertStatus(t, rr, http.StatusCreated)
 
 		var product map[string]any
@@ -203,7 +262,7 @@ func TestProductsService_Create(t *testing.T) {
 			"categoryId":  "1",
 		}
 
-		rr := makeRequest(t, server, "POST", "/products", newProduct)
+		rr := makeAuthenticatedRequest(t, server, "POST", "/products", newProduct, token)
 		assertStatus(t, rr, http.StatusCreated)
 
 		var product map[string]any
@@ -214,23 +273,48 @@ func TestProductsService_Create(t *testing.T) {
 		assert.Empty(t, imageUrls)
 	})
 
-	// TODO: Add validation tests when implemented
-	// t.Run("should return 400 for invalid product data", func(t *testing.T) {
-	// 	invalidProduct := map[string]any{
-	// 		"name":       "", // Empty name
-	// 		"price":      -10.00, // Negative price
-	// 		"stock":      -5, // Negative stock
-	// 		"categoryId": "1",
-	// 	}
+	t.Run("should return 400 for invalid product data", func(t *testing.T) {
+		invalidProduct := map[string]any{
+			"name":       "",     // Empty name
+			"price":      -10.00, // Negative price
+			"stock":      -5,     // Negative stock
+			"categoryId": "1",
+		}
+
+		rr := makeAuthenticatedRequest(t, server, "POST", "/products", invalidProduct, token)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertValidationErrors(t, rr, "/name", "/description", "/price", "/stock")
+	})
+
+	t.Run("should return 400 for a blank name", func(t *testing.T) {
+		blankProduct := map[string]any{
+			"name":        "   ",
+			"description": "Only white space in the name",
+			"price":       10.00,
+			"stock":       1,
+			"categoryId":  "1",
+		}
+
+		rr := makeAuthenticatedRequest(t, server, "POST", "/products", blankProduct, token)
+		assertStatus(t, rr, http.StatusBadRequest)
+		details := assertValidationErrors(t, rr, "/name")
+		assert.Equal(t, "minLength", details[0].Rule)
+	})
+
+	t.Run("should return 400 for a malformed body", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/products", strings.NewReader("{"))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		server.handler.ServeHTTP(rr, req)
 
-	// 	rr := makeRequest(t, server, "POST", "/products", invalidProduct)
-	// 	assertStatus(t, rr, http.StatusBadRequest)
-	// 	assertErrorResponse(t, rr, "VALIDATION_ERROR")
-	// })
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertErrorResponse(t, rr, "BAD_REQUEST")
+	})
 }
 
 func TestProductsService_Update(t *testing.T) {
-	server := setupTestServer(t)
+	server, _, token := setupTestServerWithAuth(t)
 
 	t.Run("should update product fields", func(t *testing.T) {
 		// Create a product to update
@@ -242,7 +326,7 @@ func TestProductsService_Update(t *testing.T) {
 			"stock": 30,
 		}
 
-		rr := makeRequest(t, server, "PATCH", "/products/"+productID, update)
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/products/"+productID, update, token)
 		assertStatus(t, rr, http.StatusOK)
 
 		var product map[string]any
@@ -268,7 +352,7 @@ func TestProductsService_Update(t *testing.T) {
 			"name": "Name Only Updated",
 		}
 
-		rr := makeRequest(t, server, "PATCH", "/products/"+productID, update)
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/products/"+productID, update, token)
 		assertStatus(t, rr, http.StatusOK)
 
 		var product map[string]any
@@ -285,21 +369,21 @@ func TestProductsService_Update(t *testing.T) {
 			"name": "Ghost Product",
 		}
 
-		rr := makeRequest(t, server, "PATCH", "/products/999", update)
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/products/999", update, token)
 		assertStatus(t, rr, http.StatusNotFound)
 		assertErrorResponse(t, rr, "NOT_FOUND")
 	})
 }
 
 func TestProductsService_Delete(t *testing.T) {
-	server := setupTestServer(t)
+	server, _, token := setupTestServerWithAuth(t)
 
 	t.Run("should delete a product", func(t *testing.T) {
 		// Create a product to delete
 		productID := createTestProduct(t, server, "Delete Me", 50.00, 5)
 
 		// Delete the product
-		rr := makeRequest(t, server, "DELETE", "/products/"+productID, nil)
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/products/"+productID, nil, token)
 		assertStatus(t, rr, http.StatusNoContent)
 
 		// Verify product is deleted
@@ -308,12 +392,240 @@ func TestProductsService_Delete(t *testing.T) {
 	})
 
 	t.Run("should return 404 when deleting non-existent product", func(t *testing.T) {
-		rr := makeRequest(t, server, "DELETE", "/products/999", nil)
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/products/999", nil, token)
 		assertStatus(t, rr, http.StatusNotFound)
 		assertErrorResponse(t, rr, "NOT_FOUND")
 	})
 }
 
+func TestProductsService_OptimisticConcurrency(t *testing.T) {
+	server, _, token := setupTestServerWithAuth(t)
+	auth := "Bearer " + token
+
+	t.Run("should return a version ETag", func(t *testing.T) {
+		productID := createTestProduct(t, server, "Versioned Product", 10.00, 5)
+
+		rr := makeRequest(t, server, "GET", "/products/"+productID, nil)
+		assertStatus(t, rr, http.StatusOK)
+		assert.Equal(t, `"1"`, rr.Header().Get("ETag"))
+
+		var product map[string]any
+		require.NoError(t, decodeJSON(rr, &product))
+		assert.Equal(t, float64(1), product["version"])
+	})
+
+	t.Run("should update when If-Match matches and bump the version", func(t *testing.T) {
+		productID := createTestProduct(t, server, "Matching Product", 10.00, 5)
+
+		rr := makeRequestWithHeaders(t, server, "PATCH", "/products/"+productID, map[string]any{
+			"name": "Matched",
+		}, map[string]string{"If-Match": `"1"`, "Authorization": auth})
+		assertStatus(t, rr, http.StatusOK)
+		assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+
+		var product map[string]any
+		require.NoError(t, decodeJSON(rr, &product))
+		assert.Equal(t, float64(2), product["version"])
+	})
+
+	t.Run("should return 412 for a stale If-Match", func(t *testing.T) {
+		productID := createTestProduct(t, server, "Contended Product", 10.00, 5)
+
+		// Another admin updates the product first
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/products/"+productID, map[string]any{"price": 12.00}, token)
+		assertStatus(t, rr, http.StatusOK)
+
+		rr = makeRequestWithHeaders(t, server, "PATCH", "/products/"+productID, map[string]any{
+			"price": 15.00,
+		}, map[string]string{"If-Match": `"1"`, "Authorization": auth})
+		assertStatus(t, rr, http.StatusPreconditionFailed)
+		assertErrorResponse(t, rr, "CONFLICT")
+
+		rr = makeRequestWithHeaders(t, server, "DELETE", "/products/"+productID, nil, map[string]string{"If-Match": `"1"`, "Authorization": auth})
+		assertStatus(t, rr, http.StatusPreconditionFailed)
+		assertErrorResponse(t, rr, "CONFLICT")
+
+		// The first update is kept
+		getRR := makeRequest(t, server, "GET", "/products/"+productID, nil)
+		var product map[string]any
+		require.NoError(t, decodeJSON(getRR, &product))
+		assert.Equal(t, float64(12), product["price"])
+
+		rr = makeRequestWithHeaders(t, server, "DELETE", "/products/"+productID, nil, map[string]string{"If-Match": `"0", "2"`, "Authorization": auth})
+		assertStatus(t, rr, http.StatusNoContent)
+	})
+
+	t.Run("should return 412 for an If-Match from before an order changed the stock", func(t *testing.T) {
+		productID := createTestProduct(t, server, "Ordered Product", 10.00, 5)
+		getRR := makeRequest(t, server, "GET", "/products/"+productID, nil)
+		etag := getRR.Header().Get("ETag")
+
+		userID, userToken := createLoggedInUser(t, server, "etagorder@example.com", "ETag Order")
+		addToCartAuth(t, server, userID, productID, 2, userToken)
+		orderID := createOrderAuth(t, server, userID, userToken)
+
+		rr := makeRequestWithHeaders(t, server, "PATCH", "/products/"+productID, map[string]any{
+			"stock": 5,
+		}, map[string]string{"If-Match": etag, "Authorization": auth})
+		assertStatus(t, rr, http.StatusPreconditionFailed)
+
+		// Cancelling the order changes the stock, and the version, again
+		getRR = makeRequest(t, server, "GET", "/products/"+productID, nil)
+		etag = getRR.Header().Get("ETag")
+		rr = makeAuthenticatedRequest(t, server, "POST", "/orders/cancel/"+orderID, nil, userToken)
+		assertStatus(t, rr, http.StatusOK)
+
+		rr = makeRequestWithHeaders(t, server, "PATCH", "/products/"+productID, map[string]any{
+			"stock": 5,
+		}, map[string]string{"If-Match": etag, "Authorization": auth})
+		assertStatus(t, rr, http.StatusPreconditionFailed)
+	})
+
+	t.Run("should accept If-Match: *", func(t *testing.T) {
+		productID := createTestProduct(t, server, "Wildcard Product", 10.00, 5)
+
+		rr := makeRequestWithHeaders(t, server, "DELETE", "/products/"+productID, nil, map[string]string{"If-Match": "*", "Authorization": auth})
+		assertStatus(t, rr, http.StatusNoContent)
+	})
+
+	t.Run("should return 428 without If-Match when it is required", func(t *testing.T) {
+		strictServer, _, strictToken := setupTestServerWithAuth(t, handlers.WithRequireIfMatch())
+		strictAuth := "Bearer " + strictToken
+
+		rr := makeAuthenticatedRequest(t, strictServer, "PATCH", "/products/1", map[string]any{"name": "Unconditional"}, strictToken)
+		assertStatus(t, rr, http.StatusPreconditionRequired)
+
+		rr = makeRequestWithHeaders(t, strictServer, "PATCH", "/products/1", map[string]any{
+			"name": "Conditional",
+		}, map[string]string{"If-Match": `"1"`, "Authorization": strictAuth})
+		assertStatus(t, rr, http.StatusOK)
+	})
+}
+
+func TestProductsService_ConditionalGet(t *testing.T) {
+	server, userID, token := setupTestServerWithAuth(t)
+
+	t.Run("should return 304 when If-None-Match matches", func(t *testing.T) {
+		rr := makeRequest(t, server, "GET", "/products", nil)
+		assertStatus(t, rr, http.StatusOK)
+		etag := rr.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		rr = makeRequestWithHeaders(t, server, "GET", "/products", nil, map[string]string{"If-None-Match": etag})
+		assertStatus(t, rr, http.StatusNotModified)
+		assert.Equal(t, etag, rr.Header().Get("ETag"))
+		assert.Empty(t, rr.Body.String())
+
+		// Weak comparison ignores the W/ prefix
+		rr = makeRequestWithHeaders(t, server, "GET", "/products", nil, map[string]string{"If-None-Match": `"other", W/` + etag})
+		assertStatus(t, rr, http.StatusNotModified)
+	})
+
+	t.Run("should tag each page separately", func(t *testing.T) {
+		first := makeRequest(t, server, "GET", "/products?limit=2&offset=0", nil)
+		assertStatus(t, first, http.StatusOK)
+		second := makeRequest(t, server, "GET", "/products?limit=2&offset=2", nil)
+		assertStatus(t, second, http.StatusOK)
+		assert.NotEqual(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+
+		rr := makeRequestWithHeaders(t, server, "GET", "/products?limit=2&offset=2", nil, map[string]string{
+			"If-None-Match": second.Header().Get("ETag"),
+		})
+		assertStatus(t, rr, http.StatusNotModified)
+
+		rr = makeRequestWithHeaders(t, server, "GET", "/products?limit=2&offset=2", nil, map[string]string{
+			"If-None-Match": first.Header().Get("ETag"),
+		})
+		assertStatus(t, rr, http.StatusOK)
+	})
+
+	t.Run("should honor If-Modified-Since", func(t *testing.T) {
+		waitForNextSecond()
+		rr := makeRequest(t, server, "GET", "/products", nil)
+		lastModified := rr.Header().Get("Last-Modified")
+		modifiedAt, err := http.ParseTime(lastModified)
+		require.NoError(t, err)
+
+		rr = makeRequestWithHeaders(t, server, "GET", "/products", nil, map[string]string{"If-Modified-Since": lastModified})
+		assertStatus(t, rr, http.StatusNotModified)
+
+		earlier := modifiedAt.Add(-time.Second).Format(http.TimeFormat)
+		rr = makeRequestWithHeaders(t, server, "GET", "/products", nil, map[string]string{"If-Modified-Since": earlier})
+		assertStatus(t, rr, http.StatusOK)
+
+		// If-None-Match takes precedence over If-Modified-Since
+		rr = makeRequestWithHeaders(t, server, "GET", "/products", nil, map[string]string{
+			"If-None-Match":     `"stale"`,
+			"If-Modified-Since": lastModified,
+		})
+		assertStatus(t, rr, http.StatusOK)
+	})
+
+	t.Run("should leave out Last-Modified within the second of a change", func(t *testing.T) {
+		createTestProduct(t, server, "Fresh Product", 10.00, 5)
+
+		rr := makeRequest(t, server, "GET", "/products", nil)
+		assertStatus(t, rr, http.StatusOK)
+		assert.NotEmpty(t, rr.Header().Get("ETag"))
+		assert.Empty(t, rr.Header().Get("Last-Modified"))
+	})
+
+	t.Run("should count changes that leave no newer updatedAt", func(t *testing.T) {
+		productID := createTestProduct(t, server, "Changing Product", 10.00, 5)
+		categoryID := createTestCategory(t, server, "Changing Category", nil)
+
+		for _, tc := range []struct {
+			name   string
+			change func()
+		}{
+			{"a product is deleted", func() {
+				rr := makeAuthenticatedRequest(t, server, "DELETE", "/products/"+productID, nil, token)
+				assertStatus(t, rr, http.StatusNoContent)
+			}},
+			{"a category is deleted", func() {
+				rr := makeAuthenticatedRequest(t, server, "DELETE", "/categories/"+categoryID, nil, token)
+				assertStatus(t, rr, http.StatusNoContent)
+			}},
+			{"stock is reserved", func() {
+				rr := makeAuthenticatedRequest(t, server, "POST", "/carts/users/"+userID+"/items", map[string]any{"productId": "1", "quantity": 1}, token)
+				assertStatus(t, rr, http.StatusOK)
+			}},
+			{"a reservation is released", func() {
+				rr := makeAuthenticatedRequest(t, server, "DELETE", "/carts/users/"+userID+"/items/1", nil, token)
+				assertStatus(t, rr, http.StatusNoContent)
+			}},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				waitForNextSecond()
+				rr := makeRequest(t, server, "GET", "/products", nil)
+				lastModified := rr.Header().Get("Last-Modified")
+				require.NotEmpty(t, lastModified)
+
+				tc.change()
+				waitForNextSecond()
+
+				rr = makeRequestWithHeaders(t, server, "GET", "/products", nil, map[string]string{"If-Modified-Since": lastModified})
+				assertStatus(t, rr, http.StatusOK)
+				assert.NotEqual(t, lastModified, rr.Header().Get("Last-Modified"))
+			})
+		}
+	})
+
+	t.Run("should return 200 with a new ETag after an update", func(t *testing.T) {
+		productID := createTestProduct(t, server, "Polled Product", 10.00, 5)
+
+		rr := makeRequest(t, server, "GET", "/products", nil)
+		etag := rr.Header().Get("ETag")
+
+		rr = makeAuthenticatedRequest(t, server, "PATCH", "/products/"+productID, map[string]any{"price": 11.00}, token)
+		assertStatus(t, rr, http.StatusOK)
+
+		rr = makeRequestWithHeaders(t, server, "GET", "/products", nil, map[string]string{"If-None-Match": etag})
+		assertStatus(t, rr, http.StatusOK)
+		assert.NotEqual(t, etag, rr.Header().Get("ETag"))
+	})
+}
+
 func TestProductsService_Integration(t *testing.T) {
 	server := setupTestServer(t)
 
@@ -359,6 +671,8 @@ func TestProductsService_Integration(t *testing.T) {
 func createTestProductWithCategory(t *testing.T, server *TestServer, name string, price float64, stock int, categoryID string) string {
 	t.Helper()
 
+	token := loginTestUser(t, server, "alice@example.com", "password123")
+
 	product := map[string]any{
 		"name":        name,
 		"description": "Test product",
@@ -368,7 +682,7 @@ func createTestProductWithCategory(t *testing.T, server *TestServer, name string
 		"imageUrls":   []string{},
 	}
 
-	rr := makeRequest(t, server, "POST", "/products", product)
+	rr := makeAuthenticatedRequest(t, server, "POST", "/products", product, token)
 	require.Equal(t, http.StatusCreated, rr.Code, "failed to create test product")
 
 	var response map[string]any
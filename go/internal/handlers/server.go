@@ -4,22 +4,85 @@ import (
 	"net/http"
 
 	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/cursor"
+	"github.com/blck-snwmn/hello-typespec/go/internal/inventory"
+	"github.com/blck-snwmn/hello-typespec/go/internal/oidc"
+	"github.com/blck-snwmn/hello-typespec/go/internal/search"
 	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
 	"github.com/blck-snwmn/hello-typespec/go/internal/store"
 )
 
 // Server implements the generated.ServerInterface
 type Server struct {
-	store       store.Store
-	authHandler *AuthHandlers
+	store        store.Store
+	search       *search.Store
+	authHandler  *AuthHandlers
+	reservations *inventory.Reservations
+	cursors      *cursor.Signer
+	// requireIfMatch rejects PATCH and DELETE requests without If-Match
+	requireIfMatch bool
 }
 
-// NewServer creates a new Server instance
-func NewServer(store store.Store, authStore *storage.AuthStore) *Server {
-	return &Server{
-		store:       store,
-		authHandler: NewAuthHandlers(authStore),
+// ServerOption configures optional Server dependencies
+type ServerOption func(*Server)
+
+// WithReservations sets the cart reservations, e.g. to configure their TTL.
+// By default reservations last for inventory.DefaultTTL.
+func WithReservations(reservations *inventory.Reservations) ServerOption {
+	return func(s *Server) {
+		s.reservations = reservations
+	}
+}
+
+// WithRequireIfMatch makes If-Match mandatory on PATCH and DELETE of
+// products, categories and users instead of only honoring it when sent
+func WithRequireIfMatch() ServerOption {
+	return func(s *Server) {
+		s.requireIfMatch = true
+	}
+}
+
+// WithCursorSecret sets the secret list cursors are signed with, so that
+// they stay valid across restarts and instances. By default it is random.
+func WithCursorSecret(secret []byte) ServerOption {
+	return func(s *Server) {
+		s.cursors = cursor.NewSigner(secret)
+	}
+}
+
+// WithPasswordResetSender sets how password reset tokens reach users.
+// Without it the password reset endpoints respond 404.
+func WithPasswordResetSender(sender PasswordResetSender) ServerOption {
+	return func(s *Server) {
+		s.authHandler.resetSender = sender
+	}
+}
+
+// WithOIDC enables logging in with the OpenID Connect provider of client.
+// Without it the OIDC endpoints respond 404.
+func WithOIDC(client *oidc.Client) ServerOption {
+	return func(s *Server) {
+		s.authHandler.oidc = client
+	}
+}
+
+// NewServer creates a new Server instance. Products are searched in an index
+// kept in memory, so all product writes must go through the Server, and it
+// must be the only server of a store shared with other processes; see
+// store.Claimer.
+func NewServer(store store.Store, authStore *storage.AuthStore, opts ...ServerOption) *Server {
+	products := search.NewStore(store)
+	s := &Server{
+		store:        products,
+		search:       products,
+		authHandler:  NewAuthHandlers(store, authStore),
+		reservations: inventory.NewReservations(inventory.DefaultTTL, nil),
+		cursors:      cursor.NewRandomSigner(),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // AuthServiceLogin handles user login
@@ -37,5 +100,70 @@ func (s *Server) AuthServiceGetCurrentUser(w http.ResponseWriter, r *http.Reques
 	s.authHandler.GetCurrentUser(w, r)
 }
 
+// AuthServiceRegister registers a new user
+func (s *Server) AuthServiceRegister(w http.ResponseWriter, r *http.Request) {
+	s.authHandler.Register(w, r)
+}
+
+// AuthServiceChangePassword changes the current user's password
+func (s *Server) AuthServiceChangePassword(w http.ResponseWriter, r *http.Request) {
+	s.authHandler.ChangePassword(w, r)
+}
+
+// AuthServiceRequestPasswordReset issues a password reset token
+func (s *Server) AuthServiceRequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	s.authHandler.RequestPasswordReset(w, r)
+}
+
+// AuthServiceConfirmPasswordReset sets a new password with a reset token
+func (s *Server) AuthServiceConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	s.authHandler.ConfirmPasswordReset(w, r)
+}
+
+// AuthServiceRefresh exchanges a refresh token for new tokens
+func (s *Server) AuthServiceRefresh(w http.ResponseWriter, r *http.Request) {
+	s.authHandler.Refresh(w, r)
+}
+
+// AuthServiceListSessions lists the current user's sessions
+func (s *Server) AuthServiceListSessions(w http.ResponseWriter, r *http.Request) {
+	s.authHandler.ListSessions(w, r)
+}
+
+// AuthServiceRevokeSession ends one of the current user's sessions
+func (s *Server) AuthServiceRevokeSession(w http.ResponseWriter, r *http.Request, sessionId string) {
+	s.authHandler.RevokeSession(w, r, sessionId)
+}
+
+// AuthServiceCreateApiKey creates an API key for the current user
+func (s *Server) AuthServiceCreateApiKey(w http.ResponseWriter, r *http.Request) {
+	s.authHandler.CreateAPIKey(w, r)
+}
+
+// AuthServiceListApiKeys lists the current user's API keys
+func (s *Server) AuthServiceListApiKeys(w http.ResponseWriter, r *http.Request) {
+	s.authHandler.ListAPIKeys(w, r)
+}
+
+// AuthServiceRevokeApiKey revokes one of the current user's API keys
+func (s *Server) AuthServiceRevokeApiKey(w http.ResponseWriter, r *http.Request, keyId string) {
+	s.authHandler.RevokeAPIKey(w, r, keyId)
+}
+
+// AuthServiceOidcLogin redirects to the OpenID Connect provider
+func (s *Server) AuthServiceOidcLogin(w http.ResponseWriter, r *http.Request) {
+	s.authHandler.OIDCLogin(w, r)
+}
+
+// AuthServiceOidcLink starts linking an OpenID Connect account to the current user
+func (s *Server) AuthServiceOidcLink(w http.ResponseWriter, r *http.Request) {
+	s.authHandler.OIDCLink(w, r)
+}
+
+// AuthServiceOidcCallback completes an OpenID Connect login
+func (s *Server) AuthServiceOidcCallback(w http.ResponseWriter, r *http.Request, params generated.AuthServiceOidcCallbackParams) {
+	s.authHandler.OIDCCallback(w, r, params)
+}
+
 // Ensure Server implements generated.ServerInterface
 var _ generated.ServerInterface = (*Server)(nil)
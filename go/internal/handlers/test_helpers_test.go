@@ -2,10 +2,15 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/blck-snwmn/hello-typespec/go/generated"
 	authctx "github.com/blck-snwmn/hello-typespec/go/internal/auth"
@@ -25,17 +30,64 @@ type TestServer struct {
 	store       store.Store
 }
 
-// setupTestServer creates a test server with a memory store
-func setupTestServer(t testing.TB) *TestServer {
+// testStores creates a fresh store per backend name. Backends that need
+// external services register themselves from files behind build tags.
+var testStores = map[string]func(t testing.TB) store.Store{
+	"memory": func(t testing.TB) store.Store {
+		return store.NewMemoryStore()
+	},
+	"sqlite": func(t testing.TB) store.Store {
+		sqliteStore, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { sqliteStore.Close() })
+		require.NoError(t, sqliteStore.MigrateUp(context.Background()))
+		return sqliteStore
+	},
+}
+
+// newTestStore creates the store backend selected by the STORE_DRIVER environment variable,
+// so the whole suite can be run against every backend (e.g. STORE_DRIVER=sqlite go test ./...).
+// It holds the demo data, with alice as an admin.
+func newTestStore(t testing.TB) store.Store {
+	t.Helper()
+
+	driver := os.Getenv("STORE_DRIVER")
+	if driver == "" {
+		driver = "memory"
+	}
+	newStore, ok := testStores[driver]
+	if !ok {
+		t.Fatalf("unknown STORE_DRIVER %q (backends behind build tags need e.g. -tags postgres)", driver)
+	}
+	dataStore := newStore(t)
+	require.NoError(t, store.SeedDemoData(t.Context(), dataStore))
+	require.NoError(t, storage.NewAuthStore(dataStore).GrantRole(t.Context(), "alice@example.com", storage.RoleAdmin))
+	return dataStore
+}
+
+// setupTestServer creates a test server with the selected store backend
+func setupTestServer(t testing.TB, opts ...handlers.ServerOption) *TestServer {
+	t.Helper()
+
+	dataStore := newTestStore(t)
+	return serveTestServer(t, dataStore, storage.NewAuthStore(dataStore), opts...)
+}
+
+// serveTestServer serves dataStore with authStorage, for tests that need
+// to configure authentication
+func serveTestServer(t testing.TB, dataStore store.Store, authStorage *storage.AuthStore, opts ...handlers.ServerOption) *TestServer {
 	t.Helper()
 
-	memStore := store.NewMemoryStore()
-	authStorage := storage.NewAuthStore()
-	server := handlers.NewServer(memStore, authStorage)
+	server := handlers.NewServer(dataStore, authStorage, opts...)
 
 	// Create handler with auth middleware applied to protected routes
 	authMiddleware := middleware.AuthMiddleware(authStorage)
-	handler := handlers.CreateHandlerWithMiddleware(server, authMiddleware)
+	// Every response must match the OpenAPI document
+	handler, err := handlers.CreateHandlerWithMiddleware(server, authMiddleware,
+		middleware.WithResponseValidation(func(r *http.Request, err error) {
+			t.Errorf("response to %s %s does not match the spec: %+v", r.Method, r.URL, err)
+		}))
+	require.NoError(t, err)
 
 	ts := httptest.NewServer(handler)
 	t.Cleanup(ts.Close)
@@ -44,7 +96,7 @@ func setupTestServer(t testing.TB) *TestServer {
 		Server:      ts,
 		handler:     handler,
 		authStorage: authStorage,
-		store:       memStore,
+		store:       dataStore,
 	}
 }
 
@@ -72,6 +124,31 @@ func makeRequest(t testing.TB, server *TestServer, method, path string, body any
 	return rr
 }
 
+// makeRequestWithHeaders is like makeRequest but sets additional request headers
+func makeRequestWithHeaders(t testing.TB, server *TestServer, method, path string, body any, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var bodyReader io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		require.NoError(t, err)
+		bodyReader = bytes.NewReader(jsonBody)
+	}
+	req, err := http.NewRequest(method, path, bodyReader)
+	require.NoError(t, err)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	rr := httptest.NewRecorder()
+	server.handler.ServeHTTP(rr, req)
+
+	return rr
+}
+
 // assertStatus checks if the response has the expected status code
 func assertStatus(t testing.TB, rr *httptest.ResponseRecorder, expectedStatus int) {
 	t.Helper()
@@ -93,6 +170,26 @@ func assertErrorResponse(t testing.TB, rr *httptest.ResponseRecorder, expectedCo
 	assert.NotEmpty(t, errorObj["message"], "error message should not be empty")
 }
 
+// assertValidationErrors checks that the response is a VALIDATION_ERROR
+// listing exactly the given fields in its details, and returns the details
+func assertValidationErrors(t testing.TB, rr *httptest.ResponseRecorder, expectedFields ...string) []generated.FieldError {
+	t.Helper()
+
+	var errorResp generated.ErrorResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&errorResp))
+	assert.Equal(t, generated.VALIDATIONERROR, errorResp.Error.Code, "unexpected error code")
+	require.NotNil(t, errorResp.Error.Details, "validation errors should have details")
+
+	var fields []string
+	for _, detail := range *errorResp.Error.Details {
+		assert.NotEmpty(t, detail.Rule, "field error rule should not be empty")
+		assert.NotEmpty(t, detail.Message, "field error message should not be empty")
+		fields = append(fields, detail.Field)
+	}
+	assert.ElementsMatch(t, expectedFields, fields, "unexpected invalid fields")
+	return *errorResp.Error.Details
+}
+
 // assertPaginatedResponse checks pagination response format
 func assertPaginatedResponse(t testing.TB, rr *httptest.ResponseRecorder, expectedTotal, expectedLimit, expectedOffset int) map[string]any {
 	t.Helper()
@@ -142,7 +239,7 @@ func makeAuthenticatedRequest(t testing.TB, server *TestServer, method, path str
 
 	// For /auth/me endpoint, we need to simulate the middleware behavior
 	if path == "/auth/me" && token != "" {
-		user, err := server.authStorage.ValidateToken(token)
+		user, err := server.authStorage.ValidateToken(req.Context(), token)
 		if err == nil && user != nil {
 			ctx := authctx.WithUser(req.Context(), user)
 			req = req.WithContext(ctx)
@@ -175,10 +272,10 @@ func loginTestUser(t testing.TB, server *TestServer, email, password string) str
 }
 
 // setupTestServerWithAuth creates a test server and logs in a default user
-func setupTestServerWithAuth(t testing.TB) (*TestServer, string, string) {
+func setupTestServerWithAuth(t testing.TB, opts ...handlers.ServerOption) (*TestServer, string, string) {
 	t.Helper()
 
-	server := setupTestServer(t)
+	server := setupTestServer(t, opts...)
 	token := loginTestUser(t, server, "alice@example.com", "password123")
 
 	return server, "550e8400-e29b-41d4-a716-446655440001", token
@@ -218,6 +315,32 @@ func createTestUser(t testing.TB, server *TestServer, email, name string) string
 	return id
 }
 
+// createLoggedInUser creates a test user with a password and returns its ID
+// and an access token for it
+func createLoggedInUser(t testing.TB, server *TestServer, email, name string) (string, string) {
+	t.Helper()
+
+	token := loginTestUser(t, server, "alice@example.com", "password123")
+
+	user := map[string]any{
+		"email":    email,
+		"name":     name,
+		"password": "password123",
+	}
+
+	rr := makeAuthenticatedRequest(t, server, "POST", "/users", user, token)
+	require.Equal(t, http.StatusCreated, rr.Code, "failed to create test user")
+
+	var response map[string]any
+	err := json.NewDecoder(rr.Body).Decode(&response)
+	require.NoError(t, err)
+
+	id, ok := response["id"].(string)
+	require.True(t, ok, "response should contain id as string")
+
+	return id, loginTestUser(t, server, email, "password123")
+}
+
 // createTestProduct creates a test product and returns its ID
 func createTestProduct(t testing.TB, server *TestServer, name string, price float64, stock int) string {
 	t.Helper()
@@ -248,15 +371,21 @@ func createTestProduct(t testing.TB, server *TestServer, name string, price floa
 }
 
 // createTestCategory creates a test category and returns its ID
+// waitForNextSecond sleeps until the current second is over, after which
+// the changes made so far are old enough to be sent as Last-Modified
+func waitForNextSecond() {
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+}
+
 func createTestCategory(t testing.TB, server *TestServer, name string, parentID *string) string {
 	t.Helper()
 
 	// Get a token for creating categories
 	token := loginTestUser(t, server, "alice@example.com", "password123")
 
-	category := map[string]any{
-		"name":     name,
-		"parentId": parentID,
+	category := map[string]any{"name": name}
+	if parentID != nil {
+		category["parentId"] = *parentID
 	}
 
 	rr := makeAuthenticatedRequest(t, server, "POST", "/categories", category, token)
@@ -1,19 +1,21 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/blck-snwmn/hello-typespec/go/internal/validation"
 )
 
 // UsersServiceList implements GET /users
 func (s *Server) UsersServiceList(w http.ResponseWriter, r *http.Request, params generated.UsersServiceListParams) {
-	// Get all users
-	allUsers := s.store.GetUsers()
-
 	// Apply pagination
 	limit := int32(20) // Default from TypeSpec definition
 	if params.Limit != nil {
@@ -25,30 +27,34 @@ func (s *Server) UsersServiceList(w http.ResponseWriter, r *http.Request, params
 		offset = *params.Offset
 	}
 
-	total := int32(len(allUsers))
-	start := int(offset)
-	end := int(offset + limit)
-
-	if start > len(allUsers) {
-		start = len(allUsers)
-	}
-	if end > len(allUsers) {
-		end = len(allUsers)
+	// Oldest users first
+	page, err := paginate(s.cursors, listOrder[generated.User, time.Time]{
+		name: orderName("users", string(store.SortByCreatedAt), false),
+		key:  func(user generated.User) time.Time { return user.CreatedAt },
+		id:   func(user generated.User) string { return user.Id },
+	}, limit, offset, params.Cursor, func(page store.Page) (store.Result[generated.User], error) {
+		return s.store.QueryUsers(r.Context(), store.UserQuery{Sort: store.Sort{Field: store.SortByCreatedAt}, Page: page})
+	})
+	if err != nil {
+		txErrorResponse(w, err, "User")
+		return
 	}
 
-	paginatedUsers := allUsers[start:end]
-
 	// Create response
 	response := struct {
-		Items  []generated.User `json:"items"`
-		Total  int32            `json:"total"`
-		Limit  int32            `json:"limit"`
-		Offset int32            `json:"offset"`
+		Items      []generated.User `json:"items"`
+		Total      int32            `json:"total"`
+		Limit      int32            `json:"limit"`
+		Offset     int32            `json:"offset"`
+		NextCursor *string          `json:"nextCursor,omitempty"`
+		PrevCursor *string          `json:"prevCursor,omitempty"`
 	}{
-		Items:  paginatedUsers,
-		Total:  total,
-		Limit:  limit,
-		Offset: offset,
+		Items:      page.items,
+		Total:      page.total,
+		Limit:      limit,
+		Offset:     page.offset,
+		NextCursor: page.nextCursor,
+		PrevCursor: page.prevCursor,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -57,12 +63,17 @@ func (s *Server) UsersServiceList(w http.ResponseWriter, r *http.Request, params
 
 // UsersServiceGet implements GET /users/{userId}
 func (s *Server) UsersServiceGet(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
-	user, ok := s.store.GetUser(userId)
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "User not found")
+	if !requireOwnerOrAdmin(w, r, userId) {
+		return
+	}
+
+	user, err := s.store.GetUser(r.Context(), userId)
+	if err != nil {
+		storeErrorResponse(w, err, "User")
 		return
 	}
 
+	setVersionETag(w, user.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
 }
@@ -75,28 +86,40 @@ func (s *Server) UsersServiceCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create new user
-	now := time.Now()
-	newUser := generated.User{
-		Id:        fmt.Sprintf("%d", now.UnixNano()),
-		Email:     req.Email,
-		Name:      req.Name,
-		Address:   req.Address,
-		CreatedAt: now,
-		UpdatedAt: now,
+	var errs validation.Errors
+	errs.Email("/email", string(req.Email))
+	errs.NotBlank("/name", req.Name)
+	if req.Password != nil {
+		checkPassword(&errs, "/password", *req.Password)
+	}
+	if len(errs) > 0 {
+		validationErrorResponse(w, errs)
+		return
 	}
 
-	created := s.store.CreateUser(newUser)
+	// Users created without a password set one through a password reset
+	var passwordHash string
+	if req.Password != nil {
+		hash, err := storage.HashPassword(*req.Password)
+		if err != nil {
+			storeErrorResponse(w, err, "User")
+			return
+		}
+		passwordHash = hash
+	}
 
-	// Initialize empty cart for new user
-	s.store.UpdateCart(created.Id, generated.Cart{
-		Id:        fmt.Sprintf("cart-%s", created.Id),
-		UserId:    created.Id,
-		Items:     []generated.CartItem{},
-		CreatedAt: now,
-		UpdatedAt: now,
+	var created generated.User
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		var err error
+		created, err = createUser(r.Context(), tx, req, passwordHash)
+		return err
 	})
+	if err != nil {
+		txErrorResponse(w, err, "User")
+		return
+	}
 
+	setVersionETag(w, created.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(created)
@@ -104,9 +127,7 @@ func (s *Server) UsersServiceCreate(w http.ResponseWriter, r *http.Request) {
 
 // UsersServiceUpdate implements PATCH /users/{userId}
 func (s *Server) UsersServiceUpdate(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
-	existing, ok := s.store.GetUser(userId)
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "User not found")
+	if !requireOwnerOrAdmin(w, r, userId) {
 		return
 	}
 
@@ -115,33 +136,172 @@ func (s *Server) UsersServiceUpdate(w http.ResponseWriter, r *http.Request, user
 		errorResponse(w, http.StatusBadRequest, ErrorCodeBadRequest, "Invalid request body")
 		return
 	}
-
-	// Update fields if provided
-	updatedUser := *existing
+	var errs validation.Errors
 	if req.Email != nil {
-		updatedUser.Email = *req.Email
+		errs.Email("/email", string(*req.Email))
 	}
 	if req.Name != nil {
-		updatedUser.Name = *req.Name
+		errs.NotBlank("/name", *req.Name)
 	}
-	if req.Address != nil {
-		updatedUser.Address = req.Address
+	if len(errs) > 0 {
+		validationErrorResponse(w, errs)
+		return
 	}
-	updatedUser.UpdatedAt = time.Now()
 
-	updated := s.store.UpdateUser(userId, updatedUser)
+	// Check the version and write in one transaction so concurrent edits cannot clobber each other
+	var updated generated.User
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		existing, err := tx.GetUser(r.Context(), userId)
+		if err != nil {
+			return err
+		}
+		if err := s.checkIfMatch(r, existing.Version); err != nil {
+			return err
+		}
+
+		// Update fields if provided
+		updatedUser := existing
+		if req.Email != nil {
+			updatedUser.Email = storage.NormalizeEmail(string(*req.Email))
+		}
+		if req.Name != nil {
+			updatedUser.Name = *req.Name
+		}
+		if req.Address != nil {
+			updatedUser.Address = req.Address
+		}
+		updatedUser.UpdatedAt = time.Now()
+		updatedUser.Version = nextVersion(existing.Version)
+
+		updated, err = tx.UpdateUser(r.Context(), userId, updatedUser)
+		if err != nil {
+			return err
+		}
+		if updated.Email != existing.Email {
+			return updateCredentialEmail(r.Context(), tx, userId, updated.Email, updated.UpdatedAt)
+		}
+		return nil
+	})
+	if err != nil {
+		txErrorResponse(w, err, "User")
+		return
+	}
 
+	setVersionETag(w, updated.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updated)
 }
 
 // UsersServiceDelete implements DELETE /users/{userId}
 func (s *Server) UsersServiceDelete(w http.ResponseWriter, r *http.Request, userId generated.Uuid) {
-	_, ok := s.store.DeleteUser(userId)
-	if !ok {
-		errorResponse(w, http.StatusNotFound, ErrorCodeNotFound, "User not found")
+	if !requireOwnerOrAdmin(w, r, userId) {
+		return
+	}
+
+	err := s.store.WithTx(r.Context(), func(tx store.Tx) error {
+		existing, err := tx.GetUser(r.Context(), userId)
+		if err != nil {
+			return err
+		}
+		if err := s.checkIfMatch(r, existing.Version); err != nil {
+			return err
+		}
+		if _, err := tx.DeleteUser(r.Context(), userId); err != nil {
+			return err
+		}
+		if _, err := tx.DeleteCredential(r.Context(), userId); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+		keys, err := tx.GetAPIKeys(r.Context(), userId)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if _, err := tx.DeleteAPIKey(r.Context(), key.Id); err != nil {
+				return err
+			}
+		}
+
+		// A deleted user's sessions must not keep acting on its behalf
+		sessions, err := tx.GetSessionsByUserId(r.Context(), userId)
+		if err != nil {
+			return err
+		}
+		for _, session := range sessions {
+			if _, err := tx.DeleteSession(r.Context(), session.Id); err != nil {
+				return err
+			}
+		}
+		return tx.DeleteIdentitiesByUserId(r.Context(), userId)
+	})
+	if err != nil {
+		txErrorResponse(w, err, "User")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// createUser creates a user together with its empty cart and the credential
+// it logs in with. An empty passwordHash leaves the password unset.
+func createUser(ctx context.Context, tx store.Tx, req generated.CreateUserRequest, passwordHash string) (generated.User, error) {
+	now := time.Now()
+	created, err := tx.CreateUser(ctx, generated.User{
+		Id:        fmt.Sprintf("%d", now.UnixNano()),
+		Email:     storage.NormalizeEmail(string(req.Email)),
+		Name:      req.Name,
+		Address:   req.Address,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version:   initialVersion(),
+	})
+	if err != nil {
+		return generated.User{}, err
+	}
+
+	_, err = tx.CreateCredential(ctx, store.Credential{
+		UserId:       created.Id,
+		Email:        created.Email,
+		PasswordHash: passwordHash,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+	if errors.Is(err, store.ErrConflict) {
+		return generated.User{}, &apiError{http.StatusConflict, ErrorCodeConflict, "Email is already registered"}
+	}
+	if err != nil {
+		return generated.User{}, err
+	}
+
+	// Initialize empty cart for new user
+	_, err = tx.UpdateCart(ctx, created.Id, generated.Cart{
+		Id:        fmt.Sprintf("cart-%s", created.Id),
+		UserId:    created.Id,
+		Items:     []generated.CartItem{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return generated.User{}, err
+	}
+	return created, nil
+}
+
+// updateCredentialEmail keeps the login email of userId in step with the
+// user's email. Users without a credential are left alone.
+func updateCredentialEmail(ctx context.Context, tx store.Tx, userId, email string, now time.Time) error {
+	credential, err := tx.GetCredential(ctx, userId)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	credential.Email = email
+	credential.UpdatedAt = now
+	_, err = tx.UpdateCredential(ctx, userId, credential)
+	if errors.Is(err, store.ErrConflict) {
+		return &apiError{http.StatusConflict, ErrorCodeConflict, "Email is already registered"}
+	}
+	return err
+}
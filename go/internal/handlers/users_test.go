@@ -1,9 +1,11 @@
 package handlers_test
 
 import (
+	"context"
 	"net/http"
 	"testing"
 
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -15,10 +17,10 @@ func TestUsersService_List(t *testing.T) {
 		rr := makeAuthenticatedRequest(t, server, "GET", "/users", nil, token)
 
 		assertStatus(t, rr, http.StatusOK)
-		response := assertPaginatedResponse(t, rr, 2, 20, 0) // 2 default users
+		response := assertPaginatedResponse(t, rr, 4, 20, 0) // 2 default users and 2 demo accounts
 
 		items := response["items"].([]any)
-		assert.Len(t, items, 2)
+		assert.Len(t, items, 4)
 
 		// Check first user structure
 		user := items[0].(map[string]any)
@@ -34,14 +36,14 @@ func TestUsersService_List(t *testing.T) {
 		// First page
 		rr1 := makeAuthenticatedRequest(t, server, "GET", "/users?limit=1&offset=0", nil, token)
 		assertStatus(t, rr1, http.StatusOK)
-		response1 := assertPaginatedResponse(t, rr1, 2, 1, 0)
+		response1 := assertPaginatedResponse(t, rr1, 4, 1, 0)
 		items1 := response1["items"].([]any)
 		assert.Len(t, items1, 1)
 
 		// Second page
 		rr2 := makeAuthenticatedRequest(t, server, "GET", "/users?limit=1&offset=1", nil, token)
 		assertStatus(t, rr2, http.StatusOK)
-		response2 := assertPaginatedResponse(t, rr2, 2, 1, 1)
+		response2 := assertPaginatedResponse(t, rr2, 4, 1, 1)
 		items2 := response2["items"].([]any)
 		assert.Len(t, items2, 1)
 
@@ -54,7 +56,7 @@ func TestUsersService_List(t *testing.T) {
 	t.Run("should handle empty results with offset beyond total", func(t *testing.T) {
 		rr := makeAuthenticatedRequest(t, server, "GET", "/users?limit=10&offset=100", nil, token)
 		assertStatus(t, rr, http.StatusOK)
-		response := assertPaginatedResponse(t, rr, 2, 10, 100)
+		response := assertPaginatedResponse(t, rr, 4, 10, 100)
 		items := response["items"].([]any)
 		assert.Len(t, items, 0)
 	})
@@ -67,24 +69,39 @@ func TestUsersService_List(t *testing.T) {
 }
 
 func TestUsersService_Get(t *testing.T) {
-	server, _, token := setupTestServerWithAuth(t)
+	server, userID, token := setupTestServerWithAuth(t)
 
 	t.Run("should return a user by id", func(t *testing.T) {
-		rr := makeAuthenticatedRequest(t, server, "GET", "/users/1", nil, token)
+		rr := makeAuthenticatedRequest(t, server, "GET", "/users/"+userID, nil, token)
 		assertStatus(t, rr, http.StatusOK)
 
 		var user map[string]any
 		err := decodeJSON(rr, &user)
 		require.NoError(t, err)
 
-		assert.Equal(t, "1", user["id"])
-		assert.Equal(t, "user1@example.com", user["email"])
-		assert.Equal(t, "Test User 1", user["name"])
-		assert.NotNil(t, user["address"])
+		assert.Equal(t, userID, user["id"])
+		assert.Equal(t, "alice@example.com", user["email"])
+		assert.Equal(t, "Alice Johnson", user["name"])
 	})
 
-	t.Run("should return 404 for non-existent user", func(t *testing.T) {
-		rr := makeAuthenticatedRequest(t, server, "GET", "/users/999", nil, token)
+	t.Run("should return 403 for another user", func(t *testing.T) {
+		_, otherToken := createLoggedInUser(t, server, "getother@example.com", "Get Other")
+
+		rr := makeAuthenticatedRequest(t, server, "GET", "/users/1", nil, otherToken)
+		assertStatus(t, rr, http.StatusForbidden)
+		assertErrorResponse(t, rr, "FORBIDDEN")
+
+		// Whether or not the user exists
+		rr = makeAuthenticatedRequest(t, server, "GET", "/users/999", nil, otherToken)
+		assertStatus(t, rr, http.StatusForbidden)
+		assertErrorResponse(t, rr, "FORBIDDEN")
+	})
+
+	t.Run("should return any user to admins", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "GET", "/users/1", nil, token)
+		assertStatus(t, rr, http.StatusOK)
+
+		rr = makeAuthenticatedRequest(t, server, "GET", "/users/999", nil, token)
 		assertStatus(t, rr, http.StatusNotFound)
 		assertErrorResponse(t, rr, "NOT_FOUND")
 	})
@@ -101,8 +118,9 @@ func TestUsersService_Create(t *testing.T) {
 
 	t.Run("should create a new user with address", func(t *testing.T) {
 		newUser := map[string]any{
-			"email": "newuser@example.com",
-			"name":  "New User",
+			"email":    "newuser@example.com",
+			"name":     "New User",
+			"password": "password123",
 			"address": map[string]any{
 				"street":     "789 New St",
 				"city":       "New City",
@@ -128,7 +146,8 @@ func TestUsersService_Create(t *testing.T) {
 
 		// Verify cart was created for new user
 		userID := user["id"].(string)
-		cartRR := makeAuthenticatedRequest(t, server, "GET", "/carts/users/"+userID, nil, token)
+		userToken := loginTestUser(t, server, "newuser@example.com", "password123")
+		cartRR := makeAuthenticatedRequest(t, server, "GET", "/carts/users/"+userID, nil, userToken)
 		assertStatus(t, cartRR, http.StatusOK)
 
 		var cart map[string]any
@@ -168,37 +187,36 @@ func TestUsersService_Create(t *testing.T) {
 		assertErrorResponse(t, rr, "UNAUTHORIZED")
 	})
 
-	// TODO: Implement validation in Go handlers
-	// t.Run("should return 400 for invalid user data", func(t *testing.T) {
-	// 	invalidUser := map[string]any{
-	// 		"email": "", // Empty email
-	// 		"name":  "Invalid User",
-	// 	}
-
-	// 	rr := makeAuthenticatedRequest(t, server, "POST", "/users", invalidUser, token)
-	// 	assertStatus(t, rr, http.StatusBadRequest)
-	// 	assertErrorResponse(t, rr, "VALIDATION_ERROR")
-	// })
+	t.Run("should return 400 for invalid user data", func(t *testing.T) {
+		invalidUser := map[string]any{
+			"email": "", // Empty email
+			"name":  "Invalid User",
+		}
+
+		rr := makeAuthenticatedRequest(t, server, "POST", "/users", invalidUser, token)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertValidationErrors(t, rr, "/email")
+	})
 }
 
 func TestUsersService_Update(t *testing.T) {
-	server, _, token := setupTestServerWithAuth(t)
+	server, userID, token := setupTestServerWithAuth(t)
 
 	t.Run("should update user details", func(t *testing.T) {
 		update := map[string]any{
 			"name": "Updated Name",
 		}
 
-		rr := makeAuthenticatedRequest(t, server, "PATCH", "/users/1", update, token)
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/users/"+userID, update, token)
 		assertStatus(t, rr, http.StatusOK)
 
 		var user map[string]any
 		err := decodeJSON(rr, &user)
 		require.NoError(t, err)
 
-		assert.Equal(t, "1", user["id"])
+		assert.Equal(t, userID, user["id"])
 		assert.Equal(t, "Updated Name", user["name"])
-		assert.Equal(t, "user1@example.com", user["email"]) // Email unchanged
+		assert.Equal(t, "alice@example.com", user["email"]) // Email unchanged
 	})
 
 	t.Run("should update user address", func(t *testing.T) {
@@ -212,7 +230,7 @@ func TestUsersService_Update(t *testing.T) {
 			},
 		}
 
-		rr := makeAuthenticatedRequest(t, server, "PATCH", "/users/1", update, token)
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/users/"+userID, update, token)
 		assertStatus(t, rr, http.StatusOK)
 
 		var user map[string]any
@@ -224,14 +242,28 @@ func TestUsersService_Update(t *testing.T) {
 		assert.Equal(t, "Updated City", address["city"])
 	})
 
-	t.Run("should return 404 when updating non-existent user", func(t *testing.T) {
+	t.Run("should return 403 when updating another user", func(t *testing.T) {
 		update := map[string]any{
-			"name": "Ghost User",
+			"name": "Hijacked User",
 		}
 
-		rr := makeAuthenticatedRequest(t, server, "PATCH", "/users/999", update, token)
-		assertStatus(t, rr, http.StatusNotFound)
-		assertErrorResponse(t, rr, "NOT_FOUND")
+		_, otherToken := createLoggedInUser(t, server, "updateother@example.com", "Update Other")
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/users/1", update, otherToken)
+		assertStatus(t, rr, http.StatusForbidden)
+		assertErrorResponse(t, rr, "FORBIDDEN")
+
+		user, err := server.store.GetUser(context.Background(), "1")
+		require.NoError(t, err)
+		assert.Equal(t, "Test User 1", user.Name)
+	})
+
+	t.Run("should let admins update any user", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/users/1", map[string]any{"name": "Renamed By Admin"}, token)
+		assertStatus(t, rr, http.StatusOK)
+
+		user, err := server.store.GetUser(context.Background(), "1")
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed By Admin", user.Name)
 	})
 
 	t.Run("should return 401 without authentication", func(t *testing.T) {
@@ -245,15 +277,46 @@ func TestUsersService_Update(t *testing.T) {
 	})
 }
 
+func TestUsersService_OptimisticConcurrency(t *testing.T) {
+	server := setupTestServer(t)
+
+	t.Run("should reject updates based on a stale version", func(t *testing.T) {
+		userID, token := createLoggedInUser(t, server, "versioned@example.com", "Versioned User")
+		auth := "Bearer " + token
+
+		getRR := makeAuthenticatedRequest(t, server, "GET", "/users/"+userID, nil, token)
+		assertStatus(t, getRR, http.StatusOK)
+		etag := getRR.Header().Get("ETag")
+		assert.Equal(t, `"1"`, etag)
+
+		rr := makeRequestWithHeaders(t, server, "PATCH", "/users/"+userID, map[string]any{
+			"name": "First Edit",
+		}, map[string]string{"If-Match": etag, "Authorization": auth})
+		assertStatus(t, rr, http.StatusOK)
+
+		rr = makeRequestWithHeaders(t, server, "PATCH", "/users/"+userID, map[string]any{
+			"name": "Second Edit",
+		}, map[string]string{"If-Match": etag, "Authorization": auth})
+		assertStatus(t, rr, http.StatusPreconditionFailed)
+		assertErrorResponse(t, rr, "CONFLICT")
+
+		rr = makeRequestWithHeaders(t, server, "DELETE", "/users/"+userID, nil, map[string]string{
+			"If-Match":      `"2"`,
+			"Authorization": auth,
+		})
+		assertStatus(t, rr, http.StatusNoContent)
+	})
+}
+
 func TestUsersService_Delete(t *testing.T) {
 	server, _, token := setupTestServerWithAuth(t)
 
 	t.Run("should delete a user", func(t *testing.T) {
-		// Create a new user to delete (note: createTestUser doesn't use auth)
-		// We'll create using authenticated request instead
+		// Users can only delete themselves, so create one that can log in
 		newUser := map[string]any{
-			"email": "delete@example.com",
-			"name":  "Delete Me",
+			"email":    "delete@example.com",
+			"name":     "Delete Me",
+			"password": "password123",
 			"address": map[string]any{
 				"street":     "123 Test St",
 				"city":       "Test City",
@@ -270,20 +333,33 @@ func TestUsersService_Delete(t *testing.T) {
 		err := decodeJSON(createRR, &user)
 		require.NoError(t, err)
 		userID := user["id"].(string)
+		userToken := loginTestUser(t, server, "delete@example.com", "password123")
 
 		// Delete the user
-		rr := makeAuthenticatedRequest(t, server, "DELETE", "/users/"+userID, nil, token)
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/users/"+userID, nil, userToken)
 		assertStatus(t, rr, http.StatusNoContent)
 
 		// Verify user is deleted
-		getRR := makeAuthenticatedRequest(t, server, "GET", "/users/"+userID, nil, token)
-		assertStatus(t, getRR, http.StatusNotFound)
+		_, err = server.store.GetUser(context.Background(), userID)
+		assert.ErrorIs(t, err, store.ErrNotFound)
 	})
 
-	t.Run("should return 404 when deleting non-existent user", func(t *testing.T) {
-		rr := makeAuthenticatedRequest(t, server, "DELETE", "/users/999", nil, token)
-		assertStatus(t, rr, http.StatusNotFound)
-		assertErrorResponse(t, rr, "NOT_FOUND")
+	t.Run("should return 403 when deleting another user", func(t *testing.T) {
+		_, otherToken := createLoggedInUser(t, server, "deleteother@example.com", "Delete Other")
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/users/1", nil, otherToken)
+		assertStatus(t, rr, http.StatusForbidden)
+		assertErrorResponse(t, rr, "FORBIDDEN")
+
+		_, err := server.store.GetUser(context.Background(), "1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("should let admins delete any user", func(t *testing.T) {
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/users/1", nil, token)
+		assertStatus(t, rr, http.StatusNoContent)
+
+		_, err := server.store.GetUser(context.Background(), "1")
+		assert.ErrorIs(t, err, store.ErrNotFound)
 	})
 
 	t.Run("should return 401 without authentication", func(t *testing.T) {
@@ -299,8 +375,9 @@ func TestUsersService_Integration(t *testing.T) {
 	t.Run("should handle full user lifecycle", func(t *testing.T) {
 		// Create user
 		newUser := map[string]any{
-			"email": "lifecycle@example.com",
-			"name":  "Lifecycle User",
+			"email":    "lifecycle@example.com",
+			"name":     "Lifecycle User",
+			"password": "password123",
 			"address": map[string]any{
 				"street":     "123 Test St",
 				"city":       "Test City",
@@ -317,16 +394,17 @@ func TestUsersService_Integration(t *testing.T) {
 		err := decodeJSON(createRR, &user)
 		require.NoError(t, err)
 		userID := user["id"].(string)
+		userToken := loginTestUser(t, server, "lifecycle@example.com", "password123")
 
 		// Update user
 		update := map[string]any{
 			"name": "Updated Lifecycle User",
 		}
-		updateRR := makeAuthenticatedRequest(t, server, "PATCH", "/users/"+userID, update, token)
+		updateRR := makeAuthenticatedRequest(t, server, "PATCH", "/users/"+userID, update, userToken)
 		assertStatus(t, updateRR, http.StatusOK)
 
 		// Verify update
-		getRR := makeAuthenticatedRequest(t, server, "GET", "/users/"+userID, nil, token)
+		getRR := makeAuthenticatedRequest(t, server, "GET", "/users/"+userID, nil, userToken)
 		assertStatus(t, getRR, http.StatusOK)
 
 		var updatedUser map[string]any
@@ -335,11 +413,97 @@ func TestUsersService_Integration(t *testing.T) {
 		assert.Equal(t, "Updated Lifecycle User", updatedUser["name"])
 
 		// Delete user
-		deleteRR := makeAuthenticatedRequest(t, server, "DELETE", "/users/"+userID, nil, token)
+		deleteRR := makeAuthenticatedRequest(t, server, "DELETE", "/users/"+userID, nil, userToken)
 		assertStatus(t, deleteRR, http.StatusNoContent)
 
-		// Verify deletion
-		getDeletedRR := makeAuthenticatedRequest(t, server, "GET", "/users/"+userID, nil, token)
-		assertStatus(t, getDeletedRR, http.StatusNotFound)
+		// Verify deletion; the deleted user's session ends with it
+		_, err = server.store.GetUser(context.Background(), userID)
+		assert.ErrorIs(t, err, store.ErrNotFound)
+		getDeletedRR := makeAuthenticatedRequest(t, server, "GET", "/users/"+userID, nil, userToken)
+		assertStatus(t, getDeletedRR, http.StatusUnauthorized)
+	})
+}
+
+func TestUsersService_Credentials(t *testing.T) {
+	server, _, token := setupTestServerWithAuth(t)
+
+	t.Run("should let a user created with a password log in", func(t *testing.T) {
+		newUser := map[string]any{
+			"email":    "Erin@Example.com",
+			"name":     "Erin",
+			"password": "erin password",
+		}
+		rr := makeAuthenticatedRequest(t, server, "POST", "/users", newUser, token)
+		assertStatus(t, rr, http.StatusCreated)
+
+		var user map[string]any
+		require.NoError(t, decodeJSON(rr, &user))
+		assert.Equal(t, "erin@example.com", user["email"])
+
+		erinToken := loginTestUser(t, server, "erin@example.com", "erin password")
+		meRR := makeAuthenticatedRequest(t, server, "GET", "/auth/me", nil, erinToken)
+		assertStatus(t, meRR, http.StatusOK)
+
+		var me map[string]any
+		require.NoError(t, decodeJSON(meRR, &me))
+		assert.Equal(t, user["id"], me["id"])
+	})
+
+	t.Run("should reject a password that breaks the policy", func(t *testing.T) {
+		newUser := map[string]any{
+			"email":    "frank@example.com",
+			"name":     "Frank",
+			"password": "short",
+		}
+		rr := makeAuthenticatedRequest(t, server, "POST", "/users", newUser, token)
+		assertStatus(t, rr, http.StatusBadRequest)
+		assertValidationErrors(t, rr, "/password")
+	})
+
+	t.Run("should return 409 for an email that is already in use", func(t *testing.T) {
+		newUser := map[string]any{
+			"email": "bob@example.com",
+			"name":  "Another Bob",
+		}
+		rr := makeAuthenticatedRequest(t, server, "POST", "/users", newUser, token)
+		assertStatus(t, rr, http.StatusConflict)
+		assertErrorResponse(t, rr, "CONFLICT")
+
+		update := map[string]any{"email": "BOB@example.com"}
+		rr = makeAuthenticatedRequest(t, server, "PATCH", "/users/550e8400-e29b-41d4-a716-446655440001", update, token)
+		assertStatus(t, rr, http.StatusConflict)
+		assertErrorResponse(t, rr, "CONFLICT")
+	})
+
+	t.Run("should log in with the updated email", func(t *testing.T) {
+		bobToken := loginTestUser(t, server, "bob@example.com", "password456")
+		update := map[string]any{"email": "robert@example.com"}
+		rr := makeAuthenticatedRequest(t, server, "PATCH", "/users/550e8400-e29b-41d4-a716-446655440002", update, bobToken)
+		assertStatus(t, rr, http.StatusOK)
+
+		assert.Equal(t, http.StatusUnauthorized, login(t, server, "bob@example.com", "password456").Code)
+		loginTestUser(t, server, "robert@example.com", "password456")
+	})
+
+	t.Run("should not let a user without a password log in", func(t *testing.T) {
+		createTestUser(t, server, "grace@example.com", "Grace")
+		// Users created without a password set one through a password reset
+		assert.Equal(t, http.StatusUnauthorized, login(t, server, "grace@example.com", "any password").Code)
+	})
+
+	t.Run("should end the sessions of a deleted user", func(t *testing.T) {
+		heidiID, heidiToken := createLoggedInUser(t, server, "heidi@example.com", "Heidi")
+		otherSession := loginTestUser(t, server, "heidi@example.com", "password123")
+
+		rr := makeAuthenticatedRequest(t, server, "DELETE", "/users/"+heidiID, nil, heidiToken)
+		assertStatus(t, rr, http.StatusNoContent)
+
+		rr = makeAuthenticatedRequest(t, server, "GET", "/auth/me", nil, otherSession)
+		assertStatus(t, rr, http.StatusUnauthorized)
+		assert.Equal(t, http.StatusUnauthorized, login(t, server, "heidi@example.com", "password123").Code)
+
+		// The email is free again
+		rr = makeAuthenticatedRequest(t, server, "POST", "/users", map[string]any{"email": "heidi@example.com", "name": "Heidi"}, token)
+		assertStatus(t, rr, http.StatusCreated)
 	})
 }
@@ -0,0 +1,171 @@
+// Package inventory tracks stock held by shopping carts.
+//
+// Adding an item to a cart places a reservation on the product for a limited
+// time. Reserved units are not available to other users until the
+// reservation is released or expires. Reservations are kept in the store as
+// store.Hold records and placed and released through the transaction of the
+// cart or order change they belong to, so that they are committed or rolled
+// back with it and shared by every server of the store.
+package inventory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+)
+
+// DefaultTTL is how long a reservation lasts when none is configured
+const DefaultTTL = 15 * time.Minute
+
+// ErrInsufficientStock is returned when a reservation exceeds the stock not held by other users
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// Reservations places time-limited stock reservations in a store. Its
+// methods take the store.Tx to work in: the transaction of a change, or the
+// store itself for reads. It is safe for concurrent use.
+type Reservations struct {
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewReservations returns reservations that last for ttl. now is used as
+// the clock; nil means time.Now.
+func NewReservations(ttl time.Duration, now func() time.Time) *Reservations {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if now == nil {
+		now = time.Now
+	}
+	return &Reservations{ttl: ttl, now: now}
+}
+
+// Reserve sets the quantity userID holds on productID and restarts its expiry.
+// It returns ErrInsufficientStock if stock minus the other users' holds is
+// less than quantity; the existing hold is kept in that case. Callers read
+// the product in tx first, which locks it, so that reservations of the same
+// product take turns.
+func (r *Reservations) Reserve(ctx context.Context, tx store.Tx, userID, productID string, quantity, stock int32) error {
+	holds, err := tx.GetHoldsByProductId(ctx, productID)
+	if err != nil {
+		return err
+	}
+	now := r.now()
+	if stock-reservedBy(holds, now, userID) < quantity {
+		return ErrInsufficientStock
+	}
+	_, err = tx.SetHold(ctx, store.Hold{
+		UserId:    userID,
+		ProductId: productID,
+		Quantity:  quantity,
+		ExpiresAt: now.Add(r.ttl),
+		UpdatedAt: now,
+	})
+	return err
+}
+
+// Release drops the hold userID has on productID
+func (r *Reservations) Release(ctx context.Context, tx store.Tx, userID, productID string) error {
+	holds, err := tx.GetHoldsByProductId(ctx, productID)
+	if err != nil {
+		return err
+	}
+	for _, hold := range holds {
+		if hold.UserId == userID {
+			return r.release(ctx, tx, hold)
+		}
+	}
+	return nil
+}
+
+// ReleaseAll drops every hold userID has
+func (r *Reservations) ReleaseAll(ctx context.Context, tx store.Tx, userID string) error {
+	holds, err := tx.GetHoldsByUserId(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, hold := range holds {
+		if err := r.release(ctx, tx, hold); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// release ends hold now, unless it has ended already
+func (r *Reservations) release(ctx context.Context, tx store.Tx, hold store.Hold) error {
+	now := r.now()
+	if !now.Before(hold.ExpiresAt) {
+		return nil
+	}
+	hold.ExpiresAt = now
+	hold.UpdatedAt = now
+	_, err := tx.SetHold(ctx, hold)
+	return err
+}
+
+// ChangedAt returns when the reserved quantities last changed: when a hold
+// was last placed or released, or when the latest expired hold ran out.
+// It is zero if nothing was ever reserved.
+func (r *Reservations) ChangedAt(ctx context.Context, tx store.Tx) (time.Time, error) {
+	holds, err := tx.GetHolds(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	now := r.now()
+	var changedAt time.Time
+	for _, hold := range holds {
+		if hold.UpdatedAt.After(changedAt) {
+			changedAt = hold.UpdatedAt
+		}
+		if !now.Before(hold.ExpiresAt) && hold.ExpiresAt.After(changedAt) {
+			changedAt = hold.ExpiresAt
+		}
+	}
+	return changedAt, nil
+}
+
+// Reserved returns the quantity of productID held by all users
+func (r *Reservations) Reserved(ctx context.Context, tx store.Tx, productID string) (int32, error) {
+	return r.ReservedByOthers(ctx, tx, productID, "")
+}
+
+// ReservedProducts returns the quantity held by all users of every product
+// with holds
+func (r *Reservations) ReservedProducts(ctx context.Context, tx store.Tx) (map[string]int32, error) {
+	holds, err := tx.GetHolds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := r.now()
+	reserved := make(map[string]int32)
+	for _, hold := range holds {
+		if now.Before(hold.ExpiresAt) {
+			reserved[hold.ProductId] += hold.Quantity
+		}
+	}
+	return reserved, nil
+}
+
+// ReservedByOthers returns the quantity of productID held by users other than userID
+func (r *Reservations) ReservedByOthers(ctx context.Context, tx store.Tx, productID, userID string) (int32, error) {
+	holds, err := tx.GetHoldsByProductId(ctx, productID)
+	if err != nil {
+		return 0, err
+	}
+	return reservedBy(holds, r.now(), userID), nil
+}
+
+// reservedBy sums the holds that have not expired at now, skipping the one
+// of exceptUserID
+func reservedBy(holds []store.Hold, now time.Time, exceptUserID string) int32 {
+	var total int32
+	for _, hold := range holds {
+		if now.Before(hold.ExpiresAt) && hold.UserId != exceptUserID {
+			total += hold.Quantity
+		}
+	}
+	return total
+}
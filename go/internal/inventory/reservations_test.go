@@ -0,0 +1,171 @@
+package inventory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/inventory"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClock is a clock that only moves when told to
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestReservations(ttl time.Duration) (*inventory.Reservations, store.Store, *testClock) {
+	clock := &testClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	return inventory.NewReservations(ttl, clock.Now), store.NewMemoryStore(), clock
+}
+
+func reserved(t *testing.T, reservations *inventory.Reservations, s store.Store, productID string) int32 {
+	t.Helper()
+
+	quantity, err := reservations.Reserved(context.Background(), s, productID)
+	require.NoError(t, err)
+	return quantity
+}
+
+func TestReservations_Reserve(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should hold stock against other users only", func(t *testing.T) {
+		reservations, s, _ := newTestReservations(time.Minute)
+		require.NoError(t, reservations.Reserve(ctx, s, "alice", "p1", 3, 5))
+		assert.Equal(t, int32(3), reserved(t, reservations, s, "p1"))
+
+		assert.ErrorIs(t, reservations.Reserve(ctx, s, "bob", "p1", 3, 5), inventory.ErrInsufficientStock)
+		require.NoError(t, reservations.Reserve(ctx, s, "bob", "p1", 2, 5))
+
+		// A user's own hold does not count against them
+		require.NoError(t, reservations.Reserve(ctx, s, "alice", "p1", 3, 5))
+		others, err := reservations.ReservedByOthers(ctx, s, "p1", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), others)
+		assert.Equal(t, int32(5), reserved(t, reservations, s, "p1"))
+	})
+
+	t.Run("should keep the hold when stock is short", func(t *testing.T) {
+		reservations, s, _ := newTestReservations(time.Minute)
+		require.NoError(t, reservations.Reserve(ctx, s, "alice", "p1", 2, 5))
+		require.NoError(t, reservations.Reserve(ctx, s, "bob", "p1", 2, 5))
+
+		assert.ErrorIs(t, reservations.Reserve(ctx, s, "alice", "p1", 4, 5), inventory.ErrInsufficientStock)
+		assert.Equal(t, int32(4), reserved(t, reservations, s, "p1"))
+	})
+
+	t.Run("should restart the expiry", func(t *testing.T) {
+		reservations, s, clock := newTestReservations(10 * time.Minute)
+		require.NoError(t, reservations.Reserve(ctx, s, "alice", "p1", 1, 5))
+		clock.Advance(8 * time.Minute)
+		require.NoError(t, reservations.Reserve(ctx, s, "alice", "p1", 2, 5))
+
+		clock.Advance(8 * time.Minute)
+		assert.Equal(t, int32(2), reserved(t, reservations, s, "p1"))
+		clock.Advance(2 * time.Minute)
+		assert.Equal(t, int32(0), reserved(t, reservations, s, "p1"))
+	})
+
+	t.Run("should free expired holds for others", func(t *testing.T) {
+		reservations, s, clock := newTestReservations(time.Minute)
+		require.NoError(t, reservations.Reserve(ctx, s, "alice", "p1", 5, 5))
+		assert.ErrorIs(t, reservations.Reserve(ctx, s, "bob", "p1", 1, 5), inventory.ErrInsufficientStock)
+
+		clock.Advance(time.Minute)
+		assert.NoError(t, reservations.Reserve(ctx, s, "bob", "p1", 5, 5))
+	})
+}
+
+func TestReservations_Release(t *testing.T) {
+	ctx := context.Background()
+	reservations, s, _ := newTestReservations(time.Minute)
+	require.NoError(t, reservations.Reserve(ctx, s, "alice", "p1", 1, 5))
+	require.NoError(t, reservations.Reserve(ctx, s, "alice", "p2", 2, 5))
+	require.NoError(t, reservations.Reserve(ctx, s, "bob", "p1", 3, 5))
+
+	require.NoError(t, reservations.Release(ctx, s, "alice", "p1"))
+	assert.Equal(t, int32(3), reserved(t, reservations, s, "p1"))
+	require.NoError(t, reservations.Release(ctx, s, "alice", "p1"), "releasing twice does nothing")
+	require.NoError(t, reservations.Release(ctx, s, "carol", "p1"), "nor does releasing nothing")
+
+	require.NoError(t, reservations.ReleaseAll(ctx, s, "bob"))
+	products, err := reservations.ReservedProducts(ctx, s)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int32{"p2": 2}, products)
+}
+
+func TestReservations_ChangedAt(t *testing.T) {
+	ctx := context.Background()
+	reservations, s, clock := newTestReservations(10 * time.Minute)
+	changedAt := func(t *testing.T) time.Time {
+		t.Helper()
+		at, err := reservations.ChangedAt(ctx, s)
+		require.NoError(t, err)
+		return at
+	}
+	assert.True(t, changedAt(t).IsZero(), "nothing reserved yet")
+
+	start := clock.Now()
+	require.NoError(t, reservations.Reserve(ctx, s, "alice", "p1", 1, 5))
+	assert.Equal(t, start, changedAt(t))
+
+	clock.Advance(time.Minute)
+	require.NoError(t, reservations.Reserve(ctx, s, "bob", "p1", 1, 5))
+	clock.Advance(time.Minute)
+	require.NoError(t, reservations.Release(ctx, s, "bob", "p1"))
+	assert.Equal(t, start.Add(2*time.Minute), changedAt(t), "a release is a change")
+
+	clock.Advance(5 * time.Minute)
+	require.NoError(t, reservations.Release(ctx, s, "bob", "p1"))
+	assert.Equal(t, start.Add(2*time.Minute), changedAt(t), "releasing nothing is not")
+
+	// Alice's hold runs out at start + 10m, without anything written
+	clock.Advance(5 * time.Minute)
+	assert.Equal(t, start.Add(10*time.Minute), changedAt(t))
+}
+
+func TestReservations_WithTx(t *testing.T) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	t.Run("should discard holds of failed transactions", func(t *testing.T) {
+		reservations, s, _ := newTestReservations(time.Minute)
+		require.NoError(t, reservations.Reserve(ctx, s, "alice", "p1", 1, 5))
+
+		err := s.WithTx(ctx, func(tx store.Tx) error {
+			if err := reservations.Reserve(ctx, tx, "alice", "p1", 4, 5); err != nil {
+				return err
+			}
+			if err := reservations.ReleaseAll(ctx, tx, "alice"); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		require.ErrorIs(t, err, errAbort)
+		assert.Equal(t, int32(1), reserved(t, reservations, s, "p1"), "the hold from before is kept")
+	})
+
+	t.Run("should share holds between reservations of one store", func(t *testing.T) {
+		reservations, s, clock := newTestReservations(time.Minute)
+		require.NoError(t, s.WithTx(ctx, func(tx store.Tx) error {
+			return reservations.Reserve(ctx, tx, "alice", "p1", 4, 5)
+		}))
+
+		// Another server of the store, e.g. after a restart
+		other := inventory.NewReservations(time.Minute, clock.Now)
+		assert.Equal(t, int32(4), reserved(t, other, s, "p1"))
+		assert.ErrorIs(t, other.Reserve(ctx, s, "bob", "p1", 2, 5), inventory.ErrInsufficientStock)
+	})
+}
@@ -0,0 +1,266 @@
+// Package jwt signs and verifies compact JSON Web Tokens with HS256, EdDSA or
+// RS256 keys. Keys carry an ID that is written to the "kid" header, so that
+// tokens signed with a previous key stay valid while keys are rotated.
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Supported signing algorithms
+const (
+	HS256 = "HS256"
+	EdDSA = "EdDSA"
+	RS256 = "RS256"
+)
+
+var (
+	// ErrInvalidToken is returned for malformed tokens and bad signatures
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrExpiredToken is returned for tokens past their "exp" claim
+	ErrExpiredToken = errors.New("token expired")
+	// ErrUnknownKey is returned for tokens signed with a key that is not in the key set
+	ErrUnknownKey = errors.New("unknown signing key")
+	// ErrCannotSign is returned by Sign on key sets that can only verify
+	ErrCannotSign = errors.New("key set cannot sign")
+)
+
+// RegisteredClaims are the standard claims this package looks at. Embed it in
+// the claims passed to Sign and Verify.
+type RegisteredClaims struct {
+	ID        string `json:"jti,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	IssuedAt  int64  `json:"iat,omitempty"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Key is a signing or verification key
+type Key struct {
+	ID         string
+	Algorithm  string
+	secret     []byte
+	private    ed25519.PrivateKey
+	public     ed25519.PublicKey
+	rsaPrivate *rsa.PrivateKey
+	rsaPublic  *rsa.PublicKey
+}
+
+// NewHS256Key returns an HMAC-SHA256 key
+func NewHS256Key(id string, secret []byte) Key {
+	return Key{ID: id, Algorithm: HS256, secret: secret}
+}
+
+// NewEdDSAKey returns an Ed25519 key that can sign and verify
+func NewEdDSAKey(id string, private ed25519.PrivateKey) Key {
+	return Key{ID: id, Algorithm: EdDSA, private: private, public: private.Public().(ed25519.PublicKey)}
+}
+
+// NewEdDSAPublicKey returns an Ed25519 key that can only verify
+func NewEdDSAPublicKey(id string, public ed25519.PublicKey) Key {
+	return Key{ID: id, Algorithm: EdDSA, public: public}
+}
+
+// NewRS256Key returns an RSA key that can sign and verify
+func NewRS256Key(id string, private *rsa.PrivateKey) Key {
+	return Key{ID: id, Algorithm: RS256, rsaPrivate: private, rsaPublic: &private.PublicKey}
+}
+
+// NewRS256PublicKey returns an RSA key that can only verify
+func NewRS256PublicKey(id string, public *rsa.PublicKey) Key {
+	return Key{ID: id, Algorithm: RS256, rsaPublic: public}
+}
+
+// canSign reports whether the key holds the secret or private key
+func (k Key) canSign() bool {
+	return len(k.secret) > 0 || len(k.private) > 0 || k.rsaPrivate != nil
+}
+
+func (k Key) sign(data []byte) ([]byte, error) {
+	switch k.Algorithm {
+	case EdDSA:
+		return ed25519.Sign(k.private, data), nil
+	case RS256:
+		digest := sha256.Sum256(data)
+		return rsa.SignPKCS1v15(rand.Reader, k.rsaPrivate, crypto.SHA256, digest[:])
+	}
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+func (k Key) verify(data, signature []byte) bool {
+	switch k.Algorithm {
+	case EdDSA:
+		return len(k.public) == ed25519.PublicKeySize && ed25519.Verify(k.public, data, signature)
+	case RS256:
+		digest := sha256.Sum256(data)
+		return k.rsaPublic != nil && rsa.VerifyPKCS1v15(k.rsaPublic, crypto.SHA256, digest[:], signature) == nil
+	}
+	expected, _ := k.sign(data)
+	return hmac.Equal(expected, signature)
+}
+
+// KeySet signs tokens with its current key and verifies tokens signed with
+// any of its keys
+type KeySet struct {
+	current Key
+	keys    map[string]Key
+}
+
+// NewKeySet returns a key set signing with current and also accepting tokens
+// signed with previous keys
+func NewKeySet(current Key, previous ...Key) (*KeySet, error) {
+	if !current.canSign() {
+		return nil, fmt.Errorf("key %q cannot sign", current.ID)
+	}
+	return newKeySet(current, append([]Key{current}, previous...))
+}
+
+// NewVerifyingKeySet returns a key set that only verifies tokens, such as the
+// published keys of another issuer
+func NewVerifyingKeySet(keys ...Key) (*KeySet, error) {
+	return newKeySet(Key{}, keys)
+}
+
+func newKeySet(current Key, keys []Key) (*KeySet, error) {
+	set := &KeySet{current: current, keys: make(map[string]Key)}
+	for _, key := range keys {
+		if key.Algorithm != HS256 && key.Algorithm != EdDSA && key.Algorithm != RS256 {
+			return nil, fmt.Errorf("key %q: unsupported algorithm %q", key.ID, key.Algorithm)
+		}
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key ID %q", key.ID)
+		}
+		set.keys[key.ID] = key
+	}
+	return set, nil
+}
+
+// NewRandomKeySet returns a key set with a random HS256 key. Its tokens
+// cannot be verified by other processes or after a restart.
+func NewRandomKeySet() *KeySet {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	set, _ := NewKeySet(NewHS256Key(rand.Text()[:8], secret))
+	return set
+}
+
+// ParseKeySet parses a comma-separated list of "kid:base64key" entries for
+// algorithm. The first key signs; the others are only accepted. HS256 keys are
+// secrets of at least 32 bytes, EdDSA keys are 32-byte Ed25519 seeds.
+func ParseKeySet(algorithm, spec string) (*KeySet, error) {
+	var keys []Key
+	for entry := range strings.SplitSeq(spec, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("key %q: want kid:base64key", entry)
+		}
+		material, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		switch algorithm {
+		case HS256:
+			if len(material) < 32 {
+				return nil, fmt.Errorf("key %q: HS256 secrets must be at least 32 bytes", id)
+			}
+			keys = append(keys, NewHS256Key(id, material))
+		case EdDSA:
+			if len(material) != ed25519.SeedSize {
+				return nil, fmt.Errorf("key %q: EdDSA keys must be %d-byte seeds", id, ed25519.SeedSize)
+			}
+			keys = append(keys, NewEdDSAKey(id, ed25519.NewKeyFromSeed(material)))
+		default:
+			return nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+		}
+	}
+	return NewKeySet(keys[0], keys[1:]...)
+}
+
+// header is the JOSE header of a token
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+var encoding = base64.RawURLEncoding
+
+// Sign returns claims as a token signed with the current key
+func (s *KeySet) Sign(claims any) (string, error) {
+	if !s.current.canSign() {
+		return "", ErrCannotSign
+	}
+	headerJSON, err := json.Marshal(header{Algorithm: s.current.Algorithm, Type: "JWT", KeyID: s.current.ID})
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("encode claims: %w", err)
+	}
+	signingInput := encoding.EncodeToString(headerJSON) + "." + encoding.EncodeToString(claimsJSON)
+	signature, err := s.current.sign([]byte(signingInput))
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+	return signingInput + "." + encoding.EncodeToString(signature), nil
+}
+
+// Verify checks the signature and expiry of token and decodes its claims
+// into claims
+func (s *KeySet) Verify(token string, claims any) error {
+	headerPart, rest, _ := strings.Cut(token, ".")
+	claimsPart, signaturePart, ok := strings.Cut(rest, ".")
+	if !ok {
+		return ErrInvalidToken
+	}
+
+	var h header
+	if err := decodePart(headerPart, &h); err != nil {
+		return err
+	}
+	key, ok := s.keys[h.KeyID]
+	if !ok {
+		return ErrUnknownKey
+	}
+	// The key decides the algorithm, never the token
+	if h.Algorithm != key.Algorithm {
+		return ErrInvalidToken
+	}
+	signature, err := encoding.DecodeString(signaturePart)
+	if err != nil || !key.verify([]byte(headerPart+"."+claimsPart), signature) {
+		return ErrInvalidToken
+	}
+
+	var registered RegisteredClaims
+	if err := decodePart(claimsPart, &registered); err != nil {
+		return err
+	}
+	if registered.ExpiresAt == 0 || !time.Now().Before(time.Unix(registered.ExpiresAt, 0)) {
+		return ErrExpiredToken
+	}
+	return decodePart(claimsPart, claims)
+}
+
+func decodePart(part string, v any) error {
+	data, err := encoding.DecodeString(part)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrInvalidToken
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package jwt_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testClaims struct {
+	jwt.RegisteredClaims
+	Name string `json:"name"`
+}
+
+func claimsExpiringIn(d time.Duration) testClaims {
+	return testClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1", ExpiresAt: time.Now().Add(d).Unix()},
+		Name:             "Alice",
+	}
+}
+
+func hs256Key(id string) jwt.Key {
+	return jwt.NewHS256Key(id, []byte("0123456789abcdef0123456789abcdef"+id))
+}
+
+func eddsaKey(t testing.TB, id string) jwt.Key {
+	t.Helper()
+
+	_, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	return jwt.NewEdDSAKey(id, private)
+}
+
+func rs256Key(t testing.TB, id string) jwt.Key {
+	t.Helper()
+
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return jwt.NewRS256Key(id, private)
+}
+
+func newKeySet(t testing.TB, current jwt.Key, previous ...jwt.Key) *jwt.KeySet {
+	t.Helper()
+
+	keys, err := jwt.NewKeySet(current, previous...)
+	require.NoError(t, err)
+	return keys
+}
+
+func TestKeySet_SignVerify(t *testing.T) {
+	for _, key := range []jwt.Key{hs256Key("hs"), eddsaKey(t, "ed"), rs256Key(t, "rs")} {
+		t.Run(key.Algorithm, func(t *testing.T) {
+			keys := newKeySet(t, key)
+			token, err := keys.Sign(claimsExpiringIn(time.Minute))
+			require.NoError(t, err)
+
+			var claims testClaims
+			require.NoError(t, keys.Verify(token, &claims))
+			assert.Equal(t, "user-1", claims.Subject)
+			assert.Equal(t, "Alice", claims.Name)
+		})
+	}
+}
+
+func TestKeySet_Verify(t *testing.T) {
+	signer := newKeySet(t, hs256Key("current"))
+	token, err := signer.Sign(claimsExpiringIn(time.Minute))
+	require.NoError(t, err)
+	headerPart, rest, _ := strings.Cut(token, ".")
+	claimsPart, signaturePart, _ := strings.Cut(rest, ".")
+
+	// A token whose header names the current key but another algorithm
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"EdDSA","typ":"JWT","kid":"current"}`))
+	// A token with other claims under the original signature
+	otherClaims := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin","exp":9999999999}`))
+
+	expired, err := signer.Sign(claimsExpiringIn(-time.Second))
+	require.NoError(t, err)
+	noExpiry, err := signer.Sign(testClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1"}})
+	require.NoError(t, err)
+	otherKey, err := newKeySet(t, hs256Key("other")).Sign(claimsExpiringIn(time.Minute))
+	require.NoError(t, err)
+	sameIDOtherSecret, err := newKeySet(t, jwt.NewHS256Key("current", []byte("another secret of at least 32 bytes"))).Sign(claimsExpiringIn(time.Minute))
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"not a token", "not-a-token", jwt.ErrInvalidToken},
+		{"header is not base64", "!." + claimsPart + "." + signaturePart, jwt.ErrInvalidToken},
+		{"tampered claims", headerPart + "." + otherClaims + "." + signaturePart, jwt.ErrInvalidToken},
+		{"no signature", headerPart + "." + claimsPart + ".", jwt.ErrInvalidToken},
+		{"algorithm swapped", forged + "." + claimsPart + "." + signaturePart, jwt.ErrInvalidToken},
+		{"signed with an unknown key", otherKey, jwt.ErrUnknownKey},
+		{"signed with another secret", sameIDOtherSecret, jwt.ErrInvalidToken},
+		{"expired", expired, jwt.ErrExpiredToken},
+		{"without expiry", noExpiry, jwt.ErrExpiredToken},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var claims testClaims
+			assert.ErrorIs(t, signer.Verify(tc.token, &claims), tc.err)
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey := hs256Key("old")
+	newKey := eddsaKey(t, "new")
+	oldToken, err := newKeySet(t, oldKey).Sign(claimsExpiringIn(time.Minute))
+	require.NoError(t, err)
+
+	rotated := newKeySet(t, newKey, oldKey)
+	var claims testClaims
+	assert.NoError(t, rotated.Verify(oldToken, &claims), "tokens of the previous key are still accepted")
+
+	newToken, err := rotated.Sign(claimsExpiringIn(time.Minute))
+	require.NoError(t, err)
+	assert.ErrorIs(t, newKeySet(t, oldKey).Verify(newToken, &claims), jwt.ErrUnknownKey, "new tokens are signed with the new key")
+
+	// Dropping the old key rejects its tokens
+	assert.ErrorIs(t, newKeySet(t, newKey).Verify(oldToken, &claims), jwt.ErrUnknownKey)
+}
+
+func TestKeySet_VerifyingOnly(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	token, err := newKeySet(t, jwt.NewEdDSAKey("ed", private)).Sign(claimsExpiringIn(time.Minute))
+	require.NoError(t, err)
+
+	verifier, err := jwt.NewVerifyingKeySet(jwt.NewEdDSAPublicKey("ed", private.Public().(ed25519.PublicKey)))
+	require.NoError(t, err)
+	var claims testClaims
+	assert.NoError(t, verifier.Verify(token, &claims))
+	_, err = verifier.Sign(claimsExpiringIn(time.Minute))
+	assert.ErrorIs(t, err, jwt.ErrCannotSign)
+
+	_, err = jwt.NewKeySet(jwt.NewEdDSAPublicKey("ed", private.Public().(ed25519.PublicKey)))
+	assert.Error(t, err, "a public key cannot be the current key")
+	_, err = jwt.NewKeySet(hs256Key("same"), hs256Key("same"))
+	assert.Error(t, err, "key IDs are unique")
+}
+
+func TestParseKeySet(t *testing.T) {
+	secret := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	seed := base64.StdEncoding.EncodeToString(make([]byte, ed25519.SeedSize))
+
+	t.Run("first key signs and the others verify", func(t *testing.T) {
+		keys, err := jwt.ParseKeySet(jwt.HS256, "new:"+secret+", old:"+base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210")))
+		require.NoError(t, err)
+		token, err := keys.Sign(claimsExpiringIn(time.Minute))
+		require.NoError(t, err)
+
+		var claims testClaims
+		assert.NoError(t, newKeySet(t, hs256Key("other"), jwt.NewHS256Key("new", []byte("0123456789abcdef0123456789abcdef"))).Verify(token, &claims))
+	})
+
+	t.Run("EdDSA seeds", func(t *testing.T) {
+		_, err := jwt.ParseKeySet(jwt.EdDSA, "ed:"+seed)
+		assert.NoError(t, err)
+	})
+
+	for _, tc := range []struct {
+		name, algorithm, spec string
+	}{
+		{"missing key ID", jwt.HS256, secret},
+		{"not base64", jwt.HS256, "k:not base64!"},
+		{"short HS256 secret", jwt.HS256, "k:" + base64.StdEncoding.EncodeToString([]byte("short"))},
+		{"EdDSA key that is not a seed", jwt.EdDSA, "k:" + base64.StdEncoding.EncodeToString(make([]byte, ed25519.PrivateKeySize))},
+		{"unsupported algorithm", jwt.RS256, "k:" + secret},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := jwt.ParseKeySet(tc.algorithm, tc.spec)
+			assert.Error(t, err)
+		})
+	}
+}
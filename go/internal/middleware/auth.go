@@ -1,7 +1,8 @@
 package middleware
 
 import (
-	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
 
@@ -10,10 +11,24 @@ import (
 	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
 )
 
-// AuthMiddleware validates Bearer tokens and adds user to context
+// APIKeyScheme is the Authorization scheme for API keys, which can also be
+// sent in the APIKeyHeader header
+const (
+	APIKeyScheme = "ApiKey"
+	APIKeyHeader = "X-API-Key"
+)
+
+// AuthMiddleware validates Bearer access tokens and API keys and adds user to
+// context. Tokens are checked by their signature and must belong to a session
+// that has not ended; API keys are looked up by their hash.
 func AuthMiddleware(authStore *storage.AuthStore) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" {
+				authenticateAPIKey(w, r, next, authStore, apiKey)
+				return
+			}
+
 			// Extract token from Authorization header
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
@@ -22,6 +37,10 @@ func AuthMiddleware(authStore *storage.AuthStore) func(http.Handler) http.Handle
 			}
 
 			parts := strings.Split(authHeader, " ")
+			if len(parts) == 2 && parts[0] == APIKeyScheme {
+				authenticateAPIKey(w, r, next, authStore, parts[1])
+				return
+			}
 			if len(parts) != 2 || parts[0] != "Bearer" {
 				errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "Invalid Authorization header format")
 				return
@@ -30,11 +49,16 @@ func AuthMiddleware(authStore *storage.AuthStore) func(http.Handler) http.Handle
 			token := parts[1]
 
 			// Validate token
-			user, err := authStore.ValidateToken(token)
-			if err != nil {
+			user, err := authStore.ValidateToken(r.Context(), token)
+			if errors.Is(err, storage.ErrNotFound) {
 				errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "Invalid or expired token")
 				return
 			}
+			if err != nil {
+				log.Printf("Failed to validate token: %v", err)
+				errorResponse(w, http.StatusInternalServerError, generated.INTERNALERROR, "Internal server error")
+				return
+			}
 
 			// Add user to request context
 			ctx := auth.WithUser(r.Context(), user)
@@ -43,19 +67,25 @@ func AuthMiddleware(authStore *storage.AuthStore) func(http.Handler) http.Handle
 	}
 }
 
-// errorResponse sends a standardized error response
-func errorResponse(w http.ResponseWriter, statusCode int, code generated.ErrorCode, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	response := generated.ErrorResponse{
-		Error: struct {
-			Code    generated.ErrorCode `json:"code"`
-			Details interface{}         `json:"details,omitempty"`
-			Message string              `json:"message"`
-		}{
-			Code:    code,
-			Message: message,
-		},
+// authenticateAPIKey serves r as the user apiKey acts as
+func authenticateAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, authStore *storage.AuthStore, apiKey string) {
+	user, err := authStore.ValidateAPIKey(r.Context(), apiKey)
+	if errors.Is(err, storage.ErrInvalidAPIKey) {
+		errorResponse(w, http.StatusUnauthorized, generated.UNAUTHORIZED, "Invalid API key")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to validate API key: %v", err)
+		errorResponse(w, http.StatusInternalServerError, generated.INTERNALERROR, "Internal server error")
+		return
 	}
-	json.NewEncoder(w).Encode(response)
+
+	ctx := auth.WithUser(r.Context(), user)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// errorResponse sends a standardized error response, as problem details to
+// clients that asked for them
+func errorResponse(w http.ResponseWriter, statusCode int, code generated.ErrorCode, message string) {
+	WriteError(w, statusCode, code, message, nil)
 }
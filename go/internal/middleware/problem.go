@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+)
+
+const (
+	// ProblemContentType is the media type of RFC 9457 problem details
+	ProblemContentType = "application/problem+json"
+	// ProblemTypeBase prefixes the problem type URI of every error code, e.g.
+	// ProblemTypeBase + "validation-error" for VALIDATION_ERROR
+	ProblemTypeBase = "https://hello-typespec.example/problems/"
+)
+
+// ProblemDetails negotiates the format of error responses. Clients that
+// accept application/problem+json at least as much as application/json get
+// errors as RFC 9457 problem details; everybody else gets the ErrorResponse of
+// the API document.
+func ProblemDetails(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		if acceptsProblem(r.Header.Values("Accept")) {
+			w = &problemWriter{ResponseWriter: w, instance: r.URL.Path}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WriteError sends an error response in the format ProblemDetails negotiated.
+// details lists the fields that failed validation, if any.
+func WriteError(w http.ResponseWriter, statusCode int, code generated.ErrorCode, message string, details []generated.FieldError) {
+	if problem, ok := findProblemWriter(w); ok {
+		response := generated.ProblemDetails{
+			Type:     ProblemType(code),
+			Title:    problemTitle(code),
+			Status:   int32(statusCode),
+			Detail:   message,
+			Instance: problem.instance,
+			Code:     code,
+		}
+		if len(details) > 0 {
+			response.Details = &details
+		}
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	var response generated.ErrorResponse
+	response.Error.Code = code
+	response.Error.Message = message
+	if len(details) > 0 {
+		response.Error.Details = &details
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ProblemType returns the problem type URI of code
+func ProblemType(code generated.ErrorCode) string {
+	return ProblemTypeBase + strings.ReplaceAll(strings.ToLower(string(code)), "_", "-")
+}
+
+// problemTitle turns code into a title, e.g. "Validation Error" for
+// VALIDATION_ERROR
+func problemTitle(code generated.ErrorCode) string {
+	words := strings.Split(strings.ToLower(string(code)), "_")
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// problemWriter marks a response whose errors are sent as problem details
+type problemWriter struct {
+	http.ResponseWriter
+	instance string
+}
+
+func (w *problemWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// findProblemWriter looks for a problemWriter among the writers w wraps
+func findProblemWriter(w http.ResponseWriter) (*problemWriter, bool) {
+	for {
+		switch writer := w.(type) {
+		case *problemWriter:
+			return writer, true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = writer.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
+
+// acceptsProblem reports whether the Accept headers rank problem details
+// explicitly and at least as high as application/json
+func acceptsProblem(accept []string) bool {
+	problemQ, jsonQ := -1.0, 0.0
+	for _, header := range accept {
+		for _, mediaRange := range strings.Split(header, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil {
+				continue
+			}
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(v, 64); err != nil {
+					continue
+				}
+			}
+			switch mediaType {
+			case ProblemContentType:
+				problemQ = q
+			case "application/json", "application/*", "*/*":
+				jsonQ = max(jsonQ, q)
+			}
+		}
+	}
+	return problemQ > 0 && problemQ >= jsonQ
+}
@@ -0,0 +1,132 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/middleware"
+	"github.com/blck-snwmn/hello-typespec/go/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failValidation is a handler that rejects every request with two failed
+// fields
+func failValidation(w http.ResponseWriter, r *http.Request) {
+	var errs validation.Errors
+	errs.NotBlank("/name", "")
+	errs.Minimum("/price", -1, 0)
+	middleware.WriteError(w, http.StatusBadRequest, generated.VALIDATIONERROR, "Request validation failed", errs)
+}
+
+func serveProblem(t *testing.T, handler http.HandlerFunc, accept ...string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", "/products", nil)
+	for _, value := range accept {
+		req.Header.Add("Accept", value)
+	}
+	rr := httptest.NewRecorder()
+	middleware.ProblemDetails(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func TestWriteError_ProblemDetails(t *testing.T) {
+	rr := serveProblem(t, failValidation, middleware.ProblemContentType)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, middleware.ProblemContentType, rr.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", rr.Header().Get("Vary"))
+	var problem generated.ProblemDetails
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, "https://hello-typespec.example/problems/validation-error", problem.Type)
+	assert.Equal(t, "Validation Error", problem.Title)
+	assert.Equal(t, int32(http.StatusBadRequest), problem.Status)
+	assert.Equal(t, "Request validation failed", problem.Detail)
+	assert.Equal(t, "/products", problem.Instance)
+	assert.Equal(t, generated.VALIDATIONERROR, problem.Code)
+	require.NotNil(t, problem.Details)
+	assert.Equal(t, []generated.FieldError{
+		{Field: "/name", Rule: validation.RuleMinLength, Message: "must not be empty"},
+		{Field: "/price", Rule: validation.RuleMinimum, Message: "must be at least 0"},
+	}, *problem.Details)
+}
+
+func TestWriteError_ErrorResponse(t *testing.T) {
+	rr := serveProblem(t, failValidation)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	var response generated.ErrorResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, generated.VALIDATIONERROR, response.Error.Code)
+	assert.Equal(t, "Request validation failed", response.Error.Message)
+	require.NotNil(t, response.Error.Details)
+	assert.Len(t, *response.Error.Details, 2)
+}
+
+func TestWriteError_WithoutDetails(t *testing.T) {
+	notFound := func(w http.ResponseWriter, r *http.Request) {
+		middleware.WriteError(w, http.StatusNotFound, generated.NOTFOUND, "Product not found", nil)
+	}
+
+	rr := serveProblem(t, notFound, middleware.ProblemContentType)
+	var problem map[string]any
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	assert.Equal(t, "Not Found", problem["title"])
+	assert.NotContains(t, problem, "details")
+
+	rr = serveProblem(t, notFound)
+	assert.JSONEq(t, `{"error":{"code":"NOT_FOUND","message":"Product not found"}}`, rr.Body.String())
+}
+
+// unwrapper wraps a response writer the way logging and recording
+// middleware do
+type unwrapper struct {
+	http.ResponseWriter
+}
+
+func (w unwrapper) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func TestWriteError_WrappedWriter(t *testing.T) {
+	rr := serveProblem(t, func(w http.ResponseWriter, r *http.Request) {
+		failValidation(unwrapper{w}, r)
+	}, middleware.ProblemContentType)
+
+	assert.Equal(t, middleware.ProblemContentType, rr.Header().Get("Content-Type"))
+}
+
+func TestProblemDetails_Negotiation(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		accept  []string
+		problem bool
+	}{
+		{"no Accept header", nil, false},
+		{"anything", []string{"*/*"}, false},
+		{"JSON", []string{"application/json"}, false},
+		{"problem details", []string{"application/problem+json"}, true},
+		{"problem details first", []string{"application/problem+json, application/json"}, true},
+		{"problem details preferred", []string{"application/json;q=0.5, application/problem+json"}, true},
+		{"JSON preferred", []string{"application/problem+json;q=0.5, application/json"}, false},
+		{"problem details over anything", []string{"application/problem+json, */*;q=0.1"}, true},
+		{"problem details refused", []string{"application/problem+json;q=0"}, false},
+		{"across headers", []string{"application/json;q=0.9", "application/problem+json"}, true},
+		{"malformed quality", []string{"application/problem+json;q=high"}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := serveProblem(t, failValidation, tc.accept...)
+			assert.Equal(t, tc.problem, rr.Header().Get("Content-Type") == middleware.ProblemContentType)
+		})
+	}
+}
+
+func TestProblemType(t *testing.T) {
+	assert.Equal(t, middleware.ProblemTypeBase+"invalid-state-transition", middleware.ProblemType(generated.INVALIDSTATETRANSITION))
+	assert.Equal(t, middleware.ProblemTypeBase+"unauthorized", middleware.ProblemType(generated.UNAUTHORIZED))
+}
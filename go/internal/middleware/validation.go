@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/validation"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// Validator checks requests, and optionally responses, against an OpenAPI
+// document
+type Validator struct {
+	router         routers.Router
+	options        *openapi3filter.Options
+	reportResponse func(r *http.Request, err error)
+	// problemSchema checks problem details, which operations do not list
+	problemSchema *openapi3.Schema
+}
+
+// ValidatorOption configures a Validator
+type ValidatorOption func(*Validator)
+
+// WithResponseValidation also checks responses and passes the ones that do
+// not match the document to report. Tests use it to catch drift between the
+// handlers and the spec.
+func WithResponseValidation(report func(r *http.Request, err error)) ValidatorOption {
+	return func(v *Validator) {
+		v.reportResponse = report
+	}
+}
+
+// NewValidator creates a validator for spec. Operations are matched by path
+// alone, so the servers of spec are dropped.
+func NewValidator(spec *openapi3.T, opts ...ValidatorOption) (*Validator, error) {
+	spec.Servers = nil
+	if spec.Components != nil {
+		// HTTP authentication schemes are case-insensitive, but kin-openapi
+		// only accepts them in lower case, and TypeSpec emits "Bearer"
+		for _, scheme := range spec.Components.SecuritySchemes {
+			if scheme.Value != nil {
+				scheme.Value.Scheme = strings.ToLower(scheme.Value.Scheme)
+			}
+		}
+	}
+	spec.SetStringFormatValidator("email", openapi3.NewRegexpFormatValidator(openapi3.FormatOfStringForEmail))
+	router, err := legacy.NewRouter(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Validator{
+		router: router,
+		options: &openapi3filter.Options{
+			MultiError: true,
+			// Authentication is checked per operation by the handlers
+			AuthenticationFunc:      openapi3filter.NoopAuthenticationFunc,
+			SkipSettingDefaults:     true,
+			SchemaValidationOptions: spec.GetSchemaValidationOptions(),
+		},
+	}
+	if schema := spec.Components.Schemas["ProblemDetails"]; schema != nil {
+		v.problemSchema = schema.Value
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v, nil
+}
+
+// Middleware rejects requests that do not match the document with 400
+// VALIDATION_ERROR, listing every offending field in the error's details.
+// Requests for paths outside the document are passed on.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := v.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    v.options,
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			var parseErr *openapi3filter.ParseError
+			if errors.As(err, &parseErr) {
+				errorResponse(w, http.StatusBadRequest, generated.BADREQUEST, "Invalid request body")
+				return
+			}
+			WriteError(w, http.StatusBadRequest, generated.VALIDATIONERROR, "Request does not match the API specification", fieldErrors(err))
+			return
+		}
+
+		if v.reportResponse == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		mediaType, _, _ := mime.ParseMediaType(recorder.Header().Get("Content-Type"))
+		if mediaType == ProblemContentType && v.problemSchema != nil {
+			err = v.validateProblem(recorder.body.Bytes())
+		} else {
+			err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 recorder.status,
+				Header:                 recorder.Header(),
+				Body:                   io.NopCloser(&recorder.body),
+				Options:                v.options,
+			})
+		}
+		if err != nil {
+			v.reportResponse(r, err)
+		}
+	})
+}
+
+// validateProblem checks a problem details response body against the
+// ProblemDetails schema of the document
+func (v *Validator) validateProblem(body []byte) error {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Errorf("problem details: %w", err)
+	}
+	return v.problemSchema.VisitJSON(value, v.options.SchemaValidationOptions...)
+}
+
+// fieldErrors flattens the errors of a request validation. Schema errors are
+// reported with the JSON Schema keyword they broke as the rule, and errors in
+// parameters under the name of the parameter.
+func fieldErrors(err error) validation.Errors {
+	var fields validation.Errors
+	var walk func(err error, field string)
+	walk = func(err error, field string) {
+		var requestErr *openapi3filter.RequestError
+		var schemaErr *openapi3.SchemaError
+		// errors.As would also find the MultiError a RequestError wraps and
+		// skip the RequestError
+		if multiErr, ok := err.(openapi3.MultiError); ok {
+			for _, err := range multiErr {
+				walk(err, field)
+			}
+			return
+		}
+		switch {
+		case errors.As(err, &requestErr):
+			switch {
+			case requestErr.Err == nil:
+				if requestErr.Parameter != nil {
+					field = requestErr.Parameter.Name
+				}
+				fields.Add(field, validation.RuleInvalid, requestErr.Reason)
+			case requestErr.Parameter != nil:
+				// Name the parameter, not a part of it such as facets/0
+				for _, fieldErr := range fieldErrors(requestErr.Err) {
+					fields.Add(requestErr.Parameter.Name, fieldErr.Rule, fieldErr.Message)
+				}
+			default:
+				walk(requestErr.Err, field)
+			}
+		case errors.As(err, &schemaErr):
+			fields.Add(field+validation.Pointer(schemaErr.JSONPointer()...), schemaErr.SchemaField, schemaErr.Reason)
+		case errors.Is(err, openapi3filter.ErrInvalidRequired):
+			fields.Add(field, validation.RuleRequired, err.Error())
+		default:
+			fields.Add(field, validation.RuleInvalid, err.Error())
+		}
+	}
+	walk(err, "")
+	return fields
+}
+
+// responseRecorder passes a response on while keeping a copy to validate
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
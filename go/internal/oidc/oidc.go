@@ -0,0 +1,471 @@
+// Package oidc logs users in with an OpenID Connect identity provider using
+// the authorization code flow with PKCE. The provider is configured through
+// its discovery document, and ID tokens are verified against its published
+// keys.
+package oidc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/jwt"
+)
+
+const (
+	// PendingLoginTTL is how long a user has to complete a login at the provider
+	PendingLoginTTL = 10 * time.Minute
+	// StateCookie is the cookie that binds a login to the browser that
+	// started it
+	StateCookie = "oidc_state"
+	// DefaultMaxPendingLogins is the default Config.MaxPendingLogins
+	DefaultMaxPendingLogins = 10000
+	// DefaultKeyRefreshInterval is the default Config.KeyRefreshInterval
+	DefaultKeyRefreshInterval = time.Minute
+	// minRSAKeyBits is the smallest RSA key the provider may sign with
+	minRSAKeyBits = 2048
+)
+
+var (
+	// ErrInvalidState is returned for unknown, used or expired login states,
+	// and for states that were started in another browser
+	ErrInvalidState = errors.New("unknown or expired login state")
+	// ErrTooManyPendingLogins is returned when Config.MaxPendingLogins
+	// logins are waiting for the provider already
+	ErrTooManyPendingLogins = errors.New("too many pending logins")
+	// ErrInvalidIDToken is returned for ID tokens that fail verification
+	ErrInvalidIDToken = errors.New("invalid ID token")
+)
+
+// Config describes the client registered with the provider
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string // empty for public clients
+	RedirectURL  string
+	Scopes       []string     // openid, email and profile by default
+	HTTPClient   *http.Client // http.DefaultClient by default
+
+	// MaxPendingLogins caps the logins that were started and have not come
+	// back, DefaultMaxPendingLogins by default
+	MaxPendingLogins int
+	// KeyRefreshInterval is the least time between two fetches of the
+	// provider's keys for tokens signed with an unknown key,
+	// DefaultKeyRefreshInterval by default
+	KeyRefreshInterval time.Duration
+}
+
+// Claims are the verified ID token claims a local user is derived from
+type Claims struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	LinkUserID    string // the user passed to AuthCodeURL, empty for logins
+}
+
+// metadata is the part of the discovery document the client uses
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// pendingLogin is a login that was sent to the provider and has not come back
+type pendingLogin struct {
+	nonce      string
+	verifier   string
+	linkUserID string
+	expiresAt  time.Time
+}
+
+// Client runs logins against one provider
+type Client struct {
+	config   Config
+	metadata metadata
+
+	pendingMu sync.Mutex
+	pending   map[string]pendingLogin // keyed by state
+
+	mu              sync.Mutex
+	keys            *jwt.KeySet
+	keysRefreshedAt time.Time
+}
+
+// NewClient discovers the provider at config.Issuer and fetches its keys
+func NewClient(ctx context.Context, config Config) (*Client, error) {
+	if config.HTTPClient == nil {
+		config.HTTPClient = http.DefaultClient
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+	if config.MaxPendingLogins <= 0 {
+		config.MaxPendingLogins = DefaultMaxPendingLogins
+	}
+	if config.KeyRefreshInterval <= 0 {
+		config.KeyRefreshInterval = DefaultKeyRefreshInterval
+	}
+	c := &Client{config: config, pending: make(map[string]pendingLogin)}
+
+	discoveryURL := strings.TrimSuffix(config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := c.getJSON(ctx, discoveryURL, &c.metadata); err != nil {
+		return nil, fmt.Errorf("discover provider: %w", err)
+	}
+	if c.metadata.Issuer != config.Issuer {
+		return nil, fmt.Errorf("discover provider: issuer %q does not match %q", c.metadata.Issuer, config.Issuer)
+	}
+	if c.metadata.AuthorizationEndpoint == "" || c.metadata.TokenEndpoint == "" || c.metadata.JWKSURI == "" {
+		return nil, errors.New("discover provider: authorization, token and JWKS endpoints are required")
+	}
+	c.keysRefreshedAt = time.Now()
+	if err := c.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// AuthCodeURL starts a login and returns the provider URL to send the user
+// to, with the state of the login. The state has to be kept in the browser
+// with StateCookieFor and passed back to Exchange. With a linkUserID the
+// provider account is linked to that user instead of being logged in on its
+// own.
+func (c *Client) AuthCodeURL(linkUserID string) (authCodeURL, state string, err error) {
+	authURL, err := url.Parse(c.metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", "", fmt.Errorf("parse authorization endpoint: %w", err)
+	}
+
+	state = rand.Text()
+	login := pendingLogin{
+		nonce:      rand.Text(),
+		verifier:   rand.Text() + rand.Text(),
+		linkUserID: linkUserID,
+		expiresAt:  time.Now().Add(PendingLoginTTL),
+	}
+	if err := c.addPending(state, login); err != nil {
+		return "", "", err
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", c.config.ClientID)
+	query.Set("redirect_uri", c.config.RedirectURL)
+	query.Set("scope", strings.Join(c.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", login.nonce)
+	query.Set("code_challenge", CodeChallenge(login.verifier))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+	return authURL.String(), state, nil
+}
+
+// addPending records a started login, dropping expired ones when there are
+// too many
+func (c *Client) addPending(state string, login pendingLogin) error {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+
+	if len(c.pending) >= c.config.MaxPendingLogins {
+		now := time.Now()
+		maps.DeleteFunc(c.pending, func(_ string, login pendingLogin) bool {
+			return now.After(login.expiresAt)
+		})
+		if len(c.pending) >= c.config.MaxPendingLogins {
+			return ErrTooManyPendingLogins
+		}
+	}
+	c.pending[state] = login
+	return nil
+}
+
+// takePending removes and returns the login of state
+func (c *Client) takePending(state string) (pendingLogin, bool) {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+
+	login, ok := c.pending[state]
+	delete(c.pending, state)
+	return login, ok
+}
+
+// StateCookieFor returns the cookie that keeps state in the browser that
+// started the login. It is only sent to the callback, and also on the
+// top-level redirect back from the provider.
+func (c *Client) StateCookieFor(state string) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     StateCookie,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   int(PendingLoginTTL / time.Second),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if redirect, err := url.Parse(c.config.RedirectURL); err == nil {
+		cookie.Secure = redirect.Scheme == "https"
+		if redirect.Path != "" {
+			cookie.Path = redirect.Path
+		}
+	}
+	return cookie
+}
+
+// ClearStateCookie returns a cookie that removes the StateCookieFor cookie
+func (c *Client) ClearStateCookie() *http.Cookie {
+	cookie := c.StateCookieFor("")
+	cookie.MaxAge = -1
+	return cookie
+}
+
+// CodeChallenge returns the S256 PKCE challenge of verifier
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Exchange completes the login of state with the code the provider
+// redirected back with and returns the claims of the verified ID token.
+// browserState is the state kept in the StateCookie of the browser the
+// callback came from, which must be the one that started the login.
+func (c *Client) Exchange(ctx context.Context, code, state, browserState string) (*Claims, error) {
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, ErrInvalidState
+	}
+	login, ok := c.takePending(state)
+	if !ok {
+		return nil, ErrInvalidState
+	}
+	if time.Now().After(login.expiresAt) {
+		return nil, ErrInvalidState
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {c.config.RedirectURL},
+		"client_id":     {c.config.ClientID},
+		"code_verifier": {login.verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.config.ClientID), url.QueryEscape(c.config.ClientSecret))
+	}
+	resp, err := c.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("exchange code: decode response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchange code: %s: %s %s", resp.Status, token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("exchange code: %w: none returned", ErrInvalidIDToken)
+	}
+	claims, err := c.verifyIDToken(ctx, token.IDToken, login.nonce)
+	if err != nil {
+		return nil, err
+	}
+	claims.LinkUserID = login.linkUserID
+	return claims, nil
+}
+
+// idTokenClaims are the claims of an ID token
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Issuer          string   `json:"iss"`
+	Audience        audience `json:"aud"`
+	AuthorizedParty string   `json:"azp"`
+	Nonce           string   `json:"nonce"`
+	Email           string   `json:"email"`
+	EmailVerified   bool     `json:"email_verified"`
+	Name            string   `json:"name"`
+}
+
+// audience is an "aud" claim, which is a string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+// verifyIDToken checks the signature, expiry, issuer, audience and nonce of
+// an ID token
+func (c *Client) verifyIDToken(ctx context.Context, token, nonce string) (*Claims, error) {
+	var claims idTokenClaims
+	err := c.currentKeys().Verify(token, &claims)
+	if errors.Is(err, jwt.ErrUnknownKey) && c.mayRefreshKeys() {
+		// The provider may have rotated its keys
+		if err := c.refreshKeys(ctx); err != nil {
+			return nil, err
+		}
+		err = c.currentKeys().Verify(token, &claims)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	switch {
+	case claims.Issuer != c.metadata.Issuer:
+		return nil, fmt.Errorf("%w: issued by %q", ErrInvalidIDToken, claims.Issuer)
+	case !slices.Contains(claims.Audience, c.config.ClientID):
+		return nil, fmt.Errorf("%w: not issued for this client", ErrInvalidIDToken)
+	case claims.AuthorizedParty != "" && claims.AuthorizedParty != c.config.ClientID:
+		return nil, fmt.Errorf("%w: authorized party %q", ErrInvalidIDToken, claims.AuthorizedParty)
+	case claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: nonce does not match", ErrInvalidIDToken)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+
+	return &Claims{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+func (c *Client) currentKeys() *jwt.KeySet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.keys
+}
+
+// mayRefreshKeys reports whether the keys were last fetched long enough ago
+// to fetch them again, and if so counts the fetch as done. Tokens with
+// made-up key IDs cannot make the client fetch keys more often than that.
+func (c *Client) mayRefreshKeys() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if now.Sub(c.keysRefreshedAt) < c.config.KeyRefreshInterval {
+		return false
+	}
+	c.keysRefreshedAt = now
+	return true
+}
+
+// jsonWebKey is a key of a JWK set. Only RSA and Ed25519 signing keys are used.
+type jsonWebKey struct {
+	KeyType   string `json:"kty"`
+	ID        string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	X         string `json:"x"`
+}
+
+// refreshKeys fetches the provider's signing keys
+func (c *Client) refreshKeys(ctx context.Context) error {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := c.getJSON(ctx, c.metadata.JWKSURI, &set); err != nil {
+		return fmt.Errorf("fetch provider keys: %w", err)
+	}
+
+	var keys []jwt.Key
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, ok := parseKey(jwk)
+		if ok {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return errors.New("fetch provider keys: no usable signing keys")
+	}
+	keySet, err := jwt.NewVerifyingKeySet(keys...)
+	if err != nil {
+		return fmt.Errorf("fetch provider keys: %w", err)
+	}
+
+	c.mu.Lock()
+	c.keys = keySet
+	c.mu.Unlock()
+	return nil
+}
+
+// parseKey converts a JWK into a verification key, reporting false for keys
+// of unsupported types and RSA keys shorter than 2048 bits
+func parseKey(jwk jsonWebKey) (jwt.Key, bool) {
+	switch {
+	case jwk.KeyType == "RSA" && (jwk.Algorithm == "" || jwk.Algorithm == jwt.RS256):
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			return jwt.Key{}, false
+		}
+		modulus := new(big.Int).SetBytes(n)
+		if modulus.BitLen() < minRSAKeyBits {
+			return jwt.Key{}, false
+		}
+		exponent := int(new(big.Int).SetBytes(e).Int64())
+		return jwt.NewRS256PublicKey(jwk.ID, &rsa.PublicKey{N: modulus, E: exponent}), true
+	case jwk.KeyType == "OKP" && jwk.Curve == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return jwt.Key{}, false
+		}
+		return jwt.NewEdDSAPublicKey(jwk.ID, ed25519.PublicKey(x)), true
+	}
+	return jwt.Key{}, false
+}
+
+func (c *Client) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,320 @@
+package oidc_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"maps"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/jwt"
+	"github.com/blck-snwmn/hello-typespec/go/internal/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testClientID = "hello-typespec"
+
+// testProvider is an OpenID Connect provider whose token endpoint returns an
+// ID token for any code, signed with signer and carrying claims on top of
+// valid defaults
+type testProvider struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	issuer      string // advertised in the discovery document, URL by default
+	published   []*rsa.PrivateKey
+	signer      *jwt.KeySet
+	claims      map[string]any
+	jwksFetches int
+}
+
+// newTestProvider returns a provider that publishes and signs with one key
+func newTestProvider(t testing.TB) *testProvider {
+	t.Helper()
+
+	p := &testProvider{}
+	p.rotate(t, "key-1", generateKey(t, 2048))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		issuer := p.issuer
+		if issuer == "" {
+			issuer = p.URL
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.jwksFetches++
+		keys := []map[string]string{}
+		for i, key := range p.published {
+			keys = append(keys, map[string]string{
+				"kty": "RSA",
+				"kid": "key-" + string(rune('1'+i)),
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		now := time.Now()
+		claims := map[string]any{
+			"iss": p.URL,
+			"aud": testClientID,
+			"sub": "user-1",
+			"iat": now.Unix(),
+			"exp": now.Add(time.Minute).Unix(),
+		}
+		maps.Copy(claims, p.claims)
+		idToken, err := p.signer.Sign(claims)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+// rotate signs with key from now on, publishing it next to the earlier keys
+// under the ID "key-N" for the Nth key. kid names the key in the tokens.
+func (p *testProvider) rotate(t testing.TB, kid string, key *rsa.PrivateKey) {
+	t.Helper()
+
+	signer, err := jwt.NewKeySet(jwt.NewRS256Key(kid, key))
+	require.NoError(t, err)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.published = append(p.published, key)
+	p.signer = signer
+}
+
+// signWith signs tokens with key without publishing it
+func (p *testProvider) signWith(t testing.TB, kid string, key *rsa.PrivateKey) {
+	t.Helper()
+
+	signer, err := jwt.NewKeySet(jwt.NewRS256Key(kid, key))
+	require.NoError(t, err)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.signer = signer
+}
+
+// setClaims sets the claims of the next ID tokens on top of the defaults
+func (p *testProvider) setClaims(claims map[string]any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.claims = claims
+}
+
+func (p *testProvider) fetches() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.jwksFetches
+}
+
+func generateKey(t testing.TB, bits int) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	return key
+}
+
+func newTestClient(t testing.TB, p *testProvider, config oidc.Config) *oidc.Client {
+	t.Helper()
+
+	config.Issuer = p.URL
+	config.ClientID = testClientID
+	config.RedirectURL = "https://shop.example.com/auth/oidc/callback"
+	client, err := oidc.NewClient(t.Context(), config)
+	require.NoError(t, err)
+	return client
+}
+
+// startLogin starts a login, has the provider put its nonce into the next ID
+// token along with claims, and returns its state
+func startLogin(t testing.TB, client *oidc.Client, p *testProvider, linkUserID string, claims map[string]any) string {
+	t.Helper()
+
+	authURL, state, err := client.AuthCodeURL(linkUserID)
+	require.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, state, parsed.Query().Get("state"))
+
+	withNonce := map[string]any{"nonce": parsed.Query().Get("nonce")}
+	maps.Copy(withNonce, claims)
+	p.setClaims(withNonce)
+	return state
+}
+
+func TestNewClient(t *testing.T) {
+	t.Run("should reject a provider with another issuer", func(t *testing.T) {
+		p := newTestProvider(t)
+		p.issuer = "https://evil.example.com"
+
+		_, err := oidc.NewClient(t.Context(), oidc.Config{Issuer: p.URL, ClientID: testClientID})
+		assert.ErrorContains(t, err, "does not match")
+	})
+
+	t.Run("should reject RSA keys under 2048 bits", func(t *testing.T) {
+		p := newTestProvider(t)
+		p.published = []*rsa.PrivateKey{generateKey(t, 1024)}
+
+		_, err := oidc.NewClient(t.Context(), oidc.Config{Issuer: p.URL, ClientID: testClientID})
+		assert.ErrorContains(t, err, "no usable signing keys")
+	})
+}
+
+func TestClient_Exchange(t *testing.T) {
+	p := newTestProvider(t)
+	client := newTestClient(t, p, oidc.Config{})
+
+	t.Run("should return the claims of the verified ID token", func(t *testing.T) {
+		state := startLogin(t, client, p, "", map[string]any{"email": "dana@example.com", "email_verified": true, "name": "Dana"})
+
+		claims, err := client.Exchange(t.Context(), "code", state, state)
+		require.NoError(t, err)
+		assert.Equal(t, oidc.Claims{
+			Issuer:        p.URL,
+			Subject:       "user-1",
+			Email:         "dana@example.com",
+			EmailVerified: true,
+			Name:          "Dana",
+		}, *claims)
+	})
+
+	t.Run("should return the user a link was started for", func(t *testing.T) {
+		state := startLogin(t, client, p, "user-42", nil)
+
+		claims, err := client.Exchange(t.Context(), "code", state, state)
+		require.NoError(t, err)
+		assert.Equal(t, "user-42", claims.LinkUserID)
+	})
+
+	t.Run("should only complete a login in the browser that started it", func(t *testing.T) {
+		other := startLogin(t, client, p, "", nil)
+		state := startLogin(t, client, p, "", nil)
+
+		_, err := client.Exchange(t.Context(), "code", state, other)
+		assert.ErrorIs(t, err, oidc.ErrInvalidState)
+		_, err = client.Exchange(t.Context(), "code", state, "")
+		assert.ErrorIs(t, err, oidc.ErrInvalidState)
+
+		// A rejected attempt does not use up the login
+		_, err = client.Exchange(t.Context(), "code", state, state)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should complete a login once", func(t *testing.T) {
+		state := startLogin(t, client, p, "", nil)
+
+		_, err := client.Exchange(t.Context(), "code", state, state)
+		require.NoError(t, err)
+		_, err = client.Exchange(t.Context(), "code", state, state)
+		assert.ErrorIs(t, err, oidc.ErrInvalidState)
+	})
+
+	t.Run("should reject invalid ID tokens", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			claims map[string]any
+		}{
+			{"other nonce", map[string]any{"nonce": "replayed"}},
+			{"other issuer", map[string]any{"iss": "https://evil.example.com"}},
+			{"other audience", map[string]any{"aud": "another-client"}},
+			{"other authorized party", map[string]any{"azp": "another-client"}},
+			{"no subject", map[string]any{"sub": ""}},
+			{"expired", map[string]any{"exp": time.Now().Add(-time.Minute).Unix()}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				state := startLogin(t, client, p, "", tt.claims)
+
+				_, err := client.Exchange(t.Context(), "code", state, state)
+				assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+			})
+		}
+	})
+}
+
+func TestClient_KeyRefresh(t *testing.T) {
+	t.Run("should not fetch keys for every unknown key ID", func(t *testing.T) {
+		p := newTestProvider(t)
+		client := newTestClient(t, p, oidc.Config{})
+		p.signWith(t, "made-up", generateKey(t, 2048))
+
+		for range 3 {
+			state := startLogin(t, client, p, "", nil)
+			_, err := client.Exchange(t.Context(), "code", state, state)
+			assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+		}
+		assert.Equal(t, 1, p.fetches(), "only the fetch at startup")
+	})
+
+	t.Run("should pick up rotated keys", func(t *testing.T) {
+		p := newTestProvider(t)
+		client := newTestClient(t, p, oidc.Config{KeyRefreshInterval: time.Nanosecond})
+		p.rotate(t, "key-2", generateKey(t, 2048))
+
+		state := startLogin(t, client, p, "", nil)
+		_, err := client.Exchange(t.Context(), "code", state, state)
+		require.NoError(t, err)
+		assert.Equal(t, 2, p.fetches())
+	})
+}
+
+func TestClient_PendingLogins(t *testing.T) {
+	p := newTestProvider(t)
+	client := newTestClient(t, p, oidc.Config{MaxPendingLogins: 2})
+
+	startLogin(t, client, p, "", nil)
+	last := startLogin(t, client, p, "", nil)
+	_, _, err := client.AuthCodeURL("")
+	assert.ErrorIs(t, err, oidc.ErrTooManyPendingLogins)
+
+	// Completing a login makes room for another
+	_, err = client.Exchange(t.Context(), "code", last, last)
+	require.NoError(t, err)
+	_, _, err = client.AuthCodeURL("")
+	assert.NoError(t, err)
+}
+
+func TestClient_StateCookieFor(t *testing.T) {
+	p := newTestProvider(t)
+	client := newTestClient(t, p, oidc.Config{})
+
+	cookie := client.StateCookieFor("state")
+	assert.Equal(t, oidc.StateCookie, cookie.Name)
+	assert.Equal(t, "state", cookie.Value)
+	assert.Equal(t, "/auth/oidc/callback", cookie.Path)
+	assert.True(t, cookie.HttpOnly)
+	assert.True(t, cookie.Secure)
+	assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+	assert.Equal(t, int(oidc.PendingLoginTTL/time.Second), cookie.MaxAge)
+
+	assert.Negative(t, client.ClearStateCookie().MaxAge)
+}
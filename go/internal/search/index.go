@@ -0,0 +1,349 @@
+// Package search is the full-text search of products. An Index ranks
+// products by how well their name and description match a query with BM25F,
+// matching stemmed words, prefixes of words and words with a typo or two;
+// Store keeps an Index in step with the products of a store.Store.
+package search
+
+import (
+	"cmp"
+	"html"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+)
+
+// The fields of a product that are searched
+const (
+	fieldName = iota
+	fieldDescription
+	numFields
+)
+
+// fieldWeights makes a match in the name count twice as much as one in the
+// description
+var fieldWeights = [numFields]float64{fieldName: 2, fieldDescription: 1}
+
+// BM25 parameters: k1 controls how quickly repeating a term stops adding to
+// the score and b how much long fields are penalized
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// How much a query word matching a term counts, depending on how it matches
+const (
+	exactWeight  = 1.0
+	prefixWeight = 0.8
+	typoWeight   = 0.6
+)
+
+// minPrefixLen is the shortest query word that matches the words it starts
+const minPrefixLen = 2
+
+// snippetLen is about how many bytes of a long text Highlight returns
+const snippetLen = 160
+
+// MaxHits is the most products a search returns, keeping the pages and
+// counts of a search to a bounded number of products
+const MaxHits = 1000
+
+// Index is an inverted index of products. It is not safe for concurrent use.
+type Index struct {
+	// docs holds the indexed products by ID
+	docs map[string]document
+	// postings holds the frequency of each term in each field of each
+	// product, by term and product ID
+	postings map[string]map[string][numFields]int
+	// vocab lists the terms of postings in order, for prefix lookups
+	vocab []string
+	// totalLengths sums the field lengths of all products
+	totalLengths [numFields]int
+}
+
+// document is what the index knows about a product
+type document struct {
+	// lengths counts the terms of each field
+	lengths [numFields]int
+	// terms lists the distinct terms of all fields
+	terms []string
+}
+
+// NewIndex returns an empty index
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]document),
+		postings: make(map[string]map[string][numFields]int),
+	}
+}
+
+// Len returns the number of products in the index
+func (x *Index) Len() int {
+	return len(x.docs)
+}
+
+// Put adds product to the index, replacing what was indexed under its ID
+func (x *Index) Put(product generated.Product) {
+	x.Delete(product.Id)
+
+	var doc document
+	for field, text := range [numFields]string{fieldName: product.Name, fieldDescription: product.Description} {
+		for _, term := range terms(text) {
+			postings, ok := x.postings[term]
+			if !ok {
+				postings = make(map[string][numFields]int)
+				x.postings[term] = postings
+				i, _ := slices.BinarySearch(x.vocab, term)
+				x.vocab = slices.Insert(x.vocab, i, term)
+			}
+			freqs, ok := postings[product.Id]
+			if !ok {
+				doc.terms = append(doc.terms, term)
+			}
+			freqs[field]++
+			postings[product.Id] = freqs
+			doc.lengths[field]++
+		}
+	}
+	x.docs[product.Id] = doc
+	for field, length := range doc.lengths {
+		x.totalLengths[field] += length
+	}
+}
+
+// Delete removes the product with id from the index, if it is there
+func (x *Index) Delete(id string) {
+	doc, ok := x.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		postings := x.postings[term]
+		delete(postings, id)
+		if len(postings) == 0 {
+			delete(x.postings, term)
+			if i, found := slices.BinarySearch(x.vocab, term); found {
+				x.vocab = slices.Delete(x.vocab, i, i+1)
+			}
+		}
+	}
+	delete(x.docs, id)
+	for field, length := range doc.lengths {
+		x.totalLengths[field] -= length
+	}
+}
+
+// Hit is a product that matches a query
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Results are the products that match a query
+type Results struct {
+	// Hits are ordered by descending score, then by ID
+	Hits []Hit
+	// terms are the indexed terms the query matched
+	terms map[string]bool
+}
+
+// Search returns the products that match every word of query. A word matches
+// a term of the index when they have the same stem, when the word starts the
+// term, or when they are a typo apart: one edit for words of four letters or
+// more and two for words of eight or more. Only the MaxHits best matches are
+// returned. A blank query matches nothing.
+func (x *Index) Search(query string) Results {
+	results := Results{terms: make(map[string]bool)}
+	words := tokenize(query)
+	if len(words) == 0 {
+		return results
+	}
+
+	var scores map[string]float64
+	seen := make(map[string]bool)
+	for _, word := range words {
+		if seen[word.word] {
+			continue
+		}
+		seen[word.word] = true
+
+		// A product scores for a word by the best term the word matches
+		wordScores := make(map[string]float64)
+		for term, weight := range x.expand(word.word) {
+			results.terms[term] = true
+			idf := x.idf(term)
+			for id, freqs := range x.postings[term] {
+				score := weight * idf * x.saturate(freqs, x.docs[id].lengths)
+				wordScores[id] = max(wordScores[id], score)
+			}
+		}
+
+		if scores == nil {
+			scores = wordScores
+			continue
+		}
+		for id, score := range scores {
+			if wordScore, ok := wordScores[id]; ok {
+				scores[id] = score + wordScore
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	for id, score := range scores {
+		results.Hits = append(results.Hits, Hit{ID: id, Score: score})
+	}
+	slices.SortFunc(results.Hits, func(a, b Hit) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	if len(results.Hits) > MaxHits {
+		results.Hits = results.Hits[:MaxHits:MaxHits]
+	}
+	return results
+}
+
+// expand returns the terms of the index a query word matches, with how much
+// each match counts
+func (x *Index) expand(word string) map[string]float64 {
+	matches := make(map[string]float64)
+	match := func(term string, weight float64) {
+		matches[term] = max(matches[term], weight)
+	}
+
+	stemmed := stem(word)
+	if _, ok := x.postings[stemmed]; ok {
+		match(stemmed, exactWeight)
+	}
+
+	if len([]rune(word)) >= minPrefixLen {
+		i, _ := slices.BinarySearch(x.vocab, word)
+		for ; i < len(x.vocab) && strings.HasPrefix(x.vocab[i], word); i++ {
+			match(x.vocab[i], prefixWeight)
+		}
+	}
+
+	if typos := maxTypos(stemmed); typos > 0 {
+		for _, term := range x.vocab {
+			if editDistance(stemmed, term, typos) <= typos {
+				match(term, typoWeight)
+			}
+		}
+	}
+	return matches
+}
+
+// maxTypos returns how many edits a word may be away from the terms it
+// matches
+func maxTypos(word string) int {
+	switch n := len([]rune(word)); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	}
+	return 0
+}
+
+// idf is the inverse document frequency of term: the fewer products have it,
+// the more it says about the ones that do
+func (x *Index) idf(term string) float64 {
+	n := float64(len(x.postings[term]))
+	total := float64(len(x.docs))
+	return math.Log(1 + (total-n+0.5)/(n+0.5))
+}
+
+// saturate combines the weighted term frequencies of the fields of a product,
+// each normalized by how long the field is compared to the average
+func (x *Index) saturate(freqs, lengths [numFields]int) float64 {
+	var tf float64
+	for field := range numFields {
+		if freqs[field] == 0 {
+			continue
+		}
+		avg := float64(x.totalLengths[field]) / float64(len(x.docs))
+		norm := 1 - b + b*float64(lengths[field])/avg
+		tf += fieldWeights[field] * float64(freqs[field]) / norm
+	}
+	return tf / (k1 + tf)
+}
+
+// Highlight returns text with the words that matched the query wrapped in
+// <mark> tags, or "" if none did. The text is HTML-escaped and, if long,
+// cut down to the part around the first match, with "…" marking what was
+// left out.
+func (r Results) Highlight(text string) string {
+	var marks []token
+	for _, t := range tokenize(text) {
+		if r.terms[stem(t.word)] {
+			marks = append(marks, t)
+		}
+	}
+	if len(marks) == 0 {
+		return ""
+	}
+
+	start, end := 0, len(text)
+	if len(text) > snippetLen {
+		start = wordStart(text, max(0, marks[0].start-snippetLen/4))
+		end = max(wordEnd(text, min(len(text), start+snippetLen)), marks[0].end)
+	}
+
+	var snippet strings.Builder
+	if start > 0 {
+		snippet.WriteString("…")
+	}
+	pos := start
+	for _, mark := range marks {
+		if mark.start < pos {
+			continue
+		}
+		if mark.end > end {
+			break
+		}
+		snippet.WriteString(html.EscapeString(text[pos:mark.start]))
+		snippet.WriteString("<mark>")
+		snippet.WriteString(html.EscapeString(text[mark.start:mark.end]))
+		snippet.WriteString("</mark>")
+		pos = mark.end
+	}
+	snippet.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		snippet.WriteString("…")
+	}
+	return snippet.String()
+}
+
+// wordStart moves i forward to the start of the next word, unless it is at
+// the start of one already
+func wordStart(text string, i int) int {
+	if i == 0 {
+		return 0
+	}
+	for _, t := range tokenize(text) {
+		if t.start >= i {
+			return t.start
+		}
+	}
+	return i
+}
+
+// wordEnd moves i back to the end of the word before it, unless it is at the
+// end of one already
+func wordEnd(text string, i int) int {
+	if i == len(text) {
+		return i
+	}
+	end := 0
+	for _, t := range tokenize(text) {
+		if t.end > i {
+			break
+		}
+		end = t.end
+	}
+	return end
+}
@@ -0,0 +1,71 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndex_Search(t *testing.T) {
+	index := NewIndex()
+	for _, product := range []generated.Product{
+		{Id: "1", Name: "USB Cable", Description: "Braided charging cable"},
+		{Id: "2", Name: "Wireless Mouse", Description: "Ergonomic mouse"},
+		{Id: "3", Name: "Mechanical Keyboard", Description: "Keyboard with a USB cable"},
+		{Id: "4", Name: "USB Hub", Description: "Four ports"},
+		{Id: "5", Name: "USB Hub", Description: "Four ports"},
+	} {
+		index.Put(product)
+	}
+
+	ids := func(results Results) []string {
+		ids := []string{}
+		for _, hit := range results.Hits {
+			ids = append(ids, hit.ID)
+		}
+		return ids
+	}
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"matches in the name rank first", "cable", []string{"1", "3"}},
+		{"matches by stem", "cables", []string{"1", "3"}},
+		{"matches prefixes", "keyb", []string{"3"}},
+		{"ties are ordered by ID", "hub", []string{"4", "5"}},
+		{"every word has to match", "usb keyboard", []string{"3"}},
+		{"no product matches every word", "wireless cable", []string{}},
+		{"one typo from four letters", "cabel", []string{"1", "3"}},
+		{"no typo under four letters", "usv", []string{}},
+		{"only one typo under eight letters", "keybrd", []string{}},
+		{"two typos from eight letters", "kyebaord", []string{"3"}},
+		{"blank query", "  ", []string{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ids(index.Search(tc.query)))
+		})
+	}
+
+	t.Run("deleted products no longer match", func(t *testing.T) {
+		index.Delete("4")
+		assert.Equal(t, []string{"5"}, ids(index.Search("hub")))
+		assert.Equal(t, 4, index.Len())
+	})
+}
+
+func TestIndex_SearchMaxHits(t *testing.T) {
+	index := NewIndex()
+	for i := range MaxHits + 10 {
+		// Every product scores the same, so the lowest IDs are kept
+		index.Put(generated.Product{Id: fmt.Sprintf("%05d", i), Name: "Cable"})
+	}
+
+	hits := index.Search("cable").Hits
+	assert.Len(t, hits, MaxHits)
+	assert.Equal(t, "00000", hits[0].ID)
+	assert.Equal(t, fmt.Sprintf("%05d", MaxHits-1), hits[MaxHits-1].ID)
+}
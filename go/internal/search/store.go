@@ -0,0 +1,143 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+)
+
+// Store is a store.Store that keeps an Index of its products. The index is
+// built from the store on the first search and updated whenever a product is
+// created, updated or deleted through the Store, so every product write must
+// go through it. It is safe for concurrent use.
+type Store struct {
+	store.Store
+
+	mu    sync.Mutex
+	index *Index // nil until the first search
+}
+
+// NewStore returns s with search
+func NewStore(s store.Store) *Store {
+	return &Store{Store: s}
+}
+
+// Search returns the products of the store that match query
+func (s *Store) Search(ctx context.Context, query string) (Results, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.index == nil {
+		products, err := s.Store.GetProducts(ctx)
+		if err != nil {
+			return Results{}, err
+		}
+		index := NewIndex()
+		for _, product := range products {
+			index.Put(product)
+		}
+		s.index = index
+	}
+	return s.index.Search(query), nil
+}
+
+func (s *Store) CreateProduct(ctx context.Context, product generated.Product) (generated.Product, error) {
+	created, err := s.Store.CreateProduct(ctx, product)
+	if err == nil {
+		s.refresh(ctx, created.Id)
+	}
+	return created, err
+}
+
+func (s *Store) UpdateProduct(ctx context.Context, id string, product generated.Product) (generated.Product, error) {
+	updated, err := s.Store.UpdateProduct(ctx, id, product)
+	if err == nil {
+		s.refresh(ctx, id)
+	}
+	return updated, err
+}
+
+func (s *Store) DeleteProduct(ctx context.Context, id string) (generated.Product, error) {
+	deleted, err := s.Store.DeleteProduct(ctx, id)
+	if err == nil {
+		s.refresh(ctx, id)
+	}
+	return deleted, err
+}
+
+// WithTx runs fn in a transaction of the wrapped store and updates the index
+// once the products fn wrote are committed
+func (s *Store) WithTx(ctx context.Context, fn func(tx store.Tx) error) error {
+	var written []string
+	err := s.Store.WithTx(ctx, func(tx store.Tx) error {
+		written = written[:0]
+		return fn(&indexedTx{Tx: tx, written: &written})
+	})
+	if err == nil {
+		s.refresh(ctx, written...)
+	}
+	return err
+}
+
+// refresh reads back the products with ids after they were written and
+// indexes them as they are now. Indexing what was written instead would go
+// wrong when two writes commit in one order and get here in the other.
+func (s *Store) refresh(ctx context.Context, ids ...string) {
+	if len(ids) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.index == nil {
+		return
+	}
+	// The write is committed even if the request that made it is canceled
+	ctx = context.WithoutCancel(ctx)
+	for _, id := range ids {
+		product, err := s.Store.GetProduct(ctx, id)
+		switch {
+		case err == nil:
+			s.index.Put(product)
+		case errors.Is(err, store.ErrNotFound):
+			s.index.Delete(id)
+		default:
+			// Rebuild the index on the next search rather than leave it stale
+			s.index = nil
+			return
+		}
+	}
+}
+
+// indexedTx records the products written in a transaction
+type indexedTx struct {
+	store.Tx
+	written *[]string
+}
+
+func (t *indexedTx) CreateProduct(ctx context.Context, product generated.Product) (generated.Product, error) {
+	created, err := t.Tx.CreateProduct(ctx, product)
+	if err == nil {
+		*t.written = append(*t.written, created.Id)
+	}
+	return created, err
+}
+
+func (t *indexedTx) UpdateProduct(ctx context.Context, id string, product generated.Product) (generated.Product, error) {
+	updated, err := t.Tx.UpdateProduct(ctx, id, product)
+	if err == nil {
+		*t.written = append(*t.written, id)
+	}
+	return updated, err
+}
+
+func (t *indexedTx) DeleteProduct(ctx context.Context, id string) (generated.Product, error) {
+	deleted, err := t.Tx.DeleteProduct(ctx, id)
+	if err == nil {
+		*t.written = append(*t.written, id)
+	}
+	return deleted, err
+}
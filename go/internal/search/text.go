@@ -0,0 +1,125 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// token is a word of a text and where it is in the text
+type token struct {
+	word       string // lower case
+	start, end int    // byte offsets
+}
+
+// tokenize splits text into lower-case words of letters and digits
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		wordRune := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case wordRune && start < 0:
+			start = i
+		case !wordRune && start >= 0:
+			tokens = append(tokens, token{word: strings.ToLower(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{word: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// terms returns the stemmed words of text, which is what the index stores
+func terms(text string) []string {
+	tokens := tokenize(text)
+	stems := make([]string, len(tokens))
+	for i, t := range tokens {
+		stems[i] = stem(t.word)
+	}
+	return stems
+}
+
+// stem strips common English inflections from a lower-case word, so that
+// "laptops" matches "laptop" and "charging" and "charged" match "charge".
+// Like the Porter stemmer, it only has to map related words to the same
+// stem, which need not be a word itself: "charge" becomes "charg".
+func stem(word string) string {
+	if utf8.RuneCountInString(word) <= 3 {
+		return word
+	}
+	switch {
+	case strings.HasSuffix(word, "sses"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "ies"):
+		word = word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "xes"), strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"), strings.HasSuffix(word, "is"):
+	case strings.HasSuffix(word, "s"):
+		word = word[:len(word)-1]
+	case strings.HasSuffix(word, "ing"):
+		word = stripSuffix(word, "ing")
+	case strings.HasSuffix(word, "eed"):
+	case strings.HasSuffix(word, "ed"):
+		word = stripSuffix(word, "ed")
+	}
+	if len(word) > 3 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "ee") {
+		word = word[:len(word)-1]
+	}
+	return word
+}
+
+// stripSuffix removes suffix from word if what remains has a vowel and at
+// least three letters, undoing a doubled final consonant ("running" becomes
+// "run")
+func stripSuffix(word, suffix string) string {
+	base := strings.TrimSuffix(word, suffix)
+	if len(base) < 3 || !strings.ContainsAny(base, "aeiouy") {
+		return word
+	}
+	if last := base[len(base)-1]; last == base[len(base)-2] && !strings.ContainsRune("aeioulsz", rune(last)) {
+		return base[:len(base)-1]
+	}
+	return base
+}
+
+// editDistance returns the optimal string alignment distance between a and b:
+// the number of inserted, deleted, substituted or swapped adjacent runes it
+// takes to turn one into the other. It gives up and returns max+1 once the
+// distance exceeds max.
+func editDistance(a, b string, max int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > max || -d > max {
+		return max + 1
+	}
+	// rows i-2, i-1 and i of the distance matrix
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
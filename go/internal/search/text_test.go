@@ -0,0 +1,51 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStem(t *testing.T) {
+	for _, tc := range []struct {
+		word string
+		want string
+	}{
+		{"laptops", "laptop"},
+		{"batteries", "battery"},
+		{"boxes", "box"},
+		{"watches", "watch"},
+		{"glasses", "glass"},
+		{"class", "class"},
+		{"status", "status"},
+		{"charge", "charg"},
+		{"charged", "charg"},
+		{"charging", "charg"},
+		{"running", "run"},
+		{"agreed", "agreed"},
+		{"free", "free"},
+		{"sing", "sing"},
+		{"cat", "cat"},
+	} {
+		assert.Equal(t, tc.want, stem(tc.word), "stem(%q)", tc.word)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		max  int
+		want int
+	}{
+		{"cable", "cable", 2, 0},
+		{"cabel", "cable", 2, 1},
+		{"cabl", "cable", 2, 1},
+		{"keyboard", "kyebaord", 2, 2},
+		{"kitten", "sitting", 3, 3},
+		{"kitten", "sitting", 2, 3},
+		{"usb", "usbcable", 2, 3},
+		{"ñandú", "nandu", 2, 2},
+	} {
+		assert.Equal(t, tc.want, editDistance(tc.a, tc.b, tc.max), "editDistance(%q, %q, %d)", tc.a, tc.b, tc.max)
+	}
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/google/uuid"
+)
+
+const (
+	// APIKeyPrefix starts every API key, so that leaked keys are easy to spot
+	APIKeyPrefix = "hts_"
+	// apiKeyPrefixLength is how much of a key is kept to tell keys apart
+	apiKeyPrefixLength = len(APIKeyPrefix) + 4
+	// apiKeyTouchInterval is how often the last-used time of a key is written
+	apiKeyTouchInterval = time.Minute
+)
+
+// ErrInvalidAPIKey is returned for unknown or revoked API keys
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// CreateAPIKey issues an API key acting as userID within scopes. The key is
+// only returned here; the store keeps its hash.
+func (s *AuthStore) CreateAPIKey(ctx context.Context, userID, name string, scopes []string) (string, store.APIKey, error) {
+	key := APIKeyPrefix + rand.Text()
+	created, err := s.users.CreateAPIKey(ctx, store.APIKey{
+		Id:        uuid.New().String(),
+		UserId:    userID,
+		Name:      name,
+		Prefix:    key[:apiKeyPrefixLength],
+		KeyHash:   tokenKey(key),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return "", store.APIKey{}, err
+	}
+	return key, created, nil
+}
+
+// ListAPIKeys returns the API keys of userID, oldest first
+func (s *AuthStore) ListAPIKeys(ctx context.Context, userID string) ([]store.APIKey, error) {
+	return s.users.GetAPIKeys(ctx, userID)
+}
+
+// RevokeAPIKey deletes an API key of userID. It returns ErrNotFound if userID
+// has no such key.
+func (s *AuthStore) RevokeAPIKey(ctx context.Context, userID, keyID string) error {
+	key, err := s.users.GetAPIKey(ctx, keyID)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && key.UserId != userID) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := s.users.DeleteAPIKey(ctx, keyID); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// ValidateAPIKey returns the user an API key acts as, with the key's scopes,
+// and records that the key was used. Keys without APIKeyPrefix are rejected
+// without looking them up.
+func (s *AuthStore) ValidateAPIKey(ctx context.Context, key string) (*AuthUser, error) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	apiKey, err := s.users.GetAPIKeyByHash(ctx, tokenKey(key))
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Roles are read on every use, so that revoking a role takes effect at once
+	user, err := s.users.GetUser(ctx, apiKey.UserId)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	credential, err := s.users.GetCredential(ctx, apiKey.UserId)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+
+	// Write the last-used time at most once per interval, not on every request
+	now := time.Now()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		apiKey.LastUsedAt = &now
+		if _, err := s.users.UpdateAPIKey(ctx, apiKey.Id, apiKey); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	authUser := NewAuthUser(user, credential.Roles)
+	authUser.APIKeyID = apiKey.Id
+	authUser.Scopes = apiKey.Scopes
+	return &authUser, nil
+}
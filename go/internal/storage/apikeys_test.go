@@ -0,0 +1,94 @@
+package storage_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const aliceID = "550e8400-e29b-41d4-a716-446655440001"
+
+func TestAuthStore_ValidateAPIKey(t *testing.T) {
+	ctx := context.Background()
+	auth, users := newTestAuthStore(t, testKeys(t))
+
+	key, created, err := auth.CreateAPIKey(ctx, aliceID, "Warehouse", []string{"products:write"})
+	require.NoError(t, err)
+
+	t.Run("should act as the owner within the key's scopes", func(t *testing.T) {
+		user, err := auth.ValidateAPIKey(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, aliceID, user.ID)
+		assert.Equal(t, created.Id, user.APIKeyID)
+		assert.Empty(t, user.SessionID)
+		assert.True(t, user.HasScope("products:write"))
+		assert.False(t, user.HasScope("orders:read"))
+	})
+
+	t.Run("should leave logins unscoped", func(t *testing.T) {
+		session, err := auth.Login(ctx, "alice@example.com", "password123", storage.ClientInfo{})
+		require.NoError(t, err)
+		user, err := auth.ValidateToken(ctx, session.Token)
+		require.NoError(t, err)
+		assert.True(t, user.HasScope("orders:read"))
+	})
+
+	t.Run("should act with the owner's current roles", func(t *testing.T) {
+		setRoles(t, users, aliceID, []string{storage.RoleAdmin})
+		user, err := auth.ValidateAPIKey(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, user.HasRole(storage.RoleAdmin))
+
+		setRoles(t, users, aliceID, nil)
+		user, err = auth.ValidateAPIKey(ctx, key)
+		require.NoError(t, err)
+		assert.False(t, user.HasRole(storage.RoleAdmin))
+	})
+
+	t.Run("should reject malformed keys", func(t *testing.T) {
+		for _, malformed := range []string{
+			"",
+			strings.TrimPrefix(key, storage.APIKeyPrefix),
+			strings.ToUpper(storage.APIKeyPrefix) + strings.TrimPrefix(key, storage.APIKeyPrefix),
+			" " + key,
+			storage.APIKeyPrefix,
+		} {
+			_, err := auth.ValidateAPIKey(ctx, malformed)
+			assert.ErrorIs(t, err, storage.ErrInvalidAPIKey, "key %q", malformed)
+		}
+	})
+
+	t.Run("should not revoke other users' keys", func(t *testing.T) {
+		err := auth.RevokeAPIKey(ctx, "550e8400-e29b-41d4-a716-446655440002", created.Id)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+		_, err = auth.ValidateAPIKey(ctx, key)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should reject revoked keys", func(t *testing.T) {
+		other, _, err := auth.CreateAPIKey(ctx, aliceID, "Other", []string{"orders:read"})
+		require.NoError(t, err)
+
+		require.NoError(t, auth.RevokeAPIKey(ctx, aliceID, created.Id))
+		_, err = auth.ValidateAPIKey(ctx, key)
+		assert.ErrorIs(t, err, storage.ErrInvalidAPIKey)
+		assert.ErrorIs(t, auth.RevokeAPIKey(ctx, aliceID, created.Id), storage.ErrNotFound)
+
+		_, err = auth.ValidateAPIKey(ctx, other)
+		assert.NoError(t, err, "other keys of the user keep working")
+	})
+
+	t.Run("should reject keys of deleted users", func(t *testing.T) {
+		bobKey, _, err := auth.CreateAPIKey(ctx, "550e8400-e29b-41d4-a716-446655440002", "Bob", []string{"orders:read"})
+		require.NoError(t, err)
+		_, err = users.DeleteUser(ctx, "550e8400-e29b-41d4-a716-446655440002")
+		require.NoError(t, err)
+
+		_, err = auth.ValidateAPIKey(ctx, bobKey)
+		assert.ErrorIs(t, err, storage.ErrInvalidAPIKey)
+	})
+}
@@ -1,122 +1,687 @@
 package storage
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
-	"sync"
+	"log"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/jwt"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
 	"github.com/google/uuid"
 )
 
+const (
+	// PasswordResetTTL is how long a password reset token stays valid
+	PasswordResetTTL = time.Hour
+	// DefaultAccessTokenTTL is how long an access token stays valid
+	DefaultAccessTokenTTL = 15 * time.Minute
+	// DefaultRefreshTokenTTL is how long a session can go without refreshing
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+	// sessionTouchInterval is how often the last-seen time of a session is written
+	sessionTouchInterval = time.Minute
+)
+
+// RoleAdmin is the role of users who manage the catalog, all orders and all
+// users. Carts are only ever their owners'.
+const RoleAdmin = string(generated.Admin)
+
 var (
 	// ErrNotFound is returned when a resource is not found
 	ErrNotFound = errors.New("not found")
+	// ErrInvalidCredentials is returned when an email and password do not match
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrInvalidResetToken is returned for unknown, used or expired password reset tokens
+	ErrInvalidResetToken = errors.New("invalid or expired reset token")
+	// ErrInvalidRefreshToken is returned for unknown, used or expired refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
 )
 
-// AuthUser represents an authenticated user
+// AuthUser represents an authenticated user. ID is the ID of the user's
+// generated.User record in the data store, SessionID the login the request's
+// access token was issued for. Requests made with an API key have no session;
+// APIKeyID is set instead and they are limited to Scopes.
 type AuthUser struct {
-	ID    string `json:"id"`
-	Email string `json:"email"`
-	Name  string `json:"name"`
+	ID        string   `json:"id"`
+	Email     string   `json:"email"`
+	Name      string   `json:"name"`
+	Roles     []string `json:"roles,omitempty"`
+	SessionID string   `json:"-"`
+	APIKeyID  string   `json:"-"`
+	Scopes    []string `json:"-"`
 }
 
-// AuthSession represents a user session with token
+// HasRole reports whether the user has been granted role
+func (u *AuthUser) HasRole(role string) bool {
+	return slices.Contains(u.Roles, role)
+}
+
+// HasScope reports whether the request may act within scope. Logins may do
+// anything their roles allow; API keys only what they were granted.
+func (u *AuthUser) HasScope(scope string) bool {
+	return u.APIKeyID == "" || slices.Contains(u.Scopes, scope)
+}
+
+// AuthSession represents a user session with its current tokens
 type AuthSession struct {
-	Token     string
-	User      AuthUser
-	ExpiresAt time.Time
+	ID           string
+	Token        string
+	RefreshToken string
+	User         AuthUser
+	ExpiresAt    time.Time // when Token expires
+}
+
+// accessClaims are the claims of an access token. They only name the user
+// and the session; the user's profile and roles are read when the token is
+// used.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	SessionID string `json:"sid"`
+}
+
+// ClientInfo describes the client a session is used from
+type ClientInfo struct {
+	Device string // User-Agent
+	IP     string
 }
 
-// AuthStore handles authentication token storage
+// SessionInfo describes an active session
+type SessionInfo struct {
+	ID         string
+	Client     ClientInfo
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+}
+
+// UserBackend looks up users and persists their password hashes, API keys,
+// sessions and tokens. Every store.Store implements it.
+type UserBackend interface {
+	WithTx(ctx context.Context, fn func(tx store.Tx) error) error
+	GetUser(ctx context.Context, id string) (generated.User, error)
+	GetCredential(ctx context.Context, userId string) (store.Credential, error)
+	GetCredentialByEmail(ctx context.Context, email string) (store.Credential, error)
+	UpdateCredential(ctx context.Context, userId string, credential store.Credential) (store.Credential, error)
+	GetAPIKeys(ctx context.Context, userId string) ([]store.APIKey, error)
+	GetAPIKey(ctx context.Context, id string) (store.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (store.APIKey, error)
+	CreateAPIKey(ctx context.Context, key store.APIKey) (store.APIKey, error)
+	UpdateAPIKey(ctx context.Context, id string, key store.APIKey) (store.APIKey, error)
+	DeleteAPIKey(ctx context.Context, id string) (store.APIKey, error)
+	GetSessionsByUserId(ctx context.Context, userId string) ([]store.Session, error)
+	GetSession(ctx context.Context, id string) (store.Session, error)
+	CreateSession(ctx context.Context, session store.Session) (store.Session, error)
+	UpdateSession(ctx context.Context, id string, session store.Session) (store.Session, error)
+	DeleteSession(ctx context.Context, id string) (store.Session, error)
+	CreateRefreshToken(ctx context.Context, token store.RefreshToken) (store.RefreshToken, error)
+	CreatePasswordReset(ctx context.Context, reset store.PasswordReset) (store.PasswordReset, error)
+	DeletePasswordReset(ctx context.Context, tokenHash string) (store.PasswordReset, error)
+	DeletePasswordResetsByUserId(ctx context.Context, userId string) error
+	DeleteExpiredTokens(ctx context.Context, now time.Time) error
+}
+
+// AuthStore issues and validates tokens. Sessions, refresh tokens and
+// password reset tokens are kept in the UserBackend, so that they survive
+// restarts and are shared by every replica. Access tokens are JWTs, accepted
+// while their signature is valid and their session has not ended. Like API
+// keys, they act with the user's current roles, so granting or revoking a
+// role takes effect on the next request.
 type AuthStore struct {
-	sessions sync.Map // map[string]AuthSession
-	users    map[string]struct {
-		password string
-		user     AuthUser
-	}
-}
-
-// NewAuthStore creates a new authentication store
-func NewAuthStore() *AuthStore {
-	// Initialize with mock users
-	return &AuthStore{
-		users: map[string]struct {
-			password string
-			user     AuthUser
-		}{
-			"alice@example.com": {
-				password: "password123", // In production, this would be hashed
-				user: AuthUser{
-					ID:    "550e8400-e29b-41d4-a716-446655440001",
-					Email: "alice@example.com",
-					Name:  "Alice Johnson",
-				},
-			},
-			"bob@example.com": {
-				password: "password456", // In production, this would be hashed
-				user: AuthUser{
-					ID:    "550e8400-e29b-41d4-a716-446655440002",
-					Email: "bob@example.com",
-					Name:  "Bob Smith",
-				},
-			},
+	users      UserBackend
+	keys       *jwt.KeySet
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	throttle   *loginThrottle
+	auditor    LoginAuditor
+}
+
+// AuthOption configures an AuthStore
+type AuthOption func(*AuthStore)
+
+// WithSigningKeys signs access tokens with keys. Without it a random key is
+// used, so tokens do not survive a restart.
+func WithSigningKeys(keys *jwt.KeySet) AuthOption {
+	return func(s *AuthStore) {
+		s.keys = keys
+	}
+}
+
+// WithTokenTTL sets how long access and refresh tokens stay valid
+func WithTokenTTL(access, refresh time.Duration) AuthOption {
+	return func(s *AuthStore) {
+		s.accessTTL = access
+		s.refreshTTL = refresh
+	}
+}
+
+// WithLoginLimits sets how failed logins are throttled. Without it
+// DefaultLoginLimits apply.
+func WithLoginLimits(limits LoginLimits) AuthOption {
+	return func(s *AuthStore) {
+		s.throttle = newLoginThrottle(limits)
+	}
+}
+
+// WithLoginAuditor sets where failed logins are recorded. Without it they
+// are written to the server log.
+func WithLoginAuditor(auditor LoginAuditor) AuthOption {
+	return func(s *AuthStore) {
+		s.auditor = auditor
+	}
+}
+
+// NewAuthStore creates a new authentication store whose users live in users
+func NewAuthStore(users UserBackend, opts ...AuthOption) *AuthStore {
+	s := &AuthStore{
+		users:      users,
+		accessTTL:  DefaultAccessTokenTTL,
+		refreshTTL: DefaultRefreshTokenTTL,
+		throttle:   newLoginThrottle(DefaultLoginLimits),
+		auditor:    logLoginAuditor{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.keys == nil {
+		s.keys = jwt.NewRandomKeySet()
+	}
+	return s
+}
+
+// AccessTokenTTL returns how long access tokens stay valid
+func (s *AuthStore) AccessTokenTTL() time.Duration {
+	return s.accessTTL
+}
+
+// NormalizeEmail returns the form emails are stored and looked up in
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Login authenticates a user and starts a session for client. Too many
+// failed logins for an email from the client's IP return a
+// *LoginLockedError until the lockout ends.
+func (s *AuthStore) Login(ctx context.Context, email, password string, client ClientInfo) (*AuthSession, error) {
+	email = NormalizeEmail(email)
+	now := time.Now()
+	if wait := s.throttle.lockedFor(email, client.IP, now); wait > 0 {
+		s.auditor.RecordLoginFailure(ctx, LoginFailure{
+			Email:       email,
+			Client:      client,
+			Reason:      LoginFailureLocked,
+			At:          now,
+			LockedUntil: now.Add(wait),
+		})
+		return nil, &LoginLockedError{RetryAfter: wait}
+	}
+
+	credential, err := s.users.GetCredentialByEmail(ctx, email)
+	if errors.Is(err, store.ErrNotFound) {
+		verifyPassword(dummyHash(), password)
+		return nil, s.loginFailed(ctx, email, client, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !verifyPassword(credential.PasswordHash, password) {
+		return nil, s.loginFailed(ctx, email, client, now)
+	}
+	user, err := s.users.GetUser(ctx, credential.UserId)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, s.loginFailed(ctx, email, client, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.throttle.succeed(email, client.IP)
+	return s.startSession(ctx, NewAuthUser(user, credential.Roles), client)
+}
+
+// StartSession logs in the user without a password, for logins verified
+// elsewhere such as by an OpenID Connect provider. The session is the same
+// as one started by Login.
+func (s *AuthStore) StartSession(ctx context.Context, userID string, client ClientInfo) (*AuthSession, error) {
+	credential, err := s.users.GetCredential(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.users.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.startSession(ctx, NewAuthUser(user, credential.Roles), client)
+}
+
+// loginFailed counts and records a login with bad credentials and returns
+// the error to report: ErrInvalidCredentials, or a *LoginLockedError if this
+// failure started a lockout
+func (s *AuthStore) loginFailed(ctx context.Context, email string, client ClientInfo, now time.Time) error {
+	lockedUntil := s.throttle.fail(email, client.IP, now)
+	s.auditor.RecordLoginFailure(ctx, LoginFailure{
+		Email:       email,
+		Client:      client,
+		Reason:      LoginFailureInvalidCredentials,
+		At:          now,
+		LockedUntil: lockedUntil,
+	})
+	if lockedUntil.IsZero() {
+		return ErrInvalidCredentials
+	}
+	return &LoginLockedError{RetryAfter: lockedUntil.Sub(now)}
+}
+
+// Refresh exchanges a refresh token for new tokens of the same session. Each
+// refresh token can be used once; using one again revokes the session, as
+// the token must have been stolen.
+func (s *AuthStore) Refresh(ctx context.Context, token string, client ClientInfo) (*AuthSession, error) {
+	var (
+		current store.Session
+		reused  bool
+	)
+	err := s.users.WithTx(ctx, func(tx store.Tx) error {
+		refresh, err := tx.GetRefreshToken(ctx, tokenKey(token))
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return err
+		}
+		if refresh.Used {
+			// The revocation is committed, so the error is returned afterwards
+			reused = true
+			if _, err := tx.DeleteSession(ctx, refresh.SessionId); err != nil && !errors.Is(err, store.ErrNotFound) {
+				return err
+			}
+			return nil
+		}
+		if time.Now().After(refresh.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+		refresh.Used = true
+		if _, err := tx.UpdateRefreshToken(ctx, refresh.TokenHash, refresh); err != nil {
+			return err
+		}
+		current, err = tx.GetSession(ctx, refresh.SessionId)
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// Read the user again so that profile and role changes are picked up
+	user, err := s.users.GetUser(ctx, current.UserId)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, s.revokeInvalid(ctx, current.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	credential, err := s.users.GetCredential(ctx, current.UserId)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, s.revokeInvalid(ctx, current.Id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	current.Device = client.Device
+	current.IP = client.IP
+	current.LastSeenAt = now
+	current.ExpiresAt = now.Add(s.refreshTTL)
+	_, err = s.users.UpdateSession(ctx, current.Id, current)
+	if errors.Is(err, store.ErrNotFound) {
+		// Revoked since the token was checked
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, current, NewAuthUser(user, credential.Roles))
+}
+
+// revokeInvalid ends a session whose refresh token can no longer be honored
+// and returns ErrInvalidRefreshToken, or the error ending it
+func (s *AuthStore) revokeInvalid(ctx context.Context, sessionID string) error {
+	if err := s.revokeSession(ctx, sessionID); err != nil {
+		return err
+	}
+	return ErrInvalidRefreshToken
+}
+
+// startSession starts a session of user for client and issues its first tokens
+func (s *AuthStore) startSession(ctx context.Context, user AuthUser, client ClientInfo) (*AuthSession, error) {
+	now := time.Now()
+	session, err := s.users.CreateSession(ctx, store.Session{
+		Id:         uuid.New().String(),
+		UserId:     user.ID,
+		Device:     client.Device,
+		IP:         client.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(s.refreshTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, session, user)
+}
+
+// issue signs an access token for user and stores a new refresh token of
+// session, which expires with it
+func (s *AuthStore) issue(ctx context.Context, session store.Session, user AuthUser) (*AuthSession, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.accessTTL)
+	accessToken, err := s.keys.Sign(accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
+			Subject:   user.ID,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiresAt.Unix(),
 		},
+		SessionID: session.Id,
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	token := rand.Text()
+	if _, err := s.users.CreateRefreshToken(ctx, store.RefreshToken{
+		TokenHash: tokenKey(token),
+		SessionId: session.Id,
+		ExpiresAt: session.ExpiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
+	user.SessionID = session.Id
+	return &AuthSession{
+		ID:           session.Id,
+		Token:        accessToken,
+		RefreshToken: token,
+		User:         user,
+		ExpiresAt:    expiresAt,
+	}, nil
 }
 
-// Login authenticates a user and returns a token
-func (s *AuthStore) Login(email, password string) (*AuthSession, error) {
-	user, exists := s.users[email]
-	if !exists || user.password != password {
-		return nil, ErrNotFound
+// GrantRole gives role to the user with email, in the sessions they already
+// have too
+func (s *AuthStore) GrantRole(ctx context.Context, email, role string) error {
+	return s.users.WithTx(ctx, func(tx store.Tx) error {
+		credential, err := tx.GetCredentialByEmail(ctx, NormalizeEmail(email))
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if slices.Contains(credential.Roles, role) {
+			return nil
+		}
+		credential.Roles = append(slices.Clip(credential.Roles), role)
+		credential.UpdatedAt = time.Now()
+		_, err = tx.UpdateCredential(ctx, credential.UserId, credential)
+		return err
+	})
+}
+
+// ChangePassword replaces the password of userID after checking the current one
+func (s *AuthStore) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	credential, err := s.users.GetCredential(ctx, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrInvalidCredentials
+	}
+	if err != nil {
+		return err
+	}
+	if !verifyPassword(credential.PasswordHash, currentPassword) {
+		return ErrInvalidCredentials
+	}
+	return s.setPassword(ctx, credential, newPassword)
+}
+
+// RequestPasswordReset issues a single-use token that resets the password of
+// the account with email. It returns ErrNotFound if there is no such account.
+func (s *AuthStore) RequestPasswordReset(ctx context.Context, email string) (string, error) {
+	credential, err := s.users.GetCredentialByEmail(ctx, NormalizeEmail(email))
+	if errors.Is(err, store.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	token := rand.Text()
+	if _, err := s.users.CreatePasswordReset(ctx, store.PasswordReset{
+		TokenHash: tokenKey(token),
+		UserId:    credential.UserId,
+		ExpiresAt: time.Now().Add(PasswordResetTTL),
+	}); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ResetPassword sets a new password with a token from RequestPasswordReset
+// and logs the user out everywhere
+func (s *AuthStore) ResetPassword(ctx context.Context, token, newPassword string) error {
+	reset, err := s.users.DeletePasswordReset(ctx, tokenKey(token))
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	if time.Now().After(reset.ExpiresAt) {
+		return ErrInvalidResetToken
 	}
 
-	// Generate token
-	token := uuid.New().String()
-	session := AuthSession{
-		Token:     token,
-		User:      user.user,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+	credential, err := s.users.GetCredential(ctx, reset.UserId)
+	if errors.Is(err, store.ErrNotFound) {
+		return ErrInvalidResetToken
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.setPassword(ctx, credential, newPassword); err != nil {
+		return err
 	}
 
-	// Store session
-	s.sessions.Store(token, session)
+	return s.RevokeUserSessions(ctx, reset.UserId, "")
+}
+
+// setPassword stores a new password hash and invalidates outstanding reset tokens
+func (s *AuthStore) setPassword(ctx context.Context, credential store.Credential, password string) error {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	credential.PasswordHash = hash
+	credential.UpdatedAt = time.Now()
+	if _, err := s.users.UpdateCredential(ctx, credential.UserId, credential); err != nil {
+		return err
+	}
+	return s.users.DeletePasswordResetsByUserId(ctx, credential.UserId)
+}
 
-	return &session, nil
+// Logout ends the session of an access token
+func (s *AuthStore) Logout(ctx context.Context, token string) error {
+	user, err := s.ValidateToken(ctx, token)
+	if err != nil {
+		return err
+	}
+	return s.revokeSession(ctx, user.SessionID)
 }
 
-// Logout removes a session
-func (s *AuthStore) Logout(token string) error {
-	s.sessions.Delete(token)
+// RevokeUserSessions ends every session of userID except exceptSessionID
+func (s *AuthStore) RevokeUserSessions(ctx context.Context, userID, exceptSessionID string) error {
+	sessions, err := s.users.GetSessionsByUserId(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if session.Id == exceptSessionID {
+			continue
+		}
+		if err := s.revokeSession(ctx, session.Id); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// ValidateToken checks if a token is valid and returns the user
-func (s *AuthStore) ValidateToken(token string) (*AuthUser, error) {
-	value, ok := s.sessions.Load(token)
-	if !ok {
-		return nil, ErrNotFound
+// ListSessions returns the active sessions of userID, most recently used first
+func (s *AuthStore) ListSessions(ctx context.Context, userID string) ([]SessionInfo, error) {
+	stored, err := s.users.GetSessionsByUserId(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sessions := []SessionInfo{}
+	for _, session := range stored {
+		if now.Before(session.ExpiresAt) {
+			sessions = append(sessions, SessionInfo{
+				ID:         session.Id,
+				Client:     ClientInfo{Device: session.Device, IP: session.IP},
+				CreatedAt:  session.CreatedAt,
+				LastSeenAt: session.LastSeenAt,
+			})
+		}
+	}
+	slices.SortFunc(sessions, func(a, b SessionInfo) int {
+		return b.LastSeenAt.Compare(a.LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession ends a session of userID. It returns ErrNotFound if userID
+// has no such session.
+func (s *AuthStore) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	session, err := s.users.GetSession(ctx, sessionID)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && session.UserId != userID) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
 	}
+	return s.revokeSession(ctx, sessionID)
+}
 
-	session := value.(AuthSession)
+// revokeSession ends a session. Its refresh tokens are dropped and its
+// access tokens are rejected from then on.
+func (s *AuthStore) revokeSession(ctx context.Context, sessionID string) error {
+	if _, err := s.users.DeleteSession(ctx, sessionID); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	return nil
+}
 
-	// Check if token is expired
-	if time.Now().After(session.ExpiresAt) {
-		s.sessions.Delete(token)
+// ValidateToken checks the signature and expiry of an access token and that
+// its session has not ended, records that the session was seen and returns
+// its user with the user's current profile and roles
+func (s *AuthStore) ValidateToken(ctx context.Context, token string) (*AuthUser, error) {
+	var claims accessClaims
+	if err := s.keys.Verify(token, &claims); err != nil {
+		return nil, ErrNotFound
+	}
+	session, err := s.users.GetSession(ctx, claims.SessionID)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && session.UserId != claims.Subject) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.users.GetUser(ctx, session.UserId)
+	if errors.Is(err, store.ErrNotFound) {
 		return nil, ErrNotFound
 	}
+	if err != nil {
+		return nil, err
+	}
+	credential, err := s.users.GetCredential(ctx, session.UserId)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+
+	// Write the last-seen time at most once per interval, not on every request
+	if time.Since(session.LastSeenAt) >= sessionTouchInterval {
+		if err := s.touchSession(ctx, session.Id); err != nil {
+			return nil, err
+		}
+	}
 
-	return &session.User, nil
+	authUser := NewAuthUser(user, credential.Roles)
+	authUser.SessionID = session.Id
+	return &authUser, nil
 }
 
-// CleanupExpiredTokens removes expired sessions
-func (s *AuthStore) CleanupExpiredTokens() {
-	now := time.Now()
-	s.sessions.Range(func(key, value any) bool {
-		session := value.(AuthSession)
-		if now.After(session.ExpiresAt) {
-			s.sessions.Delete(key)
+// touchSession sets the last-seen time of a session to now. The session is
+// read again in a transaction, so that a concurrent refresh is not undone.
+func (s *AuthStore) touchSession(ctx context.Context, sessionID string) error {
+	return s.users.WithTx(ctx, func(tx store.Tx) error {
+		session, err := tx.GetSession(ctx, sessionID)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
 		}
-		return true
+		session.LastSeenAt = time.Now()
+		_, err = tx.UpdateSession(ctx, sessionID, session)
+		return err
 	})
 }
+
+// CleanupExpiredTokens removes expired sessions, refresh tokens and password
+// reset tokens, and stale failed login counts
+func (s *AuthStore) CleanupExpiredTokens(ctx context.Context) error {
+	now := time.Now()
+	s.throttle.cleanup(now)
+	return s.users.DeleteExpiredTokens(ctx, now)
+}
+
+// RunJanitor calls CleanupExpiredTokens every interval until ctx is done
+func (s *AuthStore) RunJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.CleanupExpiredTokens(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("Failed to clean up expired tokens: %v", err)
+			}
+		}
+	}
+}
+
+// NewAuthUser returns the identity of user with roles as attached to sessions
+func NewAuthUser(user generated.User, roles []string) AuthUser {
+	return AuthUser{
+		ID:    user.Id,
+		Email: user.Email,
+		Name:  user.Name,
+		Roles: roles,
+	}
+}
+
+// tokenKey returns the key a reset token, refresh token or API key is stored
+// under, so that the tokens themselves are never kept
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
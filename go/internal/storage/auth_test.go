@@ -0,0 +1,175 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/jwt"
+	"github.com/blck-snwmn/hello-typespec/go/internal/storage"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAuthStore returns an auth store over the demo data, signing with
+// keys
+func newTestAuthStore(t testing.TB, keys *jwt.KeySet) (*storage.AuthStore, store.Store) {
+	t.Helper()
+
+	users := store.NewMemoryStore()
+	require.NoError(t, store.SeedDemoData(t.Context(), users))
+	return storage.NewAuthStore(users, storage.WithSigningKeys(keys)), users
+}
+
+func testKeys(t testing.TB) *jwt.KeySet {
+	t.Helper()
+
+	keys, err := jwt.NewKeySet(jwt.NewHS256Key("test", []byte("0123456789abcdef0123456789abcdef")))
+	require.NoError(t, err)
+	return keys
+}
+
+func setRoles(t testing.TB, users store.Store, userID string, roles []string) {
+	t.Helper()
+
+	credential, err := users.GetCredential(t.Context(), userID)
+	require.NoError(t, err)
+	credential.Roles = roles
+	_, err = users.UpdateCredential(t.Context(), userID, credential)
+	require.NoError(t, err)
+}
+
+func TestAuthStore_ValidateToken(t *testing.T) {
+	ctx := context.Background()
+	keys := testKeys(t)
+	auth, users := newTestAuthStore(t, keys)
+
+	login := func(t *testing.T) *storage.AuthSession {
+		session, err := auth.Login(ctx, "alice@example.com", "password123", storage.ClientInfo{Device: "test", IP: "192.0.2.1"})
+		require.NoError(t, err)
+		return session
+	}
+
+	t.Run("should return the session's user", func(t *testing.T) {
+		session := login(t)
+
+		user, err := auth.ValidateToken(ctx, session.Token)
+		require.NoError(t, err)
+		assert.Equal(t, session.User.ID, user.ID)
+		assert.Equal(t, "alice@example.com", user.Email)
+		assert.Equal(t, session.ID, user.SessionID)
+		assert.Empty(t, user.APIKeyID)
+	})
+
+	t.Run("should act with the current roles and profile", func(t *testing.T) {
+		session := login(t)
+		require.False(t, session.User.HasRole(storage.RoleAdmin))
+
+		setRoles(t, users, session.User.ID, []string{storage.RoleAdmin})
+		user, err := auth.ValidateToken(ctx, session.Token)
+		require.NoError(t, err)
+		assert.True(t, user.HasRole(storage.RoleAdmin), "a granted role applies to tokens issued before")
+
+		profile, err := users.GetUser(ctx, session.User.ID)
+		require.NoError(t, err)
+		profile.Name = "Alice Renamed"
+		_, err = users.UpdateUser(ctx, profile.Id, profile)
+		require.NoError(t, err)
+
+		setRoles(t, users, session.User.ID, nil)
+		user, err = auth.ValidateToken(ctx, session.Token)
+		require.NoError(t, err)
+		assert.False(t, user.HasRole(storage.RoleAdmin), "a revoked role is gone at once")
+		assert.Equal(t, "Alice Renamed", user.Name)
+	})
+
+	t.Run("should reject tokens of ended sessions", func(t *testing.T) {
+		session := login(t)
+		require.NoError(t, auth.Logout(ctx, session.Token))
+
+		_, err := auth.ValidateToken(ctx, session.Token)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("should reject tokens of deleted users", func(t *testing.T) {
+		session, err := auth.Login(ctx, "bob@example.com", "password456", storage.ClientInfo{})
+		require.NoError(t, err)
+		_, err = users.DeleteUser(ctx, session.User.ID)
+		require.NoError(t, err)
+
+		_, err = auth.ValidateToken(ctx, session.Token)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("should reject a session claimed for another user", func(t *testing.T) {
+		session := login(t)
+		forged, err := keys.Sign(map[string]any{
+			"sub": "550e8400-e29b-41d4-a716-446655440002",
+			"sid": session.ID,
+			"exp": time.Now().Add(time.Minute).Unix(),
+		})
+		require.NoError(t, err)
+
+		_, err = auth.ValidateToken(ctx, forged)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("should reject tokens it did not sign", func(t *testing.T) {
+		other, _ := newTestAuthStore(t, jwt.NewRandomKeySet())
+		session, err := other.Login(ctx, "alice@example.com", "password123", storage.ClientInfo{})
+		require.NoError(t, err)
+
+		_, err = auth.ValidateToken(ctx, session.Token)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("should record when the session was last seen once per minute", func(t *testing.T) {
+		session := login(t)
+		stored, err := users.GetSession(ctx, session.ID)
+		require.NoError(t, err)
+		stored.LastSeenAt = time.Now().Add(-2 * time.Minute)
+		_, err = users.UpdateSession(ctx, session.ID, stored)
+		require.NoError(t, err)
+
+		before := time.Now()
+		_, err = auth.ValidateToken(ctx, session.Token)
+		require.NoError(t, err)
+		seen, err := users.GetSession(ctx, session.ID)
+		require.NoError(t, err)
+		assert.False(t, seen.LastSeenAt.Before(before))
+
+		_, err = auth.ValidateToken(ctx, session.Token)
+		require.NoError(t, err)
+		again, err := users.GetSession(ctx, session.ID)
+		require.NoError(t, err)
+		assert.True(t, seen.LastSeenAt.Equal(again.LastSeenAt), "not written again within the minute")
+	})
+}
+
+func TestAuthStore_Refresh(t *testing.T) {
+	ctx := context.Background()
+	auth, users := newTestAuthStore(t, testKeys(t))
+
+	session, err := auth.Login(ctx, "alice@example.com", "password123", storage.ClientInfo{})
+	require.NoError(t, err)
+
+	refreshed, err := auth.Refresh(ctx, session.RefreshToken, storage.ClientInfo{Device: "new device"})
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, refreshed.ID, "the session stays the same")
+	assert.NotEqual(t, session.RefreshToken, refreshed.RefreshToken)
+	sessions, err := auth.ListSessions(ctx, session.User.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "new device", sessions[0].Client.Device)
+
+	// Reusing a refresh token ends the session
+	_, err = auth.Refresh(ctx, session.RefreshToken, storage.ClientInfo{})
+	assert.ErrorIs(t, err, storage.ErrInvalidRefreshToken)
+	_, err = auth.Refresh(ctx, refreshed.RefreshToken, storage.ClientInfo{})
+	assert.ErrorIs(t, err, storage.ErrInvalidRefreshToken)
+	_, err = auth.ValidateToken(ctx, refreshed.Token)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = users.GetSession(ctx, session.ID)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// MinPasswordLength is the shortest password accepted for new credentials
+	MinPasswordLength = 8
+	// MaxPasswordLength is the longest password bcrypt can hash, in bytes
+	MaxPasswordLength = 72
+)
+
+// ErrInvalidPassword is returned when a new password does not meet the password policy
+var ErrInvalidPassword = errors.New("invalid password")
+
+// ValidatePassword checks a new password against the password policy
+func ValidatePassword(password string) error {
+	if len([]rune(password)) < MinPasswordLength {
+		return fmt.Errorf("%w: must be at least %d characters", ErrInvalidPassword, MinPasswordLength)
+	}
+	if len(password) > MaxPasswordLength {
+		return fmt.Errorf("%w: must be at most %d bytes", ErrInvalidPassword, MaxPasswordLength)
+	}
+	return nil
+}
+
+// HashPassword returns the bcrypt hash stored for password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// verifyPassword reports whether password matches hash. The comparison takes
+// constant time with respect to the password.
+func verifyPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// dummyHash is verified against when an email is unknown, so that failed
+// logins take as long for missing accounts as for wrong passwords
+var dummyHash = sync.OnceValue(func() string {
+	hash, _ := HashPassword("dummy password")
+	return hash
+})
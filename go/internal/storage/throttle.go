@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// LoginLimits configures how failed logins are throttled. Failures are
+// counted over a sliding Window per email and client IP, per email from any
+// IP and per IP for any email. MaxFailures, MaxFailuresPerEmail and
+// MaxFailuresPerIP of them, respectively, lock out logins for BaseLockout,
+// and each lockout that follows before the failures have gone quiet for a
+// Window doubles it, up to MaxLockout. A zero maximum turns its count off.
+type LoginLimits struct {
+	MaxFailures         int
+	MaxFailuresPerEmail int
+	MaxFailuresPerIP    int
+	Window              time.Duration
+	BaseLockout         time.Duration
+	MaxLockout          time.Duration
+}
+
+// DefaultLoginLimits allows five failed logins per email and IP, 20 per
+// email and 100 per IP every 15 minutes, then locks out for one minute,
+// doubling up to an hour
+var DefaultLoginLimits = LoginLimits{
+	MaxFailures:         5,
+	MaxFailuresPerEmail: 20,
+	MaxFailuresPerIP:    100,
+	Window:              15 * time.Minute,
+	BaseLockout:         time.Minute,
+	MaxLockout:          time.Hour,
+}
+
+// LoginLockedError is returned by Login while an email, an IP or the pair of
+// them is locked out
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed logins, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// Reasons a login failed, as recorded in LoginFailure
+const (
+	LoginFailureInvalidCredentials = "invalid_credentials"
+	LoginFailureLocked             = "locked"
+)
+
+// LoginFailure is the audit record of a failed login
+type LoginFailure struct {
+	Email       string // normalized
+	Client      ClientInfo
+	Reason      string
+	At          time.Time
+	LockedUntil time.Time // zero unless the failure started a lockout
+}
+
+// LoginAuditor records failed logins
+type LoginAuditor interface {
+	RecordLoginFailure(ctx context.Context, failure LoginFailure)
+}
+
+// logLoginAuditor writes failed logins to the server log
+type logLoginAuditor struct{}
+
+func (logLoginAuditor) RecordLoginFailure(ctx context.Context, failure LoginFailure) {
+	if failure.LockedUntil.IsZero() {
+		log.Printf("Failed login for %s from %s: %s", failure.Email, failure.Client.IP, failure.Reason)
+		return
+	}
+	log.Printf("Failed login for %s from %s: %s, locked until %s",
+		failure.Email, failure.Client.IP, failure.Reason, failure.LockedUntil.Format(time.RFC3339))
+}
+
+// loginAttempts are the recent failed logins counted under one key
+type loginAttempts struct {
+	failures    []time.Time // within the window
+	lastFailure time.Time
+	lockouts    int // lockouts in a row, for the backoff
+	lockedUntil time.Time
+}
+
+// loginThrottle tracks failed logins per email and IP, per email and per IP
+type loginThrottle struct {
+	mu       sync.Mutex
+	limits   LoginLimits
+	attempts map[string]*loginAttempts
+}
+
+func newLoginThrottle(limits LoginLimits) *loginThrottle {
+	return &loginThrottle{limits: limits, attempts: make(map[string]*loginAttempts)}
+}
+
+// throttleWindow is a count of failed logins and the most it may reach
+type throttleWindow struct {
+	key         string
+	maxFailures int
+}
+
+// windows returns the counts a failed login of email from ip goes into.
+// Clients without a known IP are only counted per email.
+func (t *loginThrottle) windows(email, ip string) []throttleWindow {
+	windows := []throttleWindow{
+		{"pair\x00" + email + "\x00" + ip, t.limits.MaxFailures},
+		{"email\x00" + email, t.limits.MaxFailuresPerEmail},
+	}
+	if ip != "" {
+		windows = append(windows, throttleWindow{"ip\x00" + ip, t.limits.MaxFailuresPerIP})
+	}
+	return windows
+}
+
+// lockedFor returns how long logins of email from ip stay locked out, or 0
+func (t *loginThrottle) lockedFor(email, ip string, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var wait time.Duration
+	for _, w := range t.windows(email, ip) {
+		if a, ok := t.attempts[w.key]; ok && now.Before(a.lockedUntil) {
+			wait = max(wait, a.lockedUntil.Sub(now))
+		}
+	}
+	return wait
+}
+
+// fail records a failed login and returns when the longest lockout it
+// causes ends, or the zero time
+func (t *loginThrottle) fail(email, ip string, now time.Time) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var lockedUntil time.Time
+	for _, w := range t.windows(email, ip) {
+		if w.maxFailures <= 0 {
+			continue
+		}
+		a, ok := t.attempts[w.key]
+		if !ok {
+			a = &loginAttempts{}
+			t.attempts[w.key] = a
+		}
+		if until := t.count(a, w.maxFailures, now); until.After(lockedUntil) {
+			lockedUntil = until
+		}
+	}
+	return lockedUntil
+}
+
+// count adds a failure to a and returns when the lockout it causes ends, or
+// the zero time
+func (t *loginThrottle) count(a *loginAttempts, maxFailures int, now time.Time) time.Time {
+	if now.Sub(a.lastFailure) > t.limits.Window {
+		a.lockouts = 0
+	}
+	a.lastFailure = now
+
+	// Slide the window
+	start := now.Add(-t.limits.Window)
+	kept := a.failures[:0]
+	for _, at := range a.failures {
+		if at.After(start) {
+			kept = append(kept, at)
+		}
+	}
+	a.failures = append(kept, now)
+	if len(a.failures) < maxFailures {
+		return time.Time{}
+	}
+
+	lockout := t.limits.BaseLockout
+	for range a.lockouts {
+		if lockout >= t.limits.MaxLockout {
+			break
+		}
+		lockout *= 2
+	}
+	lockout = min(lockout, t.limits.MaxLockout)
+	a.failures = nil
+	a.lockouts++
+	a.lockedUntil = now.Add(lockout)
+	return a.lockedUntil
+}
+
+// succeed forgets the failed logins of the pair. Those counted per email
+// and per IP stand, as they may be an attacker's.
+func (t *loginThrottle) succeed(email, ip string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.attempts, t.windows(email, ip)[0].key)
+}
+
+// cleanup drops counts that are neither locked out nor failed within the
+// window
+func (t *loginThrottle) cleanup(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, a := range t.attempts {
+		if !now.Before(a.lockedUntil) && now.Sub(a.lastFailure) > t.limits.Window {
+			delete(t.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// throttleStep is a login at an offset from the start of a test: a failure,
+// or a success if succeed is set
+type throttleStep struct {
+	at        time.Duration
+	email, ip string
+	succeed   bool
+	lockedFor time.Duration // how long the login is locked out beforehand
+	lockout   time.Duration // the lockout a failure starts, 0 for none
+}
+
+func TestLoginThrottle(t *testing.T) {
+	limits := LoginLimits{
+		MaxFailures:         3,
+		MaxFailuresPerEmail: 5,
+		MaxFailuresPerIP:    4,
+		Window:              10 * time.Minute,
+		BaseLockout:         time.Minute,
+		MaxLockout:          4 * time.Minute,
+	}
+	// Only the pair is counted, for the tests of the backoff
+	pairOnly := limits
+	pairOnly.MaxFailuresPerEmail = 0
+	pairOnly.MaxFailuresPerIP = 0
+	const s = time.Second
+	const m = time.Minute
+
+	for _, tc := range []struct {
+		name   string
+		limits LoginLimits
+		steps  []throttleStep
+	}{
+		{"locks out the pair after the maximum failures", limits, []throttleStep{
+			{at: 0, email: "a", ip: "1"},
+			{at: 1 * s, email: "a", ip: "1"},
+			{at: 2 * s, email: "a", ip: "1", lockout: m},
+			{at: 32 * s, email: "a", ip: "1", succeed: true, lockedFor: 30 * s},
+			{at: 62 * s, email: "a", ip: "1", succeed: true},
+		}},
+		{"doubles lockouts in a row up to the maximum", pairOnly, []throttleStep{
+			{at: 0, email: "a", ip: "1"},
+			{at: 1 * s, email: "a", ip: "1"},
+			{at: 2 * s, email: "a", ip: "1", lockout: m},
+			{at: 70 * s, email: "a", ip: "1"},
+			{at: 71 * s, email: "a", ip: "1"},
+			{at: 72 * s, email: "a", ip: "1", lockout: 2 * m},
+			{at: 200 * s, email: "a", ip: "1"},
+			{at: 201 * s, email: "a", ip: "1"},
+			{at: 202 * s, email: "a", ip: "1", lockout: 4 * m},
+			{at: 500 * s, email: "a", ip: "1"},
+			{at: 501 * s, email: "a", ip: "1"},
+			{at: 502 * s, email: "a", ip: "1", lockout: 4 * m},
+		}},
+		{"starts over once failures go quiet for a window", pairOnly, []throttleStep{
+			{at: 0, email: "a", ip: "1"},
+			{at: 1 * s, email: "a", ip: "1"},
+			{at: 2 * s, email: "a", ip: "1", lockout: m},
+			{at: 11 * m, email: "a", ip: "1"},
+			{at: 11*m + 1*s, email: "a", ip: "1"},
+			{at: 11*m + 2*s, email: "a", ip: "1", lockout: m},
+		}},
+		{"slides failures out of the window", pairOnly, []throttleStep{
+			{at: 0, email: "a", ip: "1"},
+			{at: 1 * m, email: "a", ip: "1"},
+			{at: 10*m + 30*s, email: "a", ip: "1"},
+			{at: 10*m + 31*s, email: "a", ip: "1", lockout: m},
+		}},
+		{"limits failures per IP across emails", limits, []throttleStep{
+			{at: 0, email: "a", ip: "1"},
+			{at: 1 * s, email: "b", ip: "1"},
+			{at: 2 * s, email: "c", ip: "1"},
+			{at: 3 * s, email: "d", ip: "1", lockout: m},
+			{at: 4 * s, email: "e", ip: "1", succeed: true, lockedFor: 59 * s},
+			{at: 5 * s, email: "a", ip: "2", succeed: true},
+		}},
+		{"limits failures per email across IPs", limits, []throttleStep{
+			{at: 0, email: "a", ip: "1"},
+			{at: 1 * s, email: "a", ip: "2"},
+			{at: 2 * s, email: "a", ip: "3"},
+			{at: 3 * s, email: "a", ip: "4"},
+			{at: 4 * s, email: "a", ip: "5", lockout: m},
+			{at: 5 * s, email: "a", ip: "6", succeed: true, lockedFor: 59 * s},
+			{at: 6 * s, email: "b", ip: "6", succeed: true},
+		}},
+		{"forgets the pair on success but not the email", LoginLimits{
+			MaxFailures:         3,
+			MaxFailuresPerEmail: 5,
+			Window:              10 * time.Minute,
+			BaseLockout:         time.Minute,
+			MaxLockout:          4 * time.Minute,
+		}, []throttleStep{
+			{at: 0, email: "a", ip: "1"},
+			{at: 1 * s, email: "a", ip: "1"},
+			{at: 2 * s, email: "a", ip: "1", succeed: true},
+			{at: 3 * s, email: "a", ip: "1"},
+			{at: 4 * s, email: "a", ip: "1"},
+			{at: 5 * s, email: "a", ip: "2", lockout: m},
+		}},
+		{"counts clients without an IP per email only", limits, []throttleStep{
+			{at: 0, email: "a", ip: ""},
+			{at: 1 * s, email: "b", ip: ""},
+			{at: 2 * s, email: "c", ip: ""},
+			{at: 3 * s, email: "d", ip: ""},
+			{at: 4 * s, email: "e", ip: ""},
+			{at: 5 * s, email: "a", ip: ""},
+			{at: 6 * s, email: "a", ip: "", lockout: m},
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			throttle := newLoginThrottle(tc.limits)
+			start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			for i, step := range tc.steps {
+				now := start.Add(step.at)
+				assert.Equal(t, step.lockedFor, throttle.lockedFor(step.email, step.ip, now), "locked out before step %d", i)
+				if step.succeed {
+					throttle.succeed(step.email, step.ip)
+					continue
+				}
+				var lockout time.Duration
+				if until := throttle.fail(step.email, step.ip, now); !until.IsZero() {
+					lockout = until.Sub(now)
+				}
+				assert.Equal(t, step.lockout, lockout, "lockout of step %d", i)
+			}
+		})
+	}
+}
+
+func TestLoginThrottle_Cleanup(t *testing.T) {
+	throttle := newLoginThrottle(LoginLimits{MaxFailures: 2, Window: 10 * time.Minute, BaseLockout: 20 * time.Minute, MaxLockout: time.Hour})
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	throttle.fail("locked", "1", start)
+	throttle.fail("locked", "1", start)
+	throttle.fail("stale", "1", start)
+	throttle.fail("counted", "1", start.Add(10*time.Minute))
+
+	// Lockouts and failures within the window are kept
+	throttle.cleanup(start.Add(16 * time.Minute))
+	assert.Len(t, throttle.attempts, 2)
+	assert.Positive(t, throttle.lockedFor("locked", "1", start.Add(16*time.Minute)))
+
+	throttle.cleanup(start.Add(21 * time.Minute))
+	assert.Len(t, throttle.attempts, 0)
+}
@@ -0,0 +1,258 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqlDialect is how a SQL backend spells the parts of a listing
+type sqlDialect struct {
+	// placeholder returns the placeholder of the nth argument, from 1
+	placeholder func(n int) string
+	// arg converts a value into a query argument
+	arg func(v any) any
+	// sortColumns are the columns listings are sorted by, and idColumn the
+	// ID column as it breaks ties
+	sortColumns map[SortField]string
+	idColumn    string
+	// noLimit is the LIMIT that returns every row
+	noLimit string
+}
+
+// sqlStatement is a query with its arguments
+type sqlStatement struct {
+	query string
+	args  []any
+}
+
+// sqlListing builds the statements that return a page of the rows of a table
+// that match a query
+type sqlListing struct {
+	dialect sqlDialect
+	table   string
+	sort    Sort
+	page    Page
+	column  string
+	filters []string
+	args    []any
+}
+
+// newSQLListing starts the listing of table for sort and page, which may
+// only sort by fields
+func newSQLListing(dialect sqlDialect, table string, sort Sort, page Page, fields ...SortField) (*sqlListing, error) {
+	if err := checkPage(sort, page, fields...); err != nil {
+		return nil, err
+	}
+	column, ok := dialect.sortColumns[sort.Field]
+	if !ok {
+		return nil, unsupportedSort(sort)
+	}
+	return &sqlListing{dialect: dialect, table: table, sort: sort, page: page, column: column}, nil
+}
+
+// where keeps the rows for which condition holds. condition refers to values
+// as %s, in order.
+func (l *sqlListing) where(condition string, values ...any) {
+	for _, value := range values {
+		condition = strings.Replace(condition, "%s", l.placeholder(&l.args, value), 1)
+	}
+	l.filters = append(l.filters, condition)
+}
+
+// whereIn keeps the rows whose column is one of values, unless values is nil
+func (l *sqlListing) whereIn(column string, values []string) {
+	switch {
+	case values == nil:
+	case len(values) == 0:
+		l.filters = append(l.filters, "1 = 0")
+	default:
+		placeholders := make([]string, len(values))
+		for i, value := range values {
+			placeholders[i] = l.placeholder(&l.args, value)
+		}
+		l.filters = append(l.filters, column+" IN ("+strings.Join(placeholders, ", ")+")")
+	}
+}
+
+// productListing starts the listing of the products query selects
+func productListing(dialect sqlDialect, query ProductQuery) (*sqlListing, error) {
+	listing, err := newSQLListing(dialect, "products", query.Sort, query.Page, SortByName, SortByPrice, SortByCreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	listing.whereIn("id", query.IDs)
+	listing.whereIn("category_id", query.CategoryIDs)
+	if query.MinPrice != nil {
+		listing.where("price >= %s", *query.MinPrice)
+	}
+	if query.MaxPrice != nil {
+		listing.where("price <= %s", *query.MaxPrice)
+	}
+	return listing, nil
+}
+
+// productCounts returns the number of products query matches for each
+// category, price range and whether they are in stock. The ranges are
+// numbered as priceRange numbers them.
+func productCounts(dialect sqlDialect, query ProductQuery, priceBounds []float32) (sqlStatement, error) {
+	// Counts are neither sorted nor paged
+	query.Sort, query.Page = Sort{Field: SortByCreatedAt}, Page{}
+	listing, err := productListing(dialect, query)
+	if err != nil {
+		return sqlStatement{}, err
+	}
+	// The bounds are numbers, written into the query so that the arguments
+	// of the filters stay in order
+	priceRange := "0"
+	if len(priceBounds) > 0 {
+		priceRange = "CASE"
+		for i, bound := range priceBounds {
+			priceRange += " WHEN price < " + strconv.FormatFloat(float64(bound), 'g', -1, 64) + " THEN " + strconv.Itoa(i)
+		}
+		priceRange += " ELSE " + strconv.Itoa(len(priceBounds)) + " END"
+	}
+	return listing.countBy("category_id", priceRange, "CASE WHEN stock > 0 THEN 1 ELSE 0 END"), nil
+}
+
+// total returns the number of matching rows
+func (l *sqlListing) total() sqlStatement {
+	args := l.argsCopy()
+	return sqlStatement{`SELECT COUNT(*) FROM ` + l.table + l.whereClause(), args}
+}
+
+// countBy returns the number of matching rows for each combination of the
+// values of exprs, as rows of those values followed by the count
+func (l *sqlListing) countBy(exprs ...string) sqlStatement {
+	args := l.argsCopy()
+	groups := make([]string, len(exprs))
+	for i := range exprs {
+		groups[i] = strconv.Itoa(i + 1)
+	}
+	return sqlStatement{`SELECT ` + strings.Join(exprs, ", ") + `, COUNT(*) FROM ` + l.table + l.whereClause() +
+		` GROUP BY ` + strings.Join(groups, ", "), args}
+}
+
+// rows returns the rows of the page and whether they come in reverse order,
+// as the rows before a position are read backwards from it
+func (l *sqlListing) rows(columns string) (sqlStatement, bool) {
+	args := l.argsCopy()
+	query := `SELECT ` + columns + ` FROM ` + l.table
+	reversed := false
+	switch {
+	case l.page.After != nil:
+		query += l.whereClause(l.compare(&args, *l.page.After, ">")) + l.orderBy(false)
+	case l.page.Before != nil:
+		query += l.whereClause(l.compare(&args, *l.page.Before, "<")) + l.orderBy(true)
+		reversed = true
+	default:
+		query += l.whereClause() + l.orderBy(false)
+	}
+	query += ` LIMIT ` + l.limit(&args)
+	if l.page.After == nil && l.page.Before == nil && l.page.Offset > 0 {
+		query += ` OFFSET ` + l.placeholder(&args, l.page.Offset)
+	}
+	return sqlStatement{query, args}, reversed
+}
+
+// before returns the number of matching rows before the position the page
+// is next to, counting the position itself for pages after it. It is false
+// for pages at an offset.
+func (l *sqlListing) before() (sqlStatement, bool) {
+	args := l.argsCopy()
+	var condition string
+	switch {
+	case l.page.After != nil:
+		condition = l.compare(&args, *l.page.After, "<=")
+	case l.page.Before != nil:
+		condition = l.compare(&args, *l.page.Before, "<")
+	default:
+		return sqlStatement{}, false
+	}
+	return sqlStatement{`SELECT COUNT(*) FROM ` + l.table + l.whereClause(condition), args}, true
+}
+
+// offset returns the Offset of a Result with items read by rows, given the
+// count read by before
+func (l *sqlListing) offset(before, items int) int {
+	switch {
+	case l.page.After != nil:
+		return before
+	case l.page.Before != nil:
+		return before - items
+	}
+	return l.page.Offset
+}
+
+// compare returns the condition that rows come before (op "<" or "<=") or
+// after (op ">") position p in the order of the listing
+func (l *sqlListing) compare(args *[]any, p Position, op string) string {
+	if l.sort.Desc {
+		op = map[string]string{"<": ">", "<=": ">=", ">": "<"}[op]
+	}
+	return "(" + l.column + ", " + l.dialect.idColumn + ") " + op +
+		" (" + l.placeholder(args, p.Key) + ", " + l.placeholder(args, p.ID) + ")"
+}
+
+func (l *sqlListing) orderBy(reverse bool) string {
+	direction := ""
+	if l.sort.Desc != reverse {
+		direction = " DESC"
+	}
+	return ` ORDER BY ` + l.column + direction + `, ` + l.dialect.idColumn + direction
+}
+
+func (l *sqlListing) limit(args *[]any) string {
+	if l.page.Limit == NoLimit {
+		return l.dialect.noLimit
+	}
+	return l.placeholder(args, l.page.Limit)
+}
+
+func (l *sqlListing) whereClause(conditions ...string) string {
+	conditions = append(l.filters[:len(l.filters):len(l.filters)], conditions...)
+	if len(conditions) == 0 {
+		return ""
+	}
+	return ` WHERE ` + strings.Join(conditions, " AND ")
+}
+
+func (l *sqlListing) placeholder(args *[]any, value any) string {
+	*args = append(*args, l.dialect.arg(value))
+	return l.dialect.placeholder(len(*args))
+}
+
+func (l *sqlListing) argsCopy() []any {
+	return append([]any(nil), l.args...)
+}
+
+// Dialects of the SQL backends. Text is sorted bytewise on both.
+var (
+	sqliteDialect = sqlDialect{
+		placeholder: func(int) string { return "?" },
+		arg: func(v any) any {
+			if t, ok := v.(time.Time); ok {
+				return formatTime(t)
+			}
+			return v
+		},
+		sortColumns: map[SortField]string{
+			SortByCreatedAt: "created_at",
+			SortByName:      "name",
+			SortByPrice:     "price",
+		},
+		idColumn: "id",
+		noLimit:  "-1",
+	}
+	postgresDialect = sqlDialect{
+		placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+		arg:         func(v any) any { return v },
+		sortColumns: map[SortField]string{
+			SortByCreatedAt: "created_at",
+			SortByName:      `name COLLATE "C"`,
+			SortByPrice:     "price",
+		},
+		idColumn: `id COLLATE "C"`,
+		noLimit:  "ALL",
+	}
+)
@@ -1,7 +1,11 @@
 package store
 
 import (
+	"context"
+	"maps"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,382 +14,1054 @@ import (
 
 // MemoryStore implements the Store interface with in-memory storage
 type MemoryStore struct {
-	mu         sync.RWMutex
-	products   map[string]generated.Product
-	categories map[string]generated.Category
-	users      map[string]generated.User
-	carts      map[string]generated.Cart
-	orders     map[string]generated.Order
+	mu     sync.RWMutex
+	tables memoryTables
 }
 
-// NewMemoryStore creates a new in-memory store with mock data
+// memoryTables holds the records of a MemoryStore. Its methods implement Tx
+// without locking; callers hold MemoryStore.mu.
+type memoryTables struct {
+	products       map[string]generated.Product
+	categories     map[string]generated.Category
+	users          map[string]generated.User
+	carts          map[string]generated.Cart
+	holds          map[holdKey]Hold
+	orders         map[string]generated.Order
+	credentials    map[string]Credential // keyed by user ID
+	apiKeys        map[string]APIKey
+	sessions       map[string]Session
+	refreshTokens  map[string]RefreshToken  // keyed by token hash
+	passwordResets map[string]PasswordReset // keyed by token hash
+	identities     map[identityKey]Identity
+	deletions      map[string]time.Time // keyed by table name
+}
+
+// holdKey identifies a Hold
+type holdKey struct {
+	productId, userId string
+}
+
+// identityKey identifies an Identity
+type identityKey struct {
+	issuer, subject string
+}
+
+// NewMemoryStore creates a new, empty in-memory store
 func NewMemoryStore() *MemoryStore {
 	store := &MemoryStore{
-		products:   make(map[string]generated.Product),
-		categories: make(map[string]generated.Category),
-		users:      make(map[string]generated.User),
-		carts:      make(map[string]generated.Cart),
-		orders:     make(map[string]generated.Order),
+		tables: memoryTables{
+			products:       make(map[string]generated.Product),
+			categories:     make(map[string]generated.Category),
+			users:          make(map[string]generated.User),
+			carts:          make(map[string]generated.Cart),
+			holds:          make(map[holdKey]Hold),
+			orders:         make(map[string]generated.Order),
+			credentials:    make(map[string]Credential),
+			apiKeys:        make(map[string]APIKey),
+			sessions:       make(map[string]Session),
+			refreshTokens:  make(map[string]RefreshToken),
+			passwordResets: make(map[string]PasswordReset),
+			identities:     make(map[identityKey]Identity),
+			deletions:      make(map[string]time.Time),
+		},
 	}
-	store.initializeMockData()
 	return store
 }
 
-func (s *MemoryStore) initializeMockData() {
-	now := time.Now()
-
-	// Categories
-	s.categories["1"] = generated.Category{
-		Id:        "1",
-		Name:      "Electronics",
-		ParentId:  nil,
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
-	s.categories["2"] = generated.Category{
-		Id:        "2",
-		Name:      "Laptops",
-		ParentId:  stringPtr("1"),
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
-	s.categories["3"] = generated.Category{
-		Id:        "3",
-		Name:      "Smartphones",
-		ParentId:  stringPtr("1"),
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
-	s.categories["4"] = generated.Category{
-		Id:        "4",
-		Name:      "Clothing",
-		ParentId:  nil,
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
-
-	// Products
-	s.products["1"] = generated.Product{
-		Id:          "1",
-		Name:        "MacBook Pro 16\"",
-		Description: "Apple MacBook Pro with M3 chip",
-		Price:       2499.99,
-		Stock:       10,
-		CategoryId:  "2",
-		ImageUrls:   []string{"https://example.com/macbook.jpg"},
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-	s.products["2"] = generated.Product{
-		Id:          "2",
-		Name:        "iPhone 15 Pro",
-		Description: "Latest iPhone with titanium design",
-		Price:       999.99,
-		Stock:       25,
-		CategoryId:  "3",
-		ImageUrls:   []string{"https://example.com/iphone.jpg"},
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-	s.products["3"] = generated.Product{
-		Id:          "3",
-		Name:        "T-Shirt",
-		Description: "Comfortable cotton t-shirt",
-		Price:       29.99,
-		Stock:       100,
-		CategoryId:  "4",
-		ImageUrls:   []string{"https://example.com/tshirt.jpg"},
-		CreatedAt:   now,
-		UpdatedAt:   now,
-	}
-
-	// Users
-	s.users["1"] = generated.User{
-		Id:    "1",
-		Email: "user1@example.com",
-		Name:  "Test User 1",
-		Address: &generated.Address{
-			Street:     "123 Test St",
-			City:       "Test City",
-			State:      "TC",
-			PostalCode: "12345",
-			Country:    "USA",
-		},
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
-	s.users["2"] = generated.User{
-		Id:    "2",
-		Email: "user2@example.com",
-		Name:  "Test User 2",
-		Address: &generated.Address{
-			Street:     "456 Demo Ave",
-			City:       "Demo City",
-			State:      "DC",
-			PostalCode: "67890",
-			Country:    "USA",
-		},
-		CreatedAt: now,
-		UpdatedAt: now,
+// WithTx runs fn while holding the write lock, so transactions are fully
+// serialized. When fn fails the tables are restored from a snapshot.
+func (s *MemoryStore) WithTx(ctx context.Context, fn func(tx Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	// Initialize empty carts for users
-	s.carts["1"] = generated.Cart{
-		Id:        "cart-1",
-		UserId:    "1",
-		Items:     []generated.CartItem{},
-		CreatedAt: now,
-		UpdatedAt: now,
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.tables.clone()
+	committed := false
+	defer func() {
+		if !committed {
+			s.tables = snapshot
+		}
+	}()
+
+	if err := fn(&s.tables); err != nil {
+		return err
 	}
-	s.carts["2"] = generated.Cart{
-		Id:        "cart-2",
-		UserId:    "2",
-		Items:     []generated.CartItem{},
-		CreatedAt: now,
-		UpdatedAt: now,
+	committed = true
+	return nil
+}
+
+// clone copies the tables. Records are stored by value and never modified in
+// place, so copying the maps is enough to snapshot them.
+func (t *memoryTables) clone() memoryTables {
+	return memoryTables{
+		products:       maps.Clone(t.products),
+		categories:     maps.Clone(t.categories),
+		users:          maps.Clone(t.users),
+		carts:          maps.Clone(t.carts),
+		holds:          maps.Clone(t.holds),
+		orders:         maps.Clone(t.orders),
+		credentials:    maps.Clone(t.credentials),
+		apiKeys:        maps.Clone(t.apiKeys),
+		sessions:       maps.Clone(t.sessions),
+		refreshTokens:  maps.Clone(t.refreshTokens),
+		passwordResets: maps.Clone(t.passwordResets),
+		identities:     maps.Clone(t.identities),
+		deletions:      maps.Clone(t.deletions),
 	}
 }
 
-// Products
-func (s *MemoryStore) GetProducts() []generated.Product {
+// The Store methods lock the store and delegate to its tables
+
+func (s *MemoryStore) GetProducts(ctx context.Context) ([]generated.Product, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.tables.GetProducts(ctx)
+}
 
-	products := make([]generated.Product, 0, len(s.products))
-	for _, product := range s.products {
-		products = append(products, product)
-	}
-	return products
+func (s *MemoryStore) QueryProducts(ctx context.Context, query ProductQuery) (Result[generated.Product], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.QueryProducts(ctx, query)
 }
 
-func (s *MemoryStore) GetProduct(id string) (*generated.Product, bool) {
+func (s *MemoryStore) CountProducts(ctx context.Context, query ProductQuery, priceBounds []float32) (ProductCounts, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.tables.CountProducts(ctx, query, priceBounds)
+}
 
-	product, ok := s.products[id]
-	if !ok {
-		return nil, false
-	}
-	return &product, true
+func (s *MemoryStore) GetProduct(ctx context.Context, id string) (generated.Product, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetProduct(ctx, id)
 }
 
-func (s *MemoryStore) CreateProduct(product generated.Product) generated.Product {
+func (s *MemoryStore) CreateProduct(ctx context.Context, product generated.Product) (generated.Product, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.tables.CreateProduct(ctx, product)
+}
 
-	s.products[product.Id] = product
-	return product
+func (s *MemoryStore) UpdateProduct(ctx context.Context, id string, product generated.Product) (generated.Product, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.UpdateProduct(ctx, id, product)
 }
 
-func (s *MemoryStore) UpdateProduct(id string, product generated.Product) generated.Product {
+func (s *MemoryStore) DeleteProduct(ctx context.Context, id string) (generated.Product, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.tables.DeleteProduct(ctx, id)
+}
+
+func (s *MemoryStore) ModifiedAt(ctx context.Context, table string) (time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.ModifiedAt(ctx, table)
+}
+
+func (s *MemoryStore) GetCategories(ctx context.Context) ([]generated.Category, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetCategories(ctx)
+}
 
-	s.products[id] = product
-	return product
+func (s *MemoryStore) GetCategory(ctx context.Context, id string) (generated.Category, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetCategory(ctx, id)
 }
 
-func (s *MemoryStore) DeleteProduct(id string) (*generated.Product, bool) {
+func (s *MemoryStore) CreateCategory(ctx context.Context, category generated.Category) (generated.Category, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.tables.CreateCategory(ctx, category)
+}
 
-	product, ok := s.products[id]
-	if !ok {
-		return nil, false
-	}
-	delete(s.products, id)
-	return &product, true
+func (s *MemoryStore) UpdateCategory(ctx context.Context, id string, category generated.Category) (generated.Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.UpdateCategory(ctx, id, category)
 }
 
-// Categories
-func (s *MemoryStore) GetCategories() []generated.Category {
+func (s *MemoryStore) DeleteCategory(ctx context.Context, id string) (generated.Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.DeleteCategory(ctx, id)
+}
+
+func (s *MemoryStore) GetUsers(ctx context.Context) ([]generated.User, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.tables.GetUsers(ctx)
+}
 
-	categories := make([]generated.Category, 0, len(s.categories))
-	for _, category := range s.categories {
-		categories = append(categories, category)
-	}
-	return categories
+func (s *MemoryStore) QueryUsers(ctx context.Context, query UserQuery) (Result[generated.User], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.QueryUsers(ctx, query)
 }
 
-func (s *MemoryStore) GetCategory(id string) (*generated.Category, bool) {
+func (s *MemoryStore) GetUser(ctx context.Context, id string) (generated.User, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.tables.GetUser(ctx, id)
+}
 
-	category, ok := s.categories[id]
-	if !ok {
-		return nil, false
-	}
-	return &category, true
+func (s *MemoryStore) CreateUser(ctx context.Context, user generated.User) (generated.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.CreateUser(ctx, user)
 }
 
-func (s *MemoryStore) CreateCategory(category generated.Category) generated.Category {
+func (s *MemoryStore) UpdateUser(ctx context.Context, id string, user generated.User) (generated.User, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.tables.UpdateUser(ctx, id, user)
+}
 
-	s.categories[category.Id] = category
-	return category
+func (s *MemoryStore) DeleteUser(ctx context.Context, id string) (generated.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.DeleteUser(ctx, id)
 }
 
-func (s *MemoryStore) UpdateCategory(id string, category generated.Category) generated.Category {
+func (s *MemoryStore) GetCartByUserId(ctx context.Context, userId string) (generated.Cart, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetCartByUserId(ctx, userId)
+}
+
+func (s *MemoryStore) UpdateCart(ctx context.Context, userId string, cart generated.Cart) (generated.Cart, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.tables.UpdateCart(ctx, userId, cart)
+}
+
+func (s *MemoryStore) GetHolds(ctx context.Context) ([]Hold, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetHolds(ctx)
+}
 
-	s.categories[id] = category
-	return category
+func (s *MemoryStore) GetHoldsByProductId(ctx context.Context, productId string) ([]Hold, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetHoldsByProductId(ctx, productId)
+}
+
+func (s *MemoryStore) GetHoldsByUserId(ctx context.Context, userId string) ([]Hold, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetHoldsByUserId(ctx, userId)
 }
 
-func (s *MemoryStore) DeleteCategory(id string) (*generated.Category, bool) {
+func (s *MemoryStore) SetHold(ctx context.Context, hold Hold) (Hold, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.tables.SetHold(ctx, hold)
+}
 
-	category, ok := s.categories[id]
-	if !ok {
-		return nil, false
-	}
-	delete(s.categories, id)
-	return &category, true
+func (s *MemoryStore) GetOrders(ctx context.Context) ([]generated.Order, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetOrders(ctx)
 }
 
-// Users
-func (s *MemoryStore) GetUsers() []generated.User {
+func (s *MemoryStore) GetOrder(ctx context.Context, id string) (generated.Order, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.tables.GetOrder(ctx, id)
+}
 
-	users := make([]generated.User, 0, len(s.users))
-	for _, user := range s.users {
-		users = append(users, user)
-	}
+func (s *MemoryStore) GetOrdersByUserId(ctx context.Context, userId string) ([]generated.Order, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetOrdersByUserId(ctx, userId)
+}
 
-	// Sort by ID for consistent ordering
-	sort.Slice(users, func(i, j int) bool {
-		return users[i].Id < users[j].Id
-	})
+func (s *MemoryStore) QueryOrders(ctx context.Context, query OrderQuery) (Result[generated.Order], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.QueryOrders(ctx, query)
+}
+
+func (s *MemoryStore) CreateOrder(ctx context.Context, order generated.Order) (generated.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.CreateOrder(ctx, order)
+}
+
+func (s *MemoryStore) UpdateOrder(ctx context.Context, id string, order generated.Order) (generated.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.UpdateOrder(ctx, id, order)
+}
 
-	return users
+func (s *MemoryStore) GetCredential(ctx context.Context, userId string) (Credential, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetCredential(ctx, userId)
 }
 
-func (s *MemoryStore) GetUser(id string) (*generated.User, bool) {
+func (s *MemoryStore) GetCredentialByEmail(ctx context.Context, email string) (Credential, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.tables.GetCredentialByEmail(ctx, email)
+}
 
-	user, ok := s.users[id]
-	if !ok {
-		return nil, false
-	}
-	return &user, true
+func (s *MemoryStore) CreateCredential(ctx context.Context, credential Credential) (Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.CreateCredential(ctx, credential)
 }
 
-func (s *MemoryStore) CreateUser(user generated.User) generated.User {
+func (s *MemoryStore) UpdateCredential(ctx context.Context, userId string, credential Credential) (Credential, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.tables.UpdateCredential(ctx, userId, credential)
+}
 
-	s.users[user.Id] = user
-	return user
+func (s *MemoryStore) DeleteCredential(ctx context.Context, userId string) (Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.DeleteCredential(ctx, userId)
 }
 
-func (s *MemoryStore) UpdateUser(id string, user generated.User) generated.User {
+func (s *MemoryStore) GetAPIKeys(ctx context.Context, userId string) ([]APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetAPIKeys(ctx, userId)
+}
+
+func (s *MemoryStore) GetAPIKey(ctx context.Context, id string) (APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetAPIKey(ctx, id)
+}
+
+func (s *MemoryStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetAPIKeyByHash(ctx, keyHash)
+}
+
+func (s *MemoryStore) CreateAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.tables.CreateAPIKey(ctx, key)
+}
 
-	s.users[id] = user
-	return user
+func (s *MemoryStore) UpdateAPIKey(ctx context.Context, id string, key APIKey) (APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.UpdateAPIKey(ctx, id, key)
 }
 
-func (s *MemoryStore) DeleteUser(id string) (*generated.User, bool) {
+func (s *MemoryStore) DeleteAPIKey(ctx context.Context, id string) (APIKey, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.tables.DeleteAPIKey(ctx, id)
+}
 
-	user, ok := s.users[id]
-	if !ok {
-		return nil, false
-	}
-	delete(s.users, id)
-	return &user, true
+func (s *MemoryStore) GetSessionsByUserId(ctx context.Context, userId string) ([]Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tables.GetSessionsByUserId(ctx, userId)
 }
 
-// Carts
-func (s *MemoryStore) GetCartByUserId(userId string) generated.Cart {
+func (s *MemoryStore) GetSession(ctx context.Context, id string) (Session, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.tables.GetSession(ctx, id)
+}
 
-	cart, ok := s.carts[userId]
-	if !ok {
-		// Return a new empty cart if not exists
-		now := time.Now()
-		return generated.Cart{
-			Id:        "cart-" + userId,
-			UserId:    userId,
-			Items:     []generated.CartItem{},
-			CreatedAt: now,
-			UpdatedAt: now,
-		}
-	}
-	return cart
+func (s *MemoryStore) CreateSession(ctx context.Context, session Session) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.CreateSession(ctx, session)
 }
 
-func (s *MemoryStore) UpdateCart(userId string, cart generated.Cart) generated.Cart {
+func (s *MemoryStore) UpdateSession(ctx context.Context, id string, session Session) (Session, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.tables.UpdateSession(ctx, id, session)
+}
 
-	s.carts[userId] = cart
-	return cart
+func (s *MemoryStore) DeleteSession(ctx context.Context, id string) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.DeleteSession(ctx, id)
 }
 
-// Orders
-func (s *MemoryStore) GetOrders() []generated.Order {
+func (s *MemoryStore) GetRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.tables.GetRefreshToken(ctx, tokenHash)
+}
 
-	orders := make([]generated.Order, 0, len(s.orders))
-	for _, order := range s.orders {
-		orders = append(orders, order)
-	}
-	return orders
+func (s *MemoryStore) CreateRefreshToken(ctx context.Context, token RefreshToken) (RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.CreateRefreshToken(ctx, token)
+}
+
+func (s *MemoryStore) UpdateRefreshToken(ctx context.Context, tokenHash string, token RefreshToken) (RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.UpdateRefreshToken(ctx, tokenHash, token)
+}
+
+func (s *MemoryStore) CreatePasswordReset(ctx context.Context, reset PasswordReset) (PasswordReset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.CreatePasswordReset(ctx, reset)
+}
+
+func (s *MemoryStore) DeletePasswordReset(ctx context.Context, tokenHash string) (PasswordReset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.DeletePasswordReset(ctx, tokenHash)
 }
 
-func (s *MemoryStore) GetOrder(id string) (*generated.Order, bool) {
+func (s *MemoryStore) DeletePasswordResetsByUserId(ctx context.Context, userId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.DeletePasswordResetsByUserId(ctx, userId)
+}
+
+func (s *MemoryStore) GetIdentity(ctx context.Context, issuer, subject string) (Identity, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.tables.GetIdentity(ctx, issuer, subject)
+}
 
-	order, ok := s.orders[id]
+func (s *MemoryStore) CreateIdentity(ctx context.Context, identity Identity) (Identity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.CreateIdentity(ctx, identity)
+}
+
+func (s *MemoryStore) DeleteIdentitiesByUserId(ctx context.Context, userId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.DeleteIdentitiesByUserId(ctx, userId)
+}
+
+func (s *MemoryStore) DeleteExpiredTokens(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tables.DeleteExpiredTokens(ctx, now)
+}
+
+// Products
+func (t *memoryTables) GetProducts(ctx context.Context) ([]generated.Product, error) {
+	products := make([]generated.Product, 0, len(t.products))
+	for _, product := range t.products {
+		products = append(products, product)
+	}
+
+	// Sort by ID for consistent ordering
+	sort.Slice(products, func(i, j int) bool {
+		return products[i].Id < products[j].Id
+	})
+	return products, nil
+}
+
+func (t *memoryTables) QueryProducts(ctx context.Context, query ProductQuery) (Result[generated.Product], error) {
+	if err := checkPage(query.Sort, query.Page, SortByName, SortByPrice, SortByCreatedAt); err != nil {
+		return Result[generated.Product]{}, err
+	}
+	matches := productFilter(query)
+	products := make([]generated.Product, 0)
+	for _, product := range t.products {
+		if matches(product) {
+			products = append(products, product)
+		}
+	}
+	position := func(product generated.Product) Position {
+		switch query.Sort.Field {
+		case SortByName:
+			return Position{product.Name, product.Id}
+		case SortByPrice:
+			return Position{product.Price, product.Id}
+		}
+		return Position{product.CreatedAt, product.Id}
+	}
+	return pageRecords(products, query.Sort, query.Page, position), nil
+}
+
+func (t *memoryTables) CountProducts(ctx context.Context, query ProductQuery, priceBounds []float32) (ProductCounts, error) {
+	matches := productFilter(query)
+	counts := newProductCounts(priceBounds)
+	for _, product := range t.products {
+		if matches(product) {
+			counts = counts.add(product.CategoryId, priceRange(product.Price, priceBounds), product.Stock > 0, 1)
+		}
+	}
+	return counts, nil
+}
+
+// productFilter returns whether products pass the filters of query
+func productFilter(query ProductQuery) func(generated.Product) bool {
+	ids, categoryIDs := stringSet(query.IDs), stringSet(query.CategoryIDs)
+	return func(product generated.Product) bool {
+		return (ids == nil || ids[product.Id]) &&
+			(categoryIDs == nil || categoryIDs[product.CategoryId]) &&
+			(query.MinPrice == nil || product.Price >= *query.MinPrice) &&
+			(query.MaxPrice == nil || product.Price <= *query.MaxPrice)
+	}
+}
+
+func (t *memoryTables) GetProduct(ctx context.Context, id string) (generated.Product, error) {
+	product, ok := t.products[id]
 	if !ok {
-		return nil, false
+		return generated.Product{}, ErrNotFound
 	}
-	return &order, true
+	return product, nil
 }
 
-func (s *MemoryStore) GetOrdersByUserId(userId string) []generated.Order {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+func (t *memoryTables) CreateProduct(ctx context.Context, product generated.Product) (generated.Product, error) {
+	if _, exists := t.products[product.Id]; exists {
+		return generated.Product{}, ErrConflict
+	}
+	t.products[product.Id] = product
+	return product, nil
+}
+
+func (t *memoryTables) UpdateProduct(ctx context.Context, id string, product generated.Product) (generated.Product, error) {
+	if _, exists := t.products[id]; !exists {
+		return generated.Product{}, ErrNotFound
+	}
+	t.products[id] = product
+	return product, nil
+}
 
+func (t *memoryTables) DeleteProduct(ctx context.Context, id string) (generated.Product, error) {
+	product, ok := t.products[id]
+	if !ok {
+		return generated.Product{}, ErrNotFound
+	}
+	delete(t.products, id)
+	t.deletions[TableProducts] = time.Now()
+	return product, nil
+}
+
+func (t *memoryTables) ModifiedAt(ctx context.Context, table string) (time.Time, error) {
+	if err := checkModifiedTable(table); err != nil {
+		return time.Time{}, err
+	}
+	modifiedAt := t.deletions[table]
+	if table == TableProducts {
+		for _, product := range t.products {
+			modifiedAt = latest(modifiedAt, product.UpdatedAt)
+		}
+	} else {
+		for _, category := range t.categories {
+			modifiedAt = latest(modifiedAt, category.UpdatedAt)
+		}
+	}
+	return modifiedAt, nil
+}
+
+// Categories
+func (t *memoryTables) GetCategories(ctx context.Context) ([]generated.Category, error) {
+	categories := make([]generated.Category, 0, len(t.categories))
+	for _, category := range t.categories {
+		categories = append(categories, category)
+	}
+
+	// Sort by ID for consistent ordering
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Id < categories[j].Id
+	})
+	return categories, nil
+}
+
+func (t *memoryTables) GetCategory(ctx context.Context, id string) (generated.Category, error) {
+	category, ok := t.categories[id]
+	if !ok {
+		return generated.Category{}, ErrNotFound
+	}
+	return category, nil
+}
+
+func (t *memoryTables) CreateCategory(ctx context.Context, category generated.Category) (generated.Category, error) {
+	if _, exists := t.categories[category.Id]; exists {
+		return generated.Category{}, ErrConflict
+	}
+	t.categories[category.Id] = category
+	return category, nil
+}
+
+func (t *memoryTables) UpdateCategory(ctx context.Context, id string, category generated.Category) (generated.Category, error) {
+	if _, exists := t.categories[id]; !exists {
+		return generated.Category{}, ErrNotFound
+	}
+	t.categories[id] = category
+	return category, nil
+}
+
+func (t *memoryTables) DeleteCategory(ctx context.Context, id string) (generated.Category, error) {
+	category, ok := t.categories[id]
+	if !ok {
+		return generated.Category{}, ErrNotFound
+	}
+	delete(t.categories, id)
+	t.deletions[TableCategories] = time.Now()
+	return category, nil
+}
+
+// Users
+func (t *memoryTables) GetUsers(ctx context.Context) ([]generated.User, error) {
+	users := make([]generated.User, 0, len(t.users))
+	for _, user := range t.users {
+		users = append(users, user)
+	}
+
+	// Sort by ID for consistent ordering
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Id < users[j].Id
+	})
+	return users, nil
+}
+
+func (t *memoryTables) QueryUsers(ctx context.Context, query UserQuery) (Result[generated.User], error) {
+	if err := checkPage(query.Sort, query.Page, SortByCreatedAt); err != nil {
+		return Result[generated.User]{}, err
+	}
+	users := slices.AppendSeq(make([]generated.User, 0, len(t.users)), maps.Values(t.users))
+	return pageRecords(users, query.Sort, query.Page,
+		func(user generated.User) Position { return Position{user.CreatedAt, user.Id} }), nil
+}
+
+func (t *memoryTables) GetUser(ctx context.Context, id string) (generated.User, error) {
+	user, ok := t.users[id]
+	if !ok {
+		return generated.User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (t *memoryTables) CreateUser(ctx context.Context, user generated.User) (generated.User, error) {
+	if _, exists := t.users[user.Id]; exists {
+		return generated.User{}, ErrConflict
+	}
+	t.users[user.Id] = user
+	return user, nil
+}
+
+func (t *memoryTables) UpdateUser(ctx context.Context, id string, user generated.User) (generated.User, error) {
+	if _, exists := t.users[id]; !exists {
+		return generated.User{}, ErrNotFound
+	}
+	t.users[id] = user
+	return user, nil
+}
+
+func (t *memoryTables) DeleteUser(ctx context.Context, id string) (generated.User, error) {
+	user, ok := t.users[id]
+	if !ok {
+		return generated.User{}, ErrNotFound
+	}
+	delete(t.users, id)
+	return user, nil
+}
+
+// Carts
+func (t *memoryTables) GetCartByUserId(ctx context.Context, userId string) (generated.Cart, error) {
+	cart, ok := t.carts[userId]
+	if !ok {
+		// Return a new empty cart if not exists
+		return newEmptyCart(userId), nil
+	}
+	// Callers edit items in place before calling UpdateCart
+	cart.Items = slices.Clone(cart.Items)
+	return cart, nil
+}
+
+func (t *memoryTables) UpdateCart(ctx context.Context, userId string, cart generated.Cart) (generated.Cart, error) {
+	t.carts[userId] = cart
+	return cart, nil
+}
+
+// Holds
+func (t *memoryTables) GetHolds(ctx context.Context) ([]Hold, error) {
+	return t.holdsWhere(func(Hold) bool { return true }), nil
+}
+
+func (t *memoryTables) GetHoldsByProductId(ctx context.Context, productId string) ([]Hold, error) {
+	return t.holdsWhere(func(hold Hold) bool { return hold.ProductId == productId }), nil
+}
+
+func (t *memoryTables) GetHoldsByUserId(ctx context.Context, userId string) ([]Hold, error) {
+	return t.holdsWhere(func(hold Hold) bool { return hold.UserId == userId }), nil
+}
+
+func (t *memoryTables) SetHold(ctx context.Context, hold Hold) (Hold, error) {
+	t.holds[holdKey{hold.ProductId, hold.UserId}] = hold
+	return hold, nil
+}
+
+// holdsWhere returns the holds match accepts, ordered by product and user
+func (t *memoryTables) holdsWhere(match func(Hold) bool) []Hold {
+	holds := []Hold{}
+	for _, hold := range t.holds {
+		if match(hold) {
+			holds = append(holds, hold)
+		}
+	}
+	slices.SortFunc(holds, func(a, b Hold) int {
+		if c := strings.Compare(a.ProductId, b.ProductId); c != 0 {
+			return c
+		}
+		return strings.Compare(a.UserId, b.UserId)
+	})
+	return holds
+}
+
+// Orders
+func (t *memoryTables) GetOrders(ctx context.Context) ([]generated.Order, error) {
+	orders := make([]generated.Order, 0, len(t.orders))
+	for _, order := range t.orders {
+		orders = append(orders, order)
+	}
+
+	// Sort by ID for consistent ordering
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].Id < orders[j].Id
+	})
+	return orders, nil
+}
+
+func (t *memoryTables) GetOrder(ctx context.Context, id string) (generated.Order, error) {
+	order, ok := t.orders[id]
+	if !ok {
+		return generated.Order{}, ErrNotFound
+	}
+	return order, nil
+}
+
+func (t *memoryTables) GetOrdersByUserId(ctx context.Context, userId string) ([]generated.Order, error) {
 	orders := make([]generated.Order, 0)
-	for _, order := range s.orders {
+	for _, order := range t.orders {
 		if order.UserId == userId {
 			orders = append(orders, order)
 		}
 	}
-	return orders
+
+	// Sort by ID for consistent ordering
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].Id < orders[j].Id
+	})
+	return orders, nil
 }
 
-func (s *MemoryStore) CreateOrder(order generated.Order) generated.Order {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (t *memoryTables) QueryOrders(ctx context.Context, query OrderQuery) (Result[generated.Order], error) {
+	if err := checkPage(query.Sort, query.Page, SortByCreatedAt); err != nil {
+		return Result[generated.Order]{}, err
+	}
+	orders := make([]generated.Order, 0)
+	for _, order := range t.orders {
+		if query.UserId != "" && order.UserId != query.UserId ||
+			query.Status != nil && order.Status != *query.Status ||
+			query.CreatedFrom != nil && order.CreatedAt.Before(*query.CreatedFrom) ||
+			query.CreatedTo != nil && order.CreatedAt.After(*query.CreatedTo) {
+			continue
+		}
+		orders = append(orders, order)
+	}
+	return pageRecords(orders, query.Sort, query.Page,
+		func(order generated.Order) Position { return Position{order.CreatedAt, order.Id} }), nil
+}
 
-	s.orders[order.Id] = order
-	return order
+func (t *memoryTables) CreateOrder(ctx context.Context, order generated.Order) (generated.Order, error) {
+	if _, exists := t.orders[order.Id]; exists {
+		return generated.Order{}, ErrConflict
+	}
+	t.orders[order.Id] = order
+	return order, nil
 }
 
-func (s *MemoryStore) UpdateOrder(id string, order generated.Order) generated.Order {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (t *memoryTables) UpdateOrder(ctx context.Context, id string, order generated.Order) (generated.Order, error) {
+	if _, exists := t.orders[id]; !exists {
+		return generated.Order{}, ErrNotFound
+	}
+	t.orders[id] = order
+	return order, nil
+}
+
+// Credentials
+func (t *memoryTables) GetCredential(ctx context.Context, userId string) (Credential, error) {
+	credential, ok := t.credentials[userId]
+	if !ok {
+		return Credential{}, ErrNotFound
+	}
+	return credential, nil
+}
+
+func (t *memoryTables) GetCredentialByEmail(ctx context.Context, email string) (Credential, error) {
+	for _, credential := range t.credentials {
+		if credential.Email == email {
+			return credential, nil
+		}
+	}
+	return Credential{}, ErrNotFound
+}
+
+func (t *memoryTables) CreateCredential(ctx context.Context, credential Credential) (Credential, error) {
+	if _, exists := t.credentials[credential.UserId]; exists {
+		return Credential{}, ErrConflict
+	}
+	if _, err := t.GetCredentialByEmail(ctx, credential.Email); err == nil {
+		return Credential{}, ErrConflict
+	}
+	t.credentials[credential.UserId] = credential
+	return credential, nil
+}
+
+func (t *memoryTables) UpdateCredential(ctx context.Context, userId string, credential Credential) (Credential, error) {
+	if _, exists := t.credentials[userId]; !exists {
+		return Credential{}, ErrNotFound
+	}
+	if existing, err := t.GetCredentialByEmail(ctx, credential.Email); err == nil && existing.UserId != userId {
+		return Credential{}, ErrConflict
+	}
+	t.credentials[userId] = credential
+	return credential, nil
+}
+
+func (t *memoryTables) DeleteCredential(ctx context.Context, userId string) (Credential, error) {
+	credential, ok := t.credentials[userId]
+	if !ok {
+		return Credential{}, ErrNotFound
+	}
+	delete(t.credentials, userId)
+	return credential, nil
+}
+
+// API keys
+func (t *memoryTables) GetAPIKeys(ctx context.Context, userId string) ([]APIKey, error) {
+	keys := []APIKey{}
+	for _, key := range t.apiKeys {
+		if key.UserId == userId {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b APIKey) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	return keys, nil
+}
+
+func (t *memoryTables) GetAPIKey(ctx context.Context, id string) (APIKey, error) {
+	key, ok := t.apiKeys[id]
+	if !ok {
+		return APIKey{}, ErrNotFound
+	}
+	return key, nil
+}
+
+func (t *memoryTables) GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
+	for _, key := range t.apiKeys {
+		if key.KeyHash == keyHash {
+			return key, nil
+		}
+	}
+	return APIKey{}, ErrNotFound
+}
+
+func (t *memoryTables) CreateAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
+	if _, exists := t.apiKeys[key.Id]; exists {
+		return APIKey{}, ErrConflict
+	}
+	if _, err := t.GetAPIKeyByHash(ctx, key.KeyHash); err == nil {
+		return APIKey{}, ErrConflict
+	}
+	t.apiKeys[key.Id] = key
+	return key, nil
+}
+
+func (t *memoryTables) UpdateAPIKey(ctx context.Context, id string, key APIKey) (APIKey, error) {
+	if _, exists := t.apiKeys[id]; !exists {
+		return APIKey{}, ErrNotFound
+	}
+	if existing, err := t.GetAPIKeyByHash(ctx, key.KeyHash); err == nil && existing.Id != id {
+		return APIKey{}, ErrConflict
+	}
+	t.apiKeys[id] = key
+	return key, nil
+}
+
+func (t *memoryTables) DeleteAPIKey(ctx context.Context, id string) (APIKey, error) {
+	key, ok := t.apiKeys[id]
+	if !ok {
+		return APIKey{}, ErrNotFound
+	}
+	delete(t.apiKeys, id)
+	return key, nil
+}
+
+// Sessions
+func (t *memoryTables) GetSessionsByUserId(ctx context.Context, userId string) ([]Session, error) {
+	sessions := []Session{}
+	for _, session := range t.sessions {
+		if session.UserId == userId {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
 
-	s.orders[id] = order
-	return order
+func (t *memoryTables) GetSession(ctx context.Context, id string) (Session, error) {
+	session, ok := t.sessions[id]
+	if !ok {
+		return Session{}, ErrNotFound
+	}
+	return session, nil
+}
+
+func (t *memoryTables) CreateSession(ctx context.Context, session Session) (Session, error) {
+	if _, exists := t.sessions[session.Id]; exists {
+		return Session{}, ErrConflict
+	}
+	t.sessions[session.Id] = session
+	return session, nil
+}
+
+func (t *memoryTables) UpdateSession(ctx context.Context, id string, session Session) (Session, error) {
+	if _, exists := t.sessions[id]; !exists {
+		return Session{}, ErrNotFound
+	}
+	t.sessions[id] = session
+	return session, nil
 }
 
-// Helper function to create a pointer to a string
-func stringPtr(s string) *string {
-	return &s
+func (t *memoryTables) DeleteSession(ctx context.Context, id string) (Session, error) {
+	session, ok := t.sessions[id]
+	if !ok {
+		return Session{}, ErrNotFound
+	}
+	delete(t.sessions, id)
+	maps.DeleteFunc(t.refreshTokens, func(_ string, token RefreshToken) bool {
+		return token.SessionId == id
+	})
+	return session, nil
+}
+
+// Refresh tokens
+func (t *memoryTables) GetRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	token, ok := t.refreshTokens[tokenHash]
+	if !ok {
+		return RefreshToken{}, ErrNotFound
+	}
+	return token, nil
+}
+
+func (t *memoryTables) CreateRefreshToken(ctx context.Context, token RefreshToken) (RefreshToken, error) {
+	if _, exists := t.refreshTokens[token.TokenHash]; exists {
+		return RefreshToken{}, ErrConflict
+	}
+	t.refreshTokens[token.TokenHash] = token
+	return token, nil
+}
+
+func (t *memoryTables) UpdateRefreshToken(ctx context.Context, tokenHash string, token RefreshToken) (RefreshToken, error) {
+	if _, exists := t.refreshTokens[tokenHash]; !exists {
+		return RefreshToken{}, ErrNotFound
+	}
+	token.TokenHash = tokenHash
+	t.refreshTokens[tokenHash] = token
+	return token, nil
+}
+
+// Password resets
+func (t *memoryTables) CreatePasswordReset(ctx context.Context, reset PasswordReset) (PasswordReset, error) {
+	if _, exists := t.passwordResets[reset.TokenHash]; exists {
+		return PasswordReset{}, ErrConflict
+	}
+	t.passwordResets[reset.TokenHash] = reset
+	return reset, nil
+}
+
+func (t *memoryTables) DeletePasswordReset(ctx context.Context, tokenHash string) (PasswordReset, error) {
+	reset, ok := t.passwordResets[tokenHash]
+	if !ok {
+		return PasswordReset{}, ErrNotFound
+	}
+	delete(t.passwordResets, tokenHash)
+	return reset, nil
+}
+
+func (t *memoryTables) DeletePasswordResetsByUserId(ctx context.Context, userId string) error {
+	maps.DeleteFunc(t.passwordResets, func(_ string, reset PasswordReset) bool {
+		return reset.UserId == userId
+	})
+	return nil
+}
+
+// Identities
+func (t *memoryTables) GetIdentity(ctx context.Context, issuer, subject string) (Identity, error) {
+	identity, ok := t.identities[identityKey{issuer, subject}]
+	if !ok {
+		return Identity{}, ErrNotFound
+	}
+	return identity, nil
+}
+
+func (t *memoryTables) CreateIdentity(ctx context.Context, identity Identity) (Identity, error) {
+	key := identityKey{identity.Issuer, identity.Subject}
+	if _, exists := t.identities[key]; exists {
+		return Identity{}, ErrConflict
+	}
+	t.identities[key] = identity
+	return identity, nil
+}
+
+func (t *memoryTables) DeleteIdentitiesByUserId(ctx context.Context, userId string) error {
+	maps.DeleteFunc(t.identities, func(_ identityKey, identity Identity) bool {
+		return identity.UserId == userId
+	})
+	return nil
+}
+
+func (t *memoryTables) DeleteExpiredTokens(ctx context.Context, now time.Time) error {
+	maps.DeleteFunc(t.sessions, func(_ string, session Session) bool {
+		return session.ExpiresAt.Before(now)
+	})
+	maps.DeleteFunc(t.refreshTokens, func(_ string, token RefreshToken) bool {
+		return token.ExpiresAt.Before(now)
+	})
+	maps.DeleteFunc(t.passwordResets, func(_ string, reset PasswordReset) bool {
+		return reset.ExpiresAt.Before(now)
+	})
+	return nil
+}
+
+// pageRecords sorts the records that match a query and returns the page of
+// them, given the position of a record in the sort
+func pageRecords[T any](records []T, sort Sort, page Page, position func(T) Position) Result[T] {
+	compare := comparePositions(sort)
+	slices.SortFunc(records, func(a, b T) int {
+		return compare(position(a), position(b))
+	})
+	return PageRecords(records, page, position, compare)
+}
+
+// stringSet returns the members of values, or nil if values is nil
+func stringSet(values []string) map[string]bool {
+	if values == nil {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
 }
@@ -0,0 +1,186 @@
+package store
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+// ErrNoMigrations is returned by MigrateDown when no migration has been applied
+var ErrNoMigrations = errors.New("no migrations applied")
+
+// Migrator is implemented by store backends with a versioned SQL schema
+type Migrator interface {
+	// MigrateUp applies all pending migrations
+	MigrateUp(ctx context.Context) error
+	// MigrateDown rolls back the most recently applied migration
+	MigrateDown(ctx context.Context) error
+	// MigrationStatus reports every known migration and whether it has been applied
+	MigrationStatus(ctx context.Context) ([]MigrationStatus, error)
+}
+
+// MigrationStatus describes a single migration and its state in the database
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// migration is a single versioned schema change
+type migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// migrationDB is the backend-specific part of running migrations
+type migrationDB interface {
+	// ensureMigrationTable creates the schema_migrations bookkeeping table
+	ensureMigrationTable(ctx context.Context) error
+	// appliedMigrations returns the applied versions with their apply time
+	appliedMigrations(ctx context.Context) (map[int]time.Time, error)
+	// applyMigration runs the up (or down) script and records it in one transaction
+	applyMigration(ctx context.Context, m migration, up bool) error
+}
+
+// loadMigrations reads the embedded migrations for a backend, ordered by version.
+// Files are named <version>_<name>.up.sql and <version>_<name>.down.sql, e.g. 0001_init.up.sql.
+func loadMigrations(backend string) ([]migration, error) {
+	dir := path.Join("migrations", backend)
+	entries, err := fs.ReadDir(migrationsFS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	var migrations []migration
+	for _, entry := range entries {
+		base, ok := strings.CutSuffix(entry.Name(), ".up.sql")
+		if !ok {
+			continue
+		}
+
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", entry.Name(), err)
+		}
+
+		up, err := fs.ReadFile(migrationsFS, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read migration %q: %w", entry.Name(), err)
+		}
+		down, err := fs.ReadFile(migrationsFS, path.Join(dir, base+".down.sql"))
+		if err != nil {
+			return nil, fmt.Errorf("read down migration for %q: %w", entry.Name(), err)
+		}
+
+		migrations = append(migrations, migration{
+			Version: version,
+			Name:    name,
+			Up:      string(up),
+			Down:    string(down),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// migrateUp applies all pending migrations
+func migrateUp(ctx context.Context, db migrationDB, backend string) error {
+	migrations, err := loadMigrations(backend)
+	if err != nil {
+		return err
+	}
+	if err := db.ensureMigrationTable(ctx); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return fmt.Errorf("read applied migrations: %w", err)
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := db.applyMigration(ctx, m, true); err != nil {
+			return fmt.Errorf("apply migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// migrateDown rolls back the most recently applied migration
+func migrateDown(ctx context.Context, db migrationDB, backend string) error {
+	migrations, err := loadMigrations(backend)
+	if err != nil {
+		return err
+	}
+	if err := db.ensureMigrationTable(ctx); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return fmt.Errorf("read applied migrations: %w", err)
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if err := db.applyMigration(ctx, m, false); err != nil {
+			return fmt.Errorf("roll back migration %04d_%s: %w", m.Version, m.Name, err)
+		}
+		return nil
+	}
+
+	return ErrNoMigrations
+}
+
+// migrationStatus lists every embedded migration with its applied state
+func migrationStatus(ctx context.Context, db migrationDB, backend string) ([]MigrationStatus, error) {
+	migrations, err := loadMigrations(backend)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.ensureMigrationTable(ctx); err != nil {
+		return nil, fmt.Errorf("create schema_migrations: %w", err)
+	}
+	applied, err := db.appliedMigrations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read applied migrations: %w", err)
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		statuses = append(statuses, MigrationStatus{
+			Version:   m.Version,
+			Name:      m.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+	return statuses, nil
+}
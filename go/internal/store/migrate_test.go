@@ -0,0 +1,91 @@
+package store_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMigrator runs a full up/down/status cycle against a migrated store
+func testMigrator(t *testing.T, s interface {
+	store.Store
+	store.Migrator
+}) {
+	ctx := context.Background()
+
+	t.Run("status lists pending migrations before migrating", func(t *testing.T) {
+		statuses, err := s.MigrationStatus(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, statuses)
+		for _, status := range statuses {
+			assert.False(t, status.Applied, "migration %d should be pending", status.Version)
+		}
+	})
+
+	t.Run("up applies all migrations without seeding data", func(t *testing.T) {
+		require.NoError(t, s.MigrateUp(ctx))
+
+		statuses, err := s.MigrationStatus(ctx)
+		require.NoError(t, err)
+		for _, status := range statuses {
+			assert.True(t, status.Applied, "migration %d should be applied", status.Version)
+		}
+		users, err := s.GetUsers(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("up is idempotent", func(t *testing.T) {
+		require.NoError(t, s.MigrateUp(ctx))
+		statuses, err := s.MigrationStatus(ctx)
+		require.NoError(t, err)
+		for _, status := range statuses {
+			assert.True(t, status.Applied, "migration %d should be applied", status.Version)
+		}
+	})
+
+	t.Run("down rolls back every migration", func(t *testing.T) {
+		statuses, err := s.MigrationStatus(ctx)
+		require.NoError(t, err)
+		for range statuses {
+			require.NoError(t, s.MigrateDown(ctx))
+		}
+		assert.ErrorIs(t, s.MigrateDown(ctx), store.ErrNoMigrations)
+
+		statuses, err = s.MigrationStatus(ctx)
+		require.NoError(t, err)
+		for _, status := range statuses {
+			assert.False(t, status.Applied, "migration %d should be rolled back", status.Version)
+		}
+	})
+}
+
+// TestMigrations_SharedHistory checks that every backend has the same
+// migrations under the same versions, so a version means one schema change
+// whichever database it was applied to
+func TestMigrations_SharedHistory(t *testing.T) {
+	names := func(backend string) []string {
+		entries, err := os.ReadDir(filepath.Join("migrations", backend))
+		require.NoError(t, err)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+
+	assert.Equal(t, names("postgres"), names("sqlite"))
+}
+
+func TestSQLiteStore_Migrations(t *testing.T) {
+	s, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+
+	testMigrator(t, s)
+}
@@ -0,0 +1,4 @@
+-- version is incremented on every update and backs ETag / If-Match checks
+ALTER TABLE products ADD COLUMN version INTEGER DEFAULT 1;
+ALTER TABLE categories ADD COLUMN version INTEGER DEFAULT 1;
+ALTER TABLE users ADD COLUMN version INTEGER DEFAULT 1;
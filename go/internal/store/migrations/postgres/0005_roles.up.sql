@@ -0,0 +1,4 @@
+ALTER TABLE credentials ADD COLUMN roles JSONB NOT NULL DEFAULT '[]';
+
+-- The alice demo account administers the demo data
+UPDATE credentials SET roles = '["admin"]' WHERE user_id = '550e8400-e29b-41d4-a716-446655440001';
@@ -0,0 +1,13 @@
+-- api_keys holds the keys machine clients authenticate with, hashed
+CREATE TABLE api_keys (
+    id           TEXT PRIMARY KEY,
+    user_id      TEXT NOT NULL,
+    name         TEXT NOT NULL,
+    prefix       TEXT NOT NULL,
+    key_hash     TEXT NOT NULL UNIQUE,
+    scopes       JSONB NOT NULL DEFAULT '[]',
+    created_at   TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ
+);
+
+CREATE INDEX api_keys_user_id ON api_keys (user_id);
@@ -0,0 +1,6 @@
+DROP INDEX orders_user_id_created_at_id;
+DROP INDEX orders_created_at_id;
+DROP INDEX users_created_at_id;
+DROP INDEX products_created_at_id;
+DROP INDEX products_price_id;
+DROP INDEX products_name_id;
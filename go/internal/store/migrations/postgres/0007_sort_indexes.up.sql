@@ -0,0 +1,7 @@
+-- Listings are ordered by a field and then by id, comparing text bytewise
+CREATE INDEX products_name_id ON products (name COLLATE "C", id COLLATE "C");
+CREATE INDEX products_price_id ON products (price, id COLLATE "C");
+CREATE INDEX products_created_at_id ON products (created_at, id COLLATE "C");
+CREATE INDEX users_created_at_id ON users (created_at, id COLLATE "C");
+CREATE INDEX orders_created_at_id ON orders (created_at, id COLLATE "C");
+CREATE INDEX orders_user_id_created_at_id ON orders (user_id, created_at, id COLLATE "C");
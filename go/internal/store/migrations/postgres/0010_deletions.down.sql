@@ -0,0 +1,4 @@
+DROP TRIGGER categories_deleted ON categories;
+DROP TRIGGER products_deleted ON products;
+DROP FUNCTION record_deletion();
+DROP TABLE deletions;
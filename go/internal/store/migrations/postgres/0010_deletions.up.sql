@@ -0,0 +1,19 @@
+-- deletions records when a row of a table was last deleted, which leaves no
+-- updated_at behind to tell that the table changed
+CREATE TABLE deletions (
+    table_name TEXT PRIMARY KEY,
+    deleted_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE FUNCTION record_deletion() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO deletions (table_name, deleted_at) VALUES (TG_TABLE_NAME, clock_timestamp())
+        ON CONFLICT (table_name) DO UPDATE SET deleted_at = EXCLUDED.deleted_at;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER products_deleted AFTER DELETE ON products
+    FOR EACH STATEMENT EXECUTE FUNCTION record_deletion();
+CREATE TRIGGER categories_deleted AFTER DELETE ON categories
+    FOR EACH STATEMENT EXECUTE FUNCTION record_deletion();
@@ -0,0 +1,13 @@
+-- holds set stock aside for carts until they expire. Released holds are
+-- kept with their release as expiry, so that when the held stock last
+-- changed can be told.
+CREATE TABLE holds (
+    product_id TEXT NOT NULL,
+    user_id    TEXT NOT NULL,
+    quantity   INTEGER NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (product_id, user_id)
+);
+
+CREATE INDEX holds_user_id ON holds (user_id);
@@ -0,0 +1,5 @@
+DROP TABLE orders;
+DROP TABLE carts;
+DROP TABLE users;
+DROP TABLE products;
+DROP TABLE categories;
@@ -0,0 +1,51 @@
+CREATE TABLE categories (
+    id         TEXT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    parent_id  TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE products (
+    id          TEXT PRIMARY KEY,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL,
+    price       REAL NOT NULL,
+    stock       INTEGER NOT NULL,
+    category_id TEXT NOT NULL,
+    image_urls  TEXT NOT NULL,
+    created_at  TEXT NOT NULL,
+    updated_at  TEXT NOT NULL
+);
+
+CREATE INDEX products_category_id ON products (category_id);
+
+CREATE TABLE users (
+    id         TEXT PRIMARY KEY,
+    email      TEXT NOT NULL,
+    name       TEXT NOT NULL,
+    address    TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE carts (
+    user_id    TEXT PRIMARY KEY,
+    id         TEXT NOT NULL,
+    items      TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE orders (
+    id               TEXT PRIMARY KEY,
+    user_id          TEXT NOT NULL,
+    items            TEXT NOT NULL,
+    total_amount     REAL NOT NULL,
+    status           TEXT NOT NULL,
+    shipping_address TEXT NOT NULL,
+    created_at       TEXT NOT NULL,
+    updated_at       TEXT NOT NULL
+);
+
+CREATE INDEX orders_user_id ON orders (user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE credentials ADD COLUMN name TEXT NOT NULL DEFAULT '';
+
+UPDATE credentials SET name = (SELECT name FROM users WHERE users.id = credentials.user_id)
+WHERE user_id IN (SELECT id FROM users);
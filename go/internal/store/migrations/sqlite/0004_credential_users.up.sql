@@ -0,0 +1,6 @@
+-- Every credential belongs to a users row; names live on the user
+INSERT INTO users (id, email, name, created_at, updated_at)
+SELECT user_id, email, name, created_at, updated_at FROM credentials
+WHERE user_id NOT IN (SELECT id FROM users);
+
+ALTER TABLE credentials DROP COLUMN name;
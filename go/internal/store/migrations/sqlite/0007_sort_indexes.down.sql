@@ -0,0 +1,7 @@
+-- Padded timestamps still parse, so they are left as they are
+DROP INDEX orders_user_id_created_at_id;
+DROP INDEX orders_created_at_id;
+DROP INDEX users_created_at_id;
+DROP INDEX products_created_at_id;
+DROP INDEX products_price_id;
+DROP INDEX products_name_id;
@@ -0,0 +1,23 @@
+-- Timestamps were written with as many fractional digits as they needed, so
+-- they did not sort as text. Pad the sorted ones to nanoseconds, the format
+-- they are written in now.
+UPDATE products SET created_at = substr(created_at, 1, 19) || '.' ||
+    substr(CASE WHEN substr(created_at, 20, 1) = '.' THEN substr(created_at, 21, length(created_at) - 21) ELSE '' END || '000000000', 1, 9) || 'Z'
+    WHERE length(created_at) <> 30;
+UPDATE users SET created_at = substr(created_at, 1, 19) || '.' ||
+    substr(CASE WHEN substr(created_at, 20, 1) = '.' THEN substr(created_at, 21, length(created_at) - 21) ELSE '' END || '000000000', 1, 9) || 'Z'
+    WHERE length(created_at) <> 30;
+UPDATE orders SET created_at = substr(created_at, 1, 19) || '.' ||
+    substr(CASE WHEN substr(created_at, 20, 1) = '.' THEN substr(created_at, 21, length(created_at) - 21) ELSE '' END || '000000000', 1, 9) || 'Z'
+    WHERE length(created_at) <> 30;
+UPDATE api_keys SET created_at = substr(created_at, 1, 19) || '.' ||
+    substr(CASE WHEN substr(created_at, 20, 1) = '.' THEN substr(created_at, 21, length(created_at) - 21) ELSE '' END || '000000000', 1, 9) || 'Z'
+    WHERE length(created_at) <> 30;
+
+-- Listings are ordered by a field and then by id
+CREATE INDEX products_name_id ON products (name, id);
+CREATE INDEX products_price_id ON products (price, id);
+CREATE INDEX products_created_at_id ON products (created_at, id);
+CREATE INDEX users_created_at_id ON users (created_at, id);
+CREATE INDEX orders_created_at_id ON orders (created_at, id);
+CREATE INDEX orders_user_id_created_at_id ON orders (user_id, created_at, id);
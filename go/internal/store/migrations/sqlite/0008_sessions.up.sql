@@ -0,0 +1,30 @@
+-- sessions are logins; their refresh tokens and password reset tokens are
+-- hashed, so that a copy of the database cannot be used to log in
+CREATE TABLE sessions (
+    id           TEXT PRIMARY KEY,
+    user_id      TEXT NOT NULL,
+    device       TEXT NOT NULL DEFAULT '',
+    ip           TEXT NOT NULL DEFAULT '',
+    created_at   TEXT NOT NULL,
+    last_seen_at TEXT NOT NULL,
+    expires_at   TEXT NOT NULL
+);
+
+CREATE INDEX sessions_user_id ON sessions (user_id);
+
+CREATE TABLE refresh_tokens (
+    token_hash TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    used       INTEGER NOT NULL DEFAULT 0,
+    expires_at TEXT NOT NULL
+);
+
+CREATE INDEX refresh_tokens_session_id ON refresh_tokens (session_id);
+
+CREATE TABLE password_resets (
+    token_hash TEXT PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    expires_at TEXT NOT NULL
+);
+
+CREATE INDEX password_resets_user_id ON password_resets (user_id);
@@ -0,0 +1,3 @@
+DROP TRIGGER categories_deleted;
+DROP TRIGGER products_deleted;
+DROP TABLE deletions;
@@ -0,0 +1,18 @@
+-- deletions records when a row of a table was last deleted, which leaves no
+-- updated_at behind to tell that the table changed
+CREATE TABLE deletions (
+    table_name TEXT PRIMARY KEY,
+    deleted_at TEXT NOT NULL
+);
+
+CREATE TRIGGER products_deleted AFTER DELETE ON products
+BEGIN
+    INSERT INTO deletions (table_name, deleted_at) VALUES ('products', strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+        ON CONFLICT (table_name) DO UPDATE SET deleted_at = excluded.deleted_at;
+END;
+
+CREATE TRIGGER categories_deleted AFTER DELETE ON categories
+BEGIN
+    INSERT INTO deletions (table_name, deleted_at) VALUES ('categories', strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+        ON CONFLICT (table_name) DO UPDATE SET deleted_at = excluded.deleted_at;
+END;
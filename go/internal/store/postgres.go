@@ -0,0 +1,878 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresStore implements the Store interface on top of a PostgreSQL database
+type PostgresStore struct {
+	postgresQueries
+	pool *pgxpool.Pool
+	// claim is the connection holding the claim of Claim, if any
+	claim *pgx.Conn
+}
+
+// NewPostgresStore connects to the database described by dsn.
+// Call MigrateUp before use to bring the schema up to date.
+func NewPostgresStore(ctx context.Context, dsn string) (*PostgresStore, error) {
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres pool: %w", err)
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("connect to postgres: %w", err)
+	}
+	return &PostgresStore{postgresQueries: postgresQueries{db: pool}, pool: pool}, nil
+}
+
+// Close closes all connections in the pool
+func (s *PostgresStore) Close() error {
+	if s.claim != nil {
+		s.claim.Close(context.Background())
+		s.claim = nil
+	}
+	s.pool.Close()
+	return nil
+}
+
+// Claim takes an advisory lock on the schema of the store, held by a
+// connection of its own until the store is closed. Other processes can still
+// use the database; only their claims fail.
+func (s *PostgresStore) Claim(ctx context.Context) error {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("claim database: %w", err)
+	}
+	var locked bool
+	err = conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtext('hello-typespec'), hashtext(current_schema()))`).Scan(&locked)
+	if err != nil || !locked {
+		conn.Release()
+		if err != nil {
+			return fmt.Errorf("claim database: %w", err)
+		}
+		return ErrClaimed
+	}
+	// The lock lasts as long as the session, so take the connection out of
+	// the pool
+	s.claim = conn.Hijack()
+	return nil
+}
+
+// WithTx runs fn in a transaction. Single-row reads inside fn use SELECT ... FOR UPDATE.
+func (s *PostgresStore) WithTx(ctx context.Context, fn func(tx Tx) error) error {
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		return fn(postgresQueries{db: tx, lockRows: true})
+	})
+}
+
+// MigrateUp applies pending migrations
+func (s *PostgresStore) MigrateUp(ctx context.Context) error {
+	return migrateUp(ctx, s, "postgres")
+}
+
+// MigrateDown rolls back the most recently applied migration
+func (s *PostgresStore) MigrateDown(ctx context.Context) error {
+	return migrateDown(ctx, s, "postgres")
+}
+
+// MigrationStatus reports the state of every embedded migration
+func (s *PostgresStore) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	return migrationStatus(ctx, s, "postgres")
+}
+
+func (s *PostgresStore) ensureMigrationTable(ctx context.Context) error {
+	_, err := s.pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL
+	)`)
+	return err
+}
+
+func (s *PostgresStore) appliedMigrations(ctx context.Context) (map[int]time.Time, error) {
+	rows, err := s.pool.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+func (s *PostgresStore) applyMigration(ctx context.Context, m migration, up bool) error {
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if up {
+			if _, err := tx.Exec(ctx, m.Up); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx,
+				`INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+				m.Version, m.Name, time.Now(),
+			)
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, m.Down); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version)
+		return err
+	})
+}
+
+// pgxDB is implemented by both *pgxpool.Pool and pgx.Tx
+type pgxDB interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// postgresQueries implements the Store data methods against a pool or transaction
+type postgresQueries struct {
+	db pgxDB
+	// lockRows makes single-row reads lock the row until the transaction ends
+	lockRows bool
+}
+
+// forUpdate returns the locking clause appended to single-row reads
+func (q postgresQueries) forUpdate() string {
+	if q.lockRows {
+		return " FOR UPDATE"
+	}
+	return ""
+}
+
+// insert adds a row and reports a duplicate primary key or unique column as ErrConflict
+func (q postgresQueries) insert(ctx context.Context, table, columns string, args []any) error {
+	placeholders := make([]string, len(args))
+	for i := range args {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	_, err := q.db.Exec(ctx, `INSERT INTO `+table+` (`+columns+`) VALUES (`+strings.Join(placeholders, ", ")+`)`, args...)
+	if isUniqueViolationPG(err) {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("insert into %s: %w", table, err)
+	}
+	return nil
+}
+
+// update overwrites the row whose id is args[0], reporting a missing row as
+// ErrNotFound and a duplicate unique column as ErrConflict
+func (q postgresQueries) update(ctx context.Context, table, columns string, args []any) error {
+	names := strings.Split(columns, ", ")
+	assignments := make([]string, 0, len(names)-1)
+	for i, name := range names[1:] {
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", name, i+2))
+	}
+
+	tag, err := q.db.Exec(ctx, `UPDATE `+table+` SET `+strings.Join(assignments, ", ")+` WHERE `+names[0]+` = $1`, args...)
+	if isUniqueViolationPG(err) {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("update %s: %w", table, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// isUniqueViolationPG reports whether err is a primary key or unique constraint failure
+func isUniqueViolationPG(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation
+}
+
+// notFoundPG translates pgx.ErrNoRows into ErrNotFound
+func notFoundPG(err error) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Products
+func (q postgresQueries) GetProducts(ctx context.Context) ([]generated.Product, error) {
+	rows, err := q.db.Query(ctx, `SELECT `+productColumns+` FROM products ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query products: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (generated.Product, error) {
+		return scanProductPG(row)
+	})
+}
+
+func (q postgresQueries) QueryProducts(ctx context.Context, query ProductQuery) (Result[generated.Product], error) {
+	listing, err := productListing(postgresDialect, query)
+	if err != nil {
+		return Result[generated.Product]{}, err
+	}
+	return queryPagePG(ctx, q.db, listing, productColumns, scanProductPG)
+}
+
+func (q postgresQueries) CountProducts(ctx context.Context, query ProductQuery, priceBounds []float32) (ProductCounts, error) {
+	statement, err := productCounts(postgresDialect, query, priceBounds)
+	if err != nil {
+		return ProductCounts{}, err
+	}
+	rows, err := q.db.Query(ctx, statement.query, statement.args...)
+	if err != nil {
+		return ProductCounts{}, fmt.Errorf("count products: %w", err)
+	}
+	defer rows.Close()
+
+	counts := newProductCounts(priceBounds)
+	for rows.Next() {
+		var (
+			categoryID             string
+			priceRange, inStock, n int
+		)
+		if err := rows.Scan(&categoryID, &priceRange, &inStock, &n); err != nil {
+			return ProductCounts{}, err
+		}
+		counts = counts.add(categoryID, priceRange, inStock == 1, n)
+	}
+	return counts, rows.Err()
+}
+
+func (q postgresQueries) GetProduct(ctx context.Context, id string) (generated.Product, error) {
+	product, err := scanProductPG(q.db.QueryRow(ctx, `SELECT `+productColumns+` FROM products WHERE id = $1`+q.forUpdate(), id))
+	return product, notFoundPG(err)
+}
+
+func (q postgresQueries) CreateProduct(ctx context.Context, product generated.Product) (generated.Product, error) {
+	if err := q.insert(ctx, "products", productColumns, productArgsPG(product)); err != nil {
+		return generated.Product{}, err
+	}
+	return product, nil
+}
+
+func (q postgresQueries) UpdateProduct(ctx context.Context, id string, product generated.Product) (generated.Product, error) {
+	product.Id = id
+	if err := q.update(ctx, "products", productColumns, productArgsPG(product)); err != nil {
+		return generated.Product{}, err
+	}
+	return product, nil
+}
+
+func (q postgresQueries) DeleteProduct(ctx context.Context, id string) (generated.Product, error) {
+	product, err := scanProductPG(q.db.QueryRow(ctx, `DELETE FROM products WHERE id = $1 RETURNING `+productColumns, id))
+	return product, notFoundPG(err)
+}
+
+func (q postgresQueries) ModifiedAt(ctx context.Context, table string) (time.Time, error) {
+	if err := checkModifiedTable(table); err != nil {
+		return time.Time{}, err
+	}
+	var modifiedAt *time.Time
+	err := q.db.QueryRow(ctx, `SELECT GREATEST(
+		(SELECT MAX(updated_at) FROM `+table+`),
+		(SELECT deleted_at FROM deletions WHERE table_name = $1))`, table).Scan(&modifiedAt)
+	if err != nil || modifiedAt == nil {
+		return time.Time{}, err
+	}
+	return *modifiedAt, nil
+}
+
+func productArgsPG(product generated.Product) []any {
+	return []any{
+		product.Id, product.Name, product.Description, product.Price, product.Stock, product.CategoryId,
+		product.ImageUrls, product.CreatedAt, product.UpdatedAt, product.Version,
+	}
+}
+
+func scanProductPG(row pgx.Row) (generated.Product, error) {
+	var product generated.Product
+	if err := row.Scan(&product.Id, &product.Name, &product.Description, &product.Price, &product.Stock,
+		&product.CategoryId, &product.ImageUrls, &product.CreatedAt, &product.UpdatedAt, &product.Version); err != nil {
+		return product, fmt.Errorf("scan product: %w", err)
+	}
+	return product, nil
+}
+
+// Categories
+func (q postgresQueries) GetCategories(ctx context.Context) ([]generated.Category, error) {
+	rows, err := q.db.Query(ctx, `SELECT `+categoryColumns+` FROM categories ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query categories: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (generated.Category, error) {
+		return scanCategoryPG(row)
+	})
+}
+
+func (q postgresQueries) GetCategory(ctx context.Context, id string) (generated.Category, error) {
+	category, err := scanCategoryPG(q.db.QueryRow(ctx, `SELECT `+categoryColumns+` FROM categories WHERE id = $1`+q.forUpdate(), id))
+	return category, notFoundPG(err)
+}
+
+func (q postgresQueries) CreateCategory(ctx context.Context, category generated.Category) (generated.Category, error) {
+	if err := q.insert(ctx, "categories", categoryColumns, categoryArgsPG(category)); err != nil {
+		return generated.Category{}, err
+	}
+	return category, nil
+}
+
+func (q postgresQueries) UpdateCategory(ctx context.Context, id string, category generated.Category) (generated.Category, error) {
+	category.Id = id
+	if err := q.update(ctx, "categories", categoryColumns, categoryArgsPG(category)); err != nil {
+		return generated.Category{}, err
+	}
+	return category, nil
+}
+
+func (q postgresQueries) DeleteCategory(ctx context.Context, id string) (generated.Category, error) {
+	category, err := scanCategoryPG(q.db.QueryRow(ctx, `DELETE FROM categories WHERE id = $1 RETURNING `+categoryColumns, id))
+	return category, notFoundPG(err)
+}
+
+func categoryArgsPG(category generated.Category) []any {
+	return []any{category.Id, category.Name, category.ParentId, category.CreatedAt, category.UpdatedAt, category.Version}
+}
+
+func scanCategoryPG(row pgx.Row) (generated.Category, error) {
+	var category generated.Category
+	if err := row.Scan(&category.Id, &category.Name, &category.ParentId, &category.CreatedAt, &category.UpdatedAt,
+		&category.Version); err != nil {
+		return category, fmt.Errorf("scan category: %w", err)
+	}
+	return category, nil
+}
+
+// Users
+func (q postgresQueries) GetUsers(ctx context.Context) ([]generated.User, error) {
+	rows, err := q.db.Query(ctx, `SELECT `+userColumns+` FROM users ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query users: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (generated.User, error) {
+		return scanUserPG(row)
+	})
+}
+
+func (q postgresQueries) QueryUsers(ctx context.Context, query UserQuery) (Result[generated.User], error) {
+	listing, err := newSQLListing(postgresDialect, "users", query.Sort, query.Page, SortByCreatedAt)
+	if err != nil {
+		return Result[generated.User]{}, err
+	}
+	return queryPagePG(ctx, q.db, listing, userColumns, scanUserPG)
+}
+
+func (q postgresQueries) GetUser(ctx context.Context, id string) (generated.User, error) {
+	user, err := scanUserPG(q.db.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`+q.forUpdate(), id))
+	return user, notFoundPG(err)
+}
+
+func (q postgresQueries) CreateUser(ctx context.Context, user generated.User) (generated.User, error) {
+	if err := q.insert(ctx, "users", userColumns, userArgsPG(user)); err != nil {
+		return generated.User{}, err
+	}
+	return user, nil
+}
+
+func (q postgresQueries) UpdateUser(ctx context.Context, id string, user generated.User) (generated.User, error) {
+	user.Id = id
+	if err := q.update(ctx, "users", userColumns, userArgsPG(user)); err != nil {
+		return generated.User{}, err
+	}
+	return user, nil
+}
+
+func (q postgresQueries) DeleteUser(ctx context.Context, id string) (generated.User, error) {
+	user, err := scanUserPG(q.db.QueryRow(ctx, `DELETE FROM users WHERE id = $1 RETURNING `+userColumns, id))
+	return user, notFoundPG(err)
+}
+
+func userArgsPG(user generated.User) []any {
+	return []any{user.Id, user.Email, user.Name, user.Address, user.CreatedAt, user.UpdatedAt, user.Version}
+}
+
+func scanUserPG(row pgx.Row) (generated.User, error) {
+	var user generated.User
+	if err := row.Scan(&user.Id, &user.Email, &user.Name, &user.Address, &user.CreatedAt, &user.UpdatedAt,
+		&user.Version); err != nil {
+		return user, fmt.Errorf("scan user: %w", err)
+	}
+	return user, nil
+}
+
+// Carts
+func (q postgresQueries) GetCartByUserId(ctx context.Context, userId string) (generated.Cart, error) {
+	cart, err := scanCartPG(q.db.QueryRow(ctx, `SELECT `+cartColumns+` FROM carts WHERE user_id = $1`+q.forUpdate(), userId))
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Return a new empty cart if not exists
+		return newEmptyCart(userId), nil
+	}
+	return cart, err
+}
+
+func (q postgresQueries) UpdateCart(ctx context.Context, userId string, cart generated.Cart) (generated.Cart, error) {
+	cart.UserId = userId
+	_, err := q.db.Exec(ctx, `INSERT INTO carts (`+cartColumns+`) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET
+			id = EXCLUDED.id,
+			items = EXCLUDED.items,
+			created_at = EXCLUDED.created_at,
+			updated_at = EXCLUDED.updated_at`,
+		cart.Id, cart.UserId, cart.Items, cart.CreatedAt, cart.UpdatedAt,
+	)
+	if err != nil {
+		return generated.Cart{}, fmt.Errorf("write cart: %w", err)
+	}
+	return cart, nil
+}
+
+func scanCartPG(row pgx.Row) (generated.Cart, error) {
+	var cart generated.Cart
+	if err := row.Scan(&cart.Id, &cart.UserId, &cart.Items, &cart.CreatedAt, &cart.UpdatedAt); err != nil {
+		return cart, fmt.Errorf("scan cart: %w", err)
+	}
+	return cart, nil
+}
+
+// Holds
+func (q postgresQueries) GetHolds(ctx context.Context) ([]Hold, error) {
+	return q.queryHolds(ctx, `SELECT `+holdColumns+` FROM holds ORDER BY product_id, user_id`)
+}
+
+func (q postgresQueries) GetHoldsByProductId(ctx context.Context, productId string) ([]Hold, error) {
+	return q.queryHolds(ctx, `SELECT `+holdColumns+` FROM holds WHERE product_id = $1 ORDER BY user_id`, productId)
+}
+
+func (q postgresQueries) GetHoldsByUserId(ctx context.Context, userId string) ([]Hold, error) {
+	return q.queryHolds(ctx, `SELECT `+holdColumns+` FROM holds WHERE user_id = $1 ORDER BY product_id`, userId)
+}
+
+func (q postgresQueries) SetHold(ctx context.Context, hold Hold) (Hold, error) {
+	_, err := q.db.Exec(ctx, `INSERT INTO holds (`+holdColumns+`) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (product_id, user_id) DO UPDATE SET
+			quantity = EXCLUDED.quantity,
+			expires_at = EXCLUDED.expires_at,
+			updated_at = EXCLUDED.updated_at`,
+		hold.ProductId, hold.UserId, hold.Quantity, hold.ExpiresAt, hold.UpdatedAt,
+	)
+	if err != nil {
+		return Hold{}, fmt.Errorf("write hold: %w", err)
+	}
+	return hold, nil
+}
+
+func (q postgresQueries) queryHolds(ctx context.Context, query string, args ...any) ([]Hold, error) {
+	rows, err := q.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query holds: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Hold, error) {
+		var hold Hold
+		if err := row.Scan(&hold.ProductId, &hold.UserId, &hold.Quantity, &hold.ExpiresAt, &hold.UpdatedAt); err != nil {
+			return hold, fmt.Errorf("scan hold: %w", err)
+		}
+		return hold, nil
+	})
+}
+
+// Orders
+func (q postgresQueries) GetOrders(ctx context.Context) ([]generated.Order, error) {
+	return q.queryOrders(ctx, `SELECT `+orderColumns+` FROM orders ORDER BY id`)
+}
+
+func (q postgresQueries) GetOrder(ctx context.Context, id string) (generated.Order, error) {
+	order, err := scanOrderPG(q.db.QueryRow(ctx, `SELECT `+orderColumns+` FROM orders WHERE id = $1`+q.forUpdate(), id))
+	return order, notFoundPG(err)
+}
+
+func (q postgresQueries) GetOrdersByUserId(ctx context.Context, userId string) ([]generated.Order, error) {
+	return q.queryOrders(ctx, `SELECT `+orderColumns+` FROM orders WHERE user_id = $1 ORDER BY id`, userId)
+}
+
+func (q postgresQueries) QueryOrders(ctx context.Context, query OrderQuery) (Result[generated.Order], error) {
+	listing, err := newSQLListing(postgresDialect, "orders", query.Sort, query.Page, SortByCreatedAt)
+	if err != nil {
+		return Result[generated.Order]{}, err
+	}
+	if query.UserId != "" {
+		listing.where("user_id = %s", query.UserId)
+	}
+	if query.Status != nil {
+		listing.where("status = %s", string(*query.Status))
+	}
+	if query.CreatedFrom != nil {
+		listing.where("created_at >= %s", *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		listing.where("created_at <= %s", *query.CreatedTo)
+	}
+	return queryPagePG(ctx, q.db, listing, orderColumns, scanOrderPG)
+}
+
+func (q postgresQueries) CreateOrder(ctx context.Context, order generated.Order) (generated.Order, error) {
+	if err := q.insert(ctx, "orders", orderColumns, orderArgsPG(order)); err != nil {
+		return generated.Order{}, err
+	}
+	return order, nil
+}
+
+func (q postgresQueries) UpdateOrder(ctx context.Context, id string, order generated.Order) (generated.Order, error) {
+	order.Id = id
+	if err := q.update(ctx, "orders", orderColumns, orderArgsPG(order)); err != nil {
+		return generated.Order{}, err
+	}
+	return order, nil
+}
+
+func (q postgresQueries) queryOrders(ctx context.Context, query string, args ...any) ([]generated.Order, error) {
+	rows, err := q.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query orders: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (generated.Order, error) {
+		return scanOrderPG(row)
+	})
+}
+
+func orderArgsPG(order generated.Order) []any {
+	return []any{
+		order.Id, order.UserId, order.Items, order.TotalAmount, string(order.Status), order.ShippingAddress,
+		order.CreatedAt, order.UpdatedAt,
+	}
+}
+
+func scanOrderPG(row pgx.Row) (generated.Order, error) {
+	var order generated.Order
+	if err := row.Scan(&order.Id, &order.UserId, &order.Items, &order.TotalAmount, &order.Status, &order.ShippingAddress,
+		&order.CreatedAt, &order.UpdatedAt); err != nil {
+		return order, fmt.Errorf("scan order: %w", err)
+	}
+	return order, nil
+}
+
+// Credentials
+func (q postgresQueries) GetCredential(ctx context.Context, userId string) (Credential, error) {
+	credential, err := scanCredentialPG(q.db.QueryRow(ctx, `SELECT `+credentialColumns+` FROM credentials WHERE user_id = $1`+q.forUpdate(), userId))
+	return credential, notFoundPG(err)
+}
+
+func (q postgresQueries) GetCredentialByEmail(ctx context.Context, email string) (Credential, error) {
+	credential, err := scanCredentialPG(q.db.QueryRow(ctx, `SELECT `+credentialColumns+` FROM credentials WHERE email = $1`+q.forUpdate(), email))
+	return credential, notFoundPG(err)
+}
+
+func (q postgresQueries) CreateCredential(ctx context.Context, credential Credential) (Credential, error) {
+	if err := q.insert(ctx, "credentials", credentialColumns, credentialArgsPG(credential)); err != nil {
+		return Credential{}, err
+	}
+	return credential, nil
+}
+
+func (q postgresQueries) UpdateCredential(ctx context.Context, userId string, credential Credential) (Credential, error) {
+	credential.UserId = userId
+	if err := q.update(ctx, "credentials", credentialColumns, credentialArgsPG(credential)); err != nil {
+		return Credential{}, err
+	}
+	return credential, nil
+}
+
+func (q postgresQueries) DeleteCredential(ctx context.Context, userId string) (Credential, error) {
+	credential, err := scanCredentialPG(q.db.QueryRow(ctx, `DELETE FROM credentials WHERE user_id = $1 RETURNING `+credentialColumns, userId))
+	return credential, notFoundPG(err)
+}
+
+func credentialArgsPG(credential Credential) []any {
+	return []any{
+		credential.UserId, credential.Email, credential.PasswordHash, credential.Roles, credential.CreatedAt, credential.UpdatedAt,
+	}
+}
+
+func scanCredentialPG(row pgx.Row) (Credential, error) {
+	var credential Credential
+	if err := row.Scan(&credential.UserId, &credential.Email, &credential.PasswordHash, &credential.Roles,
+		&credential.CreatedAt, &credential.UpdatedAt); err != nil {
+		return credential, fmt.Errorf("scan credential: %w", err)
+	}
+	return credential, nil
+}
+
+// API keys
+func (q postgresQueries) GetAPIKeys(ctx context.Context, userId string) ([]APIKey, error) {
+	rows, err := q.db.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = $1 ORDER BY created_at, id`, userId)
+	if err != nil {
+		return nil, fmt.Errorf("query api keys: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (APIKey, error) {
+		return scanAPIKeyPG(row)
+	})
+}
+
+func (q postgresQueries) GetAPIKey(ctx context.Context, id string) (APIKey, error) {
+	key, err := scanAPIKeyPG(q.db.QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`+q.forUpdate(), id))
+	return key, notFoundPG(err)
+}
+
+func (q postgresQueries) GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
+	key, err := scanAPIKeyPG(q.db.QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`+q.forUpdate(), keyHash))
+	return key, notFoundPG(err)
+}
+
+func (q postgresQueries) CreateAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
+	if err := q.insert(ctx, "api_keys", apiKeyColumns, apiKeyArgsPG(key)); err != nil {
+		return APIKey{}, err
+	}
+	return key, nil
+}
+
+func (q postgresQueries) UpdateAPIKey(ctx context.Context, id string, key APIKey) (APIKey, error) {
+	key.Id = id
+	if err := q.update(ctx, "api_keys", apiKeyColumns, apiKeyArgsPG(key)); err != nil {
+		return APIKey{}, err
+	}
+	return key, nil
+}
+
+func (q postgresQueries) DeleteAPIKey(ctx context.Context, id string) (APIKey, error) {
+	key, err := scanAPIKeyPG(q.db.QueryRow(ctx, `DELETE FROM api_keys WHERE id = $1 RETURNING `+apiKeyColumns, id))
+	return key, notFoundPG(err)
+}
+
+func apiKeyArgsPG(key APIKey) []any {
+	return []any{key.Id, key.UserId, key.Name, key.Prefix, key.KeyHash, key.Scopes, key.CreatedAt, key.LastUsedAt}
+}
+
+func scanAPIKeyPG(row pgx.Row) (APIKey, error) {
+	var key APIKey
+	if err := row.Scan(&key.Id, &key.UserId, &key.Name, &key.Prefix, &key.KeyHash, &key.Scopes,
+		&key.CreatedAt, &key.LastUsedAt); err != nil {
+		return key, fmt.Errorf("scan api key: %w", err)
+	}
+	return key, nil
+}
+
+// Sessions
+func (q postgresQueries) GetSessionsByUserId(ctx context.Context, userId string) ([]Session, error) {
+	rows, err := q.db.Query(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE user_id = $1`, userId)
+	if err != nil {
+		return nil, fmt.Errorf("query sessions: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Session, error) {
+		return scanSessionPG(row)
+	})
+}
+
+func (q postgresQueries) GetSession(ctx context.Context, id string) (Session, error) {
+	session, err := scanSessionPG(q.db.QueryRow(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE id = $1`+q.forUpdate(), id))
+	return session, notFoundPG(err)
+}
+
+func (q postgresQueries) CreateSession(ctx context.Context, session Session) (Session, error) {
+	if err := q.insert(ctx, "sessions", sessionColumns, sessionArgsPG(session)); err != nil {
+		return Session{}, err
+	}
+	return session, nil
+}
+
+func (q postgresQueries) UpdateSession(ctx context.Context, id string, session Session) (Session, error) {
+	session.Id = id
+	if err := q.update(ctx, "sessions", sessionColumns, sessionArgsPG(session)); err != nil {
+		return Session{}, err
+	}
+	return session, nil
+}
+
+func (q postgresQueries) DeleteSession(ctx context.Context, id string) (Session, error) {
+	if _, err := q.db.Exec(ctx, `DELETE FROM refresh_tokens WHERE session_id = $1`, id); err != nil {
+		return Session{}, fmt.Errorf("delete refresh tokens: %w", err)
+	}
+	session, err := scanSessionPG(q.db.QueryRow(ctx, `DELETE FROM sessions WHERE id = $1 RETURNING `+sessionColumns, id))
+	return session, notFoundPG(err)
+}
+
+func sessionArgsPG(session Session) []any {
+	return []any{
+		session.Id, session.UserId, session.Device, session.IP, session.CreatedAt, session.LastSeenAt, session.ExpiresAt,
+	}
+}
+
+func scanSessionPG(row pgx.Row) (Session, error) {
+	var session Session
+	if err := row.Scan(&session.Id, &session.UserId, &session.Device, &session.IP,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt); err != nil {
+		return session, fmt.Errorf("scan session: %w", err)
+	}
+	return session, nil
+}
+
+// Refresh tokens
+func (q postgresQueries) GetRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	token, err := scanRefreshTokenPG(q.db.QueryRow(ctx, `SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE token_hash = $1`+q.forUpdate(), tokenHash))
+	return token, notFoundPG(err)
+}
+
+func (q postgresQueries) CreateRefreshToken(ctx context.Context, token RefreshToken) (RefreshToken, error) {
+	if err := q.insert(ctx, "refresh_tokens", refreshTokenColumns, refreshTokenArgsPG(token)); err != nil {
+		return RefreshToken{}, err
+	}
+	return token, nil
+}
+
+func (q postgresQueries) UpdateRefreshToken(ctx context.Context, tokenHash string, token RefreshToken) (RefreshToken, error) {
+	token.TokenHash = tokenHash
+	if err := q.update(ctx, "refresh_tokens", refreshTokenColumns, refreshTokenArgsPG(token)); err != nil {
+		return RefreshToken{}, err
+	}
+	return token, nil
+}
+
+func refreshTokenArgsPG(token RefreshToken) []any {
+	return []any{token.TokenHash, token.SessionId, token.Used, token.ExpiresAt}
+}
+
+func scanRefreshTokenPG(row pgx.Row) (RefreshToken, error) {
+	var token RefreshToken
+	if err := row.Scan(&token.TokenHash, &token.SessionId, &token.Used, &token.ExpiresAt); err != nil {
+		return token, fmt.Errorf("scan refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// Password resets
+func (q postgresQueries) CreatePasswordReset(ctx context.Context, reset PasswordReset) (PasswordReset, error) {
+	if err := q.insert(ctx, "password_resets", passwordResetColumns, []any{reset.TokenHash, reset.UserId, reset.ExpiresAt}); err != nil {
+		return PasswordReset{}, err
+	}
+	return reset, nil
+}
+
+func (q postgresQueries) DeletePasswordReset(ctx context.Context, tokenHash string) (PasswordReset, error) {
+	var reset PasswordReset
+	err := q.db.QueryRow(ctx, `DELETE FROM password_resets WHERE token_hash = $1 RETURNING `+passwordResetColumns, tokenHash).
+		Scan(&reset.TokenHash, &reset.UserId, &reset.ExpiresAt)
+	if err != nil {
+		return reset, notFoundPG(fmt.Errorf("scan password reset: %w", err))
+	}
+	return reset, nil
+}
+
+func (q postgresQueries) DeletePasswordResetsByUserId(ctx context.Context, userId string) error {
+	if _, err := q.db.Exec(ctx, `DELETE FROM password_resets WHERE user_id = $1`, userId); err != nil {
+		return fmt.Errorf("delete password resets: %w", err)
+	}
+	return nil
+}
+
+// Identities
+func (q postgresQueries) GetIdentity(ctx context.Context, issuer, subject string) (Identity, error) {
+	var identity Identity
+	err := q.db.QueryRow(ctx, `SELECT `+identityColumns+` FROM identities WHERE issuer = $1 AND subject = $2`+q.forUpdate(), issuer, subject).
+		Scan(&identity.Issuer, &identity.Subject, &identity.UserId, &identity.CreatedAt)
+	if err != nil {
+		return identity, notFoundPG(fmt.Errorf("scan identity: %w", err))
+	}
+	return identity, nil
+}
+
+func (q postgresQueries) CreateIdentity(ctx context.Context, identity Identity) (Identity, error) {
+	args := []any{identity.Issuer, identity.Subject, identity.UserId, identity.CreatedAt}
+	if err := q.insert(ctx, "identities", identityColumns, args); err != nil {
+		return Identity{}, err
+	}
+	return identity, nil
+}
+
+func (q postgresQueries) DeleteIdentitiesByUserId(ctx context.Context, userId string) error {
+	if _, err := q.db.Exec(ctx, `DELETE FROM identities WHERE user_id = $1`, userId); err != nil {
+		return fmt.Errorf("delete identities: %w", err)
+	}
+	return nil
+}
+
+func (q postgresQueries) DeleteExpiredTokens(ctx context.Context, now time.Time) error {
+	for _, table := range []string{"sessions", "refresh_tokens", "password_resets"} {
+		if _, err := q.db.Exec(ctx, `DELETE FROM `+table+` WHERE expires_at < $1`, now); err != nil {
+			return fmt.Errorf("delete expired %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// queryPagePG runs the statements of listing, scanning the rows of the page
+// with scan. They run in one read-only REPEATABLE READ transaction, unless db
+// is a transaction already, so that the total, the rows and the offset come
+// from the same snapshot.
+func queryPagePG[T any](ctx context.Context, db pgxDB, listing *sqlListing, columns string, scan func(pgx.Row) (T, error)) (Result[T], error) {
+	pool, ok := db.(*pgxpool.Pool)
+	if !ok {
+		return readPagePG(ctx, db, listing, columns, scan)
+	}
+	var result Result[T]
+	err := pgx.BeginTxFunc(ctx, pool, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}, func(tx pgx.Tx) error {
+		var err error
+		result, err = readPagePG(ctx, tx, listing, columns, scan)
+		return err
+	})
+	return result, err
+}
+
+// readPagePG runs the statements of listing against db
+func readPagePG[T any](ctx context.Context, db pgxDB, listing *sqlListing, columns string, scan func(pgx.Row) (T, error)) (Result[T], error) {
+	var result Result[T]
+	total := listing.total()
+	if err := db.QueryRow(ctx, total.query, total.args...).Scan(&result.Total); err != nil {
+		return result, fmt.Errorf("count %s: %w", listing.table, err)
+	}
+
+	statement, reversed := listing.rows(columns)
+	rows, err := db.Query(ctx, statement.query, statement.args...)
+	if err != nil {
+		return result, fmt.Errorf("query %s: %w", listing.table, err)
+	}
+	result.Items, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (T, error) {
+		return scan(row)
+	})
+	if err != nil {
+		return result, err
+	}
+	if reversed {
+		slices.Reverse(result.Items)
+	}
+
+	var before int
+	if statement, ok := listing.before(); ok {
+		if err := db.QueryRow(ctx, statement.query, statement.args...).Scan(&before); err != nil {
+			return result, fmt.Errorf("count %s: %w", listing.table, err)
+		}
+	}
+	result.Offset = listing.offset(before, len(result.Items))
+	return result, nil
+}
+
+// Ensure PostgresStore implements Store and Migrator
+var (
+	_ Store    = (*PostgresStore)(nil)
+	_ Migrator = (*PostgresStore)(nil)
+)
@@ -0,0 +1,221 @@
+package store
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+)
+
+// SortField is a field a listing can be ordered by
+type SortField string
+
+const (
+	SortByCreatedAt SortField = "createdAt"
+	SortByName      SortField = "name"
+	SortByPrice     SortField = "price"
+)
+
+// Sort orders a listing by Field and then by ID, so that records with equal
+// fields keep their order between calls. Desc reverses both. Strings are
+// compared byte by byte on every backend.
+type Sort struct {
+	Field SortField
+	Desc  bool
+}
+
+// NoLimit as the Limit of a Page puts no bound on the number of records
+const NoLimit = -1
+
+// Page selects part of a sorted listing: up to Limit records starting at
+// Offset or, if After is set, right after that position, or the Limit
+// records right before Before. At most one of After and Before is set.
+type Page struct {
+	Limit  int
+	Offset int
+	After  *Position
+	Before *Position
+}
+
+// Position is the place of a record in a listing: the value of its sorted
+// field and its ID. Key is a string for names, a float32 for prices and a
+// time.Time for creation times.
+type Position struct {
+	Key any
+	ID  string
+}
+
+// Result is a page of a listing
+type Result[T any] struct {
+	Items []T
+	// Total is the number of records the query matches
+	Total int
+	// Offset is the Offset of the page or, for pages next to a position, the
+	// number of matching records before Items
+	Offset int
+}
+
+// ProductQuery selects a page of products. Filters that are nil match every
+// product.
+type ProductQuery struct {
+	// IDs matches the products with these IDs, e.g. the hits of a search
+	IDs         []string
+	CategoryIDs []string
+	MinPrice    *float32
+	MaxPrice    *float32
+	// Sort is by name, price or createdAt
+	Sort Sort
+	Page Page
+}
+
+// ProductCounts are the numbers of products a query matches
+type ProductCounts struct {
+	Total int
+	// Categories counts the products of each category by ID, leaving out
+	// categories without any
+	Categories map[string]int
+	// PriceRanges counts the products priced under each of the ascending
+	// price bounds counted with, and at or above the bound before it; the
+	// last counts those at or above the last bound
+	PriceRanges []int
+	// InStock counts the products with a positive stock
+	InStock int
+}
+
+func newProductCounts(priceBounds []float32) ProductCounts {
+	return ProductCounts{Categories: make(map[string]int), PriceRanges: make([]int, len(priceBounds)+1)}
+}
+
+// add counts n products of categoryID in the priceRange-th price range
+func (c ProductCounts) add(categoryID string, priceRange int, inStock bool, n int) ProductCounts {
+	c.Total += n
+	c.Categories[categoryID] += n
+	c.PriceRanges[priceRange] += n
+	if inStock {
+		c.InStock += n
+	}
+	return c
+}
+
+// priceRange returns the index of the price range of price
+func priceRange(price float32, priceBounds []float32) int {
+	i := 0
+	for i < len(priceBounds) && price >= priceBounds[i] {
+		i++
+	}
+	return i
+}
+
+// OrderQuery selects a page of orders. Filters that are empty or nil match
+// every order.
+type OrderQuery struct {
+	UserId string
+	Status *generated.OrderStatus
+	// CreatedFrom and CreatedTo bound createdAt, both inclusive
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// Sort is by createdAt
+	Sort Sort
+	Page Page
+}
+
+// UserQuery selects a page of users
+type UserQuery struct {
+	// Sort is by createdAt
+	Sort Sort
+	Page Page
+}
+
+// PageRecords returns the part of records that page selects. records must be
+// sorted by compare, which orders the positions position gives them. The
+// memory store pages with it, as can callers that sort records by something
+// no store knows about.
+func PageRecords[T any](records []T, page Page, position func(T) Position, compare func(a, b Position) int) Result[T] {
+	start, end := min(page.Offset, len(records)), len(records)
+	offset := page.Offset
+	switch {
+	case page.After != nil:
+		start = sort.Search(len(records), func(i int) bool { return compare(position(records[i]), *page.After) > 0 })
+		offset = start
+	case page.Before != nil:
+		end = sort.Search(len(records), func(i int) bool { return compare(position(records[i]), *page.Before) >= 0 })
+		start = 0
+		if page.Limit != NoLimit {
+			start = max(0, end-page.Limit)
+		}
+		offset = start
+	}
+	if page.Limit != NoLimit {
+		end = min(end, start+page.Limit)
+	}
+	return Result[T]{Items: records[start:end], Total: len(records), Offset: offset}
+}
+
+// comparePositions orders positions as sort says
+func comparePositions(sort Sort) func(a, b Position) int {
+	return func(a, b Position) int {
+		c := compareKeys(a.Key, b.Key)
+		if c == 0 {
+			c = strings.Compare(a.ID, b.ID)
+		}
+		if sort.Desc {
+			return -c
+		}
+		return c
+	}
+}
+
+// compareKeys compares the keys of two positions, which checkPage made sure
+// are of the same type
+func compareKeys(a, b any) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case float32:
+		return cmp.Compare(a, b.(float32))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+	panic(fmt.Sprintf("store: cannot compare %T", a))
+}
+
+// checkPage reports a page that cannot be executed with sort, including
+// sorts by fields not in fields
+func checkPage(sort Sort, page Page, fields ...SortField) error {
+	if !slices.Contains(fields, sort.Field) {
+		return unsupportedSort(sort)
+	}
+	if page.Limit < 0 && page.Limit != NoLimit || page.Offset < 0 {
+		return fmt.Errorf("invalid page: limit %d, offset %d", page.Limit, page.Offset)
+	}
+	if page.After != nil && page.Before != nil {
+		return fmt.Errorf("invalid page: both after and before a position")
+	}
+	for _, position := range []*Position{page.After, page.Before} {
+		if position == nil {
+			continue
+		}
+		var ok bool
+		switch sort.Field {
+		case SortByName:
+			_, ok = position.Key.(string)
+		case SortByPrice:
+			_, ok = position.Key.(float32)
+		case SortByCreatedAt:
+			_, ok = position.Key.(time.Time)
+		}
+		if !ok {
+			return fmt.Errorf("invalid page: %T position for sort field %q", position.Key, sort.Field)
+		}
+	}
+	return nil
+}
+
+// unsupportedSort reports a sort field a listing cannot be ordered by
+func unsupportedSort(sort Sort) error {
+	return fmt.Errorf("unsupported sort field %q", sort.Field)
+}
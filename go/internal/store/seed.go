@@ -0,0 +1,260 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+)
+
+// SeedDemoData fills a store that has no users yet with sample categories
+// and products, and the demo users alice@example.com and bob@example.com
+// with their carts. The demo users have well-known passwords and no roles,
+// so this is for development only.
+func SeedDemoData(ctx context.Context, s Store) error {
+	data := newMockData(time.Now())
+
+	return s.WithTx(ctx, func(tx Tx) error {
+		users, err := tx.GetUsers(ctx)
+		if err != nil {
+			return err
+		}
+		if len(users) > 0 {
+			return nil
+		}
+
+		for _, category := range data.categories {
+			if _, err := tx.CreateCategory(ctx, category); err != nil {
+				return fmt.Errorf("seed category: %w", err)
+			}
+		}
+		for _, product := range data.products {
+			if _, err := tx.CreateProduct(ctx, product); err != nil {
+				return fmt.Errorf("seed product: %w", err)
+			}
+		}
+		for _, user := range data.users {
+			if _, err := tx.CreateUser(ctx, user); err != nil {
+				return fmt.Errorf("seed user: %w", err)
+			}
+		}
+		for _, cart := range data.carts {
+			if _, err := tx.UpdateCart(ctx, cart.UserId, cart); err != nil {
+				return fmt.Errorf("seed cart: %w", err)
+			}
+		}
+		for _, credential := range data.credentials {
+			if _, err := tx.CreateCredential(ctx, credential); err != nil {
+				return fmt.Errorf("seed credential: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// mockData holds the sample records SeedDemoData inserts
+type mockData struct {
+	categories  []generated.Category
+	products    []generated.Product
+	users       []generated.User
+	carts       []generated.Cart
+	credentials []Credential
+}
+
+// newMockData builds the sample records with the given timestamp
+func newMockData(now time.Time) mockData {
+	return mockData{
+		categories: []generated.Category{
+			{
+				Id:        "1",
+				Name:      "Electronics",
+				ParentId:  nil,
+				CreatedAt: now,
+				UpdatedAt: now,
+				Version:   int32Ptr(1),
+			},
+			{
+				Id:        "2",
+				Name:      "Laptops",
+				ParentId:  stringPtr("1"),
+				CreatedAt: now,
+				UpdatedAt: now,
+				Version:   int32Ptr(1),
+			},
+			{
+				Id:        "3",
+				Name:      "Smartphones",
+				ParentId:  stringPtr("1"),
+				CreatedAt: now,
+				UpdatedAt: now,
+				Version:   int32Ptr(1),
+			},
+			{
+				Id:        "4",
+				Name:      "Clothing",
+				ParentId:  nil,
+				CreatedAt: now,
+				UpdatedAt: now,
+				Version:   int32Ptr(1),
+			},
+		},
+		products: []generated.Product{
+			{
+				Id:          "1",
+				Name:        "MacBook Pro 16\"",
+				Description: "Apple MacBook Pro with M3 chip",
+				Price:       2499.99,
+				Stock:       10,
+				CategoryId:  "2",
+				ImageUrls:   []string{"https://example.com/macbook.jpg"},
+				CreatedAt:   now,
+				UpdatedAt:   now,
+				Version:     int32Ptr(1),
+			},
+			{
+				Id:          "2",
+				Name:        "iPhone 15 Pro",
+				Description: "Latest iPhone with titanium design",
+				Price:       999.99,
+				Stock:       25,
+				CategoryId:  "3",
+				ImageUrls:   []string{"https://example.com/iphone.jpg"},
+				CreatedAt:   now,
+				UpdatedAt:   now,
+				Version:     int32Ptr(1),
+			},
+			{
+				Id:          "3",
+				Name:        "T-Shirt",
+				Description: "Comfortable cotton t-shirt",
+				Price:       29.99,
+				Stock:       100,
+				CategoryId:  "4",
+				ImageUrls:   []string{"https://example.com/tshirt.jpg"},
+				CreatedAt:   now,
+				UpdatedAt:   now,
+				Version:     int32Ptr(1),
+			},
+		},
+		users: []generated.User{
+			{
+				Id:    "1",
+				Email: "user1@example.com",
+				Name:  "Test User 1",
+				Address: &generated.Address{
+					Street:     "123 Test St",
+					City:       "Test City",
+					State:      "TC",
+					PostalCode: "12345",
+					Country:    "USA",
+				},
+				CreatedAt: now,
+				UpdatedAt: now,
+				Version:   int32Ptr(1),
+			},
+			{
+				Id:    "2",
+				Email: "user2@example.com",
+				Name:  "Test User 2",
+				Address: &generated.Address{
+					Street:     "456 Demo Ave",
+					City:       "Demo City",
+					State:      "DC",
+					PostalCode: "67890",
+					Country:    "USA",
+				},
+				CreatedAt: now,
+				UpdatedAt: now,
+				Version:   int32Ptr(1),
+			},
+			// Demo accounts that can log in, see credentials below
+			{
+				Id:        "550e8400-e29b-41d4-a716-446655440001",
+				Email:     "alice@example.com",
+				Name:      "Alice Johnson",
+				CreatedAt: now,
+				UpdatedAt: now,
+				Version:   int32Ptr(1),
+			},
+			{
+				Id:        "550e8400-e29b-41d4-a716-446655440002",
+				Email:     "bob@example.com",
+				Name:      "Bob Smith",
+				CreatedAt: now,
+				UpdatedAt: now,
+				Version:   int32Ptr(1),
+			},
+		},
+		// Initialize empty carts for users
+		carts: []generated.Cart{
+			{
+				Id:        "cart-1",
+				UserId:    "1",
+				Items:     []generated.CartItem{},
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			{
+				Id:        "cart-2",
+				UserId:    "2",
+				Items:     []generated.CartItem{},
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			{
+				Id:        "cart-550e8400-e29b-41d4-a716-446655440001",
+				UserId:    "550e8400-e29b-41d4-a716-446655440001",
+				Items:     []generated.CartItem{},
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			{
+				Id:        "cart-550e8400-e29b-41d4-a716-446655440002",
+				UserId:    "550e8400-e29b-41d4-a716-446655440002",
+				Items:     []generated.CartItem{},
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+		},
+		// The demo accounts' hashes are bcrypt hashes of "password123" and "password456"
+		credentials: []Credential{
+			{
+				UserId:       "550e8400-e29b-41d4-a716-446655440001",
+				Email:        "alice@example.com",
+				PasswordHash: "$2a$10$YFU/ZoNenMSPTIY8424slu4hOmkdodQLnB3gd5g20Lh7KgttGhPOG",
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			},
+			{
+				UserId:       "550e8400-e29b-41d4-a716-446655440002",
+				Email:        "bob@example.com",
+				PasswordHash: "$2a$10$NTJkGwBMiYdLiUxGaszZNO/wYBmSbRhm7LYoATb/rj.OMZJulUrvq",
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			},
+		},
+	}
+}
+
+// Helper function to create a pointer to a string
+func stringPtr(s string) *string {
+	return &s
+}
+
+// Helper function to create a pointer to an int32
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+// newEmptyCart builds the cart returned for users that have none stored yet
+func newEmptyCart(userId string) generated.Cart {
+	now := time.Now()
+	return generated.Cart{
+		Id:        "cart-" + userId,
+		UserId:    userId,
+		Items:     []generated.CartItem{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
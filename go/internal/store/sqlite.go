@@ -0,0 +1,1234 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteBusyTimeout is how many milliseconds a statement waits for the locks
+// of other connections
+const sqliteBusyTimeout = 5000
+
+// SQLiteStore implements the Store interface on top of a single SQLite database file
+type SQLiteStore struct {
+	sqliteQueries
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (or creates) the database at path.
+// Call MigrateUp before use to bring the schema up to date.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	dsn := "file:" + path + "?" + url.Values{
+		"_pragma": {fmt.Sprintf("busy_timeout(%d)", sqliteBusyTimeout), "journal_mode(WAL)"},
+	}.Encode()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	// SQLite allows a single writer; one connection avoids SQLITE_BUSY errors
+	db.SetMaxOpenConns(1)
+
+	return &SQLiteStore{sqliteQueries: sqliteQueries{db: db}, db: db}, nil
+}
+
+// Close closes the underlying database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// WithTx runs fn in a transaction. The store has a single connection, so the
+// transaction also serializes fn against every other store call.
+func (s *SQLiteStore) WithTx(ctx context.Context, fn func(tx Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(sqliteQueries{db: tx}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Claim locks the database file until the store is closed, so that other
+// processes can neither read nor write it meanwhile
+func (s *SQLiteStore) Claim(ctx context.Context) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return claimError(err)
+	}
+	defer conn.Close()
+
+	// In exclusive locking mode the connection keeps the lock of its first
+	// write; it is the only connection of the store, so the store keeps it
+	if _, err := conn.ExecContext(ctx, `PRAGMA locking_mode = EXCLUSIVE`); err != nil {
+		return claimError(err)
+	}
+	if _, err := conn.ExecContext(ctx, `PRAGMA busy_timeout = 0`); err != nil {
+		return claimError(err)
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), fmt.Sprintf(`PRAGMA busy_timeout = %d`, sqliteBusyTimeout))
+
+	if _, err := conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		return claimError(err)
+	}
+	if _, err := conn.ExecContext(ctx, `COMMIT`); err != nil {
+		return claimError(err)
+	}
+	return nil
+}
+
+// claimError translates the lock another connection holds into ErrClaimed
+func claimError(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_BUSY {
+		return ErrClaimed
+	}
+	return fmt.Errorf("claim database: %w", err)
+}
+
+// MigrateUp applies pending migrations
+func (s *SQLiteStore) MigrateUp(ctx context.Context) error {
+	return migrateUp(ctx, s, "sqlite")
+}
+
+// MigrateDown rolls back the most recently applied migration
+func (s *SQLiteStore) MigrateDown(ctx context.Context) error {
+	return migrateDown(ctx, s, "sqlite")
+}
+
+// MigrationStatus reports the state of every embedded migration
+func (s *SQLiteStore) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	return migrationStatus(ctx, s, "sqlite")
+}
+
+func (s *SQLiteStore) ensureMigrationTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`)
+	return err
+}
+
+func (s *SQLiteStore) appliedMigrations(ctx context.Context) (map[int]time.Time, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt string
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		if applied[version], err = time.Parse(time.RFC3339Nano, appliedAt); err != nil {
+			return nil, fmt.Errorf("parse applied_at: %w", err)
+		}
+	}
+	return applied, rows.Err()
+}
+
+func (s *SQLiteStore) applyMigration(ctx context.Context, m migration, up bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if up {
+		if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+			m.Version, m.Name, formatTime(time.Now()),
+		); err != nil {
+			return err
+		}
+	} else {
+		if _, err := tx.ExecContext(ctx, m.Down); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, m.Version); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// sqlDB is implemented by both *sql.DB and *sql.Tx
+type sqlDB interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// sqliteQueries implements the Store data methods against a database or transaction
+type sqliteQueries struct {
+	db sqlDB
+}
+
+// insert adds a row and reports a duplicate primary key or unique column as ErrConflict
+func (q sqliteQueries) insert(ctx context.Context, table, columns string, args []any) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	_, err := q.db.ExecContext(ctx, `INSERT INTO `+table+` (`+columns+`) VALUES (`+placeholders+`)`, args...)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("insert into %s: %w", table, err)
+	}
+	return nil
+}
+
+// update overwrites the row whose id is args[0], reporting a missing row as
+// ErrNotFound and a duplicate unique column as ErrConflict
+func (q sqliteQueries) update(ctx context.Context, table, columns string, args []any) error {
+	names := strings.Split(columns, ", ")
+	assignments := make([]string, 0, len(names)-1)
+	for _, name := range names[1:] {
+		assignments = append(assignments, name+" = ?")
+	}
+
+	result, err := q.db.ExecContext(ctx, `UPDATE `+table+` SET `+strings.Join(assignments, ", ")+` WHERE `+names[0]+` = ?`,
+		append(args[1:len(args):len(args)], args[0])...)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	if err != nil {
+		return fmt.Errorf("update %s: %w", table, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update %s: %w", table, err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether err is a primary key or unique constraint failure
+func isUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY || code == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// notFound translates sql.ErrNoRows into ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Products
+const productColumns = `id, name, description, price, stock, category_id, image_urls, created_at, updated_at, version`
+
+func (q sqliteQueries) GetProducts(ctx context.Context) ([]generated.Product, error) {
+	rows, err := q.db.QueryContext(ctx, `SELECT `+productColumns+` FROM products ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query products: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]generated.Product, 0)
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, rows.Err()
+}
+
+func (q sqliteQueries) QueryProducts(ctx context.Context, query ProductQuery) (Result[generated.Product], error) {
+	listing, err := productListing(sqliteDialect, query)
+	if err != nil {
+		return Result[generated.Product]{}, err
+	}
+	return queryPage(ctx, q.db, listing, productColumns, scanProduct)
+}
+
+func (q sqliteQueries) CountProducts(ctx context.Context, query ProductQuery, priceBounds []float32) (ProductCounts, error) {
+	statement, err := productCounts(sqliteDialect, query, priceBounds)
+	if err != nil {
+		return ProductCounts{}, err
+	}
+	rows, err := q.db.QueryContext(ctx, statement.query, statement.args...)
+	if err != nil {
+		return ProductCounts{}, fmt.Errorf("count products: %w", err)
+	}
+	defer rows.Close()
+
+	counts := newProductCounts(priceBounds)
+	for rows.Next() {
+		var (
+			categoryID             string
+			priceRange, inStock, n int
+		)
+		if err := rows.Scan(&categoryID, &priceRange, &inStock, &n); err != nil {
+			return ProductCounts{}, err
+		}
+		counts = counts.add(categoryID, priceRange, inStock == 1, n)
+	}
+	return counts, rows.Err()
+}
+
+func (q sqliteQueries) GetProduct(ctx context.Context, id string) (generated.Product, error) {
+	product, err := scanProduct(q.db.QueryRowContext(ctx, `SELECT `+productColumns+` FROM products WHERE id = ?`, id))
+	return product, notFound(err)
+}
+
+func (q sqliteQueries) CreateProduct(ctx context.Context, product generated.Product) (generated.Product, error) {
+	args, err := productArgs(product)
+	if err != nil {
+		return generated.Product{}, err
+	}
+	if err := q.insert(ctx, "products", productColumns, args); err != nil {
+		return generated.Product{}, err
+	}
+	return product, nil
+}
+
+func (q sqliteQueries) UpdateProduct(ctx context.Context, id string, product generated.Product) (generated.Product, error) {
+	product.Id = id
+	args, err := productArgs(product)
+	if err != nil {
+		return generated.Product{}, err
+	}
+	if err := q.update(ctx, "products", productColumns, args); err != nil {
+		return generated.Product{}, err
+	}
+	return product, nil
+}
+
+func (q sqliteQueries) DeleteProduct(ctx context.Context, id string) (generated.Product, error) {
+	product, err := scanProduct(q.db.QueryRowContext(ctx, `DELETE FROM products WHERE id = ? RETURNING `+productColumns, id))
+	return product, notFound(err)
+}
+
+func (q sqliteQueries) ModifiedAt(ctx context.Context, table string) (time.Time, error) {
+	if err := checkModifiedTable(table); err != nil {
+		return time.Time{}, err
+	}
+	// julianday reads timestamps with any number of fractional digits, which
+	// the text of older rows does not sort by
+	var updatedAt, deletedAt sql.NullString
+	err := q.db.QueryRowContext(ctx, `SELECT
+		(SELECT strftime('%Y-%m-%dT%H:%M:%fZ', MAX(julianday(updated_at))) FROM `+table+`),
+		(SELECT deleted_at FROM deletions WHERE table_name = ?)`, table).Scan(&updatedAt, &deletedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query modification time of %s: %w", table, err)
+	}
+	var modifiedAt time.Time
+	for _, text := range []sql.NullString{updatedAt, deletedAt} {
+		if !text.Valid {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, text.String)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parse modification time of %s: %w", table, err)
+		}
+		modifiedAt = latest(modifiedAt, t)
+	}
+	return modifiedAt, nil
+}
+
+func productArgs(product generated.Product) ([]any, error) {
+	imageUrls, err := json.Marshal(product.ImageUrls)
+	if err != nil {
+		return nil, fmt.Errorf("encode image urls: %w", err)
+	}
+	return []any{
+		product.Id, product.Name, product.Description, product.Price, product.Stock, product.CategoryId,
+		string(imageUrls), formatTime(product.CreatedAt), formatTime(product.UpdatedAt), product.Version,
+	}, nil
+}
+
+func scanProduct(row rowScanner) (generated.Product, error) {
+	var (
+		product              generated.Product
+		imageUrls            string
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&product.Id, &product.Name, &product.Description, &product.Price, &product.Stock,
+		&product.CategoryId, &imageUrls, &createdAt, &updatedAt, &product.Version); err != nil {
+		return product, fmt.Errorf("scan product: %w", err)
+	}
+	if err := json.Unmarshal([]byte(imageUrls), &product.ImageUrls); err != nil {
+		return product, fmt.Errorf("decode image urls: %w", err)
+	}
+	return product, parseTimestamps(createdAt, updatedAt, &product.CreatedAt, &product.UpdatedAt)
+}
+
+// Categories
+const categoryColumns = `id, name, parent_id, created_at, updated_at, version`
+
+func (q sqliteQueries) GetCategories(ctx context.Context) ([]generated.Category, error) {
+	rows, err := q.db.QueryContext(ctx, `SELECT `+categoryColumns+` FROM categories ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make([]generated.Category, 0)
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+func (q sqliteQueries) GetCategory(ctx context.Context, id string) (generated.Category, error) {
+	category, err := scanCategory(q.db.QueryRowContext(ctx, `SELECT `+categoryColumns+` FROM categories WHERE id = ?`, id))
+	return category, notFound(err)
+}
+
+func (q sqliteQueries) CreateCategory(ctx context.Context, category generated.Category) (generated.Category, error) {
+	if err := q.insert(ctx, "categories", categoryColumns, categoryArgs(category)); err != nil {
+		return generated.Category{}, err
+	}
+	return category, nil
+}
+
+func (q sqliteQueries) UpdateCategory(ctx context.Context, id string, category generated.Category) (generated.Category, error) {
+	category.Id = id
+	if err := q.update(ctx, "categories", categoryColumns, categoryArgs(category)); err != nil {
+		return generated.Category{}, err
+	}
+	return category, nil
+}
+
+func (q sqliteQueries) DeleteCategory(ctx context.Context, id string) (generated.Category, error) {
+	category, err := scanCategory(q.db.QueryRowContext(ctx, `DELETE FROM categories WHERE id = ? RETURNING `+categoryColumns, id))
+	return category, notFound(err)
+}
+
+func categoryArgs(category generated.Category) []any {
+	return []any{
+		category.Id, category.Name, category.ParentId, formatTime(category.CreatedAt), formatTime(category.UpdatedAt),
+		category.Version,
+	}
+}
+
+func scanCategory(row rowScanner) (generated.Category, error) {
+	var (
+		category             generated.Category
+		parentId             sql.NullString
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&category.Id, &category.Name, &parentId, &createdAt, &updatedAt, &category.Version); err != nil {
+		return category, fmt.Errorf("scan category: %w", err)
+	}
+	if parentId.Valid {
+		category.ParentId = &parentId.String
+	}
+	return category, parseTimestamps(createdAt, updatedAt, &category.CreatedAt, &category.UpdatedAt)
+}
+
+// Users
+const userColumns = `id, email, name, address, created_at, updated_at, version`
+
+func (q sqliteQueries) GetUsers(ctx context.Context) ([]generated.User, error) {
+	rows, err := q.db.QueryContext(ctx, `SELECT `+userColumns+` FROM users ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("query users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]generated.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (q sqliteQueries) QueryUsers(ctx context.Context, query UserQuery) (Result[generated.User], error) {
+	listing, err := newSQLListing(sqliteDialect, "users", query.Sort, query.Page, SortByCreatedAt)
+	if err != nil {
+		return Result[generated.User]{}, err
+	}
+	return queryPage(ctx, q.db, listing, userColumns, scanUser)
+}
+
+func (q sqliteQueries) GetUser(ctx context.Context, id string) (generated.User, error) {
+	user, err := scanUser(q.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, id))
+	return user, notFound(err)
+}
+
+func (q sqliteQueries) CreateUser(ctx context.Context, user generated.User) (generated.User, error) {
+	args, err := userArgs(user)
+	if err != nil {
+		return generated.User{}, err
+	}
+	if err := q.insert(ctx, "users", userColumns, args); err != nil {
+		return generated.User{}, err
+	}
+	return user, nil
+}
+
+func (q sqliteQueries) UpdateUser(ctx context.Context, id string, user generated.User) (generated.User, error) {
+	user.Id = id
+	args, err := userArgs(user)
+	if err != nil {
+		return generated.User{}, err
+	}
+	if err := q.update(ctx, "users", userColumns, args); err != nil {
+		return generated.User{}, err
+	}
+	return user, nil
+}
+
+func (q sqliteQueries) DeleteUser(ctx context.Context, id string) (generated.User, error) {
+	user, err := scanUser(q.db.QueryRowContext(ctx, `DELETE FROM users WHERE id = ? RETURNING `+userColumns, id))
+	return user, notFound(err)
+}
+
+func userArgs(user generated.User) ([]any, error) {
+	var address *string
+	if user.Address != nil {
+		encoded, err := json.Marshal(user.Address)
+		if err != nil {
+			return nil, fmt.Errorf("encode address: %w", err)
+		}
+		address = stringPtr(string(encoded))
+	}
+	return []any{
+		user.Id, user.Email, user.Name, address, formatTime(user.CreatedAt), formatTime(user.UpdatedAt), user.Version,
+	}, nil
+}
+
+func scanUser(row rowScanner) (generated.User, error) {
+	var (
+		user                 generated.User
+		address              sql.NullString
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&user.Id, &user.Email, &user.Name, &address, &createdAt, &updatedAt, &user.Version); err != nil {
+		return user, fmt.Errorf("scan user: %w", err)
+	}
+	if address.Valid {
+		user.Address = &generated.Address{}
+		if err := json.Unmarshal([]byte(address.String), user.Address); err != nil {
+			return user, fmt.Errorf("decode address: %w", err)
+		}
+	}
+	return user, parseTimestamps(createdAt, updatedAt, &user.CreatedAt, &user.UpdatedAt)
+}
+
+// Carts
+const cartColumns = `id, user_id, items, created_at, updated_at`
+
+func (q sqliteQueries) GetCartByUserId(ctx context.Context, userId string) (generated.Cart, error) {
+	cart, err := scanCart(q.db.QueryRowContext(ctx, `SELECT `+cartColumns+` FROM carts WHERE user_id = ?`, userId))
+	if errors.Is(err, sql.ErrNoRows) {
+		// Return a new empty cart if not exists
+		return newEmptyCart(userId), nil
+	}
+	return cart, err
+}
+
+func (q sqliteQueries) UpdateCart(ctx context.Context, userId string, cart generated.Cart) (generated.Cart, error) {
+	cart.UserId = userId
+	items, err := json.Marshal(cart.Items)
+	if err != nil {
+		return generated.Cart{}, fmt.Errorf("encode cart items: %w", err)
+	}
+	_, err = q.db.ExecContext(ctx, `INSERT INTO carts (`+cartColumns+`) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			id = excluded.id,
+			items = excluded.items,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at`,
+		cart.Id, cart.UserId, string(items), formatTime(cart.CreatedAt), formatTime(cart.UpdatedAt),
+	)
+	if err != nil {
+		return generated.Cart{}, fmt.Errorf("write cart: %w", err)
+	}
+	return cart, nil
+}
+
+func scanCart(row rowScanner) (generated.Cart, error) {
+	var (
+		cart                 generated.Cart
+		items                string
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&cart.Id, &cart.UserId, &items, &createdAt, &updatedAt); err != nil {
+		return cart, fmt.Errorf("scan cart: %w", err)
+	}
+	if err := json.Unmarshal([]byte(items), &cart.Items); err != nil {
+		return cart, fmt.Errorf("decode cart items: %w", err)
+	}
+	return cart, parseTimestamps(createdAt, updatedAt, &cart.CreatedAt, &cart.UpdatedAt)
+}
+
+// Holds
+const holdColumns = `product_id, user_id, quantity, expires_at, updated_at`
+
+func (q sqliteQueries) GetHolds(ctx context.Context) ([]Hold, error) {
+	return q.queryHolds(ctx, `SELECT `+holdColumns+` FROM holds ORDER BY product_id, user_id`)
+}
+
+func (q sqliteQueries) GetHoldsByProductId(ctx context.Context, productId string) ([]Hold, error) {
+	return q.queryHolds(ctx, `SELECT `+holdColumns+` FROM holds WHERE product_id = ? ORDER BY user_id`, productId)
+}
+
+func (q sqliteQueries) GetHoldsByUserId(ctx context.Context, userId string) ([]Hold, error) {
+	return q.queryHolds(ctx, `SELECT `+holdColumns+` FROM holds WHERE user_id = ? ORDER BY product_id`, userId)
+}
+
+func (q sqliteQueries) SetHold(ctx context.Context, hold Hold) (Hold, error) {
+	_, err := q.db.ExecContext(ctx, `INSERT INTO holds (`+holdColumns+`) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (product_id, user_id) DO UPDATE SET
+			quantity = excluded.quantity,
+			expires_at = excluded.expires_at,
+			updated_at = excluded.updated_at`,
+		hold.ProductId, hold.UserId, hold.Quantity, formatTime(hold.ExpiresAt), formatTime(hold.UpdatedAt),
+	)
+	if err != nil {
+		return Hold{}, fmt.Errorf("write hold: %w", err)
+	}
+	return hold, nil
+}
+
+func (q sqliteQueries) queryHolds(ctx context.Context, query string, args ...any) ([]Hold, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query holds: %w", err)
+	}
+	defer rows.Close()
+
+	holds := make([]Hold, 0)
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, hold)
+	}
+	return holds, rows.Err()
+}
+
+func scanHold(row rowScanner) (Hold, error) {
+	var (
+		hold                 Hold
+		expiresAt, updatedAt string
+	)
+	if err := row.Scan(&hold.ProductId, &hold.UserId, &hold.Quantity, &expiresAt, &updatedAt); err != nil {
+		return hold, fmt.Errorf("scan hold: %w", err)
+	}
+	var err error
+	if hold.ExpiresAt, err = time.Parse(time.RFC3339Nano, expiresAt); err != nil {
+		return hold, fmt.Errorf("parse expires_at: %w", err)
+	}
+	if hold.UpdatedAt, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+		return hold, fmt.Errorf("parse updated_at: %w", err)
+	}
+	return hold, nil
+}
+
+// Orders
+const orderColumns = `id, user_id, items, total_amount, status, shipping_address, created_at, updated_at`
+
+func (q sqliteQueries) GetOrders(ctx context.Context) ([]generated.Order, error) {
+	return q.queryOrders(ctx, `SELECT `+orderColumns+` FROM orders ORDER BY id`)
+}
+
+func (q sqliteQueries) GetOrder(ctx context.Context, id string) (generated.Order, error) {
+	order, err := scanOrder(q.db.QueryRowContext(ctx, `SELECT `+orderColumns+` FROM orders WHERE id = ?`, id))
+	return order, notFound(err)
+}
+
+func (q sqliteQueries) GetOrdersByUserId(ctx context.Context, userId string) ([]generated.Order, error) {
+	return q.queryOrders(ctx, `SELECT `+orderColumns+` FROM orders WHERE user_id = ? ORDER BY id`, userId)
+}
+
+func (q sqliteQueries) QueryOrders(ctx context.Context, query OrderQuery) (Result[generated.Order], error) {
+	listing, err := newSQLListing(sqliteDialect, "orders", query.Sort, query.Page, SortByCreatedAt)
+	if err != nil {
+		return Result[generated.Order]{}, err
+	}
+	if query.UserId != "" {
+		listing.where("user_id = %s", query.UserId)
+	}
+	if query.Status != nil {
+		listing.where("status = %s", string(*query.Status))
+	}
+	if query.CreatedFrom != nil {
+		listing.where("created_at >= %s", *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		listing.where("created_at <= %s", *query.CreatedTo)
+	}
+	return queryPage(ctx, q.db, listing, orderColumns, scanOrder)
+}
+
+func (q sqliteQueries) CreateOrder(ctx context.Context, order generated.Order) (generated.Order, error) {
+	args, err := orderArgs(order)
+	if err != nil {
+		return generated.Order{}, err
+	}
+	if err := q.insert(ctx, "orders", orderColumns, args); err != nil {
+		return generated.Order{}, err
+	}
+	return order, nil
+}
+
+func (q sqliteQueries) UpdateOrder(ctx context.Context, id string, order generated.Order) (generated.Order, error) {
+	order.Id = id
+	args, err := orderArgs(order)
+	if err != nil {
+		return generated.Order{}, err
+	}
+	if err := q.update(ctx, "orders", orderColumns, args); err != nil {
+		return generated.Order{}, err
+	}
+	return order, nil
+}
+
+func (q sqliteQueries) queryOrders(ctx context.Context, query string, args ...any) ([]generated.Order, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]generated.Order, 0)
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+func orderArgs(order generated.Order) ([]any, error) {
+	items, err := json.Marshal(order.Items)
+	if err != nil {
+		return nil, fmt.Errorf("encode order items: %w", err)
+	}
+	shippingAddress, err := json.Marshal(order.ShippingAddress)
+	if err != nil {
+		return nil, fmt.Errorf("encode shipping address: %w", err)
+	}
+	return []any{
+		order.Id, order.UserId, string(items), order.TotalAmount, string(order.Status), string(shippingAddress),
+		formatTime(order.CreatedAt), formatTime(order.UpdatedAt),
+	}, nil
+}
+
+func scanOrder(row rowScanner) (generated.Order, error) {
+	var (
+		order                  generated.Order
+		items, shippingAddress string
+		createdAt, updatedAt   string
+	)
+	if err := row.Scan(&order.Id, &order.UserId, &items, &order.TotalAmount, &order.Status, &shippingAddress,
+		&createdAt, &updatedAt); err != nil {
+		return order, fmt.Errorf("scan order: %w", err)
+	}
+	if err := json.Unmarshal([]byte(items), &order.Items); err != nil {
+		return order, fmt.Errorf("decode order items: %w", err)
+	}
+	if err := json.Unmarshal([]byte(shippingAddress), &order.ShippingAddress); err != nil {
+		return order, fmt.Errorf("decode shipping address: %w", err)
+	}
+	return order, parseTimestamps(createdAt, updatedAt, &order.CreatedAt, &order.UpdatedAt)
+}
+
+// Credentials
+const credentialColumns = `user_id, email, password_hash, roles, created_at, updated_at`
+
+func (q sqliteQueries) GetCredential(ctx context.Context, userId string) (Credential, error) {
+	credential, err := scanCredential(q.db.QueryRowContext(ctx, `SELECT `+credentialColumns+` FROM credentials WHERE user_id = ?`, userId))
+	return credential, notFound(err)
+}
+
+func (q sqliteQueries) GetCredentialByEmail(ctx context.Context, email string) (Credential, error) {
+	credential, err := scanCredential(q.db.QueryRowContext(ctx, `SELECT `+credentialColumns+` FROM credentials WHERE email = ?`, email))
+	return credential, notFound(err)
+}
+
+func (q sqliteQueries) CreateCredential(ctx context.Context, credential Credential) (Credential, error) {
+	args, err := credentialArgs(credential)
+	if err != nil {
+		return Credential{}, err
+	}
+	if err := q.insert(ctx, "credentials", credentialColumns, args); err != nil {
+		return Credential{}, err
+	}
+	return credential, nil
+}
+
+func (q sqliteQueries) UpdateCredential(ctx context.Context, userId string, credential Credential) (Credential, error) {
+	credential.UserId = userId
+	args, err := credentialArgs(credential)
+	if err != nil {
+		return Credential{}, err
+	}
+	if err := q.update(ctx, "credentials", credentialColumns, args); err != nil {
+		return Credential{}, err
+	}
+	return credential, nil
+}
+
+func (q sqliteQueries) DeleteCredential(ctx context.Context, userId string) (Credential, error) {
+	credential, err := scanCredential(q.db.QueryRowContext(ctx, `DELETE FROM credentials WHERE user_id = ? RETURNING `+credentialColumns, userId))
+	return credential, notFound(err)
+}
+
+func credentialArgs(credential Credential) ([]any, error) {
+	roles, err := json.Marshal(credential.Roles)
+	if err != nil {
+		return nil, fmt.Errorf("encode roles: %w", err)
+	}
+	return []any{
+		credential.UserId, credential.Email, credential.PasswordHash, string(roles),
+		formatTime(credential.CreatedAt), formatTime(credential.UpdatedAt),
+	}, nil
+}
+
+func scanCredential(row rowScanner) (Credential, error) {
+	var (
+		credential           Credential
+		roles                string
+		createdAt, updatedAt string
+	)
+	if err := row.Scan(&credential.UserId, &credential.Email, &credential.PasswordHash, &roles, &createdAt, &updatedAt); err != nil {
+		return credential, fmt.Errorf("scan credential: %w", err)
+	}
+	if err := json.Unmarshal([]byte(roles), &credential.Roles); err != nil {
+		return credential, fmt.Errorf("decode roles: %w", err)
+	}
+	return credential, parseTimestamps(createdAt, updatedAt, &credential.CreatedAt, &credential.UpdatedAt)
+}
+
+// API keys
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, created_at, last_used_at`
+
+func (q sqliteQueries) GetAPIKeys(ctx context.Context, userId string) ([]APIKey, error) {
+	rows, err := q.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = ? ORDER BY created_at, id`, userId)
+	if err != nil {
+		return nil, fmt.Errorf("query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make([]APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (q sqliteQueries) GetAPIKey(ctx context.Context, id string) (APIKey, error) {
+	key, err := scanAPIKey(q.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = ?`, id))
+	return key, notFound(err)
+}
+
+func (q sqliteQueries) GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
+	key, err := scanAPIKey(q.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = ?`, keyHash))
+	return key, notFound(err)
+}
+
+func (q sqliteQueries) CreateAPIKey(ctx context.Context, key APIKey) (APIKey, error) {
+	args, err := apiKeyArgs(key)
+	if err != nil {
+		return APIKey{}, err
+	}
+	if err := q.insert(ctx, "api_keys", apiKeyColumns, args); err != nil {
+		return APIKey{}, err
+	}
+	return key, nil
+}
+
+func (q sqliteQueries) UpdateAPIKey(ctx context.Context, id string, key APIKey) (APIKey, error) {
+	key.Id = id
+	args, err := apiKeyArgs(key)
+	if err != nil {
+		return APIKey{}, err
+	}
+	if err := q.update(ctx, "api_keys", apiKeyColumns, args); err != nil {
+		return APIKey{}, err
+	}
+	return key, nil
+}
+
+func (q sqliteQueries) DeleteAPIKey(ctx context.Context, id string) (APIKey, error) {
+	key, err := scanAPIKey(q.db.QueryRowContext(ctx, `DELETE FROM api_keys WHERE id = ? RETURNING `+apiKeyColumns, id))
+	return key, notFound(err)
+}
+
+func apiKeyArgs(key APIKey) ([]any, error) {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return nil, fmt.Errorf("encode scopes: %w", err)
+	}
+	var lastUsedAt *string
+	if key.LastUsedAt != nil {
+		formatted := formatTime(*key.LastUsedAt)
+		lastUsedAt = &formatted
+	}
+	return []any{
+		key.Id, key.UserId, key.Name, key.Prefix, key.KeyHash, string(scopes), formatTime(key.CreatedAt), lastUsedAt,
+	}, nil
+}
+
+func scanAPIKey(row rowScanner) (APIKey, error) {
+	var (
+		key        APIKey
+		scopes     string
+		createdAt  string
+		lastUsedAt sql.NullString
+	)
+	if err := row.Scan(&key.Id, &key.UserId, &key.Name, &key.Prefix, &key.KeyHash, &scopes, &createdAt, &lastUsedAt); err != nil {
+		return key, fmt.Errorf("scan api key: %w", err)
+	}
+	if err := json.Unmarshal([]byte(scopes), &key.Scopes); err != nil {
+		return key, fmt.Errorf("decode scopes: %w", err)
+	}
+	var err error
+	if key.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return key, fmt.Errorf("parse created_at: %w", err)
+	}
+	if lastUsedAt.Valid {
+		parsed, err := time.Parse(time.RFC3339Nano, lastUsedAt.String)
+		if err != nil {
+			return key, fmt.Errorf("parse last_used_at: %w", err)
+		}
+		key.LastUsedAt = &parsed
+	}
+	return key, nil
+}
+
+// Sessions
+const sessionColumns = `id, user_id, device, ip, created_at, last_seen_at, expires_at`
+
+func (q sqliteQueries) GetSessionsByUserId(ctx context.Context, userId string) ([]Session, error) {
+	rows, err := q.db.QueryContext(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE user_id = ?`, userId)
+	if err != nil {
+		return nil, fmt.Errorf("query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := make([]Session, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func (q sqliteQueries) GetSession(ctx context.Context, id string) (Session, error) {
+	session, err := scanSession(q.db.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE id = ?`, id))
+	return session, notFound(err)
+}
+
+func (q sqliteQueries) CreateSession(ctx context.Context, session Session) (Session, error) {
+	if err := q.insert(ctx, "sessions", sessionColumns, sessionArgs(session)); err != nil {
+		return Session{}, err
+	}
+	return session, nil
+}
+
+func (q sqliteQueries) UpdateSession(ctx context.Context, id string, session Session) (Session, error) {
+	session.Id = id
+	if err := q.update(ctx, "sessions", sessionColumns, sessionArgs(session)); err != nil {
+		return Session{}, err
+	}
+	return session, nil
+}
+
+func (q sqliteQueries) DeleteSession(ctx context.Context, id string) (Session, error) {
+	if _, err := q.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE session_id = ?`, id); err != nil {
+		return Session{}, fmt.Errorf("delete refresh tokens: %w", err)
+	}
+	session, err := scanSession(q.db.QueryRowContext(ctx, `DELETE FROM sessions WHERE id = ? RETURNING `+sessionColumns, id))
+	return session, notFound(err)
+}
+
+func sessionArgs(session Session) []any {
+	return []any{
+		session.Id, session.UserId, session.Device, session.IP,
+		formatTime(session.CreatedAt), formatTime(session.LastSeenAt), formatTime(session.ExpiresAt),
+	}
+}
+
+func scanSession(row rowScanner) (Session, error) {
+	var (
+		session                          Session
+		createdAt, lastSeenAt, expiresAt string
+	)
+	if err := row.Scan(&session.Id, &session.UserId, &session.Device, &session.IP, &createdAt, &lastSeenAt, &expiresAt); err != nil {
+		return session, fmt.Errorf("scan session: %w", err)
+	}
+	var err error
+	if session.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return session, fmt.Errorf("parse created_at: %w", err)
+	}
+	if session.LastSeenAt, err = time.Parse(time.RFC3339Nano, lastSeenAt); err != nil {
+		return session, fmt.Errorf("parse last_seen_at: %w", err)
+	}
+	if session.ExpiresAt, err = time.Parse(time.RFC3339Nano, expiresAt); err != nil {
+		return session, fmt.Errorf("parse expires_at: %w", err)
+	}
+	return session, nil
+}
+
+// Refresh tokens
+const refreshTokenColumns = `token_hash, session_id, used, expires_at`
+
+func (q sqliteQueries) GetRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error) {
+	token, err := scanRefreshToken(q.db.QueryRowContext(ctx, `SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE token_hash = ?`, tokenHash))
+	return token, notFound(err)
+}
+
+func (q sqliteQueries) CreateRefreshToken(ctx context.Context, token RefreshToken) (RefreshToken, error) {
+	if err := q.insert(ctx, "refresh_tokens", refreshTokenColumns, refreshTokenArgs(token)); err != nil {
+		return RefreshToken{}, err
+	}
+	return token, nil
+}
+
+func (q sqliteQueries) UpdateRefreshToken(ctx context.Context, tokenHash string, token RefreshToken) (RefreshToken, error) {
+	token.TokenHash = tokenHash
+	if err := q.update(ctx, "refresh_tokens", refreshTokenColumns, refreshTokenArgs(token)); err != nil {
+		return RefreshToken{}, err
+	}
+	return token, nil
+}
+
+func refreshTokenArgs(token RefreshToken) []any {
+	return []any{token.TokenHash, token.SessionId, token.Used, formatTime(token.ExpiresAt)}
+}
+
+func scanRefreshToken(row rowScanner) (RefreshToken, error) {
+	var (
+		token     RefreshToken
+		expiresAt string
+	)
+	if err := row.Scan(&token.TokenHash, &token.SessionId, &token.Used, &expiresAt); err != nil {
+		return token, fmt.Errorf("scan refresh token: %w", err)
+	}
+	var err error
+	if token.ExpiresAt, err = time.Parse(time.RFC3339Nano, expiresAt); err != nil {
+		return token, fmt.Errorf("parse expires_at: %w", err)
+	}
+	return token, nil
+}
+
+// Password resets
+const passwordResetColumns = `token_hash, user_id, expires_at`
+
+func (q sqliteQueries) CreatePasswordReset(ctx context.Context, reset PasswordReset) (PasswordReset, error) {
+	if err := q.insert(ctx, "password_resets", passwordResetColumns, []any{reset.TokenHash, reset.UserId, formatTime(reset.ExpiresAt)}); err != nil {
+		return PasswordReset{}, err
+	}
+	return reset, nil
+}
+
+func (q sqliteQueries) DeletePasswordReset(ctx context.Context, tokenHash string) (PasswordReset, error) {
+	reset, err := scanPasswordReset(q.db.QueryRowContext(ctx, `DELETE FROM password_resets WHERE token_hash = ? RETURNING `+passwordResetColumns, tokenHash))
+	return reset, notFound(err)
+}
+
+func (q sqliteQueries) DeletePasswordResetsByUserId(ctx context.Context, userId string) error {
+	if _, err := q.db.ExecContext(ctx, `DELETE FROM password_resets WHERE user_id = ?`, userId); err != nil {
+		return fmt.Errorf("delete password resets: %w", err)
+	}
+	return nil
+}
+
+func scanPasswordReset(row rowScanner) (PasswordReset, error) {
+	var (
+		reset     PasswordReset
+		expiresAt string
+	)
+	if err := row.Scan(&reset.TokenHash, &reset.UserId, &expiresAt); err != nil {
+		return reset, fmt.Errorf("scan password reset: %w", err)
+	}
+	var err error
+	if reset.ExpiresAt, err = time.Parse(time.RFC3339Nano, expiresAt); err != nil {
+		return reset, fmt.Errorf("parse expires_at: %w", err)
+	}
+	return reset, nil
+}
+
+// Identities
+const identityColumns = `issuer, subject, user_id, created_at`
+
+func (q sqliteQueries) GetIdentity(ctx context.Context, issuer, subject string) (Identity, error) {
+	identity, err := scanIdentity(q.db.QueryRowContext(ctx, `SELECT `+identityColumns+` FROM identities WHERE issuer = ? AND subject = ?`, issuer, subject))
+	return identity, notFound(err)
+}
+
+func (q sqliteQueries) CreateIdentity(ctx context.Context, identity Identity) (Identity, error) {
+	args := []any{identity.Issuer, identity.Subject, identity.UserId, formatTime(identity.CreatedAt)}
+	if err := q.insert(ctx, "identities", identityColumns, args); err != nil {
+		return Identity{}, err
+	}
+	return identity, nil
+}
+
+func (q sqliteQueries) DeleteIdentitiesByUserId(ctx context.Context, userId string) error {
+	if _, err := q.db.ExecContext(ctx, `DELETE FROM identities WHERE user_id = ?`, userId); err != nil {
+		return fmt.Errorf("delete identities: %w", err)
+	}
+	return nil
+}
+
+func scanIdentity(row rowScanner) (Identity, error) {
+	var (
+		identity  Identity
+		createdAt string
+	)
+	if err := row.Scan(&identity.Issuer, &identity.Subject, &identity.UserId, &createdAt); err != nil {
+		return identity, fmt.Errorf("scan identity: %w", err)
+	}
+	var err error
+	if identity.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return identity, fmt.Errorf("parse created_at: %w", err)
+	}
+	return identity, nil
+}
+
+func (q sqliteQueries) DeleteExpiredTokens(ctx context.Context, now time.Time) error {
+	for _, table := range []string{"sessions", "refresh_tokens", "password_resets"} {
+		if _, err := q.db.ExecContext(ctx, `DELETE FROM `+table+` WHERE expires_at < ?`, formatTime(now)); err != nil {
+			return fmt.Errorf("delete expired %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// queryPage runs the statements of listing, scanning the rows of the page
+// with scan. They run in one read-only transaction, unless db is one already,
+// so that the total, the rows and the offset agree.
+func queryPage[T any](ctx context.Context, db sqlDB, listing *sqlListing, columns string, scan func(rowScanner) (T, error)) (Result[T], error) {
+	conn, ok := db.(*sql.DB)
+	if !ok {
+		return readPage(ctx, db, listing, columns, scan)
+	}
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return Result[T]{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	return readPage(ctx, tx, listing, columns, scan)
+}
+
+// readPage runs the statements of listing against db
+func readPage[T any](ctx context.Context, db sqlDB, listing *sqlListing, columns string, scan func(rowScanner) (T, error)) (Result[T], error) {
+	var result Result[T]
+	total := listing.total()
+	if err := db.QueryRowContext(ctx, total.query, total.args...).Scan(&result.Total); err != nil {
+		return result, fmt.Errorf("count %s: %w", listing.table, err)
+	}
+
+	statement, reversed := listing.rows(columns)
+	rows, err := db.QueryContext(ctx, statement.query, statement.args...)
+	if err != nil {
+		return result, fmt.Errorf("query %s: %w", listing.table, err)
+	}
+	defer rows.Close()
+	result.Items = make([]T, 0)
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return result, err
+		}
+		result.Items = append(result.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+	if reversed {
+		slices.Reverse(result.Items)
+	}
+
+	var before int
+	if statement, ok := listing.before(); ok {
+		if err := db.QueryRowContext(ctx, statement.query, statement.args...).Scan(&before); err != nil {
+			return result, fmt.Errorf("count %s: %w", listing.table, err)
+		}
+	}
+	result.Offset = listing.offset(before, len(result.Items))
+	return result, nil
+}
+
+// timeFormat is RFC 3339 with a fixed number of fractional digits, so that
+// timestamps in UTC sort as text
+const timeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+// formatTime encodes a timestamp as UTC RFC 3339 text
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// parseTimestamps decodes the created/updated text columns
+func parseTimestamps(createdAt, updatedAt string, created, updated *time.Time) error {
+	var err error
+	if *created, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return fmt.Errorf("parse created_at: %w", err)
+	}
+	if *updated, err = time.Parse(time.RFC3339Nano, updatedAt); err != nil {
+		return fmt.Errorf("parse updated_at: %w", err)
+	}
+	return nil
+}
+
+// Ensure SQLiteStore implements Store and Migrator
+var (
+	_ Store    = (*SQLiteStore)(nil)
+	_ Migrator = (*SQLiteStore)(nil)
+)
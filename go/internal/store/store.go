@@ -1,40 +1,252 @@
 package store
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/blck-snwmn/hello-typespec/go/generated"
 )
 
+var (
+	// ErrNotFound is returned when the requested record does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a record with the same ID (or, for
+	// credentials, the same email) already exists
+	ErrConflict = errors.New("conflict")
+	// ErrClaimed is returned by Claim when another process has claimed the
+	// database
+	ErrClaimed = errors.New("database claimed by another process")
+)
+
+// Credential is how the user with UserId logs in. Email mirrors the user's
+// email; PasswordHash is an encoded password hash, never the password itself,
+// and is empty until a password is set. Roles lists what the user may do
+// beyond acting on their own resources, e.g. "admin".
+type Credential struct {
+	UserId       string
+	Email        string
+	PasswordHash string
+	Roles        []string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// APIKey lets a machine client act as the user with UserId, limited to
+// Scopes. KeyHash is a SHA-256 hash of the key, which is never stored;
+// Prefix is the start of the key, shown to tell keys apart.
+type APIKey struct {
+	Id         string
+	UserId     string
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
+
+// Session is a login of the user with UserId from the client described by
+// Device (its User-Agent) and IP. Its access tokens are accepted until it is
+// deleted, and it can be refreshed until ExpiresAt.
+type Session struct {
+	Id         string
+	UserId     string
+	Device     string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time
+}
+
+// RefreshToken is a refresh token of the session with SessionId. TokenHash is
+// a SHA-256 hash of the token, which is never stored. Used tokens are kept
+// until they expire so that replaying one can be detected.
+type RefreshToken struct {
+	TokenHash string
+	SessionId string
+	Used      bool
+	ExpiresAt time.Time
+}
+
+// PasswordReset is an outstanding password reset token of the user with
+// UserId. TokenHash is a SHA-256 hash of the token, which is never stored.
+type PasswordReset struct {
+	TokenHash string
+	UserId    string
+	ExpiresAt time.Time
+}
+
+// Identity links the account Subject at the OpenID Connect provider Issuer
+// to the user with UserId, who logs in with it
+type Identity struct {
+	Issuer    string
+	Subject   string
+	UserId    string
+	CreatedAt time.Time
+}
+
+// Hold sets Quantity units of the product with ProductId aside for the cart
+// of the user with UserId until ExpiresAt. There is one hold per user and
+// product. Released holds are kept, expiring when they were released, so
+// that UpdatedAt and ExpiresAt tell when the held stock last changed.
+type Hold struct {
+	UserId    string
+	ProductId string
+	Quantity  int32
+	ExpiresAt time.Time
+	UpdatedAt time.Time
+}
+
 // Store defines the interface for data storage operations
 type Store interface {
+	Tx
+
+	// WithTx runs fn in a transaction: its writes are committed together when
+	// fn returns nil and discarded when it returns an error or panics.
+	// Single-row reads inside fn lock the row until the transaction ends, so
+	// read-check-write sequences such as stock updates cannot interleave.
+	// fn must only use tx, never the Store itself.
+	WithTx(ctx context.Context, fn func(tx Tx) error) error
+}
+
+// The tables ModifiedAt reports on
+const (
+	TableProducts   = "products"
+	TableCategories = "categories"
+)
+
+// checkModifiedTable reports a table ModifiedAt does not report on. The
+// table names are written into queries, so only these may be.
+func checkModifiedTable(table string) error {
+	if table != TableProducts && table != TableCategories {
+		return fmt.Errorf("no modification time for table %q", table)
+	}
+	return nil
+}
+
+// latest returns the later of a and b
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// Claimer is implemented by stores whose database other processes can open.
+// Claim makes this process the only server of the database until the store
+// is closed, for callers that keep what they read in memory and so have to
+// see every write. It returns ErrClaimed if another process holds the claim.
+type Claimer interface {
+	Claim(ctx context.Context) error
+}
+
+// Tx defines the data operations available on a Store and inside WithTx.
+//
+// Lookups, updates and deletes of missing records return ErrNotFound and
+// creating a record whose ID is taken returns ErrConflict. Any other error
+// is a backend failure, including cancellation of ctx.
+type Tx interface {
 	// Products
-	GetProducts() []generated.Product
-	GetProduct(id string) (*generated.Product, bool)
-	CreateProduct(product generated.Product) generated.Product
-	UpdateProduct(id string, product generated.Product) generated.Product
-	DeleteProduct(id string) (*generated.Product, bool)
+	GetProducts(ctx context.Context) ([]generated.Product, error)
+	// QueryProducts returns the page of products that query selects
+	QueryProducts(ctx context.Context, query ProductQuery) (Result[generated.Product], error)
+	// CountProducts counts the products query matches, ignoring its sort and
+	// page, by category, by the price ranges priceBounds splits prices into
+	// and by stock
+	CountProducts(ctx context.Context, query ProductQuery, priceBounds []float32) (ProductCounts, error)
+	GetProduct(ctx context.Context, id string) (generated.Product, error)
+	CreateProduct(ctx context.Context, product generated.Product) (generated.Product, error)
+	UpdateProduct(ctx context.Context, id string, product generated.Product) (generated.Product, error)
+	DeleteProduct(ctx context.Context, id string) (generated.Product, error)
+
+	// ModifiedAt returns when a row of table, TableProducts or
+	// TableCategories, was last written: the newest updatedAt or the last
+	// deletion, whichever is later. It is zero for a table never written.
+	ModifiedAt(ctx context.Context, table string) (time.Time, error)
 
 	// Categories
-	GetCategories() []generated.Category
-	GetCategory(id string) (*generated.Category, bool)
-	CreateCategory(category generated.Category) generated.Category
-	UpdateCategory(id string, category generated.Category) generated.Category
-	DeleteCategory(id string) (*generated.Category, bool)
+	GetCategories(ctx context.Context) ([]generated.Category, error)
+	GetCategory(ctx context.Context, id string) (generated.Category, error)
+	CreateCategory(ctx context.Context, category generated.Category) (generated.Category, error)
+	UpdateCategory(ctx context.Context, id string, category generated.Category) (generated.Category, error)
+	DeleteCategory(ctx context.Context, id string) (generated.Category, error)
 
 	// Users
-	GetUsers() []generated.User
-	GetUser(id string) (*generated.User, bool)
-	CreateUser(user generated.User) generated.User
-	UpdateUser(id string, user generated.User) generated.User
-	DeleteUser(id string) (*generated.User, bool)
+	GetUsers(ctx context.Context) ([]generated.User, error)
+	// QueryUsers returns the page of users that query selects
+	QueryUsers(ctx context.Context, query UserQuery) (Result[generated.User], error)
+	GetUser(ctx context.Context, id string) (generated.User, error)
+	CreateUser(ctx context.Context, user generated.User) (generated.User, error)
+	UpdateUser(ctx context.Context, id string, user generated.User) (generated.User, error)
+	DeleteUser(ctx context.Context, id string) (generated.User, error)
 
 	// Carts
-	GetCartByUserId(userId string) generated.Cart
-	UpdateCart(userId string, cart generated.Cart) generated.Cart
+	// GetCartByUserId returns an empty cart if the user has none yet
+	GetCartByUserId(ctx context.Context, userId string) (generated.Cart, error)
+	UpdateCart(ctx context.Context, userId string, cart generated.Cart) (generated.Cart, error)
+
+	// Holds
+	// The Get methods return holds whether they have expired or not, ordered
+	// by product and user
+	GetHolds(ctx context.Context) ([]Hold, error)
+	GetHoldsByProductId(ctx context.Context, productId string) ([]Hold, error)
+	GetHoldsByUserId(ctx context.Context, userId string) ([]Hold, error)
+	// SetHold creates or replaces the hold of hold.UserId on hold.ProductId
+	SetHold(ctx context.Context, hold Hold) (Hold, error)
 
 	// Orders
-	GetOrders() []generated.Order
-	GetOrder(id string) (*generated.Order, bool)
-	GetOrdersByUserId(userId string) []generated.Order
-	CreateOrder(order generated.Order) generated.Order
-	UpdateOrder(id string, order generated.Order) generated.Order
+	GetOrders(ctx context.Context) ([]generated.Order, error)
+	GetOrder(ctx context.Context, id string) (generated.Order, error)
+	GetOrdersByUserId(ctx context.Context, userId string) ([]generated.Order, error)
+	// QueryOrders returns the page of orders that query selects
+	QueryOrders(ctx context.Context, query OrderQuery) (Result[generated.Order], error)
+	CreateOrder(ctx context.Context, order generated.Order) (generated.Order, error)
+	UpdateOrder(ctx context.Context, id string, order generated.Order) (generated.Order, error)
+
+	// Credentials
+	// Emails are unique and matched exactly, so callers normalize them first
+	GetCredential(ctx context.Context, userId string) (Credential, error)
+	GetCredentialByEmail(ctx context.Context, email string) (Credential, error)
+	CreateCredential(ctx context.Context, credential Credential) (Credential, error)
+	UpdateCredential(ctx context.Context, userId string, credential Credential) (Credential, error)
+	DeleteCredential(ctx context.Context, userId string) (Credential, error)
+
+	// API keys
+	// GetAPIKeys returns the keys of a user, oldest first
+	GetAPIKeys(ctx context.Context, userId string) ([]APIKey, error)
+	GetAPIKey(ctx context.Context, id string) (APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error)
+	CreateAPIKey(ctx context.Context, key APIKey) (APIKey, error)
+	UpdateAPIKey(ctx context.Context, id string, key APIKey) (APIKey, error)
+	DeleteAPIKey(ctx context.Context, id string) (APIKey, error)
+
+	// Sessions
+	// GetSessionsByUserId returns the sessions of a user, expired or not
+	GetSessionsByUserId(ctx context.Context, userId string) ([]Session, error)
+	GetSession(ctx context.Context, id string) (Session, error)
+	CreateSession(ctx context.Context, session Session) (Session, error)
+	UpdateSession(ctx context.Context, id string, session Session) (Session, error)
+	// DeleteSession also deletes the refresh tokens of the session
+	DeleteSession(ctx context.Context, id string) (Session, error)
+
+	// Refresh tokens
+	GetRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error)
+	CreateRefreshToken(ctx context.Context, token RefreshToken) (RefreshToken, error)
+	UpdateRefreshToken(ctx context.Context, tokenHash string, token RefreshToken) (RefreshToken, error)
+
+	// Password resets
+	CreatePasswordReset(ctx context.Context, reset PasswordReset) (PasswordReset, error)
+	DeletePasswordReset(ctx context.Context, tokenHash string) (PasswordReset, error)
+	DeletePasswordResetsByUserId(ctx context.Context, userId string) error
+
+	// Identities
+	GetIdentity(ctx context.Context, issuer, subject string) (Identity, error)
+	CreateIdentity(ctx context.Context, identity Identity) (Identity, error)
+	DeleteIdentitiesByUserId(ctx context.Context, userId string) error
+
+	// DeleteExpiredTokens deletes the sessions, refresh tokens and password
+	// resets that expired before now
+	DeleteExpiredTokens(ctx context.Context, now time.Time) error
 }
@@ -0,0 +1,623 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+	"github.com/blck-snwmn/hello-typespec/go/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStoreErrors checks that a seeded store reports the sentinel errors of the Store contract
+func testStoreErrors(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	require.NoError(t, store.SeedDemoData(ctx, s))
+	now := time.Now()
+
+	t.Run("missing records return ErrNotFound", func(t *testing.T) {
+		_, err := s.GetProduct(ctx, "missing")
+		assert.ErrorIs(t, err, store.ErrNotFound)
+		_, err = s.UpdateCategory(ctx, "missing", generated.Category{Name: "Missing", CreatedAt: now, UpdatedAt: now})
+		assert.ErrorIs(t, err, store.ErrNotFound)
+		_, err = s.DeleteUser(ctx, "missing")
+		assert.ErrorIs(t, err, store.ErrNotFound)
+		_, err = s.GetOrder(ctx, "missing")
+		assert.ErrorIs(t, err, store.ErrNotFound)
+	})
+
+	t.Run("duplicate ids return ErrConflict", func(t *testing.T) {
+		product, err := s.GetProduct(ctx, "1")
+		require.NoError(t, err)
+		_, err = s.CreateProduct(ctx, product)
+		assert.ErrorIs(t, err, store.ErrConflict)
+	})
+
+	t.Run("delete returns the removed record", func(t *testing.T) {
+		created, err := s.CreateCategory(ctx, generated.Category{Id: "tmp", Name: "Temporary", CreatedAt: now, UpdatedAt: now})
+		require.NoError(t, err)
+
+		deleted, err := s.DeleteCategory(ctx, created.Id)
+		require.NoError(t, err)
+		assert.Equal(t, "Temporary", deleted.Name)
+
+		_, err = s.GetCategory(ctx, created.Id)
+		assert.ErrorIs(t, err, store.ErrNotFound)
+	})
+
+	t.Run("credentials are unique by user id and email", func(t *testing.T) {
+		credential, err := s.GetCredentialByEmail(ctx, "alice@example.com")
+		require.NoError(t, err)
+		assert.NotEmpty(t, credential.PasswordHash)
+
+		_, err = s.CreateCredential(ctx, store.Credential{
+			UserId: "new-user", Email: credential.Email, PasswordHash: "hash", CreatedAt: now, UpdatedAt: now,
+		})
+		assert.ErrorIs(t, err, store.ErrConflict)
+
+		bob, err := s.GetCredentialByEmail(ctx, "bob@example.com")
+		require.NoError(t, err)
+		bob.Email = credential.Email
+		_, err = s.UpdateCredential(ctx, bob.UserId, bob)
+		assert.ErrorIs(t, err, store.ErrConflict)
+
+		_, err = s.GetCredential(ctx, "missing")
+		assert.ErrorIs(t, err, store.ErrNotFound)
+	})
+
+	t.Run("api keys are unique by hash and listed per user", func(t *testing.T) {
+		key := store.APIKey{
+			Id: "key-1", UserId: "user-1", Name: "ERP", Prefix: "hts_ABCD", KeyHash: "hash-1",
+			Scopes: []string{"orders:read"}, CreatedAt: now.Truncate(time.Millisecond),
+		}
+		_, err := s.CreateAPIKey(ctx, key)
+		require.NoError(t, err)
+		_, err = s.CreateAPIKey(ctx, store.APIKey{Id: "key-2", UserId: "user-2", KeyHash: key.KeyHash, CreatedAt: now})
+		assert.ErrorIs(t, err, store.ErrConflict)
+
+		found, err := s.GetAPIKeyByHash(ctx, key.KeyHash)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"orders:read"}, found.Scopes)
+		assert.Nil(t, found.LastUsedAt)
+
+		usedAt := now.Add(time.Minute).Truncate(time.Millisecond)
+		found.LastUsedAt = &usedAt
+		_, err = s.UpdateAPIKey(ctx, found.Id, found)
+		require.NoError(t, err)
+		keys, err := s.GetAPIKeys(ctx, "user-1")
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		require.NotNil(t, keys[0].LastUsedAt)
+		assert.True(t, usedAt.Equal(*keys[0].LastUsedAt))
+
+		_, err = s.DeleteAPIKey(ctx, key.Id)
+		require.NoError(t, err)
+		_, err = s.GetAPIKey(ctx, key.Id)
+		assert.ErrorIs(t, err, store.ErrNotFound)
+		keys, err = s.GetAPIKeys(ctx, "user-1")
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("sessions own their refresh tokens and expire", func(t *testing.T) {
+		at := now.Truncate(time.Millisecond)
+		session := store.Session{
+			Id: "session-1", UserId: "user-1", Device: "Browser", IP: "203.0.113.1",
+			CreatedAt: at, LastSeenAt: at, ExpiresAt: at.Add(time.Hour),
+		}
+		_, err := s.CreateSession(ctx, session)
+		require.NoError(t, err)
+		_, err = s.CreateSession(ctx, session)
+		assert.ErrorIs(t, err, store.ErrConflict)
+		_, err = s.CreateRefreshToken(ctx, store.RefreshToken{TokenHash: "token-1", SessionId: session.Id, ExpiresAt: at.Add(time.Hour)})
+		require.NoError(t, err)
+
+		token, err := s.GetRefreshToken(ctx, "token-1")
+		require.NoError(t, err)
+		assert.False(t, token.Used)
+		token.Used = true
+		_, err = s.UpdateRefreshToken(ctx, token.TokenHash, token)
+		require.NoError(t, err)
+		token, err = s.GetRefreshToken(ctx, "token-1")
+		require.NoError(t, err)
+		assert.True(t, token.Used)
+
+		session.LastSeenAt = at.Add(time.Minute)
+		_, err = s.UpdateSession(ctx, session.Id, session)
+		require.NoError(t, err)
+		sessions, err := s.GetSessionsByUserId(ctx, "user-1")
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, "Browser", sessions[0].Device)
+		assert.True(t, session.LastSeenAt.Equal(sessions[0].LastSeenAt))
+
+		_, err = s.DeleteSession(ctx, session.Id)
+		require.NoError(t, err)
+		_, err = s.GetRefreshToken(ctx, "token-1")
+		assert.ErrorIs(t, err, store.ErrNotFound)
+		_, err = s.UpdateSession(ctx, session.Id, session)
+		assert.ErrorIs(t, err, store.ErrNotFound)
+
+		_, err = s.CreateSession(ctx, store.Session{Id: "session-2", UserId: "user-1", CreatedAt: at, LastSeenAt: at, ExpiresAt: at.Add(-time.Second)})
+		require.NoError(t, err)
+		_, err = s.CreatePasswordReset(ctx, store.PasswordReset{TokenHash: "reset-1", UserId: "user-1", ExpiresAt: at.Add(-time.Second)})
+		require.NoError(t, err)
+		require.NoError(t, s.DeleteExpiredTokens(ctx, at))
+		_, err = s.GetSession(ctx, "session-2")
+		assert.ErrorIs(t, err, store.ErrNotFound)
+		_, err = s.DeletePasswordReset(ctx, "reset-1")
+		assert.ErrorIs(t, err, store.ErrNotFound)
+	})
+
+	t.Run("password resets are taken once", func(t *testing.T) {
+		reset := store.PasswordReset{TokenHash: "reset-2", UserId: "user-2", ExpiresAt: now.Add(time.Hour).Truncate(time.Millisecond)}
+		_, err := s.CreatePasswordReset(ctx, reset)
+		require.NoError(t, err)
+		_, err = s.CreatePasswordReset(ctx, reset)
+		assert.ErrorIs(t, err, store.ErrConflict)
+
+		taken, err := s.DeletePasswordReset(ctx, reset.TokenHash)
+		require.NoError(t, err)
+		assert.Equal(t, "user-2", taken.UserId)
+		assert.True(t, reset.ExpiresAt.Equal(taken.ExpiresAt))
+		_, err = s.DeletePasswordReset(ctx, reset.TokenHash)
+		assert.ErrorIs(t, err, store.ErrNotFound)
+
+		_, err = s.CreatePasswordReset(ctx, reset)
+		require.NoError(t, err)
+		require.NoError(t, s.DeletePasswordResetsByUserId(ctx, "user-2"))
+		_, err = s.DeletePasswordReset(ctx, reset.TokenHash)
+		assert.ErrorIs(t, err, store.ErrNotFound)
+	})
+
+	t.Run("identities are unique by issuer and subject", func(t *testing.T) {
+		identity := store.Identity{Issuer: "https://idp.example.com", Subject: "sub-1", UserId: "user-3", CreatedAt: now.Truncate(time.Millisecond)}
+		_, err := s.CreateIdentity(ctx, identity)
+		require.NoError(t, err)
+		_, err = s.CreateIdentity(ctx, store.Identity{Issuer: identity.Issuer, Subject: identity.Subject, UserId: "user-4", CreatedAt: now})
+		assert.ErrorIs(t, err, store.ErrConflict)
+		_, err = s.CreateIdentity(ctx, store.Identity{Issuer: "https://other.example.com", Subject: identity.Subject, UserId: "user-4", CreatedAt: now})
+		require.NoError(t, err)
+
+		found, err := s.GetIdentity(ctx, identity.Issuer, identity.Subject)
+		require.NoError(t, err)
+		assert.Equal(t, "user-3", found.UserId)
+		assert.True(t, identity.CreatedAt.Equal(found.CreatedAt))
+		_, err = s.GetIdentity(ctx, identity.Issuer, "sub-2")
+		assert.ErrorIs(t, err, store.ErrNotFound)
+
+		require.NoError(t, s.DeleteIdentitiesByUserId(ctx, "user-3"))
+		_, err = s.GetIdentity(ctx, identity.Issuer, identity.Subject)
+		assert.ErrorIs(t, err, store.ErrNotFound)
+		_, err = s.GetIdentity(ctx, "https://other.example.com", identity.Subject)
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing cart is returned empty", func(t *testing.T) {
+		cart, err := s.GetCartByUserId(ctx, "no-cart")
+		require.NoError(t, err)
+		assert.Equal(t, "no-cart", cart.UserId)
+		assert.Empty(t, cart.Items)
+	})
+
+	t.Run("holds are set per user and product", func(t *testing.T) {
+		holds, err := s.GetHolds(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, holds)
+
+		// Stores may keep no more than milliseconds
+		at := now.Truncate(time.Millisecond)
+		for _, hold := range []store.Hold{
+			{UserId: "u2", ProductId: "1", Quantity: 1, ExpiresAt: at.Add(time.Minute), UpdatedAt: at},
+			{UserId: "u1", ProductId: "2", Quantity: 2, ExpiresAt: at.Add(time.Minute), UpdatedAt: at},
+			{UserId: "u1", ProductId: "1", Quantity: 3, ExpiresAt: at.Add(time.Minute), UpdatedAt: at},
+		} {
+			_, err := s.SetHold(ctx, hold)
+			require.NoError(t, err)
+		}
+		// Setting a hold again replaces it
+		_, err = s.SetHold(ctx, store.Hold{UserId: "u1", ProductId: "1", Quantity: 4, ExpiresAt: at, UpdatedAt: at.Add(time.Second)})
+		require.NoError(t, err)
+
+		holds, err = s.GetHolds(ctx)
+		require.NoError(t, err)
+		require.Len(t, holds, 3)
+		assert.Equal(t, "1", holds[0].ProductId)
+		assert.Equal(t, "u1", holds[0].UserId)
+		assert.Equal(t, int32(4), holds[0].Quantity)
+		assert.True(t, at.Equal(holds[0].ExpiresAt), "an expired hold is kept")
+		assert.True(t, at.Add(time.Second).Equal(holds[0].UpdatedAt))
+
+		byProduct, err := s.GetHoldsByProductId(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, holds[:2], byProduct)
+		byUser, err := s.GetHoldsByUserId(ctx, "u1")
+		require.NoError(t, err)
+		assert.Equal(t, []store.Hold{holds[0], holds[2]}, byUser)
+	})
+}
+
+// testQueries checks that a seeded store filters, orders and pages queries,
+// ordering by the sorted field and then by ID
+func testQueries(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	require.NoError(t, store.SeedDemoData(ctx, s))
+	// Earlier than the seeds; whole and fractional seconds tell apart stores
+	// that sort timestamps as text of varying width
+	base := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	productIDs := func(products []generated.Product) []string {
+		ids := []string{}
+		for _, product := range products {
+			ids = append(ids, product.Id)
+		}
+		return ids
+	}
+
+	t.Run("products", func(t *testing.T) {
+		for _, product := range []generated.Product{
+			{Id: "sort-b", Name: "Sorted", Price: 5, CreatedAt: base.Add(1500 * time.Millisecond)},
+			{Id: "sort-a", Name: "Sorted", Price: 5, CreatedAt: base.Add(1500 * time.Millisecond)},
+			{Id: "sort-c", Name: "sorted", Price: 1, CreatedAt: base.Add(time.Second)},
+		} {
+			product.CategoryId = "sort"
+			product.UpdatedAt = product.CreatedAt
+			_, err := s.CreateProduct(ctx, product)
+			require.NoError(t, err)
+		}
+		all := store.Page{Limit: store.NoLimit}
+
+		for _, tc := range []struct {
+			sort store.Sort
+			want []string
+		}{
+			{store.Sort{Field: store.SortByName}, []string{"sort-a", "sort-b", "sort-c"}},
+			{store.Sort{Field: store.SortByName, Desc: true}, []string{"sort-c", "sort-b", "sort-a"}},
+			{store.Sort{Field: store.SortByPrice}, []string{"sort-c", "sort-a", "sort-b"}},
+			{store.Sort{Field: store.SortByPrice, Desc: true}, []string{"sort-b", "sort-a", "sort-c"}},
+			{store.Sort{Field: store.SortByCreatedAt}, []string{"sort-c", "sort-a", "sort-b"}},
+		} {
+			result, err := s.QueryProducts(ctx, store.ProductQuery{CategoryIDs: []string{"sort"}, Sort: tc.sort, Page: all})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, productIDs(result.Items), "sorted by %+v", tc.sort)
+			assert.Equal(t, 3, result.Total)
+		}
+
+		minPrice, maxPrice := float32(2), float32(1000)
+		result, err := s.QueryProducts(ctx, store.ProductQuery{
+			IDs:      []string{"1", "2", "3", "sort-a", "sort-c"},
+			MinPrice: &minPrice,
+			MaxPrice: &maxPrice,
+			Sort:     store.Sort{Field: store.SortByPrice},
+			Page:     all,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sort-a", "3", "2"}, productIDs(result.Items), "the seeded T-shirt and iPhone")
+
+		result, err = s.QueryProducts(ctx, store.ProductQuery{IDs: []string{}, Sort: store.Sort{Field: store.SortByName}, Page: all})
+		require.NoError(t, err)
+		assert.Empty(t, result.Items)
+		assert.Equal(t, 0, result.Total)
+	})
+
+	t.Run("product pages", func(t *testing.T) {
+		query := func(page store.Page) store.Result[generated.Product] {
+			t.Helper()
+			result, err := s.QueryProducts(ctx, store.ProductQuery{
+				CategoryIDs: []string{"sort"},
+				Sort:        store.Sort{Field: store.SortByPrice, Desc: true},
+				Page:        page,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, 3, result.Total)
+			return result
+		}
+
+		result := query(store.Page{Limit: 2, Offset: 1})
+		assert.Equal(t, []string{"sort-a", "sort-c"}, productIDs(result.Items))
+		assert.Equal(t, 1, result.Offset)
+
+		result = query(store.Page{Limit: 2, After: &store.Position{Key: float32(5), ID: "sort-a"}})
+		assert.Equal(t, []string{"sort-c"}, productIDs(result.Items))
+		assert.Equal(t, 2, result.Offset)
+
+		result = query(store.Page{Limit: 1, Before: &store.Position{Key: float32(1), ID: "sort-c"}})
+		assert.Equal(t, []string{"sort-a"}, productIDs(result.Items))
+		assert.Equal(t, 1, result.Offset)
+
+		result = query(store.Page{Limit: 5, Before: &store.Position{Key: float32(5), ID: "sort-a"}})
+		assert.Equal(t, []string{"sort-b"}, productIDs(result.Items))
+		assert.Equal(t, 0, result.Offset)
+
+		_, err := s.QueryProducts(ctx, store.ProductQuery{
+			Sort: store.Sort{Field: store.SortByPrice},
+			Page: store.Page{Limit: 1, After: &store.Position{Key: "5", ID: "sort-a"}},
+		})
+		assert.Error(t, err, "positions must fit the sort")
+	})
+
+	t.Run("product counts", func(t *testing.T) {
+		_, err := s.CreateProduct(ctx, generated.Product{Id: "count-a", Name: "Counted", Price: 3, Stock: 2, CategoryId: "count", CreatedAt: base, UpdatedAt: base})
+		require.NoError(t, err)
+
+		counts, err := s.CountProducts(ctx, store.ProductQuery{CategoryIDs: []string{"sort", "count"}}, []float32{2, 5})
+		require.NoError(t, err)
+		assert.Equal(t, store.ProductCounts{
+			Total:       4,
+			Categories:  map[string]int{"sort": 3, "count": 1},
+			PriceRanges: []int{1, 1, 2},
+			InStock:     1,
+		}, counts)
+
+		maxPrice := float32(4)
+		counts, err = s.CountProducts(ctx, store.ProductQuery{IDs: []string{"sort-a", "sort-c", "count-a"}, MaxPrice: &maxPrice}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, store.ProductCounts{
+			Total:       2,
+			Categories:  map[string]int{"sort": 1, "count": 1},
+			PriceRanges: []int{2},
+			InStock:     1,
+		}, counts)
+	})
+
+	t.Run("modification times", func(t *testing.T) {
+		seeded, err := s.ModifiedAt(ctx, store.TableCategories)
+		require.NoError(t, err)
+		assert.False(t, seeded.IsZero())
+
+		// An update moves it to the new updatedAt
+		later := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+		product, err := s.GetProduct(ctx, "sort-a")
+		require.NoError(t, err)
+		product.UpdatedAt = later
+		_, err = s.UpdateProduct(ctx, product.Id, product)
+		require.NoError(t, err)
+		modifiedAt, err := s.ModifiedAt(ctx, store.TableProducts)
+		require.NoError(t, err)
+		assert.WithinDuration(t, later, modifiedAt, time.Millisecond)
+
+		// A deletion moves it to when the row was deleted, although every
+		// row left is older
+		_, err = s.CreateCategory(ctx, generated.Category{Id: "deleted", Name: "Deleted", CreatedAt: base, UpdatedAt: base})
+		require.NoError(t, err)
+		// Stores may keep no more than milliseconds
+		beforeDelete := time.Now().Add(-time.Millisecond)
+		_, err = s.DeleteCategory(ctx, "deleted")
+		require.NoError(t, err)
+		modifiedAt, err = s.ModifiedAt(ctx, store.TableCategories)
+		require.NoError(t, err)
+		assert.False(t, modifiedAt.Before(beforeDelete), "%v is before %v", modifiedAt, beforeDelete)
+		assert.True(t, modifiedAt.After(seeded))
+
+		_, err = s.ModifiedAt(ctx, "users")
+		assert.Error(t, err)
+	})
+
+	t.Run("users", func(t *testing.T) {
+		for _, id := range []string{"sort-b", "sort-a"} {
+			_, err := s.CreateUser(ctx, generated.User{Id: id, Email: id + "@example.com", Name: id, CreatedAt: base, UpdatedAt: base})
+			require.NoError(t, err)
+		}
+
+		result, err := s.QueryUsers(ctx, store.UserQuery{Sort: store.Sort{Field: store.SortByCreatedAt}, Page: store.Page{Limit: 2}})
+		require.NoError(t, err)
+		require.Len(t, result.Items, 2)
+		assert.Equal(t, "sort-a", result.Items[0].Id)
+		assert.Equal(t, "sort-b", result.Items[1].Id)
+		assert.Equal(t, 6, result.Total, "4 seeded users and 2 new ones")
+
+		_, err = s.QueryUsers(ctx, store.UserQuery{Sort: store.Sort{Field: store.SortByPrice}})
+		assert.Error(t, err)
+	})
+
+	t.Run("orders", func(t *testing.T) {
+		for _, order := range []generated.Order{
+			{Id: "sort-1", CreatedAt: base.Add(time.Second), Status: generated.Delivered},
+			{Id: "sort-3", CreatedAt: base.Add(1500 * time.Millisecond), Status: generated.Pending},
+			{Id: "sort-2", CreatedAt: base.Add(1500 * time.Millisecond), Status: generated.Pending},
+		} {
+			order.UserId = "sort-a"
+			order.Items = []generated.OrderItem{}
+			order.UpdatedAt = order.CreatedAt
+			_, err := s.CreateOrder(ctx, order)
+			require.NoError(t, err)
+		}
+		orderIDs := func(result store.Result[generated.Order]) []string {
+			ids := []string{}
+			for _, order := range result.Items {
+				ids = append(ids, order.Id)
+			}
+			return ids
+		}
+		newestFirst := store.Sort{Field: store.SortByCreatedAt, Desc: true}
+
+		result, err := s.QueryOrders(ctx, store.OrderQuery{UserId: "sort-a", Sort: newestFirst, Page: store.Page{Limit: 10}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sort-3", "sort-2", "sort-1"}, orderIDs(result))
+
+		pending := generated.Pending
+		from, to := base.Add(time.Second+time.Millisecond), base.Add(1500*time.Millisecond)
+		result, err = s.QueryOrders(ctx, store.OrderQuery{
+			Status:      &pending,
+			CreatedFrom: &from,
+			CreatedTo:   &to,
+			Sort:        store.Sort{Field: store.SortByCreatedAt},
+			Page:        store.Page{Limit: 10},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sort-2", "sort-3"}, orderIDs(result))
+		assert.Equal(t, 2, result.Total)
+	})
+}
+
+// testWithTx checks that a seeded store commits or discards transactional writes as a unit
+func testWithTx(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	require.NoError(t, store.SeedDemoData(ctx, s))
+	errAbort := errors.New("abort")
+
+	t.Run("commits every write when fn succeeds", func(t *testing.T) {
+		err := s.WithTx(ctx, func(tx store.Tx) error {
+			product, err := tx.GetProduct(ctx, "1")
+			if err != nil {
+				return err
+			}
+			product.Stock--
+			if _, err := tx.UpdateProduct(ctx, product.Id, product); err != nil {
+				return err
+			}
+			cart, err := tx.GetCartByUserId(ctx, "1")
+			if err != nil {
+				return err
+			}
+			cart.Items = []generated.CartItem{{ProductId: "1", Quantity: 1}}
+			_, err = tx.UpdateCart(ctx, "1", cart)
+			return err
+		})
+		require.NoError(t, err)
+
+		product, err := s.GetProduct(ctx, "1")
+		require.NoError(t, err)
+		assert.Equal(t, int32(9), product.Stock)
+		cart, err := s.GetCartByUserId(ctx, "1")
+		require.NoError(t, err)
+		assert.Len(t, cart.Items, 1)
+	})
+
+	t.Run("discards every write when fn fails", func(t *testing.T) {
+		err := s.WithTx(ctx, func(tx store.Tx) error {
+			product, err := tx.GetProduct(ctx, "2")
+			if err != nil {
+				return err
+			}
+			product.Stock = 0
+			if _, err := tx.UpdateProduct(ctx, product.Id, product); err != nil {
+				return err
+			}
+			cart, err := tx.GetCartByUserId(ctx, "1")
+			if err != nil {
+				return err
+			}
+			cart.Items[0].Quantity = 5
+			if _, err := tx.UpdateCart(ctx, "1", cart); err != nil {
+				return err
+			}
+			if _, err := tx.SetHold(ctx, store.Hold{UserId: "1", ProductId: "2", Quantity: 5, ExpiresAt: time.Now().Add(time.Minute)}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		assert.ErrorIs(t, err, errAbort)
+
+		product, err := s.GetProduct(ctx, "2")
+		require.NoError(t, err)
+		assert.Equal(t, int32(25), product.Stock)
+		cart, err := s.GetCartByUserId(ctx, "1")
+		require.NoError(t, err)
+		require.Len(t, cart.Items, 1)
+		assert.Equal(t, int32(1), cart.Items[0].Quantity)
+		holds, err := s.GetHolds(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, holds)
+	})
+}
+
+func TestSeedDemoData(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemoryStore()
+
+	users, err := s.GetUsers(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, users, "stores start empty")
+
+	require.NoError(t, store.SeedDemoData(ctx, s))
+	products, err := s.GetProducts(ctx)
+	require.NoError(t, err)
+	assert.Len(t, products, 3)
+	users, err = s.GetUsers(ctx)
+	require.NoError(t, err)
+	assert.Len(t, users, 4)
+	for _, email := range []string{"alice@example.com", "bob@example.com"} {
+		credential, err := s.GetCredentialByEmail(ctx, email)
+		require.NoError(t, err)
+		_, err = s.GetUser(ctx, credential.UserId)
+		assert.NoError(t, err, "credentials should belong to a user")
+		assert.Empty(t, credential.Roles, "demo users are never admins")
+	}
+
+	// Stores with users are left alone
+	_, err = s.DeleteProduct(ctx, "1")
+	require.NoError(t, err)
+	require.NoError(t, store.SeedDemoData(ctx, s))
+	products, err = s.GetProducts(ctx)
+	require.NoError(t, err)
+	assert.Len(t, products, 2)
+}
+
+func TestMemoryStore_Errors(t *testing.T) {
+	testStoreErrors(t, store.NewMemoryStore())
+}
+
+func TestMemoryStore_WithTx(t *testing.T) {
+	testWithTx(t, store.NewMemoryStore())
+}
+
+func TestMemoryStore_Queries(t *testing.T) {
+	testQueries(t, store.NewMemoryStore())
+}
+
+func TestSQLiteStore_Errors(t *testing.T) {
+	s, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	require.NoError(t, s.MigrateUp(context.Background()))
+
+	testStoreErrors(t, s)
+}
+
+func TestSQLiteStore_WithTx(t *testing.T) {
+	s, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	require.NoError(t, s.MigrateUp(context.Background()))
+
+	testWithTx(t, s)
+}
+
+func TestSQLiteStore_Queries(t *testing.T) {
+	s, err := store.NewSQLiteStore(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	require.NoError(t, s.MigrateUp(context.Background()))
+
+	testQueries(t, s)
+}
+
+func TestSQLiteStore_Claim(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "test.db")
+	open := func() *store.SQLiteStore {
+		s, err := store.NewSQLiteStore(path)
+		require.NoError(t, err)
+		t.Cleanup(func() { s.Close() })
+		return s
+	}
+
+	claimed := open()
+	require.NoError(t, claimed.MigrateUp(ctx))
+	require.NoError(t, claimed.Claim(ctx))
+	_, err := claimed.CreateProduct(ctx, generated.Product{Id: "claimed", Name: "Claimed"})
+	require.NoError(t, err, "the claiming store keeps working")
+
+	other := open()
+	assert.ErrorIs(t, other.Claim(ctx), store.ErrClaimed)
+
+	require.NoError(t, claimed.Close())
+	assert.NoError(t, other.Claim(ctx), "closing the store gives up its claim")
+	_, err = other.GetProduct(ctx, "claimed")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,102 @@
+// Package validation checks request bodies field by field. Every problem is
+// collected as a FieldError naming the field with a JSON pointer, the rule it
+// broke and a message, so that clients learn about all of them at once.
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/blck-snwmn/hello-typespec/go/generated"
+)
+
+// FieldError is a field of a request that failed validation
+type FieldError = generated.FieldError
+
+// Rules a field can break. Rules the OpenAPI document can express are named
+// after their JSON Schema keyword.
+const (
+	RuleRequired    = "required"
+	RuleType        = "type"
+	RuleFormat      = "format"
+	RuleEnum        = "enum"
+	RuleMinLength   = "minLength"
+	RuleMinimum     = "minimum"
+	RuleMinItems    = "minItems"
+	RuleUniqueItems = "uniqueItems"
+	// RulePassword is broken by passwords outside the password policy
+	RulePassword = "password"
+	// RuleReference is broken by IDs that refer to something they cannot
+	RuleReference = "reference"
+	// RuleInvalid is used for problems no other rule describes
+	RuleInvalid = "invalid"
+)
+
+// Errors is the list of fields of a request that failed validation. The zero
+// value is an empty list; as an error it aborts store transactions like
+// the other errors handlers return from them.
+type Errors []FieldError
+
+// Add records that field broke rule
+func (e *Errors) Add(field, rule, message string) {
+	*e = append(*e, FieldError{Field: field, Rule: rule, Message: message})
+}
+
+// Err returns e as an error, or nil if no field failed
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e Errors) Error() string {
+	problems := make([]string, len(e))
+	for i, fieldErr := range e {
+		problems[i] = fieldErr.Field + ": " + fieldErr.Message
+	}
+	return "invalid request: " + strings.Join(problems, "; ")
+}
+
+// NotBlank checks that value contains more than white space
+func (e *Errors) NotBlank(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.Add(field, RuleMinLength, "must not be empty")
+	}
+}
+
+// Email checks that value is a bare email address such as "alice@example.com"
+func (e *Errors) Email(field, value string) {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value {
+		e.Add(field, RuleFormat, "must be an email address")
+	}
+}
+
+// Minimum checks that value is at least min
+func (e *Errors) Minimum(field string, value, min float64) {
+	if value < min {
+		e.Add(field, RuleMinimum, fmt.Sprintf("must be at least %v", min))
+	}
+}
+
+// MinItems checks that a list of n items has at least min
+func (e *Errors) MinItems(field string, n, min int) {
+	if n < min {
+		e.Add(field, RuleMinItems, fmt.Sprintf("must have at least %d items", min))
+	}
+}
+
+// Pointer joins tokens into an RFC 6901 JSON pointer, such as
+// Pointer("items", "0", "quantity") for "/items/0/quantity"
+func Pointer(tokens ...string) string {
+	var pointer strings.Builder
+	for _, token := range tokens {
+		pointer.WriteByte('/')
+		pointer.WriteString(pointerEscaper.Replace(token))
+	}
+	return pointer.String()
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
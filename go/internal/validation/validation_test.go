@@ -0,0 +1,100 @@
+package validation_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/blck-snwmn/hello-typespec/go/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors(t *testing.T) {
+	t.Run("should be no error when empty", func(t *testing.T) {
+		var errs validation.Errors
+		assert.NoError(t, errs.Err())
+
+		errs.NotBlank("/name", "Alice")
+		errs.Email("/email", "alice@example.com")
+		errs.Minimum("/price", 0, 0)
+		errs.MinItems("/scopes", 1, 1)
+		assert.NoError(t, errs.Err())
+	})
+
+	t.Run("should collect every failed field in order", func(t *testing.T) {
+		var errs validation.Errors
+		errs.NotBlank("/name", " \t")
+		errs.Email("/email", "Alice <alice@example.com>")
+		errs.Minimum("/price", -1, 0)
+		errs.MinItems("/scopes", 0, 1)
+		errs.Add("/categoryId", validation.RuleReference, "category does not exist")
+
+		err := errs.Err()
+		require.Error(t, err)
+		assert.Equal(t, validation.Errors{
+			{Field: "/name", Rule: validation.RuleMinLength, Message: "must not be empty"},
+			{Field: "/email", Rule: validation.RuleFormat, Message: "must be an email address"},
+			{Field: "/price", Rule: validation.RuleMinimum, Message: "must be at least 0"},
+			{Field: "/scopes", Rule: validation.RuleMinItems, Message: "must have at least 1 items"},
+			{Field: "/categoryId", Rule: validation.RuleReference, Message: "category does not exist"},
+		}, err)
+		assert.Equal(t, "invalid request: /name: must not be empty; /email: must be an email address; "+
+			"/price: must be at least 0; /scopes: must have at least 1 items; /categoryId: category does not exist", err.Error())
+	})
+
+	t.Run("should keep repeated failures of a field", func(t *testing.T) {
+		var errs validation.Errors
+		errs.NotBlank("/password", "")
+		errs.Add("/password", validation.RulePassword, "must contain a digit")
+		assert.Len(t, errs, 2)
+	})
+
+	t.Run("should be found in wrapped errors", func(t *testing.T) {
+		var errs validation.Errors
+		errs.Add("cursor", validation.RuleInvalid, "cursor is invalid")
+		wrapped := fmt.Errorf("transaction: %w", errs.Err())
+
+		var found validation.Errors
+		require.True(t, errors.As(wrapped, &found))
+		assert.Equal(t, errs, found)
+	})
+}
+
+func TestErrors_Email(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		valid bool
+	}{
+		{"alice@example.com", true},
+		{"alice+shop@example.co.jp", true},
+		{"", false},
+		{"alice", false},
+		{"alice@", false},
+		{"Alice <alice@example.com>", false},
+		{" alice@example.com", false},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			var errs validation.Errors
+			errs.Email("/email", tc.value)
+			assert.Equal(t, tc.valid, errs.Err() == nil)
+		})
+	}
+}
+
+func TestPointer(t *testing.T) {
+	for _, tc := range []struct {
+		tokens []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"name"}, "/name"},
+		{[]string{"items", "0", "quantity"}, "/items/0/quantity"},
+		{[]string{"a/b", "m~n"}, "/a~1b/m~0n"},
+		{[]string{""}, "/"},
+	} {
+		t.Run(tc.want, func(t *testing.T) {
+			assert.Equal(t, tc.want, validation.Pointer(tc.tokens...))
+		})
+	}
+}
@@ -55,6 +55,211 @@ export interface paths {
         patch?: never;
         trace?: never;
     };
+    "/auth/register": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        /** @description Register a new user with email and password */
+        post: operations["AuthService_register"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/auth/change-password": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        /** @description Change the current user's password */
+        post: operations["AuthService_changePassword"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/auth/password-reset": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        /** @description Request a password reset token for an email address */
+        post: operations["AuthService_requestPasswordReset"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/auth/password-reset/confirm": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        /** @description Set a new password with a password reset token */
+        post: operations["AuthService_confirmPasswordReset"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/auth/refresh": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        /** @description Exchange a refresh token for new access and refresh tokens */
+        post: operations["AuthService_refresh"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/auth/sessions": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        /** @description List the current user's active sessions */
+        get: operations["AuthService_listSessions"];
+        put?: never;
+        post?: never;
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/auth/sessions/{sessionId}": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        post?: never;
+        /** @description End one of the current user's sessions */
+        delete: operations["AuthService_revokeSession"];
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/auth/api-keys": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        /** @description List the current user's API keys */
+        get: operations["AuthService_listApiKeys"];
+        put?: never;
+        /** @description Create an API key that acts as the current user within its scopes */
+        post: operations["AuthService_createApiKey"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/auth/api-keys/{keyId}": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        post?: never;
+        /** @description Revoke one of the current user's API keys */
+        delete: operations["AuthService_revokeApiKey"];
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/auth/oidc/login": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        /** @description Start an OpenID Connect login by redirecting to the identity provider */
+        get: operations["AuthService_oidcLogin"];
+        put?: never;
+        post?: never;
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/auth/oidc/link": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        get?: never;
+        put?: never;
+        /** @description Start linking an identity provider account to the current user */
+        post: operations["AuthService_oidcLink"];
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
+    "/auth/oidc/callback": {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        /** @description Complete an OpenID Connect login with the identity provider's authorization code */
+        get: operations["AuthService_oidcCallback"];
+        put?: never;
+        post?: never;
+        delete?: never;
+        options?: never;
+        head?: never;
+        patch?: never;
+        trace?: never;
+    };
     "/carts/users/{userId}": {
         parameters: {
             query?: never;
@@ -295,7 +500,7 @@ export interface paths {
         /** @description List all users (Admin only) */
         get: operations["UsersService_list"];
         put?: never;
-        /** @description Create a new user */
+        /** @description Create a new user (Admin only) */
         post: operations["UsersService_create"];
         delete?: never;
         options?: never;
@@ -349,6 +554,32 @@ export interface components {
             /** @description Country name */
             country: string;
         };
+        /** @description API key a machine client authenticates with instead of logging in */
+        ApiKey: {
+            /** @description API key ID */
+            id: string;
+            /** @description Name of the client the key is for */
+            name: string;
+            /** @description First characters of the key, to tell keys apart */
+            prefix: string;
+            /** @description What the key may be used for */
+            scopes: components["schemas"]["ApiKeyScope"][];
+            /**
+             * Format: date-time
+             * @description When the key was created
+             */
+            createdAt: string;
+            /**
+             * Format: date-time
+             * @description When the key was last used
+             */
+            lastUsedAt?: string;
+        };
+        /**
+         * @description Permission granted to an API key
+         * @enum {string}
+         */
+        ApiKeyScope: "products:write" | "categories:write" | "orders:read" | "orders:write" | "carts:read" | "carts:write" | "users:read" | "users:write";
         /** @description Authenticated user context */
         AuthUser: {
             /** @description User ID */
@@ -357,6 +588,8 @@ export interface components {
             email: string;
             /** @description User's full name */
             name: string;
+            /** @description Roles granted to the user; customers have none */
+            roles?: components["schemas"]["Role"][];
         };
         /** @description Shopping cart */
         Cart: {
@@ -410,6 +643,11 @@ export interface components {
             name: string;
             /** @description ID of the parent category for hierarchical structure */
             parentId?: components["schemas"]["uuid"];
+            /**
+             * Format: int32
+             * @description Version of the resource, incremented on every update
+             */
+            version?: number;
             /**
              * Format: date-time
              * @description Timestamp when the resource was created
@@ -421,11 +659,37 @@ export interface components {
              */
             updatedAt: string;
         };
+        /** @description Number of products in a category and its descendants */
+        CategoryFacet: {
+            /** @description ID of the category */
+            categoryId: components["schemas"]["uuid"];
+            /** @description Name of the category */
+            name: string;
+            /**
+             * Format: int32
+             * @description Number of products in the category or one of its descendants
+             */
+            count: number;
+        };
         /** @description Category with nested children */
         CategoryTree: {
             /** @description List of child categories */
             children: components["schemas"]["CategoryTree"][];
         } & components["schemas"]["Category"];
+        /** @description Password change request */
+        ChangePasswordRequest: {
+            /** @description Current password */
+            currentPassword: string;
+            /** @description New password (at least 8 characters) */
+            newPassword: string;
+        };
+        /** @description API key creation request */
+        CreateApiKeyRequest: {
+            /** @description Name of the client the key is for */
+            name: string;
+            /** @description What the key may be used for */
+            scopes: components["schemas"]["ApiKeyScope"][];
+        };
         /** @description Category creation request */
         CreateCategoryRequest: {
             /** @description Name of the category */
@@ -433,10 +697,20 @@ export interface components {
             /** @description Optional ID of the parent category */
             parentId?: components["schemas"]["uuid"];
         };
+        /** @description Item of a create order request. Price and name are taken from the product. */
+        CreateOrderItem: {
+            /** @description ID of the product to order */
+            productId: components["schemas"]["uuid"];
+            /**
+             * Format: int32
+             * @description Quantity to order
+             */
+            quantity: number;
+        };
         /** @description Create order request */
         CreateOrderRequest: {
             /** @description List of items to order */
-            items: components["schemas"]["OrderItem"][];
+            items: components["schemas"]["CreateOrderItem"][];
             /** @description Shipping address for the order */
             shippingAddress: components["schemas"]["Address"];
         };
@@ -463,18 +737,46 @@ export interface components {
         };
         /** @description User creation request */
         CreateUserRequest: {
-            /** @description User's email address */
+            /**
+             * Format: email
+             * @description User's email address
+             */
             email: string;
             /** @description User's full name */
             name: string;
             /** @description Optional shipping address */
             address?: components["schemas"]["Address"];
+            /** @description Optional initial password (at least 8 characters); without one the user sets it via password reset */
+            password?: string;
+        };
+        /** @description Newly created API key */
+        CreatedApiKey: {
+            /** @description API key ID */
+            id: string;
+            /** @description Name of the client the key is for */
+            name: string;
+            /** @description First characters of the key, to tell keys apart */
+            prefix: string;
+            /** @description What the key may be used for */
+            scopes: components["schemas"]["ApiKeyScope"][];
+            /**
+             * Format: date-time
+             * @description When the key was created
+             */
+            createdAt: string;
+            /**
+             * Format: date-time
+             * @description When the key was last used
+             */
+            lastUsedAt?: string;
+            /** @description The key itself. It is only returned on creation. */
+            key: string;
         };
         /**
          * @description Standard error codes used throughout the API
          * @enum {string}
          */
-        ErrorCode: "BAD_REQUEST" | "UNAUTHORIZED" | "FORBIDDEN" | "NOT_FOUND" | "CONFLICT" | "VALIDATION_ERROR" | "INSUFFICIENT_STOCK" | "INVALID_STATE_TRANSITION" | "INTERNAL_ERROR" | "SERVICE_UNAVAILABLE";
+        ErrorCode: "BAD_REQUEST" | "UNAUTHORIZED" | "FORBIDDEN" | "NOT_FOUND" | "CONFLICT" | "VALIDATION_ERROR" | "INSUFFICIENT_STOCK" | "INVALID_STATE_TRANSITION" | "TOO_MANY_REQUESTS" | "INTERNAL_ERROR" | "SERVICE_UNAVAILABLE";
         /** @description Common error response */
         ErrorResponse: {
             /** @description Error information */
@@ -483,10 +785,19 @@ export interface components {
                 code: components["schemas"]["ErrorCode"];
                 /** @description Human-readable error message */
                 message: string;
-                /** @description Additional error details */
-                details?: unknown;
+                /** @description Fields that failed validation, for VALIDATION_ERROR responses */
+                details?: components["schemas"]["FieldError"][];
             };
         };
+        /** @description Field of a request that failed validation */
+        FieldError: {
+            /** @description JSON pointer to the field in the request body, such as /items/0/quantity, or the name of a parameter */
+            field: string;
+            /** @description Rule the field broke, such as required, minLength or minimum */
+            rule: string;
+            /** @description Human-readable description of the problem */
+            message: string;
+        };
         /** @description Login request */
         LoginRequest: {
             /** @description User's email address */
@@ -508,6 +819,8 @@ export interface components {
              * @description Token expiration time in seconds
              */
             expiresIn: number;
+            /** @description Single-use token for getting new tokens from /auth/refresh */
+            refreshToken?: string;
             /** @description Authenticated user information */
             user: {
                 /** @description User ID */
@@ -518,6 +831,11 @@ export interface components {
                 name: string;
             };
         };
+        /** @description Identity provider login that links the account to the current user */
+        OidcLink: {
+            /** @description Authorization URL of the identity provider to send the user to */
+            authorizationUrl: string;
+        };
         /** @description Simple OK response */
         OkResponse: {
             message: string;
@@ -572,6 +890,59 @@ export interface components {
          * @enum {string}
          */
         OrderStatus: "pending" | "processing" | "shipped" | "delivered" | "cancelled";
+        /** @description Password reset confirmation */
+        PasswordResetConfirmRequest: {
+            /** @description Password reset token */
+            token: string;
+            /** @description New password (at least 8 characters) */
+            newPassword: string;
+        };
+        /** @description Password reset request */
+        PasswordResetRequest: {
+            /** @description Email address of the account to reset */
+            email: string;
+        };
+        /** @description Number of products in a price range */
+        PriceRangeFacet: {
+            /**
+             * Format: float
+             * @description Lowest price of the range, inclusive. Absent for the cheapest range.
+             */
+            min?: number;
+            /**
+             * Format: float
+             * @description Highest price of the range, exclusive. Absent for the most expensive range.
+             */
+            max?: number;
+            /**
+             * Format: int32
+             * @description Number of products in the range
+             */
+            count: number;
+        };
+        /** @description RFC 9457 problem details, sent to clients that accept application/problem+json */
+        ProblemDetails: {
+            /**
+             * Format: uri
+             * @description URI identifying the type of problem; there is one per error code
+             */
+            type: string;
+            /** @description Short summary of the type of problem */
+            title: string;
+            /**
+             * Format: int32
+             * @description HTTP status code of the response
+             */
+            status: number;
+            /** @description Human-readable explanation of this occurrence of the problem */
+            detail: string;
+            /** @description Path of the request the problem occurred on */
+            instance: string;
+            /** @description Error code identifying the type of error */
+            code: components["schemas"]["ErrorCode"];
+            /** @description Fields that failed validation, for VALIDATION_ERROR problems */
+            details?: components["schemas"]["FieldError"][];
+        };
         /** @description Product model */
         Product: {
             /** @description Unique identifier for the product */
@@ -590,10 +961,32 @@ export interface components {
              * @description Current stock quantity
              */
             stock: number;
+            /**
+             * Format: int32
+             * @description Quantity held by unexpired cart reservations
+             */
+            reservedStock?: number;
+            /**
+             * Format: int32
+             * @description Quantity that can still be added to a cart (stock minus reservedStock)
+             */
+            availableStock?: number;
             /** @description ID of the category this product belongs to */
             categoryId: components["schemas"]["uuid"];
             /** @description List of product image URLs */
             imageUrls: string[];
+            /**
+             * Format: int32
+             * @description Version of the resource, incremented on every update
+             */
+            version?: number;
+            /**
+             * Format: float
+             * @description Relevance of the product to the name search, only set in search results
+             */
+            score?: number;
+            /** @description Parts of the product that matched the name search, only set in search results */
+            highlight?: components["schemas"]["ProductHighlight"];
             /**
              * Format: date-time
              * @description Timestamp when the resource was created
@@ -605,114 +998,459 @@ export interface components {
              */
             updatedAt: string;
         };
-        /** @description Update cart item request */
-        UpdateCartItemRequest: {
+        /** @description Aggregate counts over the products that match a search */
+        ProductFacets: {
+            /** @description Matching products per category, counting those in descendant categories too. Categories without matching products are left out. */
+            categories?: components["schemas"]["CategoryFacet"][];
+            /** @description Matching products per price range, from the cheapest range up */
+            priceRanges?: components["schemas"]["PriceRangeFacet"][];
+            /** @description Matching products with and without available stock */
+            stock?: components["schemas"]["StockFacet"];
+        };
+        /**
+         * @description Snippets of a product with the words that matched a search wrapped in
+         *     <mark> tags. The snippets are HTML-escaped.
+         */
+        ProductHighlight: {
+            /** @description Name of the product, if it matched */
+            name?: string;
+            /** @description Part of the description around the first match, if it matched */
+            description?: string;
+        };
+        /** @description Page of products, with the facets that were asked for */
+        ProductListResponse: {
+            /** @description Array of items in the current page */
+            items: components["schemas"]["Product"][];
             /**
              * Format: int32
-             * @description New quantity for the cart item
+             * @description Total number of items
              */
-            quantity: number;
+            total: number;
+            /**
+             * Format: int32
+             * @description Maximum number of items per page
+             */
+            limit: number;
+            /**
+             * Format: int32
+             * @description Number of items skipped
+             */
+            offset: number;
+            /** @description Cursor to the page after this one, absent on the last page */
+            nextCursor?: string;
+            /** @description Cursor to the page before this one, absent on the first page */
+            prevCursor?: string;
+            /** @description Counts over all products that match the filters, not just the current page */
+            facets?: components["schemas"]["ProductFacets"];
+        };
+        /** @description Token refresh request */
+        RefreshRequest: {
+            /** @description Refresh token from the login or the previous refresh */
+            refreshToken: string;
+        };
+        /** @description Registration request */
+        RegisterRequest: {
+            /** @description User's email address */
+            email: string;
+            /** @description User's full name */
+            name: string;
+            /** @description User's password (at least 8 characters) */
+            password: string;
+        };
+        /**
+         * @description Role granting access beyond a user's own resources
+         * @enum {string}
+         */
+        Role: "admin";
+        /** @description Active login session of the current user */
+        Session: {
+            /** @description Session ID */
+            id: string;
+            /** @description User agent of the client that last used the session */
+            device?: string;
+            /** @description IP address the session was last used from */
+            ipAddress?: string;
+            /**
+             * Format: date-time
+             * @description When the user logged in
+             */
+            createdAt: string;
+            /**
+             * Format: date-time
+             * @description When the session was last used
+             */
+            lastSeenAt: string;
+            /** @description Whether the request was made with this session's access token */
+            current: boolean;
+        };
+        /** @description Number of products with and without available stock */
+        StockFacet: {
+            /**
+             * Format: int32
+             * @description Number of products with availableStock above zero
+             */
+            inStock: number;
+            /**
+             * Format: int32
+             * @description Number of products with no availableStock
+             */
+            outOfStock: number;
+        };
+        /** @description Update cart item request */
+        UpdateCartItemRequest: {
+            /**
+             * Format: int32
+             * @description New quantity for the cart item
+             */
+            quantity: number;
+        };
+        /** @description Category update request */
+        UpdateCategoryRequest: {
+            /** @description Updated name of the category */
+            name?: string;
+            /** @description Updated parent category ID */
+            parentId?: components["schemas"]["uuid"];
+        };
+        /** @description Update order status request */
+        UpdateOrderStatusRequest: {
+            /** @description New status for the order */
+            status: components["schemas"]["OrderStatus"];
+        };
+        /** @description Product update request */
+        UpdateProductRequest: {
+            /** @description Updated name of the product */
+            name?: string;
+            /** @description Updated description of the product */
+            description?: string;
+            /**
+             * Format: float
+             * @description Updated price of the product
+             */
+            price?: number;
+            /**
+             * Format: int32
+             * @description Updated stock quantity
+             */
+            stock?: number;
+            /** @description Updated category ID */
+            categoryId?: components["schemas"]["uuid"];
+            /** @description Updated list of product image URLs */
+            imageUrls?: string[];
+        };
+        /** @description User update request */
+        UpdateUserRequest: {
+            /**
+             * Format: email
+             * @description Updated email address
+             */
+            email?: string;
+            /** @description Updated user name */
+            name?: string;
+            /** @description Updated shipping address */
+            address?: components["schemas"]["Address"];
+        };
+        /** @description User model */
+        User: {
+            /** @description Unique identifier for the user */
+            id: components["schemas"]["uuid"];
+            /** @description User's email address */
+            email: string;
+            /** @description User's full name */
+            name: string;
+            /** @description User's shipping address */
+            address?: components["schemas"]["Address"];
+            /**
+             * Format: int32
+             * @description Version of the resource, incremented on every update
+             */
+            version?: number;
+            /**
+             * Format: date-time
+             * @description Timestamp when the resource was created
+             */
+            createdAt: string;
+            /**
+             * Format: date-time
+             * @description Timestamp when the resource was last updated
+             */
+            updatedAt: string;
+        };
+        /** @description UUID type alias */
+        uuid: string;
+    };
+    responses: never;
+    parameters: {
+        /** @description End date for order creation */
+        "OrderSearchParams.endDate": string;
+        /** @description Start date for order creation */
+        "OrderSearchParams.startDate": string;
+        /** @description Filter by order status */
+        "OrderSearchParams.status": components["schemas"]["OrderStatus"];
+        /** @description Filter by user ID */
+        "OrderSearchParams.userId": components["schemas"]["uuid"];
+        /** @description Opaque cursor from the nextCursor or prevCursor of a previous page. It selects the page instead of offset. */
+        "PaginationParams.cursor": string;
+        /** @description Maximum number of items to return */
+        "PaginationParams.limit": number;
+        /** @description Number of items to skip */
+        "PaginationParams.offset": number;
+        /** @description Filter by category ID */
+        "ProductSearchParams.categoryId": components["schemas"]["uuid"];
+        /** @description Facets to count over all products that match the filters, e.g. facets=category,price */
+        "ProductSearchParams.facets": ("category" | "price" | "stock")[];
+        /** @description Also match products in the subcategories of categoryId, at any depth */
+        "ProductSearchParams.includeDescendants": boolean;
+        /** @description Maximum price */
+        "ProductSearchParams.maxPrice": number;
+        /** @description Minimum price */
+        "ProductSearchParams.minPrice": number;
+        /** @description Full-text search over product names and descriptions. Words are matched by stem, by prefix and with typos, and every word must match. */
+        "ProductSearchParams.name": string;
+        /** @description Sort order */
+        "ProductSearchParams.order": "asc" | "desc";
+        /** @description Sort field. relevance sorts by score, and is the default when searching by name. */
+        "ProductSearchParams.sortBy": "name" | "price" | "createdAt" | "relevance";
+    };
+    requestBodies: never;
+    headers: never;
+    pathItems: never;
+}
+export type $defs = Record<string, never>;
+export interface operations {
+    AuthService_login: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["LoginRequest"];
+            };
+        };
+        responses: {
+            /** @description The request has succeeded. */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["LoginResponse"] | components["schemas"]["ErrorResponse"];
+                };
+            };
+        };
+    };
+    AuthService_logout: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description The request has succeeded. */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["OkResponse"] | components["schemas"]["ErrorResponse"];
+                };
+            };
+        };
+    };
+    AuthService_getCurrentUser: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description The request has succeeded. */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["AuthUser"] | components["schemas"]["ErrorResponse"];
+                };
+            };
+        };
+    };
+    AuthService_register: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["RegisterRequest"];
+            };
+        };
+        responses: {
+            /** @description The request has succeeded. */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["AuthUser"] | components["schemas"]["ErrorResponse"];
+                };
+            };
+        };
+    };
+    AuthService_changePassword: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["ChangePasswordRequest"];
+            };
+        };
+        responses: {
+            /** @description The request has succeeded. */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["OkResponse"] | components["schemas"]["ErrorResponse"];
+                };
+            };
+        };
+    };
+    AuthService_requestPasswordReset: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["PasswordResetRequest"];
+            };
+        };
+        responses: {
+            /** @description The request has succeeded. */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["OkResponse"] | components["schemas"]["ErrorResponse"];
+                };
+            };
+        };
+    };
+    AuthService_confirmPasswordReset: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["PasswordResetConfirmRequest"];
+            };
         };
-        /** @description Category update request */
-        UpdateCategoryRequest: {
-            /** @description Updated name of the category */
-            name?: string;
-            /** @description Updated parent category ID */
-            parentId?: components["schemas"]["uuid"];
+        responses: {
+            /** @description The request has succeeded. */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["OkResponse"] | components["schemas"]["ErrorResponse"];
+                };
+            };
         };
-        /** @description Update order status request */
-        UpdateOrderStatusRequest: {
-            /** @description New status for the order */
-            status: components["schemas"]["OrderStatus"];
+    };
+    AuthService_refresh: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
         };
-        /** @description Product update request */
-        UpdateProductRequest: {
-            /** @description Updated name of the product */
-            name?: string;
-            /** @description Updated description of the product */
-            description?: string;
-            /**
-             * Format: float
-             * @description Updated price of the product
-             */
-            price?: number;
-            /**
-             * Format: int32
-             * @description Updated stock quantity
-             */
-            stock?: number;
-            /** @description Updated category ID */
-            categoryId?: components["schemas"]["uuid"];
-            /** @description Updated list of product image URLs */
-            imageUrls?: string[];
+        requestBody: {
+            content: {
+                "application/json": components["schemas"]["RefreshRequest"];
+            };
         };
-        /** @description User update request */
-        UpdateUserRequest: {
-            /** @description Updated email address */
-            email?: string;
-            /** @description Updated user name */
-            name?: string;
-            /** @description Updated shipping address */
-            address?: components["schemas"]["Address"];
+        responses: {
+            /** @description The request has succeeded. */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["LoginResponse"] | components["schemas"]["ErrorResponse"];
+                };
+            };
         };
-        /** @description User model */
-        User: {
-            /** @description Unique identifier for the user */
-            id: components["schemas"]["uuid"];
-            /** @description User's email address */
-            email: string;
-            /** @description User's full name */
-            name: string;
-            /** @description User's shipping address */
-            address?: components["schemas"]["Address"];
-            /**
-             * Format: date-time
-             * @description Timestamp when the resource was created
-             */
-            createdAt: string;
-            /**
-             * Format: date-time
-             * @description Timestamp when the resource was last updated
-             */
-            updatedAt: string;
+    };
+    AuthService_listSessions: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description The request has succeeded. */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["Session"][] | components["schemas"]["ErrorResponse"];
+                };
+            };
         };
-        /** @description UUID type alias */
-        uuid: string;
     };
-    responses: never;
-    parameters: {
-        /** @description End date for order creation */
-        "OrderSearchParams.endDate": string;
-        /** @description Start date for order creation */
-        "OrderSearchParams.startDate": string;
-        /** @description Filter by order status */
-        "OrderSearchParams.status": components["schemas"]["OrderStatus"];
-        /** @description Filter by user ID */
-        "OrderSearchParams.userId": components["schemas"]["uuid"];
-        /** @description Maximum number of items to return */
-        "PaginationParams.limit": number;
-        /** @description Number of items to skip */
-        "PaginationParams.offset": number;
-        /** @description Filter by category ID */
-        "ProductSearchParams.categoryId": components["schemas"]["uuid"];
-        /** @description Maximum price */
-        "ProductSearchParams.maxPrice": number;
-        /** @description Minimum price */
-        "ProductSearchParams.minPrice": number;
-        /** @description Search by product name */
-        "ProductSearchParams.name": string;
-        /** @description Sort order */
-        "ProductSearchParams.order": "asc" | "desc";
-        /** @description Sort field */
-        "ProductSearchParams.sortBy": "name" | "price" | "createdAt";
+    AuthService_revokeSession: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path: {
+                sessionId: string;
+            };
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description The request has succeeded. */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["ErrorResponse"];
+                };
+            };
+            /** @description There is no content to send for this request, but the headers may be useful. */
+            204: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+        };
     };
-    requestBodies: never;
-    headers: never;
-    pathItems: never;
-}
-export type $defs = Record<string, never>;
-export interface operations {
-    AuthService_login: {
+    AuthService_createApiKey: {
         parameters: {
             query?: never;
             header?: never;
@@ -721,7 +1459,7 @@ export interface operations {
         };
         requestBody: {
             content: {
-                "application/json": components["schemas"]["LoginRequest"];
+                "application/json": components["schemas"]["CreateApiKeyRequest"];
             };
         };
         responses: {
@@ -731,12 +1469,12 @@ export interface operations {
                     [name: string]: unknown;
                 };
                 content: {
-                    "application/json": components["schemas"]["LoginResponse"] | components["schemas"]["ErrorResponse"];
+                    "application/json": components["schemas"]["CreatedApiKey"] | components["schemas"]["ErrorResponse"];
                 };
             };
         };
     };
-    AuthService_logout: {
+    AuthService_listApiKeys: {
         parameters: {
             query?: never;
             header?: never;
@@ -751,12 +1489,41 @@ export interface operations {
                     [name: string]: unknown;
                 };
                 content: {
-                    "application/json": components["schemas"]["OkResponse"] | components["schemas"]["ErrorResponse"];
+                    "application/json": components["schemas"]["ApiKey"][] | components["schemas"]["ErrorResponse"];
                 };
             };
         };
     };
-    AuthService_getCurrentUser: {
+    AuthService_revokeApiKey: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path: {
+                keyId: string;
+            };
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description The request has succeeded. */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["ErrorResponse"];
+                };
+            };
+            /** @description There is no content to send for this request, but the headers may be useful. */
+            204: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+        };
+    };
+    AuthService_oidcLogin: {
         parameters: {
             query?: never;
             header?: never;
@@ -771,7 +1538,63 @@ export interface operations {
                     [name: string]: unknown;
                 };
                 content: {
-                    "application/json": components["schemas"]["AuthUser"] | components["schemas"]["ErrorResponse"];
+                    "application/json": components["schemas"]["ErrorResponse"];
+                };
+            };
+            /** @description Redirection */
+            302: {
+                headers: {
+                    /** @description Authorization URL of the identity provider */
+                    location: string;
+                    [name: string]: unknown;
+                };
+                content?: never;
+            };
+        };
+    };
+    AuthService_oidcLink: {
+        parameters: {
+            query?: never;
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description The request has succeeded. */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["OidcLink"] | components["schemas"]["ErrorResponse"];
+                };
+            };
+        };
+    };
+    AuthService_oidcCallback: {
+        parameters: {
+            query: {
+                /** @description Authorization code issued by the identity provider */
+                code?: string;
+                /** @description State of the login being completed */
+                state: string;
+                /** @description Error reported by the identity provider instead of a code */
+                error?: string;
+            };
+            header?: never;
+            path?: never;
+            cookie?: never;
+        };
+        requestBody?: never;
+        responses: {
+            /** @description The request has succeeded. */
+            200: {
+                headers: {
+                    [name: string]: unknown;
+                };
+                content: {
+                    "application/json": components["schemas"]["LoginResponse"] | components["schemas"]["ErrorResponse"];
                 };
             };
         };
@@ -1051,6 +1874,8 @@ export interface operations {
                 limit?: components["parameters"]["PaginationParams.limit"];
                 /** @description Number of items to skip */
                 offset?: components["parameters"]["PaginationParams.offset"];
+                /** @description Opaque cursor from the nextCursor or prevCursor of a previous page. It selects the page instead of offset. */
+                cursor?: components["parameters"]["PaginationParams.cursor"];
                 /** @description Filter by order status */
                 status?: components["parameters"]["OrderSearchParams.status"];
                 /** @description Filter by user ID */
@@ -1090,6 +1915,10 @@ export interface operations {
                          * @description Number of items skipped
                          */
                         offset: number;
+                        /** @description Cursor to the page after this one, absent on the last page */
+                        nextCursor?: string;
+                        /** @description Cursor to the page before this one, absent on the first page */
+                        prevCursor?: string;
                     } | components["schemas"]["ErrorResponse"];
                 };
             };
@@ -1150,6 +1979,8 @@ export interface operations {
                 limit?: components["parameters"]["PaginationParams.limit"];
                 /** @description Number of items to skip */
                 offset?: components["parameters"]["PaginationParams.offset"];
+                /** @description Opaque cursor from the nextCursor or prevCursor of a previous page. It selects the page instead of offset. */
+                cursor?: components["parameters"]["PaginationParams.cursor"];
             };
             header?: never;
             path: {
@@ -1183,6 +2014,10 @@ export interface operations {
                          * @description Number of items skipped
                          */
                         offset: number;
+                        /** @description Cursor to the page after this one, absent on the last page */
+                        nextCursor?: string;
+                        /** @description Cursor to the page before this one, absent on the first page */
+                        prevCursor?: string;
                     } | components["schemas"]["ErrorResponse"];
                 };
             };
@@ -1243,18 +2078,24 @@ export interface operations {
                 limit?: components["parameters"]["PaginationParams.limit"];
                 /** @description Number of items to skip */
                 offset?: components["parameters"]["PaginationParams.offset"];
-                /** @description Search by product name */
+                /** @description Opaque cursor from the nextCursor or prevCursor of a previous page. It selects the page instead of offset. */
+                cursor?: components["parameters"]["PaginationParams.cursor"];
+                /** @description Full-text search over product names and descriptions. Words are matched by stem, by prefix and with typos, and every word must match. */
                 name?: components["parameters"]["ProductSearchParams.name"];
                 /** @description Filter by category ID */
                 categoryId?: components["parameters"]["ProductSearchParams.categoryId"];
+                /** @description Also match products in the subcategories of categoryId, at any depth */
+                includeDescendants?: components["parameters"]["ProductSearchParams.includeDescendants"];
                 /** @description Minimum price */
                 minPrice?: components["parameters"]["ProductSearchParams.minPrice"];
                 /** @description Maximum price */
                 maxPrice?: components["parameters"]["ProductSearchParams.maxPrice"];
-                /** @description Sort field */
+                /** @description Sort field. relevance sorts by score, and is the default when searching by name. */
                 sortBy?: components["parameters"]["ProductSearchParams.sortBy"];
                 /** @description Sort order */
                 order?: components["parameters"]["ProductSearchParams.order"];
+                /** @description Facets to count over all products that match the filters, e.g. facets=category,price */
+                facets?: components["parameters"]["ProductSearchParams.facets"];
             };
             header?: never;
             path?: never;
@@ -1268,25 +2109,7 @@ export interface operations {
                     [name: string]: unknown;
                 };
                 content: {
-                    "application/json": {
-                        /** @description Array of items in the current page */
-                        items: components["schemas"]["Product"][];
-                        /**
-                         * Format: int32
-                         * @description Total number of items
-                         */
-                        total: number;
-                        /**
-                         * Format: int32
-                         * @description Maximum number of items per page
-                         */
-                        limit: number;
-                        /**
-                         * Format: int32
-                         * @description Number of items skipped
-                         */
-                        offset: number;
-                    } | components["schemas"]["ErrorResponse"];
+                    "application/json": components["schemas"]["ProductListResponse"] | components["schemas"]["ErrorResponse"];
                 };
             };
         };
@@ -1399,6 +2222,8 @@ export interface operations {
                 limit?: components["parameters"]["PaginationParams.limit"];
                 /** @description Number of items to skip */
                 offset?: components["parameters"]["PaginationParams.offset"];
+                /** @description Opaque cursor from the nextCursor or prevCursor of a previous page. It selects the page instead of offset. */
+                cursor?: components["parameters"]["PaginationParams.cursor"];
             };
             header?: never;
             path?: never;
@@ -1430,6 +2255,10 @@ export interface operations {
                          * @description Number of items skipped
                          */
                         offset: number;
+                        /** @description Cursor to the page after this one, absent on the last page */
+                        nextCursor?: string;
+                        /** @description Cursor to the page before this one, absent on the first page */
+                        prevCursor?: string;
                     } | components["schemas"]["ErrorResponse"];
                 };
             };